@@ -74,6 +74,10 @@ module.exports = [
                         text: 'File extractor',
                         link: '/guide/services/file_extractor',
                     },
+                    {
+                        text: 'File validator',
+                        link: '/guide/services/file_validator',
+                    },
                     {
                         text: 'Localizer',
                         link: '/guide/services/localizer',
@@ -98,6 +102,10 @@ module.exports = [
                         text: 'Password',
                         link: '/guide/services/password',
                     },
+                    {
+                        text: 'Push',
+                        link: '/guide/services/push',
+                    },
                     {
                         text: 'HTML2PDF',
                         link: '/guide/services/html2pdf',
@@ -129,6 +137,10 @@ module.exports = [
                         text: 'Exporter',
                         link: '/guide/services/exporter',
                     },
+                    {
+                        text: 'Importer',
+                        link: '/guide/services/importer',
+                    },
                     {
                         text: 'Feature flags',
                         link: '/guide/services/feature_flag',
@@ -203,6 +215,22 @@ module.exports = [
                         text: 'Dataloaders',
                         link: '/guide/graphql/dataloaders',
                     },
+                    {
+                        text: 'Query limits',
+                        link: '/guide/graphql/limits',
+                    },
+                    {
+                        text: 'Persisted queries',
+                        link: '/guide/graphql/persisted_queries',
+                    },
+                    {
+                        text: 'Subscriptions',
+                        link: '/guide/graphql/subscriptions',
+                    },
+                    {
+                        text: 'Federation',
+                        link: '/guide/graphql/federation',
+                    },
                 ],
             },
         ],
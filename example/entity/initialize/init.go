@@ -19,6 +19,7 @@ func Init(registry *beeorm.Registry) {
 		&entity2.AdminUserEntity{},
 		&entity2.DevPanelUserEntity{},
 		&entity.FileEntity{},
+		&entity.FileReferenceEntity{},
 		&entity.SmsTrackerEntity{},
 		&entity.OTPTrackerEntity{},
 		&entity.FeatureFlagEntity{},
@@ -27,6 +28,11 @@ func Init(registry *beeorm.Registry) {
 		&entity.ResourceEntity{},
 		&entity.PrivilegeEntity{},
 		&entity.PermissionEntity{},
+		&entity.DeviceTokenEntity{},
+		&entity.FCMTopicSubscriptionEntity{},
+		&entity.ExportJobEntity{},
+		&entity.ImportJobEntity{},
+		&entity.SavedViewEntity{},
 	)
 
 	registry.RegisterEnumStruct("entity.FileStatusAll", entity.FileStatusAll)
@@ -34,9 +40,13 @@ func Init(registry *beeorm.Registry) {
 	registry.RegisterEnumStruct("entity.APILogStatusAll", entity2.APILogStatusAll)
 	registry.RegisterEnumStruct("entity.APILogStatusAll", entity2.APILogStatusAll)
 	registry.RegisterEnumStruct("entity.SMSTrackerTypeAll", entity.SMSTrackerTypeAll)
+	registry.RegisterEnumStruct("entity.SMSTrackerDeliveryStatusAll", entity.SMSTrackerDeliveryStatusAll)
 	registry.RegisterEnumStruct("entity.OTPTrackerTypeAll", entity.OTPTrackerTypeAll)
 	registry.RegisterEnumStruct("entity.OTPTrackerGatewaySendStatusAll", entity.OTPTrackerGatewaySendStatusAll)
 	registry.RegisterEnumStruct("entity.OTPTrackerGatewayVerifyStatusAll", entity.OTPTrackerGatewayVerifyStatusAll)
+	registry.RegisterEnumStruct("entity.DeviceTokenPlatformAll", entity.DeviceTokenPlatformAll)
+	registry.RegisterEnumStruct("entity.ExportJobStatusAll", entity.ExportJobStatusAll)
+	registry.RegisterEnumStruct("entity.ImportJobStatusAll", entity.ImportJobStatusAll)
 
 	registry.RegisterPlugin(crud_stream.Init(nil))
 	registry.RegisterPlugin(fake_delete.Init(nil))
@@ -0,0 +1,34 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/coretrix/hitrix/example/entity"
+	"github.com/coretrix/hitrix/example/graph/generated"
+	"github.com/coretrix/hitrix/example/graph/model"
+	"github.com/coretrix/hitrix/pkg/errors"
+	"github.com/coretrix/hitrix/pkg/graphql/federation"
+	"github.com/coretrix/hitrix/pkg/view/account"
+	"github.com/coretrix/hitrix/service"
+)
+
+// FindUserByID is the resolver for the findUserByID field.
+func (r *entityResolver) FindUserByID(ctx context.Context, id string) (*model.User, error) {
+	// users are exposed to logged dev panel users only
+	if _, ok := service.GinFromContext(ctx).Get(account.LoggedDevPanelUserEntity); !ok {
+		return nil, &errors.PermissionError{}
+	}
+
+	return federation.ResolveReference[*entity.DevPanelUserEntity](ctx, id, func(user *entity.DevPanelUserEntity) *model.User {
+		return &model.User{ID: strconv.FormatUint(user.ID, 10), Name: user.Email}
+	})
+}
+
+// Entity returns generated.EntityResolver implementation.
+func (r *Resolver) Entity() generated.EntityResolver { return &entityResolver{r} }
+
+type entityResolver struct{ *Resolver }
@@ -18,3 +18,5 @@ type User struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
 }
+
+func (User) IsEntity() {}
@@ -1,15 +1,54 @@
 package middleware
 
 import (
+	"context"
+
 	"github.com/gin-gonic/gin"
+	"github.com/latolukasz/beeorm/v2"
 
+	"github.com/coretrix/hitrix/datalayer"
 	"github.com/coretrix/hitrix/example/rest/controller"
+	"github.com/coretrix/hitrix/pkg/entity"
 	"github.com/coretrix/hitrix/pkg/middleware"
+	"github.com/coretrix/hitrix/pkg/view/account"
+	"github.com/coretrix/hitrix/service"
+	"github.com/coretrix/hitrix/service/component/crud"
 )
 
+type featureFlagRequest struct {
+	Name    string `binding:"required,max=100"`
+	Enabled *bool
+}
+
 func Router(ginEngine *gin.Engine) {
 	var websocketController *controller.WebsocketController
 	ginEngine.GET("/ws/", websocketController.InitConnection)
 
 	middleware.ACLRouter(ginEngine)
+
+	crud.ResourceFor(&entity.FeatureFlagEntity{}, &crud.ResourceOptions{
+		ORM: func(ctx context.Context) *datalayer.ORM {
+			return service.DI().OrmEngineForContext(ctx)
+		},
+		// feature flags are managed by dev panel users only
+		ACL: func(c *gin.Context, _ *datalayer.ORM, _, _ string) bool {
+			_, ok := c.Get(account.LoggedDevPanelUserEntity)
+
+			return ok
+		},
+		NewRequest: func() interface{} {
+			return &featureFlagRequest{}
+		},
+		BeforeSave: func(_ *gin.Context, _ *datalayer.ORM, savedEntity beeorm.Entity, _ interface{}) error {
+			featureFlagEntity := savedEntity.(*entity.FeatureFlagEntity)
+			if featureFlagEntity.ID == 0 {
+				featureFlagEntity.CreatedAt = service.DI().Clock().Now()
+			}
+
+			return nil
+		},
+		Fields: []string{"Name", "Registered", "Enabled", "UpdatedAt", "CreatedAt"},
+		Facets: true,
+		Delete: true,
+	}).Register(ginEngine.Group("/v1/feature-flags", middleware.AuthorizeDevUser()))
 }
@@ -17,10 +17,15 @@ import (
 	"github.com/gin-contrib/timeout"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
+	"github.com/vektah/gqlparser/v2/gqlerror"
 
 	hitrixBinding "github.com/coretrix/hitrix/pkg/binding"
+	"github.com/coretrix/hitrix/pkg/graphql/federation"
+	"github.com/coretrix/hitrix/pkg/graphql/limit"
 	"github.com/coretrix/hitrix/pkg/middleware"
 	"github.com/coretrix/hitrix/service"
+	persistedquery "github.com/coretrix/hitrix/service/component/persisted_query"
+	"github.com/coretrix/hitrix/service/component/subscription"
 )
 
 type GinInitHandler func(ginEngine *gin.Engine)
@@ -63,21 +68,26 @@ func InitGin(server graphql.ExecutableSchema, ginInitHandler GinInitHandler, gql
 		ginInitHandler(ginEngine)
 	}
 
+	// after GinInitHandler, so Clockwork middleware registered there is available to log loaders stats
+	middleware.DataLoaders(ginEngine)
+
 	if app.DevPanel != nil {
 		devRouter := app.DevPanel.Router
 		devRouter(ginEngine)
 	}
 
 	if server != nil {
+		gqlHandler := graphqlHandler(server, gqlServerInitHandler)
+
 		var queryHandler gin.HandlerFunc
 		if app.IsInLocalMode() || app.IsInTestMode() {
-			queryHandler = graphqlHandler(server, gqlServerInitHandler)
+			queryHandler = gqlHandler
 		} else {
 			timeoutSecs := service.DI().Config().DefInt64("server.timeout_sec", 10)
 
 			queryHandler = timeout.New(
 				timeout.WithTimeout(time.Duration(timeoutSecs)*time.Second),
-				timeout.WithHandler(graphqlHandler(server, gqlServerInitHandler)),
+				timeout.WithHandler(gqlHandler),
 				timeout.WithResponse(func(c *gin.Context) {
 					service.DI().ErrorLogger().LogErrorWithRequest(c, "TIMEOUT ERROR")
 				}),
@@ -86,6 +96,11 @@ func InitGin(server graphql.ExecutableSchema, ginInitHandler GinInitHandler, gql
 
 		ginEngine.POST("/query", queryHandler)
 
+		if service.HasService(service.SubscriptionBrokerService) {
+			// websocket connections of subscriptions live longer than the timeout
+			ginEngine.GET("/query", gqlHandler)
+		}
+
 		if app.IsInProdMode() {
 			ginEngine.GET("/", middleware.AuthorizeWithQueryParam(), playgroundHandler())
 		} else {
@@ -101,18 +116,55 @@ func InitGin(server graphql.ExecutableSchema, ginInitHandler GinInitHandler, gql
 func graphqlHandler(server graphql.ExecutableSchema, gqlServerInitHandler GQLServerInitHandler) gin.HandlerFunc {
 	h := handler.New(server)
 
-	h.AddTransport(transport.Websocket{
+	websocketTransport := transport.Websocket{
 		KeepAlivePingInterval: 10 * time.Second,
-	})
+	}
+
+	if service.HasService(service.SubscriptionBrokerService) {
+		broker := service.DI().SubscriptionBroker()
+
+		websocketTransport.InitFunc = func(ctx context.Context, initPayload transport.InitPayload) (context.Context, error) {
+			if ginContext, ok := ctx.Value(service.GinKey).(*gin.Context); ok {
+				ctx = subscription.WithClientIP(ctx, ginContext.ClientIP())
+			}
+
+			return broker.InitWebsocket(ctx, initPayload)
+		}
+	}
+
+	h.AddTransport(websocketTransport)
 	h.AddTransport(transport.Options{})
 	h.AddTransport(transport.POST{})
 
 	h.SetQueryCache(lru.New(1000))
 
 	h.Use(extension.Introspection{})
-	h.Use(extension.AutomaticPersistedQuery{
-		Cache: lru.New(100),
-	})
+	if service.HasService(service.PersistedQueryService) {
+		persistedQueryService := service.DI().PersistedQuery()
+
+		if persistedQueryService.AllowList() {
+			isSubgraph := federation.IsSubgraph(server)
+			allowEntities, _ := service.DI().Config().Bool("server.graphql.persisted_queries.allow_entities")
+
+			h.Use(&persistedquery.AllowList{
+				PersistedQuery:  persistedQueryService,
+				OnReject:        logGraphqlRejection,
+				AllowServiceSDL: isSubgraph,
+				AllowEntities:   isSubgraph && allowEntities,
+			})
+		} else {
+			h.Use(extension.AutomaticPersistedQuery{
+				Cache: persistedQueryService.APQCache(),
+			})
+		}
+	} else {
+		h.Use(extension.AutomaticPersistedQuery{
+			Cache: lru.New(100),
+		})
+	}
+
+	useGraphqlLimits(h)
+
 	h.SetRecoverFunc(func(ctx context.Context, err interface{}) error {
 		var message string
 		asErr, is := err.(error)
@@ -140,6 +192,61 @@ func graphqlHandler(server graphql.ExecutableSchema, gqlServerInitHandler GQLSer
 	}
 }
 
+// useGraphqlLimits adds depth and complexity limits from server.graphql config, limits are disabled when not set
+func useGraphqlLimits(h *handler.Server) {
+	configService := service.DI().Config()
+
+	if maxDepth := configService.DefInt("server.graphql.max_depth", 0); maxDepth > 0 {
+		h.Use(&limit.DepthLimit{MaxDepth: maxDepth, OnReject: logGraphqlRejection})
+	}
+
+	maxComplexity := configService.DefInt("server.graphql.max_complexity", 0)
+	clientBudget := configService.DefInt("server.graphql.client_complexity_budget", 0)
+
+	if maxComplexity <= 0 && clientBudget <= 0 {
+		return
+	}
+
+	complexityLimit := &limit.ComplexityLimit{MaxComplexity: maxComplexity, OnReject: logGraphqlRejection}
+
+	if clientBudget > 0 {
+		complexityLimit.Budget = &limit.Budget{
+			Limit:  clientBudget,
+			Window: time.Duration(configService.DefInt("server.graphql.client_complexity_window_sec", 60)) * time.Second,
+			Store: &limit.RedisBudgetStore{
+				Redis: service.DI().OrmEngine().GetRedis(),
+				Clock: service.DI().Clock(),
+			},
+			ClientFunc: func(ctx context.Context) string {
+				ginContext, ok := ctx.Value(service.GinKey).(*gin.Context)
+				if !ok {
+					return ""
+				}
+
+				return ginContext.ClientIP()
+			},
+		}
+	}
+
+	h.Use(complexityLimit)
+}
+
+func logGraphqlRejection(ctx context.Context, operationName string, err *gqlerror.Error) {
+	rejection := fmt.Errorf("graphql operation %s rejected: %s", operationName, err.Message)
+
+	if ginContext, ok := ctx.Value(service.GinKey).(*gin.Context); ok {
+		if requestBody, ok := ctx.Value(service.RequestBodyKey).([]byte); ok {
+			ginContext.Request.Body = io.NopCloser(bytes.NewReader(requestBody))
+		}
+
+		service.DI().ErrorLogger().LogErrorWithRequest(ginContext, rejection)
+
+		return
+	}
+
+	service.DI().ErrorLogger().LogError(rejection)
+}
+
 func playgroundHandler() gin.HandlerFunc {
 	h := playground.Handler("GraphQL", "/query")
 
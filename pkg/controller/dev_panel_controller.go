@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -23,6 +24,7 @@ import (
 	"github.com/coretrix/hitrix/pkg/view/requestlogger"
 	"github.com/coretrix/hitrix/service"
 	"github.com/coretrix/hitrix/service/component/app"
+	persistedquery "github.com/coretrix/hitrix/service/component/persisted_query"
 )
 
 type MenuItem struct {
@@ -435,6 +437,42 @@ func (controller *DevPanelController) PostDisableFeatureFlag(c *gin.Context) {
 	response.SuccessResponse(c, nil)
 }
 
+func (controller *DevPanelController) GetSMSInbox(c *gin.Context) {
+	response.SuccessResponse(c, service.DI().SMSFake().Inbox(c.Query("number")))
+}
+
+func (controller *DevPanelController) DeleteSMSInbox(c *gin.Context) {
+	service.DI().SMSFake().Clear()
+
+	response.SuccessResponse(c, nil)
+}
+
+func (controller *DevPanelController) PostSMSFailure(c *gin.Context) {
+	number := c.Param("number")
+	if number == "" {
+		response.ErrorResponseGlobal(c, "number is required", nil)
+
+		return
+	}
+
+	times, err := strconv.Atoi(c.DefaultQuery("times", "-1"))
+	if err != nil {
+		response.ErrorResponseGlobal(c, "times must be a number", nil)
+
+		return
+	}
+
+	fakeSMSService := service.DI().SMSFake()
+
+	if times == 0 {
+		fakeSMSService.StopFailing(number)
+	} else {
+		fakeSMSService.FailNextSends(number, times)
+	}
+
+	response.SuccessResponse(c, nil)
+}
+
 func (controller *DevPanelController) GetEnvValues(c *gin.Context) {
 	response.SuccessResponse(c, map[string]interface{}{"list": os.Environ()})
 }
@@ -454,3 +492,27 @@ func (controller *DevPanelController) PostRequestsLogger(c *gin.Context) {
 
 	response.SuccessResponse(c, res)
 }
+
+func (controller *DevPanelController) GetPersistedQueries(c *gin.Context) {
+	persistedQueryService := service.DI().PersistedQuery()
+
+	type persistedQueries struct {
+		AllowList  bool
+		Operations []*persistedquery.Operation
+	}
+
+	response.SuccessResponse(c, &persistedQueries{
+		AllowList:  persistedQueryService.AllowList(),
+		Operations: persistedQueryService.Operations(),
+	})
+}
+
+func (controller *DevPanelController) PostReloadPersistedQueries(c *gin.Context) {
+	if err := service.DI().PersistedQuery().Reload(); err != nil {
+		response.ErrorResponseGlobal(c, err, nil)
+
+		return
+	}
+
+	response.SuccessResponse(c, nil)
+}
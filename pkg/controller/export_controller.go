@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/coretrix/hitrix/pkg/binding"
+	"github.com/coretrix/hitrix/pkg/dto/export"
+	errorhandling "github.com/coretrix/hitrix/pkg/error_handling"
+	exportModel "github.com/coretrix/hitrix/pkg/model/export"
+	"github.com/coretrix/hitrix/pkg/response"
+)
+
+type ExportController struct {
+	// GetUserIDFunc returns ID of the logged user, who is notified when the export is finished
+	GetUserIDFunc func(c *gin.Context) uint64
+}
+
+// @Description Start async crud export, files are generated by export job consumer
+// @Tags Export
+// @Param body body export.RequestDTOExportJob true "Request in body"
+// @Router /export-job/ [post]
+// @Security BearerAuth
+// @Success 200 {object} export.ExportJob
+// @Failure 400 {object} response.Error
+// @Failure 500 "Something bad happened"
+func (controller *ExportController) PostCreateExportJobAction(c *gin.Context) {
+	request := &export.RequestDTOExportJob{}
+
+	err := binding.ShouldBindJSON(c, request)
+	if errorhandling.HandleError(c, err) {
+		return
+	}
+
+	exportJob, err := exportModel.CreateExportJob(c.Request.Context(), controller.GetUserIDFunc(c), request)
+	if errorhandling.HandleError(c, err) {
+		return
+	}
+
+	response.SuccessResponse(c, exportJob)
+}
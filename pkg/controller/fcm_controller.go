@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/coretrix/hitrix/pkg/binding"
+	"github.com/coretrix/hitrix/pkg/dto/fcm"
+	errorhandling "github.com/coretrix/hitrix/pkg/error_handling"
+	"github.com/coretrix/hitrix/pkg/response"
+	"github.com/coretrix/hitrix/service"
+)
+
+type FCMController struct {
+	// GetUserIDFunc returns ID of the logged user, device tokens are owned by users
+	GetUserIDFunc func(c *gin.Context) uint64
+}
+
+// @Description Register device token of the logged user and subscribe it to topics of the user
+// @Tags FCM
+// @Param body body fcm.RequestDTORegisterDeviceToken true "Request in body"
+// @Router /fcm/device-token/ [post]
+// @Security BearerAuth
+// @Success 200
+// @Failure 400 {object} response.Error
+// @Failure 500 "Something bad happened"
+func (controller *FCMController) PostRegisterDeviceTokenAction(c *gin.Context) {
+	request := &fcm.RequestDTORegisterDeviceToken{}
+
+	err := binding.ShouldBindJSON(c, request)
+	if errorhandling.HandleError(c, err) {
+		return
+	}
+
+	ormService := service.DI().OrmEngineForContext(c.Request.Context())
+	deviceRegistry := service.DI().FCMDeviceRegistry()
+	userID := controller.GetUserIDFunc(c)
+
+	_, err = deviceRegistry.Register(c.Request.Context(), ormService, userID, request.Platform, request.Token, request.AppVersion)
+	if err != nil {
+		response.ErrorResponseGlobal(c, err, nil)
+
+		return
+	}
+
+	err = deviceRegistry.ResyncUserTopics(c.Request.Context(), ormService, userID)
+	if err != nil {
+		response.ErrorResponseGlobal(c, err, nil)
+
+		return
+	}
+
+	response.SuccessResponse(c, nil)
+}
+
+// @Description Unregister device token of the logged user, tokens of other users are ignored
+// @Tags FCM
+// @Param body body fcm.RequestDTOUnregisterDeviceToken true "Request in body"
+// @Router /fcm/device-token/ [delete]
+// @Security BearerAuth
+// @Success 200
+// @Failure 400 {object} response.Error
+// @Failure 500 "Something bad happened"
+func (controller *FCMController) DeleteDeviceTokenAction(c *gin.Context) {
+	request := &fcm.RequestDTOUnregisterDeviceToken{}
+
+	err := binding.ShouldBindJSON(c, request)
+	if errorhandling.HandleError(c, err) {
+		return
+	}
+
+	ormService := service.DI().OrmEngineForContext(c.Request.Context())
+	deviceRegistry := service.DI().FCMDeviceRegistry()
+
+	for _, deviceTokenEntity := range deviceRegistry.GetUserTokens(ormService, controller.GetUserIDFunc(c)) {
+		if deviceTokenEntity.Token != request.Token {
+			continue
+		}
+
+		err = deviceRegistry.Unregister(c.Request.Context(), ormService, request.Token)
+		if err != nil {
+			response.ErrorResponseGlobal(c, err, nil)
+
+			return
+		}
+	}
+
+	response.SuccessResponse(c, nil)
+}
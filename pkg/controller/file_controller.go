@@ -11,6 +11,8 @@ import (
 )
 
 type FileController struct {
+	// GetUserIDFunc returns ID of the logged user, presigned uploads can be confirmed only by the user who created them
+	GetUserIDFunc func(c *gin.Context) uint64
 }
 
 // @Description Upload
@@ -52,3 +54,55 @@ func (controller *FileController) PostUploadImageAction(c *gin.Context) {
 
 	response.SuccessResponse(c, res)
 }
+
+// @Description Creates presigned upload which allows uploading the file directly to the bucket
+// @Tags File
+// @Param body body file.RequestDTOPresignedUpload true "Request in body"
+// @Router /file/presigned-upload/ [post]
+// @Security BearerAuth
+// @Success 200 {object} file.PresignedUpload
+// @Failure 400 {object} response.Error
+// @Failure 401 "Unauthorized"
+// @Failure 403 "Forbidden"
+// @Failure 500 "Something bad happened"
+func (controller *FileController) PostPresignedUploadAction(c *gin.Context) {
+	request := &file.RequestDTOPresignedUpload{}
+
+	err := binding.ShouldBindJSON(c, request)
+	if errorhandling.HandleError(c, err) {
+		return
+	}
+
+	res, err := fileModel.CreatePresignedUpload(c.Request.Context(), controller.GetUserIDFunc(c), request)
+	if errorhandling.HandleError(c, err) {
+		return
+	}
+
+	response.SuccessResponse(c, res)
+}
+
+// @Description Confirms that the file was uploaded with presigned upload
+// @Tags File
+// @Param ID path string true "File ID"
+// @Router /file/presigned-upload/{ID}/confirm/ [post]
+// @Security BearerAuth
+// @Success 200 {object} file.File
+// @Failure 400 {object} response.Error
+// @Failure 401 "Unauthorized"
+// @Failure 403 "Forbidden"
+// @Failure 500 "Something bad happened"
+func (controller *FileController) PostConfirmUploadAction(c *gin.Context) {
+	request := &file.RequestDTOConfirmUpload{}
+
+	err := binding.ShouldBindURI(c, request)
+	if errorhandling.HandleError(c, err) {
+		return
+	}
+
+	res, err := fileModel.ConfirmUpload(c.Request.Context(), controller.GetUserIDFunc(c), request.ID)
+	if errorhandling.HandleError(c, err) {
+		return
+	}
+
+	response.SuccessResponse(c, res)
+}
@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"os"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/coretrix/hitrix/pkg/errors"
+	"github.com/coretrix/hitrix/pkg/response"
+	"github.com/coretrix/hitrix/service"
+	"github.com/coretrix/hitrix/service/component/oss"
+)
+
+type OSSController struct {
+}
+
+// @Description Serves object stored by local oss provider
+// @Tags OSS
+// @Param Bucket path string true "Bucket name"
+// @Param StorageKey path string true "Object storage key"
+// @Param expires query string false "Expiration unix timestamp of signed url"
+// @Param signature query string false "Signature of signed url"
+// @Router /oss/{Bucket}/{StorageKey} [get]
+// @Success 200
+// @Failure 403 "Invalid signature or expired url"
+// @Failure 404 "Object not found"
+func (controller *OSSController) GetObjectAction(c *gin.Context) {
+	localOSS, ok := service.DI().OSService().(*oss.LocalOSS)
+	if !ok {
+		response.NotFoundResponse(c)
+
+		return
+	}
+
+	objectPath, err := localOSS.GetServedObjectPath(c.Param("Bucket"), c.Param("StorageKey"), c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		if err == oss.ErrInvalidSignature || err == oss.ErrURLExpired {
+			response.ErrorResponseGlobal(c, &errors.PermissionError{Message: err.Error()}, nil)
+
+			return
+		}
+
+		response.NotFoundResponse(c)
+
+		return
+	}
+
+	if _, err := os.Stat(objectPath); err != nil {
+		response.NotFoundResponse(c)
+
+		return
+	}
+
+	c.File(objectPath)
+}
+
+// @Description Stores object uploaded with presigned upload created by local oss provider
+// @Tags OSS
+// @Param Bucket path string true "Bucket name"
+// @Param StorageKey path string true "Object storage key"
+// @Param expires query string true "Expiration unix timestamp of signed url"
+// @Param max_size query string true "Max size of the object"
+// @Param content_type query string true "Content type of the object"
+// @Param signature query string true "Signature of signed url"
+// @Router /oss/{Bucket}/{StorageKey} [put]
+// @Success 200
+// @Failure 400 {object} response.Error
+// @Failure 403 "Invalid signature or expired url"
+// @Failure 404 "Local oss provider is not used"
+func (controller *OSSController) PutObjectAction(c *gin.Context) {
+	localOSS, ok := service.DI().OSService().(*oss.LocalOSS)
+	if !ok {
+		response.NotFoundResponse(c)
+
+		return
+	}
+
+	err := localOSS.SaveUploadedObject(c.Param("Bucket"), c.Param("StorageKey"), c.Request.URL.Query(), c.ContentType(), c.Request.Body)
+	if err != nil {
+		if err == oss.ErrInvalidSignature || err == oss.ErrURLExpired {
+			response.ErrorResponseGlobal(c, &errors.PermissionError{Message: err.Error()}, nil)
+
+			return
+		}
+
+		response.ErrorResponseGlobal(c, err, nil)
+
+		return
+	}
+
+	response.SuccessResponse(c, nil)
+}
@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/coretrix/hitrix/pkg/binding"
+	"github.com/coretrix/hitrix/pkg/dto/savedview"
+	errorhandling "github.com/coretrix/hitrix/pkg/error_handling"
+	"github.com/coretrix/hitrix/pkg/errors"
+	savedViewModel "github.com/coretrix/hitrix/pkg/model/savedview"
+	"github.com/coretrix/hitrix/pkg/response"
+	savedViewView "github.com/coretrix/hitrix/pkg/view/savedview"
+	"github.com/coretrix/hitrix/service/component/crud"
+)
+
+type SavedViewController struct {
+	// GetUserIDFunc returns ID of the logged user, views are owned by users
+	GetUserIDFunc func(c *gin.Context) uint64
+	// GetColumnsFunc returns current columns of the crud list screen
+	GetColumnsFunc func(c *gin.Context, resource string) ([]*crud.Column, bool)
+}
+
+// @Description List own and shared views of the resource
+// @Tags SavedView
+// @Param Resource path string true "Resource"
+// @Router /saved-view/list/{Resource}/ [get]
+// @Security BearerAuth
+// @Success 200 {object} []savedview.SavedView
+// @Failure 400 {object} response.Error
+// @Failure 500 "Something bad happened"
+func (controller *SavedViewController) GetListAction(c *gin.Context) {
+	request := &savedview.RequestDTOResource{}
+
+	err := binding.ShouldBindURI(c, request)
+	if errorhandling.HandleError(c, err) {
+		return
+	}
+
+	response.SuccessResponse(c, savedViewView.List(c.Request.Context(), controller.GetUserIDFunc(c), request.Resource))
+}
+
+// @Description Create view or update own view when ID is set
+// @Tags SavedView
+// @Param body body savedview.RequestDTOSaveView true "Request in body"
+// @Router /saved-view/ [post]
+// @Security BearerAuth
+// @Success 200 {object} savedview.SavedView
+// @Failure 400 {object} response.Error
+// @Failure 500 "Something bad happened"
+func (controller *SavedViewController) PostSaveAction(c *gin.Context) {
+	request := &savedview.RequestDTOSaveView{}
+
+	err := binding.ShouldBindJSON(c, request)
+	if errorhandling.HandleError(c, err) {
+		return
+	}
+
+	cols, ok := controller.GetColumnsFunc(c, request.Resource)
+	if !ok {
+		errorhandling.HandleError(c, errors.HandleCustomErrors(map[string]string{"Resource": "resource not found"}))
+
+		return
+	}
+
+	res, err := savedViewModel.Save(c.Request.Context(), controller.GetUserIDFunc(c), cols, request)
+	if errorhandling.HandleError(c, err) {
+		return
+	}
+
+	response.SuccessResponse(c, res)
+}
+
+// @Description Returns view with the request and columns validated against current columns of the resource
+// @Tags SavedView
+// @Param Resource path string true "Resource"
+// @Param ID path string true "View ID"
+// @Router /saved-view/apply/{Resource}/{ID}/ [get]
+// @Security BearerAuth
+// @Success 200 {object} savedview.ResponseDTOApplyView
+// @Failure 400 {object} response.Error
+// @Failure 500 "Something bad happened"
+func (controller *SavedViewController) GetApplyAction(c *gin.Context) {
+	request := &savedview.RequestDTOApplyView{}
+
+	err := binding.ShouldBindURI(c, request)
+	if errorhandling.HandleError(c, err) {
+		return
+	}
+
+	cols, ok := controller.GetColumnsFunc(c, request.Resource)
+	if !ok {
+		errorhandling.HandleError(c, errors.HandleCustomErrors(map[string]string{"Resource": "resource not found"}))
+
+		return
+	}
+
+	res, err := savedViewView.Apply(c.Request.Context(), controller.GetUserIDFunc(c), request.ID, request.Resource, cols)
+	if errorhandling.HandleError(c, err) {
+		return
+	}
+
+	response.SuccessResponse(c, res)
+}
+
+// @Description Share own view with other users or make it private
+// @Tags SavedView
+// @Param ID path string true "View ID"
+// @Param body body savedview.RequestDTOShareView true "Request in body"
+// @Router /saved-view/{ID}/share/ [put]
+// @Security BearerAuth
+// @Success 200
+// @Failure 400 {object} response.Error
+// @Failure 500 "Something bad happened"
+func (controller *SavedViewController) PutShareAction(c *gin.Context) {
+	requestID := &savedview.RequestDTOViewID{}
+
+	err := binding.ShouldBindURI(c, requestID)
+	if errorhandling.HandleError(c, err) {
+		return
+	}
+
+	request := &savedview.RequestDTOShareView{}
+
+	err = binding.ShouldBindJSON(c, request)
+	if errorhandling.HandleError(c, err) {
+		return
+	}
+
+	err = savedViewModel.Share(c.Request.Context(), controller.GetUserIDFunc(c), requestID.ID, request.Shared)
+	if errorhandling.HandleError(c, err) {
+		return
+	}
+
+	response.SuccessResponse(c, nil)
+}
+
+// @Description Delete own view
+// @Tags SavedView
+// @Param ID path string true "View ID"
+// @Router /saved-view/{ID}/ [delete]
+// @Security BearerAuth
+// @Success 200
+// @Failure 400 {object} response.Error
+// @Failure 500 "Something bad happened"
+func (controller *SavedViewController) DeleteAction(c *gin.Context) {
+	request := &savedview.RequestDTOViewID{}
+
+	err := binding.ShouldBindURI(c, request)
+	if errorhandling.HandleError(c, err) {
+		return
+	}
+
+	err = savedViewModel.Delete(c.Request.Context(), controller.GetUserIDFunc(c), request.ID)
+	if errorhandling.HandleError(c, err) {
+		return
+	}
+
+	response.SuccessResponse(c, nil)
+}
@@ -0,0 +1,29 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/coretrix/hitrix/pkg/response"
+	"github.com/coretrix/hitrix/service"
+)
+
+type SMSController struct {
+}
+
+// @Description Delivery receipt callback called by sms provider
+// @Tags SMS
+// @Param Provider path string true "Provider name"
+// @Router /sms/delivery-receipt/{Provider}/ [post]
+// @Success 200
+// @Failure 400 {object} response.Error
+// @Failure 500 "Something bad happened"
+func (controller *SMSController) PostDeliveryReceiptAction(c *gin.Context) {
+	err := service.DI().SMS().HandleDeliveryReceipt(service.DI().OrmEngine(), c.Param("Provider"), c.Request)
+	if err != nil {
+		response.ErrorResponseGlobal(c, err, nil)
+
+		return
+	}
+
+	response.SuccessResponse(c, nil)
+}
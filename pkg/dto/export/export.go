@@ -0,0 +1,27 @@
+package export
+
+import (
+	"time"
+)
+
+type RequestDTOExportJob struct {
+	ExportID  string `binding:"required"`
+	Format    string `binding:"required,oneof=csv xlsx jsonl parquet"`
+	Lang      string
+	Search    map[string]interface{}
+	SearchOR  map[string]interface{}
+	Sort      map[string]interface{}
+	SortOrder []string
+	ExtraArgs map[string]string
+}
+
+type ExportJob struct {
+	ID          uint64
+	ExportID    string
+	Format      string
+	Status      string
+	Rows        int
+	Error       string
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+}
@@ -0,0 +1,11 @@
+package fcm
+
+type RequestDTORegisterDeviceToken struct {
+	Platform   string `binding:"required,oneof=android ios web" example:"android"`
+	Token      string `binding:"required,max=255"`
+	AppVersion string `binding:"max=50" example:"1.0.0"`
+}
+
+type RequestDTOUnregisterDeviceToken struct {
+	Token string `binding:"required,max=255"`
+}
@@ -3,6 +3,7 @@ package file
 import (
 	"io"
 	"mime/multipart"
+	"time"
 
 	"github.com/coretrix/hitrix/service/component/oss"
 )
@@ -48,6 +49,25 @@ func (r *RequestDTOUploadFile) ToUploadImage() (*RequestDTOUploadImage, func() e
 	}, deferFn, nil
 }
 
+type RequestDTOPresignedUpload struct {
+	Namespace   string `binding:"required"`
+	ContentType string `binding:"required"`
+	MaxSize     int64  `binding:"required,min=1"`
+}
+
+type PresignedUpload struct {
+	ID        uint64
+	Method    string
+	URL       string
+	Fields    map[string]string
+	Headers   map[string]string
+	ExpiresAt time.Time
+}
+
+type RequestDTOConfirmUpload struct {
+	ID uint64 `binding:"required"`
+}
+
 type RequestDTOUploadImage struct {
 	Image     Upload
 	Namespace oss.Namespace
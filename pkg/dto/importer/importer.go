@@ -0,0 +1,25 @@
+package importer
+
+import (
+	"time"
+)
+
+type RequestDTOImportJob struct {
+	ImportID string `binding:"required"`
+	FileID   uint64 `binding:"required"`
+	DryRun   bool
+}
+
+type ImportJob struct {
+	ID          uint64
+	ImportID    string
+	FileID      uint64
+	DryRun      bool
+	Status      string
+	Rows        int
+	Imported    int
+	Failed      int
+	Error       string
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+}
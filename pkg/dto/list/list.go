@@ -1,9 +1,11 @@
 package list
 
 type RequestDTOList struct {
-	Page     *int `binding:"required"`
-	PageSize *int `binding:"required"`
-	Search   map[string]interface{}
-	SearchOR map[string]interface{}
-	Sort     map[string]interface{}
+	Page      *int `binding:"required"`
+	PageSize  *int `binding:"required"`
+	Search    map[string]interface{}
+	SearchOR  map[string]interface{}
+	Sort      map[string]interface{}
+	SortOrder []string
+	After     *string
 }
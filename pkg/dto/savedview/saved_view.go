@@ -0,0 +1,55 @@
+package savedview
+
+import (
+	"time"
+
+	"github.com/coretrix/hitrix/service/component/crud"
+)
+
+type RequestDTOSaveView struct {
+	// ID updates existing view of the user
+	ID        *uint64
+	Resource  string `binding:"required,max=100"`
+	Name      string `binding:"required,max=100"`
+	PageSize  *int
+	Search    map[string]interface{}
+	SearchOR  map[string]interface{}
+	Sort      map[string]interface{}
+	SortOrder []string
+	Columns   []string
+	Shared    bool
+}
+
+type RequestDTOShareView struct {
+	Shared bool
+}
+
+type RequestDTOApplyView struct {
+	Resource string `uri:"Resource" binding:"required"`
+	ID       uint64 `uri:"ID" binding:"required" example:"1"`
+}
+
+type RequestDTOViewID struct {
+	ID uint64 `uri:"ID" binding:"required" example:"1"`
+}
+
+type RequestDTOResource struct {
+	Resource string `uri:"Resource" binding:"required"`
+}
+
+type SavedView struct {
+	ID        uint64
+	Resource  string
+	Name      string
+	Request   *crud.ListRequest
+	Columns   []string
+	Shared    bool
+	Owner     bool
+	CreatedAt time.Time
+}
+
+type ResponseDTOApplyView struct {
+	View *SavedView
+	// Columns are current columns of the resource with visibility of the view
+	Columns []*crud.Column
+}
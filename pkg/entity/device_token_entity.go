@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/latolukasz/beeorm/v2"
+)
+
+const (
+	DeviceTokenPlatformAndroid = "android"
+	DeviceTokenPlatformIOS     = "ios"
+	DeviceTokenPlatformWeb     = "web"
+)
+
+type deviceTokenPlatformAll struct {
+	DeviceTokenPlatformAndroid string
+	DeviceTokenPlatformIOS     string
+	DeviceTokenPlatformWeb     string
+}
+
+var DeviceTokenPlatformAll = deviceTokenPlatformAll{
+	DeviceTokenPlatformAndroid: DeviceTokenPlatformAndroid,
+	DeviceTokenPlatformIOS:     DeviceTokenPlatformIOS,
+	DeviceTokenPlatformWeb:     DeviceTokenPlatformWeb,
+}
+
+type DeviceTokenEntity struct {
+	beeorm.ORM `orm:"table=device_tokens"`
+	ID         uint64
+	UserID     uint64    `orm:"required;index=UserID"`
+	Platform   string    `orm:"enum=entity.DeviceTokenPlatformAll;required"`
+	Token      string    `orm:"length=255;required;unique=Token"`
+	AppVersion string    `orm:"length=50"`
+	LastSeenAt time.Time `orm:"time=true"`
+	CreatedAt  time.Time `orm:"time=true"`
+}
@@ -0,0 +1,54 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/latolukasz/beeorm/v2"
+)
+
+type ExportJobStatus string
+
+func (s ExportJobStatus) String() string {
+	return string(s)
+}
+
+const (
+	ExportJobStatusPending    ExportJobStatus = "pending"
+	ExportJobStatusProcessing ExportJobStatus = "processing"
+	ExportJobStatusCompleted  ExportJobStatus = "completed"
+	ExportJobStatusFailed     ExportJobStatus = "failed"
+)
+
+type exportJobStatus struct {
+	ExportJobStatusPending    string
+	ExportJobStatusProcessing string
+	ExportJobStatusCompleted  string
+	ExportJobStatusFailed     string
+}
+
+var ExportJobStatusAll = exportJobStatus{
+	ExportJobStatusPending:    ExportJobStatusPending.String(),
+	ExportJobStatusProcessing: ExportJobStatusProcessing.String(),
+	ExportJobStatusCompleted:  ExportJobStatusCompleted.String(),
+	ExportJobStatusFailed:     ExportJobStatusFailed.String(),
+}
+
+// ExportJobEntity keeps the request of async crud export and the storage keys of exported files.
+// Large exports are split into several files, so every file stays under the row limit of the format
+type ExportJobEntity struct {
+	beeorm.ORM  `orm:"table=export_jobs"`
+	ID          uint64
+	UserID      uint64 `orm:"required;index=UserID_CreatedAt:1"`
+	ExportID    string `orm:"required"`
+	Format      string `orm:"required;length=20"`
+	Lang        string `orm:"length=10"`
+	Request     string `orm:"length=max"`
+	ExtraArgs   string `orm:"length=max"`
+	Status      string `orm:"required;enum=entity.ExportJobStatusAll;index=Status_StartedAt:1"`
+	Rows        int
+	Objects     []string
+	Error       string     `orm:"length=max"`
+	CreatedAt   time.Time  `orm:"time=true;index=UserID_CreatedAt:2"`
+	StartedAt   *time.Time `orm:"time=true;index=Status_StartedAt:2"`
+	CompletedAt *time.Time `orm:"time=true"`
+}
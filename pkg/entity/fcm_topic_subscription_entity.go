@@ -0,0 +1,15 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/latolukasz/beeorm/v2"
+)
+
+type FCMTopicSubscriptionEntity struct {
+	beeorm.ORM `orm:"table=fcm_topic_subscriptions"`
+	ID         uint64
+	UserID     uint64    `orm:"required;unique=UserID_Topic:1"`
+	Topic      string    `orm:"length=255;required;unique=UserID_Topic:2"`
+	CreatedAt  time.Time `orm:"time=true"`
+}
@@ -13,18 +13,24 @@ func (f FileStatus) String() string {
 }
 
 const (
-	FileStatusNew       FileStatus = "new"
-	FileStatusProcessed FileStatus = "processed"
+	FileStatusPending     FileStatus = "pending"
+	FileStatusNew         FileStatus = "new"
+	FileStatusProcessed   FileStatus = "processed"
+	FileStatusQuarantined FileStatus = "quarantined"
 )
 
 type fileStatus struct {
-	FileStatusNew       string
-	FileStatusProcessed string
+	FileStatusPending     string
+	FileStatusNew         string
+	FileStatusProcessed   string
+	FileStatusQuarantined string
 }
 
 var FileStatusAll = fileStatus{
-	FileStatusNew:       FileStatusNew.String(),
-	FileStatusProcessed: FileStatusProcessed.String(),
+	FileStatusPending:     FileStatusPending.String(),
+	FileStatusNew:         FileStatusNew.String(),
+	FileStatusProcessed:   FileStatusProcessed.String(),
+	FileStatusQuarantined: FileStatusQuarantined.String(),
 }
 
 type FileObject struct {
@@ -37,7 +43,12 @@ type FileEntity struct {
 	beeorm.ORM `orm:"table=files;redisSearch=search_pool"`
 	ID         uint64 `orm:"searchable;sortable"`
 	File       *FileObject
-	Status     string    `orm:"required;enum=entity.FileStatusAll"`
-	Namespace  string    `orm:"required;searchable"`
-	CreatedAt  time.Time `orm:"time=true"`
+	Status     string `orm:"required;enum=entity.FileStatusAll;index=Status_CreatedAt:1"`
+	Namespace  string `orm:"required;searchable"`
+	MimeType   string
+	Size       int64
+	ScanResult string
+	UploadID   string `orm:"length=100;unique=UploadID"`
+	UploaderID uint64
+	CreatedAt  time.Time `orm:"time=true;index=Status_CreatedAt:2"`
 }
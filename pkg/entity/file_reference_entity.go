@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/latolukasz/beeorm/v2"
+)
+
+type FileReferenceEntity struct {
+	beeorm.ORM `orm:"table=file_references"`
+	ID         uint64
+	File       *FileEntity `orm:"required;unique=File_Entity_EntityID:1"`
+	Entity     string      `orm:"length=100;required;unique=File_Entity_EntityID:2"`
+	EntityID   uint64      `orm:"required;unique=File_Entity_EntityID:3"`
+	CreatedAt  time.Time   `orm:"time=true"`
+}
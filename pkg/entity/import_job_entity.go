@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/latolukasz/beeorm/v2"
+)
+
+type ImportJobStatus string
+
+func (s ImportJobStatus) String() string {
+	return string(s)
+}
+
+const (
+	ImportJobStatusPending    ImportJobStatus = "pending"
+	ImportJobStatusProcessing ImportJobStatus = "processing"
+	ImportJobStatusCompleted  ImportJobStatus = "completed"
+	ImportJobStatusFailed     ImportJobStatus = "failed"
+)
+
+type importJobStatus struct {
+	ImportJobStatusPending    string
+	ImportJobStatusProcessing string
+	ImportJobStatusCompleted  string
+	ImportJobStatusFailed     string
+}
+
+var ImportJobStatusAll = importJobStatus{
+	ImportJobStatusPending:    ImportJobStatusPending.String(),
+	ImportJobStatusProcessing: ImportJobStatusProcessing.String(),
+	ImportJobStatusCompleted:  ImportJobStatusCompleted.String(),
+	ImportJobStatusFailed:     ImportJobStatusFailed.String(),
+}
+
+// ImportJobEntity keeps the result of async import. ErrorReport is the storage key of CSV file with failed rows
+type ImportJobEntity struct {
+	beeorm.ORM  `orm:"table=import_jobs"`
+	ID          uint64
+	UserID      uint64      `orm:"required;index=UserID_CreatedAt:1"`
+	ImportID    string      `orm:"required"`
+	File        *FileEntity `orm:"required"`
+	Format      string      `orm:"required;length=20"`
+	DryRun      bool
+	Status      string `orm:"required;enum=entity.ImportJobStatusAll"`
+	Rows        int
+	Imported    int
+	Failed      int
+	ErrorReport string
+	Error       string     `orm:"length=max"`
+	CreatedAt   time.Time  `orm:"time=true;index=UserID_CreatedAt:2"`
+	CompletedAt *time.Time `orm:"time=true"`
+}
@@ -7,21 +7,24 @@ import (
 )
 
 const (
-	OTPTrackerTypeSMS     = "sms"
-	OTPTrackerTypeCallout = "callout"
-	OTPTrackerTypeEmail   = "email"
+	OTPTrackerTypeSMS      = "sms"
+	OTPTrackerTypeCallout  = "callout"
+	OTPTrackerTypeEmail    = "email"
+	OTPTrackerTypeWhatsApp = "whatsapp"
 )
 
 type otpTrackerTypeAll struct {
-	OTPTrackerTypeSMS     string
-	OTPTrackerTypeCallout string
-	OTPTrackerTypeEmail   string
+	OTPTrackerTypeSMS      string
+	OTPTrackerTypeCallout  string
+	OTPTrackerTypeEmail    string
+	OTPTrackerTypeWhatsApp string
 }
 
 var OTPTrackerTypeAll = otpTrackerTypeAll{
-	OTPTrackerTypeSMS:     OTPTrackerTypeSMS,
-	OTPTrackerTypeCallout: OTPTrackerTypeCallout,
-	OTPTrackerTypeEmail:   OTPTrackerTypeEmail,
+	OTPTrackerTypeSMS:      OTPTrackerTypeSMS,
+	OTPTrackerTypeCallout:  OTPTrackerTypeCallout,
+	OTPTrackerTypeEmail:    OTPTrackerTypeEmail,
+	OTPTrackerTypeWhatsApp: OTPTrackerTypeWhatsApp,
 }
 
 const (
@@ -43,27 +46,30 @@ var OTPTrackerGatewaySendStatusAll = OTPTrackerGatewaySendStatus{
 }
 
 const (
-	OTPTrackerGatewayVerifyStatusNew          = "new"
-	OTPTrackerGatewayVerifyStatusGatewayError = "gateway_error"
-	OTPTrackerGatewayVerifyStatusInvalidCode  = "invalid_code"
-	OTPTrackerGatewayVerifyStatusSuccess      = "success"
-	OTPTrackerGatewayVerifyStatusExpired      = "expired"
+	OTPTrackerGatewayVerifyStatusNew             = "new"
+	OTPTrackerGatewayVerifyStatusGatewayError    = "gateway_error"
+	OTPTrackerGatewayVerifyStatusInvalidCode     = "invalid_code"
+	OTPTrackerGatewayVerifyStatusSuccess         = "success"
+	OTPTrackerGatewayVerifyStatusExpired         = "expired"
+	OTPTrackerGatewayVerifyStatusTooManyAttempts = "too_many_attempts"
 )
 
 type OTPTrackerGatewayVerifyStatus struct {
-	OTPTrackerGatewayVerifyStatusNewError     string
-	OTPTrackerGatewayVerifyStatusGatewayError string
-	OTPTrackerGatewayVerifyStatusInvalidCode  string
-	OTPTrackerGatewayVerifyStatusSuccess      string
-	OTPTrackerGatewayVerifyStatusExpired      string
+	OTPTrackerGatewayVerifyStatusNewError        string
+	OTPTrackerGatewayVerifyStatusGatewayError    string
+	OTPTrackerGatewayVerifyStatusInvalidCode     string
+	OTPTrackerGatewayVerifyStatusSuccess         string
+	OTPTrackerGatewayVerifyStatusExpired         string
+	OTPTrackerGatewayVerifyStatusTooManyAttempts string
 }
 
 var OTPTrackerGatewayVerifyStatusAll = OTPTrackerGatewayVerifyStatus{
-	OTPTrackerGatewayVerifyStatusNewError:     OTPTrackerGatewayVerifyStatusNew,
-	OTPTrackerGatewayVerifyStatusGatewayError: OTPTrackerGatewayVerifyStatusGatewayError,
-	OTPTrackerGatewayVerifyStatusInvalidCode:  OTPTrackerGatewayVerifyStatusInvalidCode,
-	OTPTrackerGatewayVerifyStatusSuccess:      OTPTrackerGatewayVerifyStatusSuccess,
-	OTPTrackerGatewayVerifyStatusExpired:      OTPTrackerGatewayVerifyStatusExpired,
+	OTPTrackerGatewayVerifyStatusNewError:        OTPTrackerGatewayVerifyStatusNew,
+	OTPTrackerGatewayVerifyStatusGatewayError:    OTPTrackerGatewayVerifyStatusGatewayError,
+	OTPTrackerGatewayVerifyStatusInvalidCode:     OTPTrackerGatewayVerifyStatusInvalidCode,
+	OTPTrackerGatewayVerifyStatusSuccess:         OTPTrackerGatewayVerifyStatusSuccess,
+	OTPTrackerGatewayVerifyStatusExpired:         OTPTrackerGatewayVerifyStatusExpired,
+	OTPTrackerGatewayVerifyStatusTooManyAttempts: OTPTrackerGatewayVerifyStatusTooManyAttempts,
 }
 
 type OTPTrackerEntity struct {
@@ -81,6 +87,7 @@ type OTPTrackerEntity struct {
 	GatewayVerifyRequest  string `orm:"length=max"`
 	GatewayVerifyResponse string `orm:"length=max"`
 	RetryCount            int
+	VerifyAttempts        int
 	MaxRetriesReached     bool
 	SentAt                time.Time `orm:"time"`
 }
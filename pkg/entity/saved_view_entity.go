@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/latolukasz/beeorm/v2"
+)
+
+// SavedViewEntity keeps filters, sort and visible columns of crud list screen. Request is JSON of crud.ListRequest
+type SavedViewEntity struct {
+	beeorm.ORM `orm:"table=saved_views"`
+	ID         uint64
+	UserID     uint64 `orm:"required;index=UserID_Resource:1"`
+	Resource   string `orm:"required;length=100;index=UserID_Resource:2;index=Resource_Shared:1"`
+	Name       string `orm:"required;length=100"`
+	Request    string `orm:"length=max"`
+	Columns    []string
+	Shared     bool       `orm:"index=Resource_Shared:2"`
+	CreatedAt  time.Time  `orm:"time=true"`
+	UpdatedAt  *time.Time `orm:"time=true"`
+}
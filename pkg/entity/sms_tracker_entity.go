@@ -21,18 +21,45 @@ var SMSTrackerTypeAll = smsTrackerTypeAll{
 	SMSTrackerTypeCallout: SMSTrackerTypeCallout,
 }
 
+const (
+	SMSTrackerDeliveryStatusDelivered = "delivered"
+	SMSTrackerDeliveryStatusFailed    = "failed"
+	SMSTrackerDeliveryStatusExpired   = "expired"
+)
+
+type smsTrackerDeliveryStatusAll struct {
+	SMSTrackerDeliveryStatusDelivered string
+	SMSTrackerDeliveryStatusFailed    string
+	SMSTrackerDeliveryStatusExpired   string
+}
+
+var SMSTrackerDeliveryStatusAll = smsTrackerDeliveryStatusAll{
+	SMSTrackerDeliveryStatusDelivered: SMSTrackerDeliveryStatusDelivered,
+	SMSTrackerDeliveryStatusFailed:    SMSTrackerDeliveryStatusFailed,
+	SMSTrackerDeliveryStatusExpired:   SMSTrackerDeliveryStatusExpired,
+}
+
 type SmsTrackerEntity struct {
-	beeorm.ORM            `orm:"table=sms_tracker"`
-	ID                    uint64
-	Status                string
-	To                    string `orm:"length=15"`
-	Text                  string `orm:"length=max"`
-	FromPrimaryGateway    string
-	FromSecondaryGateway  string
-	PrimaryGatewayError   string    `orm:"length=max"`
-	SecondaryGatewayError string    `orm:"length=max"`
-	Type                  string    `orm:"enum=entity.SMSTrackerTypeAll;required"`
-	SentAt                time.Time `orm:"time"`
+	beeorm.ORM             `orm:"table=sms_tracker"`
+	ID                     uint64
+	Status                 string
+	To                     string `orm:"length=15"`
+	Text                   string `orm:"length=max"`
+	FromPrimaryGateway     string
+	FromSecondaryGateway   string
+	PrimaryGatewayError    string `orm:"length=max"`
+	SecondaryGatewayError  string `orm:"length=max"`
+	SentByGateway          string
+	GatewayErrors          string    `orm:"length=max"`
+	Cost                   float64   `orm:"decimal=10,5"`
+	Type                   string    `orm:"enum=entity.SMSTrackerTypeAll;required"`
+	SentAt                 time.Time `orm:"time"`
+	ProviderMessageID      string    `orm:"index=ProviderMessageID"`
+	DeliveryStatus         string    `orm:"enum=entity.SMSTrackerDeliveryStatusAll"`
+	ProviderDeliveryStatus string
+	DeliveredAt            *time.Time `orm:"time"`
+	FailedAt               *time.Time `orm:"time"`
+	ExpiredAt              *time.Time `orm:"time"`
 }
 
 func (s *SmsTrackerEntity) SetStatus(status string) {
@@ -70,3 +97,19 @@ func (s *SmsTrackerEntity) SetType(typ string) {
 func (s *SmsTrackerEntity) SetSentAt(sendAt time.Time) {
 	s.SentAt = sendAt
 }
+
+func (s *SmsTrackerEntity) SetSentByProvider(provider string) {
+	s.SentByGateway = provider
+}
+
+func (s *SmsTrackerEntity) SetProviderErrors(providerErrors string) {
+	s.GatewayErrors = providerErrors
+}
+
+func (s *SmsTrackerEntity) SetCost(cost float64) {
+	s.Cost = cost
+}
+
+func (s *SmsTrackerEntity) SetProviderMessageID(providerMessageID string) {
+	s.ProviderMessageID = providerMessageID
+}
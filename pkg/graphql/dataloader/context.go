@@ -0,0 +1,145 @@
+package dataloader
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/latolukasz/beeorm/v2"
+
+	"github.com/coretrix/hitrix/service"
+)
+
+const ginKey = "hitrix_dataloaders"
+
+type statsProvider interface {
+	Stats() Stats
+}
+
+// Loaders are loaders of one request, they are stored in gin context next to request services like OrmEngineForContext
+type Loaders struct {
+	mutex   sync.Mutex
+	loaders map[string]statsProvider
+}
+
+func NewLoaders() *Loaders {
+	return &Loaders{loaders: map[string]statsProvider{}}
+}
+
+// attachMutex guards lazy attach, resolvers of one request run in parallel and have to get the same loaders
+var attachMutex sync.Mutex
+
+// Attach stores new loaders in gin context, DataLoaders middleware calls it at the beginning of the request
+func Attach(c *gin.Context) *Loaders {
+	attachMutex.Lock()
+	defer attachMutex.Unlock()
+
+	loaders := NewLoaders()
+	c.Set(ginKey, loaders)
+
+	return loaders
+}
+
+// ForContext returns loaders of the request. Loaders are attached on the first use when the middleware is not registered
+func ForContext(ctx context.Context) *Loaders {
+	c := service.GinFromContext(ctx)
+
+	if loaders, has := c.Get(ginKey); has {
+		return loaders.(*Loaders)
+	}
+
+	attachMutex.Lock()
+	defer attachMutex.Unlock()
+
+	if loaders, has := c.Get(ginKey); has {
+		return loaders.(*Loaders)
+	}
+
+	loaders := NewLoaders()
+	c.Set(ginKey, loaders)
+
+	return loaders
+}
+
+// Get returns loader registered under the name for the request, create is called only once per request
+func Get[K comparable, V any](ctx context.Context, name string, create func(ctx context.Context) *Loader[K, V]) *Loader[K, V] {
+	loaders := ForContext(ctx)
+
+	loaders.mutex.Lock()
+	defer loaders.mutex.Unlock()
+
+	if existing, has := loaders.loaders[name]; has {
+		loader, ok := existing.(*Loader[K, V])
+		if !ok {
+			panic(fmt.Errorf("dataloader %s is registered as %T", name, existing))
+		}
+
+		return loader
+	}
+
+	loader := create(ctx)
+	loaders.loaders[name] = loader
+
+	return loader
+}
+
+// EntityLoader returns request loader of entities by ID, E has to be pointer to the entity
+func EntityLoader[E beeorm.Entity](ctx context.Context, references ...string) *Loader[uint64, E] {
+	return Get(ctx, loaderName[E]("EntityByID", references), func(ctx context.Context) *Loader[uint64, E] {
+		return NewLoader(EntitiesByIDs[E](service.DI().OrmEngineForContext(ctx), references...), 0, 0)
+	})
+}
+
+// ReferenceLoader returns request loader of entities which reference the ID in the field, E has to be pointer to the entity
+func ReferenceLoader[E beeorm.Entity](ctx context.Context, field string, references ...string) *Loader[uint64, []E] {
+	return Get(ctx, loaderName[E]("EntitiesBy"+field, references), func(ctx context.Context) *Loader[uint64, []E] {
+		return NewLoader(EntitiesByReference[E](service.DI().OrmEngineForContext(ctx), field, references...), 0, 0)
+	})
+}
+
+// Stats returns stats of all loaders used in the request
+func (l *Loaders) Stats() map[string]Stats {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	stats := make(map[string]Stats, len(l.loaders))
+
+	for name, loader := range l.loaders {
+		stats[name] = loader.Stats()
+	}
+
+	return stats
+}
+
+// NPlusOne returns warnings for loaders which loaded at least threshold batches with one key.
+// It happens when resolver waits for every Load in a loop instead of using LoadAll, so every key is one query
+func (l *Loaders) NPlusOne(threshold int) []string {
+	warnings := make([]string, 0)
+
+	for name, stats := range l.Stats() {
+		if stats.SingleKeyBatches >= threshold {
+			warnings = append(warnings, fmt.Sprintf(
+				"possible N+1: dataloader %s loaded %d batches with one key out of %d batches, use LoadAll or LoadThunk",
+				name, stats.SingleKeyBatches, stats.Batches,
+			))
+		}
+	}
+
+	sort.Strings(warnings)
+
+	return warnings
+}
+
+func loaderName[E beeorm.Entity](prefix string, references []string) string {
+	name := prefix + ":" + reflect.TypeOf((*E)(nil)).Elem().String()
+
+	if len(references) > 0 {
+		name += ":" + strings.Join(references, ",")
+	}
+
+	return name
+}
@@ -0,0 +1,226 @@
+package dataloader
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultWait     = 2 * time.Millisecond
+	DefaultMaxBatch = 1000
+)
+
+// BatchFunc loads values of all keys with one query. Keys missing in the result are resolved with zero value
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Stats describe how the loader was used during the request
+type Stats struct {
+	Loads            int
+	CacheHits        int
+	Batches          int
+	Keys             int
+	SingleKeyBatches int
+}
+
+// Loader collects keys requested within the wait time and loads them with one BatchFunc call.
+// Loaded values are cached, so the loader has to be created for every request
+type Loader[K comparable, V any] struct {
+	fetch    BatchFunc[K, V]
+	wait     time.Duration
+	maxBatch int
+
+	mutex sync.Mutex
+	cache map[K]V
+	batch *batch[K, V]
+	stats Stats
+}
+
+type batch[K comparable, V any] struct {
+	keys   []K
+	index  map[K]bool
+	data   map[K]V
+	err    error
+	closed bool
+	done   chan struct{}
+}
+
+// NewLoader creates loader, DefaultWait and DefaultMaxBatch are used when wait or maxBatch is zero
+func NewLoader[K comparable, V any](fetch BatchFunc[K, V], wait time.Duration, maxBatch int) *Loader[K, V] {
+	if wait <= 0 {
+		wait = DefaultWait
+	}
+
+	if maxBatch <= 0 {
+		maxBatch = DefaultMaxBatch
+	}
+
+	return &Loader[K, V]{fetch: fetch, wait: wait, maxBatch: maxBatch, cache: map[K]V{}}
+}
+
+// Load returns the value of the key, it blocks until the batch with the key is loaded
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	return l.LoadThunk(ctx, key)()
+}
+
+// LoadThunk adds the key to the current batch and returns function which waits for the value.
+// Use it to request several keys before waiting for any of them
+func (l *Loader[K, V]) LoadThunk(ctx context.Context, key K) func() (V, error) {
+	l.mutex.Lock()
+	l.stats.Loads++
+
+	if value, has := l.cache[key]; has {
+		l.stats.CacheHits++
+		l.mutex.Unlock()
+
+		return func() (V, error) {
+			return value, nil
+		}
+	}
+
+	if l.batch == nil {
+		l.batch = &batch[K, V]{index: map[K]bool{}, done: make(chan struct{})}
+		go l.dispatchAfterWait(ctx, l.batch)
+	}
+
+	b := l.batch
+
+	if !b.index[key] {
+		b.index[key] = true
+		b.keys = append(b.keys, key)
+
+		if len(b.keys) >= l.maxBatch {
+			l.batch = nil
+			go l.dispatch(ctx, b)
+		}
+	}
+
+	l.mutex.Unlock()
+
+	return func() (V, error) {
+		<-b.done
+
+		return b.data[key], b.err
+	}
+}
+
+// LoadAll loads all keys in the same batches and returns values in order of the keys
+func (l *Loader[K, V]) LoadAll(ctx context.Context, keys []K) ([]V, error) {
+	thunks := make([]func() (V, error), len(keys))
+
+	for i, key := range keys {
+		thunks[i] = l.LoadThunk(ctx, key)
+	}
+
+	values := make([]V, len(keys))
+
+	for i, thunk := range thunks {
+		value, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+
+		values[i] = value
+	}
+
+	return values, nil
+}
+
+// Prime adds the value to the cache, it returns false when the key is already cached
+func (l *Loader[K, V]) Prime(key K, value V) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if _, has := l.cache[key]; has {
+		return false
+	}
+
+	l.cache[key] = value
+
+	return true
+}
+
+// Clear removes the key from the cache, next Load fetches it again
+func (l *Loader[K, V]) Clear(key K) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	delete(l.cache, key)
+}
+
+func (l *Loader[K, V]) Stats() Stats {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.stats
+}
+
+func (l *Loader[K, V]) dispatchAfterWait(ctx context.Context, b *batch[K, V]) {
+	time.Sleep(l.wait)
+
+	l.mutex.Lock()
+	if l.batch == b {
+		l.batch = nil
+	}
+	l.mutex.Unlock()
+
+	l.dispatch(ctx, b)
+}
+
+func (l *Loader[K, V]) dispatch(ctx context.Context, b *batch[K, V]) {
+	l.mutex.Lock()
+	if b.closed {
+		l.mutex.Unlock()
+
+		return
+	}
+
+	b.closed = true
+	l.stats.Batches++
+	l.stats.Keys += len(b.keys)
+
+	if len(b.keys) == 1 {
+		l.stats.SingleKeyBatches++
+	}
+	l.mutex.Unlock()
+
+	defer close(b.done)
+
+	data, err := l.fetchSafe(ctx, b.keys)
+
+	b.data = data
+	b.err = err
+
+	if err != nil {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	for _, key := range b.keys {
+		l.cache[key] = data[key]
+	}
+}
+
+// fetchSafe converts panic of the batch function to error, otherwise all waiting resolvers would be blocked
+func (l *Loader[K, V]) fetchSafe(ctx context.Context, keys []K) (data map[K]V, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			data = nil
+			err = &PanicError{Value: r}
+		}
+	}()
+
+	return l.fetch(ctx, keys)
+}
+
+// PanicError is returned to all keys of the batch when the batch function panics
+type PanicError struct {
+	Value interface{}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("dataloader batch function panic: %v", e.Value)
+}
@@ -0,0 +1,185 @@
+package dataloader_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/coretrix/hitrix/pkg/graphql/dataloader"
+	"github.com/coretrix/hitrix/service"
+)
+
+type fetchRecorder struct {
+	mutex   sync.Mutex
+	batches [][]uint64
+}
+
+func (r *fetchRecorder) fetch(_ context.Context, keys []uint64) (map[uint64]string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	batch := append([]uint64{}, keys...)
+	sort.Slice(batch, func(i, j int) bool { return batch[i] < batch[j] })
+	r.batches = append(r.batches, batch)
+
+	result := map[uint64]string{}
+
+	for _, key := range keys {
+		if key != 404 {
+			result[key] = "value" + string(rune('0'+key))
+		}
+	}
+
+	return result, nil
+}
+
+func TestLoaderBatchesConcurrentLoads(t *testing.T) {
+	recorder := &fetchRecorder{}
+	loader := dataloader.NewLoader(recorder.fetch, 10*time.Millisecond, 0)
+
+	var wg sync.WaitGroup
+
+	values := make([]string, 4)
+
+	for i, key := range []uint64{1, 2, 3, 2} {
+		wg.Add(1)
+
+		go func(i int, key uint64) {
+			defer wg.Done()
+
+			value, err := loader.Load(context.Background(), key)
+			assert.Nil(t, err)
+
+			values[i] = value
+		}(i, key)
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, []string{"value1", "value2", "value3", "value2"}, values)
+	assert.Equal(t, [][]uint64{{1, 2, 3}}, recorder.batches)
+
+	value, err := loader.Load(context.Background(), 3)
+	assert.Nil(t, err)
+	assert.Equal(t, "value3", value)
+	assert.Len(t, recorder.batches, 1)
+
+	stats := loader.Stats()
+	assert.Equal(t, 5, stats.Loads)
+	assert.Equal(t, 1, stats.CacheHits)
+	assert.Equal(t, 1, stats.Batches)
+	assert.Equal(t, 3, stats.Keys)
+}
+
+func TestLoaderLoadAll(t *testing.T) {
+	recorder := &fetchRecorder{}
+	loader := dataloader.NewLoader(recorder.fetch, time.Millisecond, 2)
+
+	assert.True(t, loader.Prime(5, "primed"))
+	assert.False(t, loader.Prime(5, "other"))
+
+	values, err := loader.LoadAll(context.Background(), []uint64{1, 404, 3, 5, 4})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"value1", "", "value3", "primed", "value4"}, values)
+	assert.ElementsMatch(t, [][]uint64{{1, 404}, {3, 4}}, recorder.batches)
+
+	loader.Clear(1)
+
+	_, err = loader.Load(context.Background(), 404)
+	assert.Nil(t, err)
+
+	_, err = loader.Load(context.Background(), 1)
+	assert.Nil(t, err)
+	assert.Len(t, recorder.batches, 3)
+	assert.Equal(t, []uint64{1}, recorder.batches[2])
+}
+
+func TestLoaderErrors(t *testing.T) {
+	calls := 0
+	loader := dataloader.NewLoader(func(_ context.Context, keys []uint64) (map[uint64]string, error) {
+		calls++
+
+		if calls == 1 {
+			return nil, errors.New("mysql is down")
+		}
+
+		panic("broken")
+	}, time.Millisecond, 0)
+
+	_, err := loader.Load(context.Background(), 1)
+	assert.EqualError(t, err, "mysql is down")
+
+	_, err = loader.LoadAll(context.Background(), []uint64{1, 2})
+	assert.EqualError(t, err, "dataloader batch function panic: broken")
+	assert.Equal(t, 2, calls)
+}
+
+func TestLoadersForContext(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx := context.WithValue(context.Background(), service.GinKey, c)
+
+	recorder := &fetchRecorder{}
+	created := 0
+
+	create := func(ctx context.Context) *dataloader.Loader[uint64, string] {
+		created++
+
+		return dataloader.NewLoader(recorder.fetch, time.Millisecond, 0)
+	}
+
+	loader := dataloader.Get(ctx, "names", create)
+	assert.Same(t, loader, dataloader.Get(ctx, "names", create))
+	assert.Equal(t, 1, created)
+
+	assert.Panics(t, func() {
+		dataloader.Get(ctx, "names", func(ctx context.Context) *dataloader.Loader[string, string] {
+			return nil
+		})
+	})
+
+	for _, key := range []uint64{1, 2, 3} {
+		_, err := loader.Load(ctx, key)
+		assert.Nil(t, err)
+	}
+
+	loaders := dataloader.ForContext(ctx)
+	assert.Equal(t, dataloader.Stats{Loads: 3, Batches: 3, Keys: 3, SingleKeyBatches: 3}, loaders.Stats()["names"])
+	assert.Empty(t, loaders.NPlusOne(4))
+	assert.Equal(t, []string{
+		"possible N+1: dataloader names loaded 3 batches with one key out of 3 batches, use LoadAll or LoadThunk",
+	}, loaders.NPlusOne(3))
+
+	assert.NotSame(t, loaders, dataloader.Attach(c))
+	assert.Empty(t, dataloader.ForContext(ctx).Stats())
+}
+
+func TestLoadersForContextConcurrent(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx := context.WithValue(context.Background(), service.GinKey, c)
+
+	results := make([]*dataloader.Loaders, 20)
+
+	wg := sync.WaitGroup{}
+	for i := range results {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			results[i] = dataloader.ForContext(ctx)
+		}(i)
+	}
+
+	wg.Wait()
+
+	for _, loaders := range results {
+		assert.Same(t, results[0], loaders)
+	}
+}
@@ -0,0 +1,94 @@
+package dataloader
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	redisearch "github.com/coretrix/beeorm-redisearch-plugin"
+	"github.com/latolukasz/beeorm/v2"
+
+	"github.com/coretrix/hitrix/datalayer"
+)
+
+const referencePageSize = 1000
+
+// EntitiesByIDs returns batch function which loads entities with LoadByIDs, so local and redis cache of the entity is used.
+// E has to be pointer to the entity, for example *entity.UserEntity. Not found entities are nil
+func EntitiesByIDs[E beeorm.Entity](ormService *datalayer.ORM, references ...string) BatchFunc[uint64, E] {
+	return func(_ context.Context, ids []uint64) (map[uint64]E, error) {
+		entities := make([]E, 0, len(ids))
+		ormService.LoadByIDs(ids, &entities, references...)
+
+		result := make(map[uint64]E, len(ids))
+
+		for i, id := range ids {
+			if reflect.ValueOf(entities[i]).IsNil() {
+				continue
+			}
+
+			result[id] = entities[i]
+		}
+
+		return result, nil
+	}
+}
+
+// EntitiesByReference returns batch function which loads entities referencing the IDs in the field, for example
+// all permissions of the resources. The field has to be searchable in the RedisSearch index of the entity.
+// IDs without entities get empty slice
+func EntitiesByReference[E beeorm.Entity](ormService *datalayer.ORM, field string, references ...string) BatchFunc[uint64, []E] {
+	return func(_ context.Context, ids []uint64) (map[uint64][]E, error) {
+		result := make(map[uint64][]E, len(ids))
+
+		for _, id := range ids {
+			result[id] = make([]E, 0)
+		}
+
+		query := redisearch.NewRedisSearchQuery().FilterUint(field, ids...)
+
+		for page := 1; ; page++ {
+			entities := make([]E, 0)
+			total := ormService.RedisSearch(query, beeorm.NewPager(page, referencePageSize), &entities, references...)
+
+			for _, entity := range entities {
+				id, err := referenceID(entity, field)
+				if err != nil {
+					return nil, err
+				}
+
+				if _, has := result[id]; has {
+					result[id] = append(result[id], entity)
+				}
+			}
+
+			if len(entities) < referencePageSize || uint64(page*referencePageSize) >= total {
+				break
+			}
+		}
+
+		return result, nil
+	}
+}
+
+// referenceID returns ID stored in the field, the field can be reference to other entity or uint ID
+func referenceID(entity beeorm.Entity, field string) (uint64, error) {
+	value := reflect.Indirect(reflect.ValueOf(entity)).FieldByName(field)
+
+	switch {
+	case !value.IsValid():
+		return 0, fmt.Errorf("field %s not found in %T", field, entity)
+	case value.Kind() == reflect.Pointer && value.IsNil():
+		return 0, nil
+	case value.Kind() == reflect.Pointer && value.Type().Implements(reflect.TypeOf((*beeorm.Entity)(nil)).Elem()):
+		return value.Interface().(beeorm.Entity).GetID(), nil
+	case value.Kind() == reflect.Pointer:
+		value = value.Elem()
+	}
+
+	if !value.CanUint() {
+		return 0, fmt.Errorf("field %s in %T is not reference or uint", field, entity)
+	}
+
+	return value.Uint(), nil
+}
@@ -0,0 +1,97 @@
+package federation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/latolukasz/beeorm/v2"
+
+	"github.com/coretrix/hitrix/pkg/graphql/dataloader"
+)
+
+var ErrEntityNotFound = errors.New("entity not found")
+
+// ID is type of @key field, gqlgen binds ID to string by default
+type ID interface {
+	~string | ~int | ~int64 | ~uint64
+}
+
+// LoadEntity loads entity of @key representation. gqlgen resolves representations of one _entities query concurrently,
+// so they are loaded with one LoadByIDs. E has to be pointer to the entity
+func LoadEntity[E beeorm.Entity, K ID](ctx context.Context, id K, references ...string) (E, error) {
+	var empty E
+
+	entityID, err := ParseID(id)
+	if err != nil {
+		return empty, err
+	}
+
+	entity, err := dataloader.EntityLoader[E](ctx, references...).Load(ctx, entityID)
+	if err != nil {
+		return empty, err
+	}
+
+	if reflect.ValueOf(entity).IsNil() {
+		return empty, fmt.Errorf("%w: %T %d", ErrEntityNotFound, entity, entityID)
+	}
+
+	return entity, nil
+}
+
+// LoadEntities loads entities for resolvers with @entityResolver(multi: true), not found entities are nil
+func LoadEntities[E beeorm.Entity, K ID](ctx context.Context, ids []K, references ...string) ([]E, error) {
+	entityIDs := make([]uint64, len(ids))
+
+	for i, id := range ids {
+		entityID, err := ParseID(id)
+		if err != nil {
+			return nil, err
+		}
+
+		entityIDs[i] = entityID
+	}
+
+	return dataloader.EntityLoader[E](ctx, references...).LoadAll(ctx, entityIDs)
+}
+
+// ResolveReference loads entity of @key representation and converts it to gql model
+func ResolveReference[E beeorm.Entity, M any, K ID](ctx context.Context, id K, convert func(entity E) M, references ...string) (M, error) {
+	entity, err := LoadEntity[E](ctx, id, references...)
+	if err != nil {
+		var empty M
+
+		return empty, err
+	}
+
+	return convert(entity), nil
+}
+
+// ParseID converts @key field value to entity ID
+func ParseID[K ID](id K) (uint64, error) {
+	value := reflect.ValueOf(id)
+
+	switch value.Kind() {
+	case reflect.String:
+		entityID, err := strconv.ParseUint(value.String(), 10, 64)
+		if err != nil || entityID == 0 {
+			return 0, fmt.Errorf("invalid entity ID %q", value.String())
+		}
+
+		return entityID, nil
+	case reflect.Int, reflect.Int64:
+		if value.Int() <= 0 {
+			return 0, fmt.Errorf("invalid entity ID %d", value.Int())
+		}
+
+		return uint64(value.Int()), nil
+	default:
+		if value.Uint() == 0 {
+			return 0, errors.New("invalid entity ID 0")
+		}
+
+		return value.Uint(), nil
+	}
+}
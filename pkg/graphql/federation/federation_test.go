@@ -0,0 +1,85 @@
+package federation_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/coretrix/hitrix/pkg/graphql/federation"
+)
+
+type userID string
+
+func TestValidateSubgraphSDL(t *testing.T) {
+	valid := `
+extend schema @link(url: "https://specs.apollo.dev/federation/v2.0", import: ["@key", {name: "@shareable", as: "@share"}])
+
+type User @key(fields: "id") @key(fields: "account { id }") {
+  id: ID!
+  name: String! @share
+  account: Account! @federation__external
+}
+
+type Account {
+  id: ID!
+}
+
+extend type Query {
+  me: User
+}`
+	assert.Nil(t, federation.ValidateSubgraphSDL(valid))
+
+	err := federation.ValidateSubgraphSDL(`
+type User @key(fields: "id") {
+  id: ID!
+}`)
+	assert.EqualError(t, err, `input: federation v2 requires extend schema @link(url: "https://specs.apollo.dev/federation/v2.0", import: [...]) in the schema
+subgraph:2: @key is not imported in @link, add it to import or use @federation__key
+`)
+
+	err = federation.ValidateSubgraphSDL(`
+extend schema @link(url: "https://specs.apollo.dev/federation/v2.3", import: ["@key", "@unknown"])
+
+type User @key(fields: "uuid") @key(fields: "account { number }") {
+  id: ID!
+  account: Account! @shareable
+}
+
+type Account {
+  id: ID!
+}`)
+	assert.EqualError(t, err, `subgraph:2: @unknown is not federation v2 directive
+subgraph:4: @key(fields: "uuid"): field uuid not found in User
+subgraph:4: @key(fields: "account { number }"): field number not found in Account
+subgraph:6: @shareable is not imported in @link, add it to import or use @federation__shareable
+`)
+
+	assert.NotNil(t, federation.ValidateSubgraphSDL("type User {"))
+}
+
+func TestParseID(t *testing.T) {
+	id, err := federation.ParseID("12")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(12), id)
+
+	id, err = federation.ParseID(userID("7"))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(7), id)
+
+	id, err = federation.ParseID(int64(3))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3), id)
+
+	id, err = federation.ParseID(uint64(5))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(5), id)
+
+	_, err = federation.ParseID("abc")
+	assert.EqualError(t, err, `invalid entity ID "abc"`)
+
+	_, err = federation.ParseID(-1)
+	assert.EqualError(t, err, "invalid entity ID -1")
+
+	_, err = federation.ParseID(uint64(0))
+	assert.EqualError(t, err, "invalid entity ID 0")
+}
@@ -0,0 +1,218 @@
+package federation
+
+import (
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+const (
+	LinkURLPrefix     = "https://specs.apollo.dev/federation/v2."
+	namespacePrefix   = "federation__"
+	keyDirectiveName  = "key"
+	linkDirectiveName = "link"
+)
+
+// directives of federation v2 which have to be imported in @link or used with federation__ prefix
+var directives = map[string]bool{
+	"key":              true,
+	"shareable":        true,
+	"external":         true,
+	"requires":         true,
+	"provides":         true,
+	"extends":          true,
+	"override":         true,
+	"inaccessible":     true,
+	"tag":              true,
+	"interfaceObject":  true,
+	"composeDirective": true,
+}
+
+// ValidateSubgraphSDL checks SDL returned by _service query the way the gateway composes it: schema has to link
+// federation v2 spec, used federation directives have to be imported and @key fields have to exist
+func ValidateSubgraphSDL(sdl string) error {
+	document, err := parser.ParseSchema(&ast.Source{Name: "subgraph", Input: sdl})
+	if err != nil {
+		return err
+	}
+
+	errs := gqlerror.List{}
+
+	imports, linked := linkImports(document, &errs)
+	if !linked {
+		errs = append(errs, gqlerror.Errorf(
+			`federation v2 requires extend schema @link(url: "%s0", import: [...]) in the schema`, LinkURLPrefix,
+		))
+	}
+
+	custom := map[string]bool{}
+	for _, directive := range document.Directives {
+		custom[directive.Name] = true
+	}
+
+	types := map[string]ast.FieldList{}
+	definitions := append(append(ast.DefinitionList{}, document.Definitions...), document.Extensions...)
+
+	for _, definition := range definitions {
+		types[definition.Name] = append(types[definition.Name], definition.Fields...)
+	}
+
+	for _, definition := range definitions {
+		for _, directive := range definition.Directives {
+			name := resolveDirective(directive, imports, custom, &errs)
+
+			if name == keyDirectiveName {
+				validateKeyFields(definition, directive, types, &errs)
+			}
+		}
+
+		for _, field := range definition.Fields {
+			for _, directive := range field.Directives {
+				resolveDirective(directive, imports, custom, &errs)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// linkImports returns federation directives imported in @link, key is the name used in the schema
+func linkImports(document *ast.SchemaDocument, errs *gqlerror.List) (map[string]string, bool) {
+	imports := map[string]string{}
+	linked := false
+
+	schemas := append(append(ast.SchemaDefinitionList{}, document.Schema...), document.SchemaExtension...)
+
+	for _, schema := range schemas {
+		for _, link := range schema.Directives.ForNames(linkDirectiveName) {
+			url := link.Arguments.ForName("url")
+			if url == nil || !strings.HasPrefix(url.Value.Raw, LinkURLPrefix) {
+				continue
+			}
+
+			linked = true
+
+			importArgument := link.Arguments.ForName("import")
+			if importArgument == nil {
+				continue
+			}
+
+			for _, child := range importArgument.Value.Children {
+				name, alias := child.Value.Raw, child.Value.Raw
+
+				if child.Value.Kind == ast.ObjectValue {
+					name, alias = "", ""
+
+					for _, field := range child.Value.Children {
+						switch field.Name {
+						case "name":
+							name = field.Value.Raw
+						case "as":
+							alias = field.Value.Raw
+						}
+					}
+
+					if alias == "" {
+						alias = name
+					}
+				}
+
+				if !strings.HasPrefix(name, "@") {
+					continue
+				}
+
+				if !directives[strings.TrimPrefix(name, "@")] {
+					*errs = append(*errs, gqlerror.ErrorPosf(link.Position, "%s is not federation v2 directive", name))
+
+					continue
+				}
+
+				imports[strings.TrimPrefix(alias, "@")] = strings.TrimPrefix(name, "@")
+			}
+		}
+	}
+
+	return imports, linked
+}
+
+// resolveDirective returns federation name of the directive, empty string for other directives
+func resolveDirective(directive *ast.Directive, imports map[string]string, custom map[string]bool, errs *gqlerror.List) string {
+	if name, ok := imports[directive.Name]; ok {
+		return name
+	}
+
+	if strings.HasPrefix(directive.Name, namespacePrefix) && directives[strings.TrimPrefix(directive.Name, namespacePrefix)] {
+		return strings.TrimPrefix(directive.Name, namespacePrefix)
+	}
+
+	if directives[directive.Name] && !custom[directive.Name] {
+		*errs = append(*errs, gqlerror.ErrorPosf(
+			directive.Position, "@%s is not imported in @link, add it to import or use @%s%s", directive.Name, namespacePrefix, directive.Name,
+		))
+	}
+
+	return ""
+}
+
+func validateKeyFields(definition *ast.Definition, directive *ast.Directive, types map[string]ast.FieldList, errs *gqlerror.List) {
+	fields := directive.Arguments.ForName("fields")
+	if fields == nil || strings.TrimSpace(fields.Value.Raw) == "" {
+		*errs = append(*errs, gqlerror.ErrorPosf(directive.Position, "@key on %s requires fields", definition.Name))
+
+		return
+	}
+
+	query, err := parser.ParseQuery(&ast.Source{Input: "{" + fields.Value.Raw + "}"})
+	if err != nil || len(query.Operations) != 1 {
+		*errs = append(*errs, gqlerror.ErrorPosf(directive.Position, "@key on %s has invalid fields %q", definition.Name, fields.Value.Raw))
+
+		return
+	}
+
+	validateFieldSet(definition.Name, query.Operations[0].SelectionSet, directive, fields.Value.Raw, types, errs)
+}
+
+func validateFieldSet(
+	typeName string,
+	selectionSet ast.SelectionSet,
+	directive *ast.Directive,
+	raw string,
+	types map[string]ast.FieldList,
+	errs *gqlerror.List,
+) {
+	for _, selection := range selectionSet {
+		selected, ok := selection.(*ast.Field)
+		if !ok {
+			*errs = append(*errs, gqlerror.ErrorPosf(directive.Position, "@key(fields: %q) can not use fragments", raw))
+
+			continue
+		}
+
+		field := types[typeName].ForName(selected.Name)
+		if field == nil {
+			*errs = append(*errs, gqlerror.ErrorPosf(
+				directive.Position, "@key(fields: %q): field %s not found in %s", raw, selected.Name, typeName,
+			))
+
+			continue
+		}
+
+		if len(selected.SelectionSet) > 0 {
+			validateFieldSet(field.Type.Name(), selected.SelectionSet, directive, raw, types, errs)
+		}
+	}
+}
+
+// IsSubgraph returns true when the schema was generated with gqlgen federation plugin
+func IsSubgraph(executableSchema graphql.ExecutableSchema) bool {
+	query := executableSchema.Schema().Query
+
+	return query != nil && query.Fields.ForName("_service") != nil
+}
@@ -0,0 +1,57 @@
+package limit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/latolukasz/beeorm/v2"
+
+	"github.com/coretrix/hitrix/service/component/clock"
+)
+
+const budgetKeyPrefix = "graphql_complexity_budget"
+
+// Budget is the complexity one client can use in the time window
+type Budget struct {
+	Limit  int
+	Window time.Duration
+	Store  BudgetStore
+	// ClientFunc returns identifier of the client, for example IP or user ID. Operations without client are not limited
+	ClientFunc func(ctx context.Context) string
+}
+
+type BudgetStore interface {
+	// Consume adds the complexity to the client usage in current window and returns the usage
+	Consume(client string, complexity int, window time.Duration) int
+}
+
+func (b *Budget) client(ctx context.Context) string {
+	if b.ClientFunc == nil {
+		return ""
+	}
+
+	return b.ClientFunc(ctx)
+}
+
+// RedisBudgetStore counts the usage in fixed windows shared by all instances
+type RedisBudgetStore struct {
+	Redis beeorm.RedisCache
+	Clock clock.IClock
+}
+
+func (s *RedisBudgetStore) Consume(client string, complexity int, window time.Duration) int {
+	windowSeconds := int64(window / time.Second)
+	if windowSeconds <= 0 {
+		windowSeconds = 1
+	}
+
+	key := fmt.Sprintf("%s:%s:%d", budgetKeyPrefix, client, s.Clock.Now().Unix()/windowSeconds)
+
+	used := s.Redis.IncrBy(key, int64(complexity))
+	if used == int64(complexity) {
+		s.Redis.Expire(key, time.Duration(windowSeconds)*time.Second)
+	}
+
+	return int(used)
+}
@@ -0,0 +1,253 @@
+package limit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const (
+	complexityExtension = "ComplexityLimit"
+	maxInt              = int(^uint(0) >> 1)
+)
+
+// ComplexityLimit rejects operations with complexity higher than MaxComplexity and operations of clients
+// which used their Budget. Stats are available with extension.GetComplexityStats
+type ComplexityLimit struct {
+	// MaxComplexity of single operation, 0 means no limit
+	MaxComplexity int
+	// Budget limits complexity of all operations of the client in the time window, nil means no budget
+	Budget   *Budget
+	OnReject RejectFunc
+
+	es graphql.ExecutableSchema
+}
+
+var _ interface {
+	graphql.OperationContextMutator
+	graphql.HandlerExtension
+} = &ComplexityLimit{}
+
+func (c *ComplexityLimit) ExtensionName() string {
+	return complexityExtension
+}
+
+func (c *ComplexityLimit) Validate(schema graphql.ExecutableSchema) error {
+	c.es = schema
+
+	return nil
+}
+
+func (c *ComplexityLimit) MutateOperationContext(ctx context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	op := operation(rc)
+	if op == nil {
+		return nil
+	}
+
+	complexity := Calculate(c.es, op, rc.Variables)
+
+	rc.Stats.SetExtension(complexityExtension, &extension.ComplexityStats{
+		Complexity:      complexity,
+		ComplexityLimit: c.MaxComplexity,
+	})
+
+	if c.MaxComplexity > 0 && complexity > c.MaxComplexity {
+		return reject(ctx, rc, c.OnReject, ErrComplexityLimit, "operation has complexity %d, which exceeds the limit of %d", complexity, c.MaxComplexity)
+	}
+
+	if c.Budget == nil {
+		return nil
+	}
+
+	client := c.Budget.client(ctx)
+	if client == "" {
+		return nil
+	}
+
+	if used := c.Budget.Store.Consume(client, complexity, c.Budget.Window); used > c.Budget.Limit {
+		return reject(ctx, rc, c.OnReject, ErrComplexityBudgetLimit, "client used complexity %d, which exceeds the budget of %d", used, c.Budget.Limit)
+	}
+
+	return nil
+}
+
+// Calculate returns complexity of the operation. Fields annotated with the cost directive use its value and multipliers,
+// other fields use complexity functions generated by gqlgen or 1 + complexity of the children
+func Calculate(es graphql.ExecutableSchema, op *ast.OperationDefinition, variables map[string]interface{}) int {
+	walker := &complexityWalker{es: es, schema: es.Schema(), variables: variables}
+
+	return walker.selectionSetComplexity(op.SelectionSet)
+}
+
+type complexityWalker struct {
+	es        graphql.ExecutableSchema
+	schema    *ast.Schema
+	variables map[string]interface{}
+}
+
+func (w *complexityWalker) selectionSetComplexity(selectionSet ast.SelectionSet) int {
+	complexity := 0
+
+	for _, selection := range selectionSet {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if selection.Definition == nil || strings.HasPrefix(selection.Name, "__") {
+				continue
+			}
+
+			childComplexity := 0
+
+			if typeDefinition := w.schema.Types[selection.Definition.Type.Name()]; typeDefinition != nil {
+				switch typeDefinition.Kind {
+				case ast.Object, ast.Interface, ast.Union:
+					childComplexity = w.selectionSetComplexity(selection.SelectionSet)
+				}
+			}
+
+			args := selection.ArgumentMap(w.variables)
+			fieldComplexity := 0
+
+			if selection.ObjectDefinition != nil && selection.ObjectDefinition.Kind == ast.Interface {
+				for _, implementor := range w.schema.GetPossibleTypes(selection.ObjectDefinition) {
+					implementorComplexity := w.fieldComplexity(implementor, selection.Name, childComplexity, args)
+					if implementorComplexity > fieldComplexity {
+						fieldComplexity = implementorComplexity
+					}
+				}
+			} else {
+				fieldComplexity = w.fieldComplexity(selection.ObjectDefinition, selection.Name, childComplexity, args)
+			}
+
+			complexity = safeAdd(complexity, fieldComplexity)
+		case *ast.FragmentSpread:
+			if selection.Definition != nil {
+				complexity = safeAdd(complexity, w.selectionSetComplexity(selection.Definition.SelectionSet))
+			}
+		case *ast.InlineFragment:
+			complexity = safeAdd(complexity, w.selectionSetComplexity(selection.SelectionSet))
+		}
+	}
+
+	return complexity
+}
+
+func (w *complexityWalker) fieldComplexity(object *ast.Definition, field string, childComplexity int, args map[string]interface{}) int {
+	if object == nil {
+		return safeAdd(1, childComplexity)
+	}
+
+	if fieldDefinition := object.Fields.ForName(field); fieldDefinition != nil {
+		if directive := fieldDefinition.Directives.ForName(CostDirectiveName); directive != nil {
+			return costComplexity(directive, childComplexity, args)
+		}
+	}
+
+	if w.es != nil {
+		if customComplexity, ok := w.es.Complexity(object.Name, field, childComplexity, args); ok && customComplexity >= childComplexity {
+			return customComplexity
+		}
+	}
+
+	return safeAdd(1, childComplexity)
+}
+
+func costComplexity(directive *ast.Directive, childComplexity int, args map[string]interface{}) int {
+	value := 1
+
+	if argument := directive.Arguments.ForName("value"); argument != nil {
+		if directiveValue, err := argument.Value.Value(nil); err == nil {
+			value = toInt(directiveValue)
+		}
+	}
+
+	if argument := directive.Arguments.ForName("multipliers"); argument != nil {
+		multipliers, _ := argument.Value.Value(nil)
+		list, _ := multipliers.([]interface{})
+
+		for _, path := range list {
+			name, _ := path.(string)
+			if multiplier := toInt(argumentValue(args, name)); multiplier > 0 {
+				childComplexity = safeMultiply(childComplexity, multiplier)
+			}
+		}
+	}
+
+	return safeAdd(value, childComplexity)
+}
+
+// argumentValue returns value of the argument, dots select fields of input objects, for example "pager.pageSize"
+func argumentValue(args map[string]interface{}, path string) interface{} {
+	var value interface{} = args
+
+	for _, name := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		value = object[name]
+	}
+
+	return value
+}
+
+func toInt(value interface{}) int {
+	switch value := value.(type) {
+	case json.Number:
+		number, _ := value.Int64()
+
+		return int(number)
+	case nil:
+		return 0
+	}
+
+	reflectValue := reflect.Indirect(reflect.ValueOf(value))
+
+	switch {
+	case !reflectValue.IsValid():
+		return 0
+	case reflectValue.CanInt():
+		return int(reflectValue.Int())
+	case reflectValue.CanUint():
+		return int(reflectValue.Uint())
+	case reflectValue.CanFloat():
+		return int(reflectValue.Float())
+	default:
+		return 0
+	}
+}
+
+// safeAdd and safeMultiply saturate at max int and ignore negative values, so the complexity can't overflow
+func safeAdd(a, b int) int {
+	if a < 0 {
+		a = 0
+	}
+
+	if b < 0 {
+		b = 0
+	}
+
+	if a > maxInt-b {
+		return maxInt
+	}
+
+	return a + b
+}
+
+func safeMultiply(a, b int) int {
+	if a <= 0 || b <= 0 {
+		return 0
+	}
+
+	if a > maxInt/b {
+		return maxInt
+	}
+
+	return a * b
+}
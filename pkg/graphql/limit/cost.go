@@ -0,0 +1,24 @@
+package limit
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// CostDirectiveName is the directive used to annotate field cost:
+//
+//	directive @cost(value: Int!, multipliers: [String!]) on FIELD_DEFINITION
+//
+//	type Query {
+//	    products(pager: Pager!): [Product!]! @cost(value: 5, multipliers: ["pager.pageSize"])
+//	}
+//
+// Complexity of the field is value + complexity of the selected children * product of the multiplier arguments
+const CostDirectiveName = "cost"
+
+// Cost is the runtime implementation of the cost directive, it only calls the resolver.
+// Set it in generated DirectiveRoot or set `skip_runtime: true` for the directive in gqlgen.yml
+func Cost(ctx context.Context, _ interface{}, next graphql.Resolver, _ int, _ []string) (interface{}, error) {
+	return next(ctx)
+}
@@ -0,0 +1,86 @@
+package limit
+
+import (
+	"context"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const depthExtension = "DepthLimit"
+
+// DepthLimit rejects operations nested deeper than MaxDepth. Introspection fields are not counted,
+// so playground keeps working with low limits
+type DepthLimit struct {
+	MaxDepth int
+	OnReject RejectFunc
+}
+
+var _ interface {
+	graphql.OperationContextMutator
+	graphql.HandlerExtension
+} = &DepthLimit{}
+
+func (d *DepthLimit) ExtensionName() string {
+	return depthExtension
+}
+
+func (d *DepthLimit) Validate(_ graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (d *DepthLimit) MutateOperationContext(ctx context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	op := operation(rc)
+	if op == nil || d.MaxDepth <= 0 {
+		return nil
+	}
+
+	operationDepth := 0
+
+	for _, selection := range op.SelectionSet {
+		if field, isField := selection.(*ast.Field); isField && strings.HasPrefix(field.Name, "__") {
+			continue
+		}
+
+		if selectionDepth := selectionDepth(ast.SelectionSet{selection}); selectionDepth > operationDepth {
+			operationDepth = selectionDepth
+		}
+	}
+
+	if operationDepth > d.MaxDepth {
+		return reject(ctx, rc, d.OnReject, ErrDepthLimit, "operation has depth %d, which exceeds the limit of %d", operationDepth, d.MaxDepth)
+	}
+
+	return nil
+}
+
+// selectionDepth returns number of nested fields with selections. Unlike depth.GetOperationDepth
+// fragments do not add a level, so moving fields to a fragment does not change the depth
+func selectionDepth(selections ast.SelectionSet) int {
+	maxDepth := 0
+
+	for _, selection := range selections {
+		depth := 0
+
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if len(selection.SelectionSet) > 0 {
+				depth = selectionDepth(selection.SelectionSet) + 1
+			}
+		case *ast.FragmentSpread:
+			if selection.Definition != nil {
+				depth = selectionDepth(selection.Definition.SelectionSet)
+			}
+		case *ast.InlineFragment:
+			depth = selectionDepth(selection.SelectionSet)
+		}
+
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+	}
+
+	return maxDepth
+}
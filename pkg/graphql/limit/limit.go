@@ -0,0 +1,54 @@
+package limit
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const (
+	ErrDepthLimit            = "DEPTH_LIMIT_EXCEEDED"
+	ErrComplexityLimit       = "COMPLEXITY_LIMIT_EXCEEDED"
+	ErrComplexityBudgetLimit = "COMPLEXITY_BUDGET_EXCEEDED"
+)
+
+// RejectFunc is called when the operation is rejected, hitrix logs the rejection with the error logger
+type RejectFunc func(ctx context.Context, operationName string, err *gqlerror.Error)
+
+func reject(ctx context.Context, rc *graphql.OperationContext, onReject RejectFunc, code, format string, args ...interface{}) *gqlerror.Error {
+	err := gqlerror.Errorf(format, args...)
+	errcode.Set(err, code)
+
+	if onReject != nil {
+		onReject(ctx, operationName(rc), err)
+	}
+
+	return err
+}
+
+func operation(rc *graphql.OperationContext) *ast.OperationDefinition {
+	if rc.Operation != nil {
+		return rc.Operation
+	}
+
+	if rc.Doc == nil {
+		return nil
+	}
+
+	return rc.Doc.Operations.ForName(rc.OperationName)
+}
+
+func operationName(rc *graphql.OperationContext) string {
+	if rc.OperationName != "" {
+		return rc.OperationName
+	}
+
+	if op := operation(rc); op != nil && op.Name != "" {
+		return op.Name
+	}
+
+	return "anonymous"
+}
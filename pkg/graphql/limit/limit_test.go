@@ -0,0 +1,153 @@
+package limit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/stretchr/testify/assert"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/coretrix/hitrix/pkg/graphql/depth"
+	"github.com/coretrix/hitrix/pkg/graphql/limit"
+)
+
+const schema = `
+directive @cost(value: Int!, multipliers: [String!]) on FIELD_DEFINITION
+
+input Pager {
+	pageSize: Int!
+}
+
+type Query {
+	user(id: ID!): User
+	users(pager: Pager!): [User!]! @cost(value: 5, multipliers: ["pager.pageSize"])
+}
+
+type User {
+	name: String!
+	friends(first: Int!): [User!]! @cost(value: 2, multipliers: ["first"])
+}
+`
+
+type executableSchema struct {
+	schema *ast.Schema
+}
+
+func (e *executableSchema) Schema() *ast.Schema {
+	return e.schema
+}
+
+func (e *executableSchema) Complexity(_, _ string, _ int, _ map[string]interface{}) (int, bool) {
+	return 0, false
+}
+
+func (e *executableSchema) Exec(_ context.Context) graphql.ResponseHandler {
+	return nil
+}
+
+type memoryBudgetStore struct {
+	used map[string]int
+}
+
+func (s *memoryBudgetStore) Consume(client string, complexity int, _ time.Duration) int {
+	s.used[client] += complexity
+
+	return s.used[client]
+}
+
+func operationContext(t *testing.T, es *executableSchema, query string, variables map[string]interface{}) *graphql.OperationContext {
+	doc, err := gqlparser.LoadQuery(es.schema, query)
+	assert.Nil(t, err)
+
+	return &graphql.OperationContext{Doc: doc, Variables: variables}
+}
+
+func TestDepthLimit(t *testing.T) {
+	es := &executableSchema{schema: gqlparser.MustLoadSchema(&ast.Source{Input: schema})}
+
+	var rejected []string
+
+	depthLimit := &limit.DepthLimit{MaxDepth: 2, OnReject: func(_ context.Context, operationName string, _ *gqlerror.Error) {
+		rejected = append(rejected, operationName)
+	}}
+
+	friends := operationContext(t, es,
+		`query Friends { user(id: 1) { ...UserFields } } fragment UserFields on User { friends(first: 2) { name } }`, nil)
+
+	err := depthLimit.MutateOperationContext(context.Background(), friends)
+	assert.Nil(t, err)
+
+	// depth.GetOperationDepth keeps counting fragments as levels
+	friends.Operation = friends.Doc.Operations[0]
+	assert.Equal(t, 3, depth.GetOperationDepth(graphql.WithOperationContext(context.Background(), friends)))
+
+	err = depthLimit.MutateOperationContext(context.Background(), operationContext(t, es,
+		`query Inline { user(id: 1) { ... on User { friends(first: 2) { name } } } }`, nil))
+	assert.Nil(t, err)
+
+	err = depthLimit.MutateOperationContext(context.Background(), operationContext(t, es,
+		`query Nested { user(id: 1) { friends(first: 2) { friends(first: 2) { name } } } }`, nil))
+	assert.NotNil(t, err)
+	assert.Equal(t, limit.ErrDepthLimit, err.Extensions["code"])
+	assert.Equal(t, []string{"Nested"}, rejected)
+
+	err = depthLimit.MutateOperationContext(context.Background(), operationContext(t, es,
+		`{ __schema { types { fields { type { ofType { ofType { name } } } } } } }`, nil))
+	assert.Nil(t, err)
+}
+
+func TestCalculate(t *testing.T) {
+	es := &executableSchema{schema: gqlparser.MustLoadSchema(&ast.Source{Input: schema})}
+
+	rc := operationContext(t, es, `query ($size: Int!) { users(pager: {pageSize: $size}) { name friends(first: 3) { name } } }`,
+		map[string]interface{}{"size": int64(10)})
+
+	// users: 5 + 10 * (name 1 + friends (2 + 3 * name 1))
+	assert.Equal(t, 65, limit.Calculate(es, rc.Doc.Operations[0], rc.Variables))
+
+	rc = operationContext(t, es, `{ user(id: 1) { name } }`, nil)
+	assert.Equal(t, 2, limit.Calculate(es, rc.Doc.Operations[0], rc.Variables))
+}
+
+func TestComplexityLimit(t *testing.T) {
+	es := &executableSchema{schema: gqlparser.MustLoadSchema(&ast.Source{Input: schema})}
+	store := &memoryBudgetStore{used: map[string]int{}}
+
+	var rejected []string
+
+	complexityLimit := &limit.ComplexityLimit{
+		MaxComplexity: 50,
+		Budget: &limit.Budget{
+			Limit: 4,
+			Store: store,
+			ClientFunc: func(_ context.Context) string {
+				return "127.0.0.1"
+			},
+		},
+		OnReject: func(_ context.Context, operationName string, _ *gqlerror.Error) {
+			rejected = append(rejected, operationName)
+		},
+	}
+	assert.Nil(t, complexityLimit.Validate(es))
+
+	rc := operationContext(t, es, `query Users { users(pager: {pageSize: 100}) { name } }`, nil)
+	err := complexityLimit.MutateOperationContext(context.Background(), rc)
+	assert.NotNil(t, err)
+	assert.Equal(t, limit.ErrComplexityLimit, err.Extensions["code"])
+	assert.Equal(t, 0, store.used["127.0.0.1"])
+
+	rc = operationContext(t, es, `{ user(id: 1) { name } }`, nil)
+	assert.Nil(t, complexityLimit.MutateOperationContext(context.Background(), rc))
+	assert.Equal(t, 2, rc.Stats.GetExtension("ComplexityLimit").(*extension.ComplexityStats).Complexity)
+	assert.Nil(t, complexityLimit.MutateOperationContext(context.Background(), rc))
+
+	err = complexityLimit.MutateOperationContext(context.Background(), rc)
+	assert.NotNil(t, err)
+	assert.Equal(t, limit.ErrComplexityBudgetLimit, err.Extensions["code"])
+	assert.Equal(t, []string{"Users", "anonymous"}, rejected)
+}
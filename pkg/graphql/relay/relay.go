@@ -0,0 +1,65 @@
+package relay
+
+import (
+	"reflect"
+)
+
+type PageInfo struct {
+	HasNextPage     bool
+	HasPreviousPage bool
+	StartCursor     *string
+	EndCursor       *string
+}
+
+type Edge struct {
+	Cursor string
+	Node   interface{}
+}
+
+type Connection struct {
+	Edges    []*Edge
+	PageInfo *PageInfo
+}
+
+// CursorFunc returns the cursor of the row, usually crud.Crud.NewCursor
+type CursorFunc func(row interface{}) string
+
+// NewConnection builds the connection from rows loaded with crud.Crud.KeysetPager, so the extra row means next page exists.
+// hasPreviousPage should be true when the rows were loaded after cursor
+func NewConnection(rows interface{}, pageSize int, hasPreviousPage bool, cursorFunc CursorFunc) *Connection {
+	rowsValue := reflect.ValueOf(rows)
+	length := rowsValue.Len()
+
+	connection := &Connection{
+		Edges:    make([]*Edge, 0, length),
+		PageInfo: &PageInfo{HasPreviousPage: hasPreviousPage},
+	}
+
+	if length > pageSize {
+		connection.PageInfo.HasNextPage = true
+		length = pageSize
+	}
+
+	for i := 0; i < length; i++ {
+		row := rowsValue.Index(i).Interface()
+		connection.Edges = append(connection.Edges, &Edge{Cursor: cursorFunc(row), Node: row})
+	}
+
+	if length > 0 {
+		connection.PageInfo.StartCursor = &connection.Edges[0].Cursor
+		connection.PageInfo.EndCursor = &connection.Edges[length-1].Cursor
+	}
+
+	return connection
+}
+
+// Nodes returns nodes of the edges, resolvers use it to convert the nodes to their gql models
+func (c *Connection) Nodes() []interface{} {
+	nodes := make([]interface{}, len(c.Edges))
+
+	for i, edge := range c.Edges {
+		nodes[i] = edge.Node
+	}
+
+	return nodes
+}
@@ -0,0 +1,30 @@
+package relay_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/coretrix/hitrix/pkg/graphql/relay"
+)
+
+func TestNewConnection(t *testing.T) {
+	cursorFunc := func(row interface{}) string {
+		return strconv.Itoa(row.(int))
+	}
+
+	connection := relay.NewConnection([]int{1, 2, 3}, 2, false, cursorFunc)
+	assert.Len(t, connection.Edges, 2)
+	assert.True(t, connection.PageInfo.HasNextPage)
+	assert.False(t, connection.PageInfo.HasPreviousPage)
+	assert.Equal(t, "1", *connection.PageInfo.StartCursor)
+	assert.Equal(t, "2", *connection.PageInfo.EndCursor)
+	assert.Equal(t, []interface{}{1, 2}, connection.Nodes())
+
+	connection = relay.NewConnection([]int{}, 2, true, cursorFunc)
+	assert.Len(t, connection.Edges, 0)
+	assert.False(t, connection.PageInfo.HasNextPage)
+	assert.True(t, connection.PageInfo.HasPreviousPage)
+	assert.Nil(t, connection.PageInfo.EndCursor)
+}
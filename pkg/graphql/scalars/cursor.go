@@ -0,0 +1,30 @@
+package scalars
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/99designs/gqlgen/graphql"
+
+	"github.com/coretrix/hitrix/service/component/crud"
+)
+
+func MarshalCursor(cursor string) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		_, _ = io.WriteString(w, strconv.Quote(cursor))
+	})
+}
+
+func UnmarshalCursor(v interface{}) (string, error) {
+	cursor, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("%T is not a cursor", v)
+	}
+
+	if _, err := crud.DecodeCursor(cursor); err != nil {
+		return "", err
+	}
+
+	return cursor, nil
+}
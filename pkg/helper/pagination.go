@@ -5,3 +5,9 @@ type URLQueryPager struct {
 	CurrentPage int `binding:"min=1" form:"current_page"`
 	PageSize    int `binding:"min=1" form:"page_size"`
 }
+
+type URLQueryCursorPager struct {
+	// example = ?after=eyJmIjoiSUQiLCJhIjp0cnVlLCJpIjoyNX0&page_size=25
+	After    string `form:"after"`
+	PageSize int    `binding:"min=1" form:"page_size"`
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"fmt"
+
+	dataSource "github.com/coretrix/clockwork/datasource"
+	"github.com/gin-gonic/gin"
+
+	"github.com/coretrix/hitrix/pkg/graphql/dataloader"
+	"github.com/coretrix/hitrix/service"
+)
+
+// DataLoaders attaches request loaders to gin context. In local and dev mode loaders stats and possible N+1
+// patterns are logged to Clockwork, so the middleware has to be registered after Clockwork middleware.
+// InitGin registers it after GinInitHandler
+func DataLoaders(ginEngine *gin.Engine) {
+	ginEngine.Use(func(c *gin.Context) {
+		loaders := dataloader.Attach(c)
+
+		c.Next()
+
+		appService := service.DI().App()
+		if !appService.IsInLocalMode() && !appService.IsInDevMode() {
+			return
+		}
+
+		configService := service.DI().Config()
+		if _, ok := configService.String("clockwork.password"); !ok {
+			return
+		}
+
+		logDataLoaders(
+			service.DI().ClockWorkForContext(c.Request.Context()).GetLoggerDataSource(),
+			loaders,
+			configService.DefInt("server.graphql.dataloader_n_plus_one_batches", 3),
+		)
+	})
+}
+
+func logDataLoaders(logger dataSource.LoggerInterface, loaders *dataloader.Loaders, nPlusOneBatches int) {
+	stats := loaders.Stats()
+	if len(stats) == 0 {
+		return
+	}
+
+	statsMap := make(map[string]string, len(stats))
+	for name, loaderStats := range stats {
+		statsMap[name] = fmt.Sprintf(
+			"loads: %d, cache hits: %d, batches: %d, keys: %d",
+			loaderStats.Loads, loaderStats.CacheHits, loaderStats.Batches, loaderStats.Keys,
+		)
+	}
+
+	logger.LogDebugMap("Dataloaders", statsMap)
+
+	for _, warning := range loaders.NPlusOne(nPlusOneBatches) {
+		logger.LogDebugString("N+1", warning)
+	}
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/coretrix/hitrix/pkg/graphql/dataloader"
+	"github.com/coretrix/hitrix/service"
+)
+
+type debugLogger struct {
+	maps    map[string]map[string]string
+	strings map[string][]string
+}
+
+func (l *debugLogger) LogDebugSlice(_ string, _ []string) {
+}
+
+func (l *debugLogger) LogDebugMap(name string, debugData map[string]string) {
+	l.maps[name] = debugData
+}
+
+func (l *debugLogger) LogDebugString(name string, debugData string) {
+	l.strings[name] = append(l.strings[name], debugData)
+}
+
+func TestLogDataLoaders(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx := context.WithValue(context.Background(), service.GinKey, c)
+	loaders := dataloader.Attach(c)
+
+	logger := &debugLogger{maps: map[string]map[string]string{}, strings: map[string][]string{}}
+
+	logDataLoaders(logger, loaders, 3)
+	assert.Empty(t, logger.maps)
+
+	loader := dataloader.Get(ctx, "names", func(ctx context.Context) *dataloader.Loader[uint64, string] {
+		return dataloader.NewLoader(func(_ context.Context, keys []uint64) (map[uint64]string, error) {
+			return map[uint64]string{}, nil
+		}, time.Millisecond, 0)
+	})
+
+	// every key is loaded in own batch
+	for _, key := range []uint64{1, 2, 3} {
+		_, err := loader.Load(ctx, key)
+		assert.Nil(t, err)
+	}
+
+	logDataLoaders(logger, loaders, 3)
+	assert.Equal(t, "loads: 3, cache hits: 0, batches: 3, keys: 3", logger.maps["Dataloaders"]["names"])
+	assert.Equal(t, []string{
+		"possible N+1: dataloader names loaded 3 batches with one key out of 3 batches, use LoadAll or LoadThunk",
+	}, logger.strings["N+1"])
+
+	logger.strings = map[string][]string{}
+
+	logDataLoaders(logger, loaders, 4)
+	assert.Empty(t, logger.strings["N+1"])
+}
@@ -44,6 +44,17 @@ func DevPanelRouter(ginEngine *gin.Engine) {
 			devGroup.POST("feature-flag/disable/:name/", devPanel.PostDisableFeatureFlag)
 
 			devGroup.POST("request-logger/list/", devPanel.PostRequestsLogger)
+
+			if service.HasService(service.SMSFakeService) {
+				devGroup.GET("sms/inbox/", devPanel.GetSMSInbox)
+				devGroup.DELETE("sms/inbox/", devPanel.DeleteSMSInbox)
+				devGroup.POST("sms/failure/:number/", devPanel.PostSMSFailure)
+			}
+
+			if service.HasService(service.PersistedQueryService) {
+				devGroup.GET("persisted-queries/", devPanel.GetPersistedQueries)
+				devGroup.POST("persisted-queries/reload/", devPanel.PostReloadPersistedQueries)
+			}
 		}
 	}
 
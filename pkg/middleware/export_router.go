@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/coretrix/hitrix/pkg/controller"
+)
+
+func ExportRouter(ginEngine *gin.Engine, exportController *controller.ExportController) {
+	if exportController.GetUserIDFunc == nil {
+		panic("ExportController.GetUserIDFunc is required")
+	}
+
+	v1Group := ginEngine.Group("/v1/")
+
+	exportGroup := v1Group.Group("export-job/")
+	{
+		exportGroup.POST("", exportController.PostCreateExportJobAction)
+	}
+}
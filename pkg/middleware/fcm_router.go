@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/coretrix/hitrix/pkg/controller"
+)
+
+func FCMRouter(ginEngine *gin.Engine, fcmController *controller.FCMController) {
+	if fcmController.GetUserIDFunc == nil {
+		panic("FCMController.GetUserIDFunc is required")
+	}
+
+	v1Group := ginEngine.Group("/v1/")
+
+	fcmGroup := v1Group.Group("fcm/")
+	{
+		fcmGroup.POST("device-token/", fcmController.PostRegisterDeviceTokenAction)
+		fcmGroup.DELETE("device-token/", fcmController.DeleteDeviceTokenAction)
+	}
+}
@@ -15,3 +15,18 @@ func FileRouter(ginEngine *gin.Engine) {
 		fileGroup.POST("upload/", fileController.PostUploadImageAction)
 	}
 }
+
+// PresignedUploadRouter exposes presigned uploads which are owned by the user returned by FileController.GetUserIDFunc
+func PresignedUploadRouter(ginEngine *gin.Engine, fileController *controller.FileController) {
+	if fileController.GetUserIDFunc == nil {
+		panic("FileController.GetUserIDFunc is required")
+	}
+
+	v1Group := ginEngine.Group("/v1/")
+
+	fileGroup := v1Group.Group("file/")
+	{
+		fileGroup.POST("presigned-upload/", fileController.PostPresignedUploadAction)
+		fileGroup.POST("presigned-upload/:ID/confirm/", fileController.PostConfirmUploadAction)
+	}
+}
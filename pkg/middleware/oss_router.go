@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/coretrix/hitrix/pkg/controller"
+)
+
+// OSSRouter serves and stores objects of oss.LocalOSS
+func OSSRouter(ginEngine *gin.Engine) {
+	v1Group := ginEngine.Group("/v1/")
+
+	var ossController *controller.OSSController
+	ossGroup := v1Group.Group("oss/")
+	{
+		ossGroup.GET(":Bucket/*StorageKey", ossController.GetObjectAction)
+		ossGroup.PUT(":Bucket/*StorageKey", ossController.PutObjectAction)
+	}
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/coretrix/hitrix/pkg/controller"
+)
+
+func SavedViewRouter(ginEngine *gin.Engine, savedViewController *controller.SavedViewController) {
+	if savedViewController.GetUserIDFunc == nil {
+		panic("SavedViewController.GetUserIDFunc is required")
+	}
+
+	if savedViewController.GetColumnsFunc == nil {
+		panic("SavedViewController.GetColumnsFunc is required")
+	}
+
+	v1Group := ginEngine.Group("/v1/")
+
+	savedViewGroup := v1Group.Group("saved-view/")
+	{
+		savedViewGroup.GET("list/:Resource/", savedViewController.GetListAction)
+		savedViewGroup.GET("apply/:Resource/:ID/", savedViewController.GetApplyAction)
+		savedViewGroup.POST("", savedViewController.PostSaveAction)
+		savedViewGroup.PUT(":ID/share/", savedViewController.PutShareAction)
+		savedViewGroup.DELETE(":ID/", savedViewController.DeleteAction)
+	}
+}
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/coretrix/hitrix/pkg/controller"
+)
+
+func SMSRouter(ginEngine *gin.Engine) {
+	v1Group := ginEngine.Group("/v1/")
+
+	var smsController *controller.SMSController
+	smsGroup := v1Group.Group("sms/")
+	{
+		smsGroup.POST("delivery-receipt/:Provider/", smsController.PostDeliveryReceiptAction)
+	}
+}
@@ -0,0 +1,85 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/coretrix/hitrix/pkg/dto/export"
+	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/pkg/errors"
+	"github.com/coretrix/hitrix/pkg/queue/streams"
+	"github.com/coretrix/hitrix/service"
+	"github.com/coretrix/hitrix/service/component/crud"
+)
+
+// CreateExportJob stores the export request and publishes it to msg.export-job stream.
+// Files are generated by export job consumer, which notifies the user with signed download URLs
+func CreateExportJob(ctx context.Context, userID uint64, request *export.RequestDTOExportJob) (*export.ExportJob, error) {
+	ormService := service.DI().OrmEngineForContext(ctx)
+
+	exportConfig, ok := service.DI().Crud().GetExportConfig(request.ExportID)
+	if !ok {
+		return nil, errors.HandleCustomErrors(map[string]string{"ExportID": "export not found"})
+	}
+
+	for arg := range request.ExtraArgs {
+		allowed := false
+
+		for _, allowedArg := range exportConfig.AllowedExtraArgs {
+			if arg == allowedArg {
+				allowed = true
+
+				break
+			}
+		}
+
+		if !allowed {
+			return nil, errors.HandleCustomErrors(map[string]string{"ExtraArgs": "argument " + arg + " is not allowed"})
+		}
+	}
+
+	listRequest, err := json.Marshal(&crud.ListRequest{
+		Search:    request.Search,
+		SearchOR:  request.SearchOR,
+		Sort:      request.Sort,
+		SortOrder: request.SortOrder,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	extraArgs, err := json.Marshal(request.ExtraArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	exportJobEntity := &entity.ExportJobEntity{
+		UserID:    userID,
+		ExportID:  request.ExportID,
+		Format:    request.Format,
+		Lang:      request.Lang,
+		Request:   string(listRequest),
+		ExtraArgs: string(extraArgs),
+		Status:    entity.ExportJobStatusPending.String(),
+		CreatedAt: service.DI().Clock().Now(),
+	}
+
+	ormService.Flush(exportJobEntity)
+
+	ormService.GetEventBroker().Publish(streams.StreamMsgExportJob, &crud.ExportJobDTO{ExportJobEntityID: exportJobEntity.ID}, nil)
+
+	return ToExportJob(exportJobEntity), nil
+}
+
+func ToExportJob(exportJobEntity *entity.ExportJobEntity) *export.ExportJob {
+	return &export.ExportJob{
+		ID:          exportJobEntity.ID,
+		ExportID:    exportJobEntity.ExportID,
+		Format:      exportJobEntity.Format,
+		Status:      exportJobEntity.Status,
+		Rows:        exportJobEntity.Rows,
+		Error:       exportJobEntity.Error,
+		CreatedAt:   exportJobEntity.CreatedAt,
+		CompletedAt: exportJobEntity.CompletedAt,
+	}
+}
@@ -12,22 +12,27 @@ import (
 	"github.com/coretrix/hitrix/pkg/dto/file"
 	"github.com/coretrix/hitrix/pkg/entity"
 	"github.com/coretrix/hitrix/pkg/errors"
+	"github.com/coretrix/hitrix/pkg/queue/streams"
 	"github.com/coretrix/hitrix/service"
+	filevalidator "github.com/coretrix/hitrix/service/component/file_validator"
 	"github.com/coretrix/hitrix/service/component/oss"
 )
 
 func CreateFile(ctx context.Context, newFile *file.RequestDTOUploadImage) (*file.File, error) {
 	ormService := service.DI().OrmEngineForContext(ctx)
 
-	now := service.DI().Clock().Now()
-
 	ext := strings.Replace(filepath.Ext(newFile.Image.Filename), ".", "", 1)
 
 	tempFile, err := os.CreateTemp("", fmt.Sprintf("*.%s", ext))
+	if err != nil {
+		return nil, err
+	}
+
+	tempFileName := tempFile.Name()
 	clean := func() {
-		err = os.Remove(tempFile.Name())
+		err = os.Remove(tempFileName)
 		if err != nil {
-			service.DI().ErrorLogger().LogError(fmt.Sprintf("failed deleting temp file %s\nError: %s", tempFile.Name(), err.Error()))
+			service.DI().ErrorLogger().LogError(fmt.Sprintf("failed deleting temp file %s\nError: %s", tempFileName, err.Error()))
 		}
 	}
 
@@ -59,21 +64,66 @@ func CreateFile(ctx context.Context, newFile *file.RequestDTOUploadImage) (*file
 		return nil, errors.HandleCustomErrors(map[string]string{"Namespace": "namespace invalid"})
 	}
 
-	obj, err := service.DI().OSService().UploadImageFromFile(ormService, namespace, tempFile.Name())
-
-	if err != nil {
-		return nil, err
-	}
-
 	fileEntity := &entity.FileEntity{
-		File:      &obj,
 		Namespace: newFile.Namespace.String(),
 		Status:    entity.FileStatusNew.String(),
 		CreatedAt: service.DI().Clock().Now(),
 	}
 
+	hasScanner := false
+
+	if service.HasService(service.FileValidatorService) {
+		validatorService := service.DI().FileValidator()
+
+		fileInfo, err := validatorService.ValidateFile(newFile.Namespace.String(), tempFileName)
+		if err != nil {
+			return nil, errors.HandleCustomErrors(map[string]string{"Image": err.Error()})
+		}
+
+		// object extension is taken from the local file name, so the detected extension is used instead of the client one
+		if fileInfo.Extension != "" && fileInfo.Extension != ext {
+			detectedFileName := strings.TrimSuffix(tempFileName, filepath.Ext(tempFileName)) + "." + fileInfo.Extension
+
+			if err := os.Rename(tempFileName, detectedFileName); err != nil {
+				return nil, err
+			}
+
+			tempFileName = detectedFileName
+		}
+
+		fileEntity.MimeType = fileInfo.MimeType
+		fileEntity.Size = fileInfo.Size
+		hasScanner = validatorService.HasScanner()
+
+		if !hasScanner {
+			fileEntity.Status = entity.FileStatusProcessed.String()
+		}
+	}
+
+	obj, err := service.DI().OSService().UploadImageFromFile(ormService, namespace, tempFileName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	fileEntity.File = &obj
+
 	ormService.Flush(fileEntity)
 
+	if hasScanner {
+		ormService.GetEventBroker().Publish(streams.StreamMsgFileScan, &filevalidator.FileScanDTO{FileEntityID: fileEntity.ID}, nil)
+	}
+
+	return &file.File{
+		ID:        fileEntity.ID,
+		URL:       getFileURL(namespace, fileEntity),
+		Filename:  newFile.Image.Filename,
+		Namespace: oss.Namespace(fileEntity.Namespace),
+		IDType:    file.IDTypeFileID,
+	}, nil
+}
+
+func getFileURL(namespace oss.Namespace, fileEntity *entity.FileEntity) string {
 	bucketConfig, err := service.DI().OSService().GetNamespaceBucketConfig(namespace)
 
 	if err != nil {
@@ -86,6 +136,7 @@ func CreateFile(ctx context.Context, newFile *file.RequestDTOUploadImage) (*file
 	case oss.BucketPublic:
 		objectURL, err = service.DI().OSService().GetObjectURL(namespace, fileEntity.File)
 	case oss.BucketPrivate:
+		now := service.DI().Clock().Now()
 		objectURL, err = service.DI().OSService().GetObjectSignedURL(namespace, fileEntity.File, now.Add(30*time.Minute)) // TODO make this time dynamic
 	}
 
@@ -93,11 +144,5 @@ func CreateFile(ctx context.Context, newFile *file.RequestDTOUploadImage) (*file
 		panic(err)
 	}
 
-	return &file.File{
-		ID:        fileEntity.ID,
-		URL:       objectURL,
-		Filename:  newFile.Image.Filename,
-		Namespace: oss.Namespace(fileEntity.Namespace),
-		IDType:    file.IDTypeFileID,
-	}, nil
+	return objectURL
 }
@@ -0,0 +1,52 @@
+package file
+
+import (
+	"context"
+
+	"github.com/latolukasz/beeorm/v2"
+
+	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/service"
+	"github.com/coretrix/hitrix/service/component/oss"
+)
+
+// DeleteFile deletes the file entity together with its OSS object, image variants and references
+func DeleteFile(ctx context.Context, fileEntity *entity.FileEntity) error {
+	ormService := service.DI().OrmEngineForContext(ctx)
+
+	if err := deleteObjects(service.DI().OSService(), fileEntity); err != nil {
+		return err
+	}
+
+	fileReferenceEntities := make([]*entity.FileReferenceEntity, 0)
+	ormService.Search(beeorm.NewWhere("File = ?", fileEntity.ID), beeorm.NewPager(1, 10000), &fileReferenceEntities)
+
+	flusher := ormService.NewFlusher()
+
+	for _, fileReferenceEntity := range fileReferenceEntities {
+		flusher.Delete(fileReferenceEntity)
+	}
+
+	flusher.Delete(fileEntity)
+	flusher.Flush()
+
+	return nil
+}
+
+func deleteObjects(ossService oss.IProvider, fileEntity *entity.FileEntity) error {
+	if fileEntity.File == nil {
+		return nil
+	}
+
+	namespace := oss.Namespace(fileEntity.Namespace)
+
+	if imageData, ok := oss.GetImageData(fileEntity.File); ok {
+		for _, variant := range imageData.Variants {
+			if err := ossService.DeleteObject(namespace, &entity.FileObject{ID: variant.ID, StorageKey: variant.StorageKey}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return ossService.DeleteObject(namespace, fileEntity.File)
+}
@@ -0,0 +1,78 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/latolukasz/beeorm/v2"
+
+	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/service"
+)
+
+const garbageCollectorBatchSize = 1000
+
+type GarbageReport struct {
+	Files  int
+	Size   int64
+	Failed int
+}
+
+// CollectGarbage deletes pending and new files created between createdAfter and createdBefore which are not referenced by any entity.
+// createdAfter should be the time when file references were introduced, older files may be used without a reference.
+// Quarantined files are kept for investigation unless deleteQuarantined is true. In dry run mode nothing is deleted and the report contains reclaimable files and storage.
+// Size of files uploaded before the file validator was registered is unknown and it is not included in the report
+func CollectGarbage(ctx context.Context, createdAfter, createdBefore time.Time, dryRun, deleteQuarantined bool) *GarbageReport {
+	ormService := service.DI().OrmEngineForContext(ctx)
+
+	report := &GarbageReport{}
+
+	statuses := []string{entity.FileStatusPending.String(), entity.FileStatusNew.String()}
+
+	if deleteQuarantined {
+		statuses = append(statuses, entity.FileStatusQuarantined.String())
+	}
+
+	lastID := uint64(0)
+
+	for {
+		where := beeorm.NewWhere(
+			"Status IN ? AND CreatedAt >= ? AND CreatedAt < ? AND ID > ? ORDER BY ID",
+			statuses,
+			createdAfter,
+			createdBefore,
+			lastID,
+		)
+
+		fileEntities := make([]*entity.FileEntity, 0)
+		ormService.Search(where, beeorm.NewPager(1, garbageCollectorBatchSize), &fileEntities)
+
+		for _, fileEntity := range fileEntities {
+			lastID = fileEntity.ID
+
+			if countReferences(ormService, fileEntity) > 0 {
+				continue
+			}
+
+			if !dryRun {
+				if err := DeleteFile(ctx, fileEntity); err != nil {
+					service.DI().ErrorLogger().LogError(fmt.Sprintf("failed deleting file %d\nError: %s", fileEntity.ID, err.Error()))
+
+					report.Failed++
+
+					continue
+				}
+			}
+
+			report.Files++
+			report.Size += fileEntity.Size
+		}
+
+		if len(fileEntities) < garbageCollectorBatchSize {
+			break
+		}
+	}
+
+	return report
+}
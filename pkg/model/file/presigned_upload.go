@@ -0,0 +1,146 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"mime"
+
+	"github.com/coretrix/hitrix/pkg/dto/file"
+	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/pkg/errors"
+	"github.com/coretrix/hitrix/pkg/queue/streams"
+	"github.com/coretrix/hitrix/service"
+	filevalidator "github.com/coretrix/hitrix/service/component/file_validator"
+	"github.com/coretrix/hitrix/service/component/oss"
+)
+
+// CreatePresignedUpload creates pending FileEntity owned by uploaderID and returns the request the client should use to upload
+// the object directly to the bucket. Expected content type and max size are kept in MimeType and Size fields until the upload is confirmed
+func CreatePresignedUpload(ctx context.Context, uploaderID uint64, request *file.RequestDTOPresignedUpload) (*file.PresignedUpload, error) {
+	ormService := service.DI().OrmEngineForContext(ctx)
+	ossService := service.DI().OSService()
+
+	namespace := oss.Namespace(request.Namespace)
+
+	if _, err := ossService.GetNamespaceBucketConfig(namespace); err != nil {
+		return nil, errors.HandleCustomErrors(map[string]string{"Namespace": "namespace invalid"})
+	}
+
+	maxSize := request.MaxSize
+
+	if service.HasService(service.FileValidatorService) {
+		policy := service.DI().FileValidator().GetPolicy(request.Namespace)
+
+		if policy != nil {
+			if !policy.IsMimeTypeAllowed(request.ContentType) {
+				return nil, errors.HandleCustomErrors(map[string]string{"ContentType": filevalidator.ErrMimeTypeNotAllowed.Error()})
+			}
+
+			if policy.MaxSize > 0 && maxSize > policy.MaxSize {
+				maxSize = policy.MaxSize
+			}
+		}
+	}
+
+	presignedUpload, err := ossService.CreatePresignedUpload(ormService, namespace, request.ContentType, maxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	fileEntity := &entity.FileEntity{
+		File:       &presignedUpload.Object,
+		Namespace:  request.Namespace,
+		Status:     entity.FileStatusPending.String(),
+		MimeType:   request.ContentType,
+		Size:       maxSize,
+		UploaderID: uploaderID,
+		CreatedAt:  service.DI().Clock().Now(),
+	}
+
+	ormService.Flush(fileEntity)
+
+	return &file.PresignedUpload{
+		ID:        fileEntity.ID,
+		Method:    presignedUpload.Method,
+		URL:       presignedUpload.URL,
+		Fields:    presignedUpload.Fields,
+		Headers:   presignedUpload.Headers,
+		ExpiresAt: presignedUpload.ExpiresAt,
+	}, nil
+}
+
+// ConfirmUpload verifies that the object exists and matches expected size and content type.
+// Only the user who created the presigned upload can confirm it.
+// Object which does not match is deleted and the file is moved to quarantined status
+func ConfirmUpload(ctx context.Context, uploaderID, fileID uint64) (*file.File, error) {
+	ormService := service.DI().OrmEngineForContext(ctx)
+	ossService := service.DI().OSService()
+
+	fileEntity := &entity.FileEntity{}
+	if !ormService.LoadByID(fileID, fileEntity) || fileEntity.Status != entity.FileStatusPending.String() || fileEntity.UploaderID != uploaderID {
+		return nil, fmt.Errorf("pending file with ID %v not found", fileID)
+	}
+
+	namespace := oss.Namespace(fileEntity.Namespace)
+
+	attributes, err := ossService.GetObjectAttributes(namespace, fileEntity.File)
+	if err != nil {
+		if err == oss.ErrObjectNotFound {
+			return nil, errors.HandleCustomErrors(map[string]string{"ID": "file is not uploaded"})
+		}
+
+		return nil, err
+	}
+
+	validationErr := validateUploadedObject(fileEntity, attributes)
+	if validationErr != nil {
+		if err := ossService.DeleteObject(namespace, fileEntity.File); err != nil {
+			return nil, err
+		}
+
+		fileEntity.File = nil
+		fileEntity.Status = entity.FileStatusQuarantined.String()
+		fileEntity.ScanResult = validationErr.Error()
+		ormService.Flush(fileEntity)
+
+		return nil, errors.HandleCustomErrors(map[string]string{"ID": validationErr.Error()})
+	}
+
+	hasValidator := service.HasService(service.FileValidatorService)
+	hasScanner := hasValidator && service.DI().FileValidator().HasScanner()
+
+	fileEntity.Status = entity.FileStatusNew.String()
+	fileEntity.Size = attributes.Size
+
+	if hasValidator && !hasScanner {
+		fileEntity.Status = entity.FileStatusProcessed.String()
+	}
+
+	ormService.Flush(fileEntity)
+
+	if hasScanner {
+		ormService.GetEventBroker().Publish(streams.StreamMsgFileScan, &filevalidator.FileScanDTO{FileEntityID: fileEntity.ID}, nil)
+	}
+
+	return &file.File{
+		ID:        fileEntity.ID,
+		URL:       getFileURL(namespace, fileEntity),
+		Namespace: namespace,
+		IDType:    file.IDTypeFileID,
+	}, nil
+}
+
+func validateUploadedObject(fileEntity *entity.FileEntity, attributes *oss.ObjectAttributes) error {
+	if attributes.Size > fileEntity.Size {
+		return oss.ErrObjectTooLarge
+	}
+
+	expectedMediaType, _, _ := mime.ParseMediaType(fileEntity.MimeType)
+	mediaType, _, _ := mime.ParseMediaType(attributes.ContentType)
+
+	if mediaType != expectedMediaType {
+		return oss.ErrInvalidContentType
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/latolukasz/beeorm/v2"
+
+	"github.com/coretrix/hitrix/datalayer"
+	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/service"
+)
+
+// AddReference marks the file as used by the owner entity. Referenced files are never deleted by the garbage collector
+func AddReference(ctx context.Context, fileID uint64, owner beeorm.Entity) error {
+	ormService := service.DI().OrmEngineForContext(ctx)
+
+	fileEntity := &entity.FileEntity{}
+	if !ormService.LoadByID(fileID, fileEntity) {
+		return fmt.Errorf("file with ID %v not found", fileID)
+	}
+
+	if fileEntity.Status == entity.FileStatusQuarantined.String() {
+		return fmt.Errorf("file with ID %v is quarantined", fileID)
+	}
+
+	if findReference(ormService, fileEntity, owner) != nil {
+		return nil
+	}
+
+	flusher := ormService.NewFlusher()
+
+	flusher.Track(&entity.FileReferenceEntity{
+		File:      fileEntity,
+		Entity:    getReferenceEntityName(owner),
+		EntityID:  owner.GetID(),
+		CreatedAt: service.DI().Clock().Now(),
+	})
+
+	err := flusher.FlushWithCheck()
+	if _, ok := err.(*beeorm.DuplicatedKeyError); ok {
+		return nil
+	}
+
+	return err
+}
+
+// RemoveReference removes the reference between the file and the owner entity.
+// File without references is deleted by the garbage collector
+func RemoveReference(ctx context.Context, fileID uint64, owner beeorm.Entity) error {
+	ormService := service.DI().OrmEngineForContext(ctx)
+
+	fileEntity := &entity.FileEntity{}
+	if !ormService.LoadByID(fileID, fileEntity) {
+		return fmt.Errorf("file with ID %v not found", fileID)
+	}
+
+	fileReferenceEntity := findReference(ormService, fileEntity, owner)
+	if fileReferenceEntity == nil {
+		return nil
+	}
+
+	ormService.Delete(fileReferenceEntity)
+
+	return nil
+}
+
+func findReference(ormService *datalayer.ORM, fileEntity *entity.FileEntity, owner beeorm.Entity) *entity.FileReferenceEntity {
+	fileReferenceEntity := &entity.FileReferenceEntity{}
+
+	where := beeorm.NewWhere("File = ? AND Entity = ? AND EntityID = ?", fileEntity.ID, getReferenceEntityName(owner), owner.GetID())
+	if !ormService.SearchOne(where, fileReferenceEntity) {
+		return nil
+	}
+
+	return fileReferenceEntity
+}
+
+func countReferences(ormService *datalayer.ORM, fileEntity *entity.FileEntity) int {
+	fileReferenceEntities := make([]*entity.FileReferenceEntity, 0)
+
+	return ormService.SearchWithCount(beeorm.NewWhere("File = ?", fileEntity.ID), beeorm.NewPager(1, 1), &fileReferenceEntities)
+}
+
+func getReferenceEntityName(owner beeorm.Entity) string {
+	return reflect.TypeOf(owner).Elem().String()
+}
@@ -0,0 +1,77 @@
+package importer
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/coretrix/hitrix/pkg/dto/importer"
+	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/pkg/errors"
+	"github.com/coretrix/hitrix/pkg/model/file"
+	"github.com/coretrix/hitrix/pkg/queue/streams"
+	"github.com/coretrix/hitrix/service"
+	importerComponent "github.com/coretrix/hitrix/service/component/importer"
+)
+
+// CreateImportJob creates import job for the uploaded CSV or XLSX file and publishes it to msg.import-job stream.
+// Only files uploaded by the user can be imported. The file is referenced by the job, so it is not removed by the garbage collector
+func CreateImportJob(ctx context.Context, userID uint64, request *importer.RequestDTOImportJob) (*importer.ImportJob, error) {
+	ormService := service.DI().OrmEngineForContext(ctx)
+
+	if _, ok := service.DI().Importer().GetDefinition(request.ImportID); !ok {
+		return nil, errors.HandleCustomErrors(map[string]string{"ImportID": "import not found"})
+	}
+
+	fileEntity := &entity.FileEntity{}
+	if !ormService.LoadByID(request.FileID, fileEntity) || fileEntity.File == nil || fileEntity.UploaderID != userID {
+		return nil, errors.HandleCustomErrors(map[string]string{"FileID": "file not found"})
+	}
+
+	format := importerComponent.Format(strings.ToLower(strings.TrimPrefix(filepath.Ext(fileEntity.File.StorageKey), ".")))
+	if format != importerComponent.FormatCSV && format != importerComponent.FormatXLSX {
+		return nil, errors.HandleCustomErrors(map[string]string{"FileID": "only csv and xlsx files can be imported"})
+	}
+
+	importJobEntity := &entity.ImportJobEntity{
+		UserID:    userID,
+		ImportID:  request.ImportID,
+		File:      fileEntity,
+		Format:    format.String(),
+		DryRun:    request.DryRun,
+		Status:    entity.ImportJobStatusPending.String(),
+		CreatedAt: service.DI().Clock().Now(),
+	}
+
+	ormService.Flush(importJobEntity)
+
+	if err := file.AddReference(ctx, fileEntity.ID, importJobEntity); err != nil {
+		ormService.Delete(importJobEntity)
+
+		return nil, errors.HandleCustomErrors(map[string]string{"FileID": err.Error()})
+	}
+
+	ormService.GetEventBroker().Publish(
+		streams.StreamMsgImportJob,
+		&importerComponent.ImportJobDTO{ImportJobEntityID: importJobEntity.ID},
+		nil,
+	)
+
+	return ToImportJob(importJobEntity), nil
+}
+
+func ToImportJob(importJobEntity *entity.ImportJobEntity) *importer.ImportJob {
+	return &importer.ImportJob{
+		ID:          importJobEntity.ID,
+		ImportID:    importJobEntity.ImportID,
+		FileID:      importJobEntity.File.ID,
+		DryRun:      importJobEntity.DryRun,
+		Status:      importJobEntity.Status,
+		Rows:        importJobEntity.Rows,
+		Imported:    importJobEntity.Imported,
+		Failed:      importJobEntity.Failed,
+		Error:       importJobEntity.Error,
+		CreatedAt:   importJobEntity.CreatedAt,
+		CompletedAt: importJobEntity.CompletedAt,
+	}
+}
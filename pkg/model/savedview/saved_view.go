@@ -0,0 +1,134 @@
+package savedview
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/coretrix/hitrix/pkg/dto/savedview"
+	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/pkg/errors"
+	"github.com/coretrix/hitrix/service"
+	"github.com/coretrix/hitrix/service/component/crud"
+)
+
+// Save creates or updates the view of the user. Filters, sort and columns are validated against current columns of the resource
+func Save(ctx context.Context, userID uint64, cols []*crud.Column, request *savedview.RequestDTOSaveView) (*savedview.SavedView, error) {
+	ormService := service.DI().OrmEngineForContext(ctx)
+
+	listRequest, dropped := service.DI().Crud().SanitizeListRequest(cols, &crud.ListRequest{
+		PageSize:  request.PageSize,
+		Search:    request.Search,
+		SearchOR:  request.SearchOR,
+		Sort:      request.Sort,
+		SortOrder: request.SortOrder,
+	})
+
+	if len(dropped) > 0 {
+		return nil, errors.HandleCustomErrors(map[string]string{"Request": "invalid filter or sort: " + strings.Join(dropped, ", ")})
+	}
+
+	for _, column := range request.Columns {
+		if !hasColumn(cols, column) {
+			return nil, errors.HandleCustomErrors(map[string]string{"Columns": "unknown column " + column})
+		}
+	}
+
+	encodedRequest, err := json.Marshal(listRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	savedViewEntity := &entity.SavedViewEntity{}
+	now := service.DI().Clock().Now()
+
+	if request.ID != nil {
+		savedViewEntity, err = loadOwnView(ctx, userID, *request.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if savedViewEntity.Resource != request.Resource {
+			return nil, errors.HandleCustomErrors(map[string]string{"Resource": "view belongs to another resource"})
+		}
+
+		savedViewEntity.UpdatedAt = &now
+	} else {
+		savedViewEntity.UserID = userID
+		savedViewEntity.Resource = request.Resource
+		savedViewEntity.CreatedAt = now
+	}
+
+	savedViewEntity.Name = request.Name
+	savedViewEntity.Request = string(encodedRequest)
+	savedViewEntity.Columns = request.Columns
+	savedViewEntity.Shared = request.Shared
+
+	ormService.Flush(savedViewEntity)
+
+	return ToSavedView(userID, savedViewEntity), nil
+}
+
+// Share shares the view with all users of the resource or makes it private again
+func Share(ctx context.Context, userID, id uint64, shared bool) error {
+	savedViewEntity, err := loadOwnView(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	savedViewEntity.Shared = shared
+	service.DI().OrmEngineForContext(ctx).Flush(savedViewEntity)
+
+	return nil
+}
+
+func Delete(ctx context.Context, userID, id uint64) error {
+	savedViewEntity, err := loadOwnView(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	service.DI().OrmEngineForContext(ctx).Delete(savedViewEntity)
+
+	return nil
+}
+
+// ToSavedView decodes stored request. Invalid request is returned empty, so the view can still be applied
+func ToSavedView(userID uint64, savedViewEntity *entity.SavedViewEntity) *savedview.SavedView {
+	listRequest := &crud.ListRequest{}
+	if savedViewEntity.Request != "" {
+		_ = json.Unmarshal([]byte(savedViewEntity.Request), listRequest)
+	}
+
+	return &savedview.SavedView{
+		ID:        savedViewEntity.ID,
+		Resource:  savedViewEntity.Resource,
+		Name:      savedViewEntity.Name,
+		Request:   listRequest,
+		Columns:   savedViewEntity.Columns,
+		Shared:    savedViewEntity.Shared,
+		Owner:     savedViewEntity.UserID == userID,
+		CreatedAt: savedViewEntity.CreatedAt,
+	}
+}
+
+func loadOwnView(ctx context.Context, userID, id uint64) (*entity.SavedViewEntity, error) {
+	savedViewEntity := &entity.SavedViewEntity{}
+
+	if !service.DI().OrmEngineForContext(ctx).LoadByID(id, savedViewEntity) || savedViewEntity.UserID != userID {
+		return nil, errors.HandleCustomErrors(map[string]string{"ID": fmt.Sprintf("view %d not found", id)})
+	}
+
+	return savedViewEntity, nil
+}
+
+func hasColumn(cols []*crud.Column, key string) bool {
+	for _, column := range cols {
+		if column.Key == key {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,338 @@
+package consumers
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/latolukasz/beeorm/v2"
+
+	"github.com/coretrix/hitrix/datalayer"
+	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/pkg/queue/streams"
+	"github.com/coretrix/hitrix/service/component/clock"
+	"github.com/coretrix/hitrix/service/component/crud"
+	"github.com/coretrix/hitrix/service/component/exporter"
+	"github.com/coretrix/hitrix/service/component/oss"
+)
+
+// ExportNotifyFunc is called when export job is finished. Failed job is passed without URLs
+type ExportNotifyFunc func(ormService *datalayer.ORM, exportJobEntity *entity.ExportJobEntity, urls []string) error
+
+const exportJobRecoveryBatchSize = 1000
+
+type ExportJobOptions struct {
+	Namespace      oss.Namespace
+	PageSize       int
+	MaxRowsPerFile int
+	URLExpiration  time.Duration
+	// ProcessingTimeout after which the job in processing status is considered stuck, because its consumer died
+	ProcessingTimeout time.Duration
+}
+
+// ExportJobConsumer pages through crud export handler, streams the rows into files, uploads them to OSS
+// and notifies the user with signed download URLs. New file is started every MaxRowsPerFile rows
+type ExportJobConsumer struct {
+	ormService      *datalayer.ORM
+	crudService     *crud.Crud
+	exporterService exporter.IExporter
+	ossService      oss.IProvider
+	clockService    clock.IClock
+	notifyFunc      ExportNotifyFunc
+	options         ExportJobOptions
+}
+
+func NewExportJobConsumer(
+	ormService *datalayer.ORM,
+	crudService *crud.Crud,
+	exporterService exporter.IExporter,
+	ossService oss.IProvider,
+	clockService clock.IClock,
+	notifyFunc ExportNotifyFunc,
+	options ExportJobOptions,
+) *ExportJobConsumer {
+	if options.URLExpiration == 0 {
+		options.URLExpiration = 24 * time.Hour
+	}
+
+	if options.ProcessingTimeout == 0 {
+		options.ProcessingTimeout = time.Hour
+	}
+
+	return &ExportJobConsumer{
+		ormService:      ormService,
+		crudService:     crudService,
+		exporterService: exporterService,
+		ossService:      ossService,
+		clockService:    clockService,
+		notifyFunc:      notifyFunc,
+		options:         options,
+	}
+}
+
+func (c *ExportJobConsumer) GetQueueName() string {
+	return streams.StreamMsgExportJob
+}
+
+func (c *ExportJobConsumer) GetGroupName(suffix *string) string {
+	return streams.GetGroupName(c.GetQueueName(), suffix)
+}
+
+func (c *ExportJobConsumer) Consume(_ *datalayer.ORM, event beeorm.Event) error {
+	ormService := c.ormService.Clone()
+
+	exportJobDTO := &crud.ExportJobDTO{}
+	event.Unserialize(exportJobDTO)
+
+	exportJobEntity := &entity.ExportJobEntity{}
+	if !ormService.LoadByID(exportJobDTO.ExportJobEntityID, exportJobEntity) ||
+		(exportJobEntity.Status != entity.ExportJobStatusPending.String() && !c.isStuck(exportJobEntity)) {
+		return nil
+	}
+
+	startedAt := c.clockService.Now()
+	exportJobEntity.Status = entity.ExportJobStatusProcessing.String()
+	exportJobEntity.StartedAt = &startedAt
+	ormService.Flush(exportJobEntity)
+
+	objects, rows, err := c.export(ormService, exportJobEntity)
+
+	now := c.clockService.Now()
+	exportJobEntity.CompletedAt = &now
+	exportJobEntity.Objects = make([]string, len(objects))
+	exportJobEntity.Rows = rows
+
+	for i, object := range objects {
+		exportJobEntity.Objects[i] = object.StorageKey
+	}
+
+	var urls []string
+
+	// urls are generated before the job is completed, so the user is always notified
+	if err == nil {
+		urls, err = c.getSignedURLs(objects, now)
+	}
+
+	if err != nil {
+		exportJobEntity.Status = entity.ExportJobStatusFailed.String()
+		exportJobEntity.Error = err.Error()
+		ormService.Flush(exportJobEntity)
+
+		return c.notify(ormService, exportJobEntity, nil)
+	}
+
+	exportJobEntity.Status = entity.ExportJobStatusCompleted.String()
+	ormService.Flush(exportJobEntity)
+
+	return c.notify(ormService, exportJobEntity, urls)
+}
+
+func (c *ExportJobConsumer) getSignedURLs(objects []entity.FileObject, now time.Time) ([]string, error) {
+	urls := make([]string, len(objects))
+
+	for i := range objects {
+		url, err := c.ossService.GetObjectSignedURL(c.options.Namespace, &objects[i], now.Add(c.options.URLExpiration))
+		if err != nil {
+			return nil, err
+		}
+
+		urls[i] = url
+	}
+
+	return urls, nil
+}
+
+// RecoverStuckJobs publishes again jobs which are in processing status longer than ProcessingTimeout
+func (c *ExportJobConsumer) RecoverStuckJobs() int {
+	ormService := c.ormService.Clone()
+	where := beeorm.NewWhere(
+		"`Status` = ? AND `StartedAt` < ?",
+		entity.ExportJobStatusProcessing.String(),
+		c.clockService.Now().Add(-c.options.ProcessingTimeout),
+	)
+
+	var exportJobEntities []*entity.ExportJobEntity
+	ormService.Search(where, beeorm.NewPager(1, exportJobRecoveryBatchSize), &exportJobEntities)
+
+	for _, exportJobEntity := range exportJobEntities {
+		ormService.GetEventBroker().Publish(c.GetQueueName(), &crud.ExportJobDTO{ExportJobEntityID: exportJobEntity.ID}, nil)
+	}
+
+	return len(exportJobEntities)
+}
+
+// uploadPart streams the part, so big exports are never loaded into memory
+func (c *ExportJobConsumer) uploadPart(ormService *datalayer.ORM, localFile string, format exporter.Format) (entity.FileObject, error) {
+	file, err := os.Open(localFile)
+	if err != nil {
+		return entity.FileObject{}, err
+	}
+
+	defer file.Close()
+
+	return oss.UploadObjectFromReader(c.ossService, ormService, c.options.Namespace, file, format.String())
+}
+
+func (c *ExportJobConsumer) isStuck(exportJobEntity *entity.ExportJobEntity) bool {
+	return exportJobEntity.Status == entity.ExportJobStatusProcessing.String() &&
+		exportJobEntity.StartedAt != nil &&
+		exportJobEntity.StartedAt.Before(c.clockService.Now().Add(-c.options.ProcessingTimeout))
+}
+
+func (c *ExportJobConsumer) notify(ormService *datalayer.ORM, exportJobEntity *entity.ExportJobEntity, urls []string) error {
+	if c.notifyFunc == nil {
+		return nil
+	}
+
+	return c.notifyFunc(ormService, exportJobEntity, urls)
+}
+
+func (c *ExportJobConsumer) export(ormService *datalayer.ORM, exportJobEntity *entity.ExportJobEntity) ([]entity.FileObject, int, error) {
+	request := &crud.ListRequest{}
+	if err := json.Unmarshal([]byte(exportJobEntity.Request), request); err != nil {
+		return nil, 0, err
+	}
+
+	extraArgs := map[string]string{}
+	if exportJobEntity.ExtraArgs != "" {
+		if err := json.Unmarshal([]byte(exportJobEntity.ExtraArgs), &extraArgs); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	format := exporter.Format(exportJobEntity.Format)
+
+	maxRowsPerFile := c.options.MaxRowsPerFile
+	if format == exporter.FormatXLSX && (maxRowsPerFile <= 0 || maxRowsPerFile >= exporter.MaxXLSXSheetRows) {
+		maxRowsPerFile = exporter.MaxXLSXSheetRows - 1
+	}
+
+	part := &exportPart{format: format, exporterService: c.exporterService}
+	defer part.cleanup()
+
+	objects := make([]entity.FileObject, 0)
+	rows := 0
+
+	upload := func() error {
+		localFile, err := part.finish()
+
+		defer os.Remove(localFile)
+
+		if err != nil {
+			return err
+		}
+
+		object, err := c.uploadPart(ormService, localFile, part.format)
+		if err != nil {
+			return err
+		}
+
+		objects = append(objects, object)
+
+		return nil
+	}
+
+	var exportColumns []string
+
+	err := c.crudService.ExportPages(
+		exportJobEntity.ExportID,
+		entity.TranslationTextLang(exportJobEntity.Lang),
+		ormService,
+		request,
+		exportJobEntity.UserID,
+		extraArgs,
+		c.options.PageSize,
+		func(columns []string, pageRows [][]interface{}) error {
+			exportColumns = columns
+
+			for _, row := range pageRows {
+				if part.writer == nil {
+					if err := part.start(columns); err != nil {
+						return err
+					}
+				}
+
+				if err := part.writer.WriteRow(row); err != nil {
+					return err
+				}
+
+				part.rows++
+				rows++
+
+				if maxRowsPerFile > 0 && part.rows == maxRowsPerFile {
+					if err := upload(); err != nil {
+						return err
+					}
+				}
+			}
+
+			return nil
+		},
+	)
+	if err != nil {
+		return objects, rows, err
+	}
+
+	if part.writer == nil && len(objects) == 0 {
+		if err := part.start(exportColumns); err != nil {
+			return objects, rows, err
+		}
+	}
+
+	if part.writer != nil {
+		if err := upload(); err != nil {
+			return objects, rows, err
+		}
+	}
+
+	return objects, rows, nil
+}
+
+type exportPart struct {
+	format          exporter.Format
+	exporterService exporter.IExporter
+	file            *os.File
+	writer          exporter.ExportWriter
+	rows            int
+}
+
+func (p *exportPart) start(columns []string) error {
+	file, err := os.CreateTemp("", "export-*."+p.format.String())
+	if err != nil {
+		return err
+	}
+
+	p.file = file
+	p.rows = 0
+
+	writer, err := p.exporterService.NewExportWriter(p.format, file, "Export", columns)
+	if err != nil {
+		return err
+	}
+
+	p.writer = writer
+
+	return nil
+}
+
+func (p *exportPart) finish() (string, error) {
+	localFile := p.file.Name()
+
+	err := p.writer.Close()
+	p.writer = nil
+
+	if closeErr := p.file.Close(); err == nil {
+		err = closeErr
+	}
+
+	p.file = nil
+
+	return localFile, err
+}
+
+func (p *exportPart) cleanup() {
+	if p.file != nil {
+		_ = p.file.Close()
+		_ = os.Remove(p.file.Name())
+	}
+}
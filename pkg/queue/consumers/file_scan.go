@@ -0,0 +1,101 @@
+package consumers
+
+import (
+	"context"
+
+	"github.com/latolukasz/beeorm/v2"
+
+	"github.com/coretrix/hitrix/datalayer"
+	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/pkg/queue/streams"
+	filevalidator "github.com/coretrix/hitrix/service/component/file_validator"
+	"github.com/coretrix/hitrix/service/component/oss"
+)
+
+// FileScanConsumer validates the content of new files and scans them for malware.
+// File is moved to processed status or to quarantined status when the validation or the scan fails
+type FileScanConsumer struct {
+	ormService       *datalayer.ORM
+	ossService       oss.IProvider
+	validatorService filevalidator.IValidator
+}
+
+func NewFileScanConsumer(ormService *datalayer.ORM, ossService oss.IProvider, validatorService filevalidator.IValidator) *FileScanConsumer {
+	return &FileScanConsumer{ormService: ormService, ossService: ossService, validatorService: validatorService}
+}
+
+func (c *FileScanConsumer) GetQueueName() string {
+	return streams.StreamMsgFileScan
+}
+
+func (c *FileScanConsumer) GetGroupName(suffix *string) string {
+	return streams.GetGroupName(c.GetQueueName(), suffix)
+}
+
+func (c *FileScanConsumer) Consume(_ *datalayer.ORM, event beeorm.Event) error {
+	ormService := c.ormService.Clone()
+
+	fileScanDTO := &filevalidator.FileScanDTO{}
+	event.Unserialize(fileScanDTO)
+
+	fileEntity := &entity.FileEntity{}
+	if !ormService.LoadByID(fileScanDTO.FileEntityID, fileEntity) || fileEntity.Status != entity.FileStatusNew.String() {
+		return nil
+	}
+
+	return ScanFile(ormService, c.ossService, c.validatorService, fileEntity)
+}
+
+// ScanFile streams the file, validates it against namespace policy and scans it if scanner is registered.
+// The object is read twice so the content is never kept in memory
+func ScanFile(
+	ormService *datalayer.ORM,
+	ossService oss.IProvider,
+	validatorService filevalidator.IValidator,
+	fileEntity *entity.FileEntity,
+) error {
+	reader, err := oss.GetObjectReader(ossService, oss.Namespace(fileEntity.Namespace), fileEntity.File)
+	if err != nil {
+		return err
+	}
+
+	fileInfo, err := validatorService.ValidateReader(fileEntity.Namespace, reader)
+	_ = reader.Close()
+
+	if fileInfo != nil {
+		fileEntity.MimeType = fileInfo.MimeType
+		fileEntity.Size = fileInfo.Size
+	}
+
+	fileEntity.Status = entity.FileStatusProcessed.String()
+
+	if err != nil {
+		if fileInfo == nil {
+			return err
+		}
+
+		fileEntity.Status = entity.FileStatusQuarantined.String()
+		fileEntity.ScanResult = err.Error()
+	} else if validatorService.HasScanner() {
+		reader, err = oss.GetObjectReader(ossService, oss.Namespace(fileEntity.Namespace), fileEntity.File)
+		if err != nil {
+			return err
+		}
+
+		scanResult, err := validatorService.Scan(context.Background(), reader)
+		_ = reader.Close()
+
+		if err != nil {
+			return err
+		}
+
+		if scanResult.Infected {
+			fileEntity.Status = entity.FileStatusQuarantined.String()
+			fileEntity.ScanResult = scanResult.Signature
+		}
+	}
+
+	ormService.Flush(fileEntity)
+
+	return nil
+}
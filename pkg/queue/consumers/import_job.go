@@ -0,0 +1,214 @@
+package consumers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/latolukasz/beeorm/v2"
+
+	"github.com/coretrix/hitrix/datalayer"
+	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/pkg/queue/streams"
+	"github.com/coretrix/hitrix/service/component/clock"
+	"github.com/coretrix/hitrix/service/component/importer"
+	"github.com/coretrix/hitrix/service/component/oss"
+)
+
+// ImportNotifyFunc is called when import job is finished. Error report URL is empty when all rows were imported
+type ImportNotifyFunc func(ormService *datalayer.ORM, importJobEntity *entity.ImportJobEntity, errorReportURL string) error
+
+type ImportJobOptions struct {
+	Namespace     oss.Namespace
+	URLExpiration time.Duration
+	Comma         rune
+	// DownloadTimeout limits the download of the uploaded file, 10 minutes by default
+	DownloadTimeout time.Duration
+}
+
+// ImportJobConsumer downloads the uploaded file to local disk, imports it and uploads the error report of failed rows to OSS
+type ImportJobConsumer struct {
+	ormService      *datalayer.ORM
+	importerService importer.IImporter
+	ossService      oss.IProvider
+	clockService    clock.IClock
+	notifyFunc      ImportNotifyFunc
+	options         ImportJobOptions
+	httpClient      *http.Client
+}
+
+func NewImportJobConsumer(
+	ormService *datalayer.ORM,
+	importerService importer.IImporter,
+	ossService oss.IProvider,
+	clockService clock.IClock,
+	notifyFunc ImportNotifyFunc,
+	options ImportJobOptions,
+) *ImportJobConsumer {
+	if options.URLExpiration == 0 {
+		options.URLExpiration = 24 * time.Hour
+	}
+
+	if options.DownloadTimeout == 0 {
+		options.DownloadTimeout = 10 * time.Minute
+	}
+
+	return &ImportJobConsumer{
+		ormService:      ormService,
+		importerService: importerService,
+		ossService:      ossService,
+		clockService:    clockService,
+		notifyFunc:      notifyFunc,
+		options:         options,
+		httpClient:      &http.Client{Timeout: options.DownloadTimeout},
+	}
+}
+
+func (c *ImportJobConsumer) GetQueueName() string {
+	return streams.StreamMsgImportJob
+}
+
+func (c *ImportJobConsumer) GetGroupName(suffix *string) string {
+	return streams.GetGroupName(c.GetQueueName(), suffix)
+}
+
+func (c *ImportJobConsumer) Consume(_ *datalayer.ORM, event beeorm.Event) error {
+	ormService := c.ormService.Clone()
+
+	importJobDTO := &importer.ImportJobDTO{}
+	event.Unserialize(importJobDTO)
+
+	importJobEntity := &entity.ImportJobEntity{}
+	if !ormService.LoadByID(importJobDTO.ImportJobEntityID, importJobEntity, "File") ||
+		importJobEntity.Status != entity.ImportJobStatusPending.String() {
+		return nil
+	}
+
+	importJobEntity.Status = entity.ImportJobStatusProcessing.String()
+	ormService.Flush(importJobEntity)
+
+	report, errorReport, err := c.importFile(ormService, importJobEntity)
+
+	now := c.clockService.Now()
+	importJobEntity.CompletedAt = &now
+	importJobEntity.Status = entity.ImportJobStatusCompleted.String()
+
+	if report != nil {
+		importJobEntity.Rows = report.Rows
+		importJobEntity.Imported = report.Imported
+		importJobEntity.Failed = report.Failed
+	}
+
+	if err != nil {
+		importJobEntity.Status = entity.ImportJobStatusFailed.String()
+		importJobEntity.Error = err.Error()
+	}
+
+	if errorReport != nil {
+		importJobEntity.ErrorReport = errorReport.StorageKey
+	}
+
+	ormService.Flush(importJobEntity)
+
+	if c.notifyFunc == nil {
+		return nil
+	}
+
+	errorReportURL := ""
+
+	if errorReport != nil {
+		errorReportURL, err = c.ossService.GetObjectSignedURL(c.options.Namespace, errorReport, now.Add(c.options.URLExpiration))
+		if err != nil {
+			return err
+		}
+	}
+
+	return c.notifyFunc(ormService, importJobEntity, errorReportURL)
+}
+
+func (c *ImportJobConsumer) importFile(
+	ormService *datalayer.ORM,
+	importJobEntity *entity.ImportJobEntity,
+) (*importer.Report, *entity.FileObject, error) {
+	localFile, err := c.download(importJobEntity.File)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer func() {
+		_ = localFile.Close()
+		_ = os.Remove(localFile.Name())
+	}()
+
+	errorReportFile, err := os.CreateTemp("", "import-errors-*.csv")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer func() {
+		_ = errorReportFile.Close()
+		_ = os.Remove(errorReportFile.Name())
+	}()
+
+	report, err := c.importerService.Import(
+		ormService,
+		importJobEntity.ImportID,
+		importer.Format(importJobEntity.Format),
+		localFile,
+		&importer.Options{DryRun: importJobEntity.DryRun, Comma: c.options.Comma, ErrorReport: errorReportFile},
+	)
+
+	if report == nil || report.Failed == 0 {
+		return report, nil, err
+	}
+
+	if _, seekErr := errorReportFile.Seek(0, io.SeekStart); seekErr != nil {
+		return report, nil, seekErr
+	}
+
+	errorReport, uploadErr := oss.UploadObjectFromReader(c.ossService, ormService, c.options.Namespace, errorReportFile, "csv")
+	if uploadErr != nil {
+		return report, nil, uploadErr
+	}
+
+	return report, &errorReport, err
+}
+
+// download streams the object to temporary file using signed URL, so big files are never loaded in memory
+func (c *ImportJobConsumer) download(fileEntity *entity.FileEntity) (*os.File, error) {
+	url, err := c.ossService.GetObjectSignedURL(oss.Namespace(fileEntity.Namespace), fileEntity.File, c.clockService.Now().Add(time.Hour))
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.httpClient.Get(url) //nolint //G107 url is signed by OSS provider
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed downloading file %d: %s", fileEntity.ID, response.Status)
+	}
+
+	localFile, err := os.CreateTemp("", "import-*")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = io.Copy(localFile, response.Body); err == nil {
+		_, err = localFile.Seek(0, io.SeekStart)
+	}
+
+	if err != nil {
+		_ = localFile.Close()
+		_ = os.Remove(localFile.Name())
+
+		return nil, err
+	}
+
+	return localFile, nil
+}
@@ -1,6 +1,12 @@
 package streams
 
-const StreamMsgRetryOTP = "msg.retry-otp"
+const (
+	StreamMsgRetryOTP     = "msg.retry-otp"
+	StreamMsgFileScan     = "msg.file-scan"
+	StreamMsgFileUploaded = "msg.file-uploaded"
+	StreamMsgExportJob    = "msg.export-job"
+	StreamMsgImportJob    = "msg.import-job"
+)
 
 func GetGroupName(queueName string, suffix *string) string {
 	if suffix == nil {
@@ -23,9 +23,11 @@ import (
 
 	"github.com/coretrix/hitrix"
 	"github.com/coretrix/hitrix/datalayer"
+	"github.com/coretrix/hitrix/pkg/graphql/federation"
 	graphqlParser "github.com/coretrix/hitrix/pkg/test/graphql-parser"
 	"github.com/coretrix/hitrix/service"
 	"github.com/coretrix/hitrix/service/component/app"
+	fakesms "github.com/coretrix/hitrix/service/component/fake_sms"
 )
 
 var dbAlters string
@@ -48,6 +50,21 @@ func (env *Environment) HandleQuery(query interface{}, variables map[string]inte
 	return env.handle(buff, query, headers)
 }
 
+// ValidateSubgraphSDL loads SDL with _service query and validates it the way federation gateway composes it
+func (env *Environment) ValidateSubgraphSDL() error {
+	var query struct {
+		Service struct {
+			SDL string `json:"sdl"`
+		} `json:"_service"`
+	}
+
+	if errs := env.HandleQuery(&query, nil, nil); errs != nil {
+		return errs
+	}
+
+	return federation.ValidateSubgraphSDL(query.Service.SDL)
+}
+
 func (env *Environment) HandleMutationMultiPart(query string, variables map[string]interface{},
 	files map[string]string, variablesMap map[string]interface{}, headers map[string]string) (*graphqlParser.Errors, json.RawMessage) {
 	return env.handleMultiPart(query, variables, files, variablesMap, headers)
@@ -183,6 +200,29 @@ func (env *Environment) handleMultiPart(query string, variables interface{},
 	return nil, *out.Data
 }
 
+// LastSMSTo returns last message sent to number through fake sms gateway. It requires ServiceProviderSMSFake
+func (env *Environment) LastSMSTo(number string) *fakesms.Message {
+	return service.DI().SMSFake().LastMessageTo(number)
+}
+
+// LastOTPCodeTo returns last otp code sent to number through fake sms gateway
+func (env *Environment) LastOTPCodeTo(number string) string {
+	for _, message := range service.DI().SMSFake().Inbox(number) {
+		if message.Code != "" {
+			return message.Code
+		}
+	}
+
+	env.t.Fatalf("otp code to %s not found", number)
+
+	return ""
+}
+
+// FailSMSTo makes next times sends to number through fake sms gateway fail. Negative times fails all sends
+func (env *Environment) FailSMSTo(number string, times int) {
+	service.DI().SMSFake().FailNextSends(number, times)
+}
+
 func CreateContext(
 	t *testing.T,
 	projectName string,
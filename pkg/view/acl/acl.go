@@ -2,10 +2,12 @@ package acl
 
 import (
 	redisearch "github.com/coretrix/beeorm-redisearch-plugin"
+	"github.com/gin-gonic/gin"
 	"github.com/latolukasz/beeorm/v2"
 
 	"github.com/coretrix/hitrix/datalayer"
 	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/service/component/crud"
 )
 
 func ACL(ormService *datalayer.ORM, roleEntity *entity.RoleEntity, resource string, permissions ...string) bool {
@@ -41,3 +43,25 @@ func ACL(ormService *datalayer.ORM, roleEntity *entity.RoleEntity, resource stri
 
 	return ormService.RedisSearchOne(&entity.PrivilegeEntity{}, privilegeQuery)
 }
+
+// ResourceACL checks the role of the request user for crud resources, user entity has to implement UserRoleGetter
+func ResourceACL(getUserFunc func(c *gin.Context) beeorm.Entity) crud.ACLFunc {
+	return func(c *gin.Context, ormService *datalayer.ORM, resource, permission string) bool {
+		userEntity := getUserFunc(c)
+		if userEntity == nil {
+			return false
+		}
+
+		userWithGettableRole, ok := userEntity.(UserRoleGetter)
+		if !ok {
+			panic("user entity does not implement UserRoleGetter interface")
+		}
+
+		roleEntity := userWithGettableRole.GetRole()
+		if roleEntity == nil {
+			return false
+		}
+
+		return ACL(ormService, roleEntity, resource, permission)
+	}
+}
@@ -29,11 +29,19 @@ func ValidateListRequest(request list.RequestDTOList, pageSizeMin, pageSizeMax i
 		return nil, fmt.Errorf("page size must be greater than or equal to %v", pageSizeMax)
 	}
 
+	if request.After != nil {
+		if _, err := crud.DecodeCursor(*request.After); err != nil {
+			return nil, err
+		}
+	}
+
 	return &crud.ListRequest{
-		Page:     request.Page,
-		PageSize: request.PageSize,
-		Search:   request.Search,
-		SearchOR: request.SearchOR,
-		Sort:     request.Sort,
+		Page:      request.Page,
+		PageSize:  request.PageSize,
+		Search:    request.Search,
+		SearchOR:  request.SearchOR,
+		Sort:      request.Sort,
+		SortOrder: request.SortOrder,
+		After:     request.After,
 	}, nil
 }
@@ -0,0 +1,82 @@
+package savedview
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/latolukasz/beeorm/v2"
+
+	"github.com/coretrix/hitrix/pkg/dto/savedview"
+	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/pkg/errors"
+	model "github.com/coretrix/hitrix/pkg/model/savedview"
+	"github.com/coretrix/hitrix/service"
+	"github.com/coretrix/hitrix/service/component/crud"
+)
+
+const maxViews = 1000
+
+// List returns views of the user and views shared by other users for the resource
+func List(ctx context.Context, userID uint64, resource string) []*savedview.SavedView {
+	ormService := service.DI().OrmEngineForContext(ctx)
+
+	var savedViewEntities []*entity.SavedViewEntity
+
+	ormService.Search(
+		beeorm.NewWhere("`Resource` = ? AND (`UserID` = ? OR `Shared` = 1) ORDER BY `Name` ASC, `ID` ASC", resource, userID),
+		beeorm.NewPager(1, maxViews),
+		&savedViewEntities,
+	)
+
+	views := make([]*savedview.SavedView, len(savedViewEntities))
+
+	for i, savedViewEntity := range savedViewEntities {
+		views[i] = model.ToSavedView(userID, savedViewEntity)
+	}
+
+	return views
+}
+
+// Apply returns the view with request validated against current columns of the resource. Filters, sort and columns
+// which are not part of the resource anymore are dropped
+func Apply(ctx context.Context, userID, id uint64, resource string, cols []*crud.Column) (*savedview.ResponseDTOApplyView, error) {
+	savedViewEntity := &entity.SavedViewEntity{}
+
+	if !service.DI().OrmEngineForContext(ctx).LoadByID(id, savedViewEntity) ||
+		savedViewEntity.Resource != resource ||
+		(savedViewEntity.UserID != userID && !savedViewEntity.Shared) {
+		return nil, errors.HandleCustomErrors(map[string]string{"ID": fmt.Sprintf("view %d not found", id)})
+	}
+
+	view := model.ToSavedView(userID, savedViewEntity)
+	view.Request, _ = service.DI().Crud().SanitizeListRequest(cols, view.Request)
+
+	keys := map[string]bool{}
+	for _, column := range cols {
+		keys[column.Key] = true
+	}
+
+	visible := map[string]bool{}
+	existing := make([]string, 0, len(view.Columns))
+
+	for _, column := range view.Columns {
+		if keys[column] {
+			visible[column] = true
+			existing = append(existing, column)
+		}
+	}
+
+	view.Columns = existing
+	columns := make([]*crud.Column, len(cols))
+
+	for i, column := range cols {
+		columnCopy := *column
+		if len(visible) > 0 {
+			columnCopy.Visible = visible[column.Key]
+		}
+
+		columns[i] = &columnCopy
+	}
+
+	return &savedview.ResponseDTOApplyView{View: view, Columns: columns}, nil
+}
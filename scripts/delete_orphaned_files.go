@@ -0,0 +1,61 @@
+package scripts
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/coretrix/hitrix/pkg/helper"
+	"github.com/coretrix/hitrix/pkg/model/file"
+	"github.com/coretrix/hitrix/service"
+	"github.com/coretrix/hitrix/service/component/app"
+)
+
+// DeleteOrphanedFiles deletes files which were uploaded and never attached to any entity.
+// It uses oss.garbage_collector.references_since (required, files created before it are skipped),
+// oss.garbage_collector.ttl (seconds, 24 hours by default), oss.garbage_collector.dry_run (true by default)
+// and oss.garbage_collector.delete_quarantined config keys
+type DeleteOrphanedFiles struct {
+}
+
+func (script *DeleteOrphanedFiles) Run(ctx context.Context, _ app.IExit) {
+	configService := service.DI().Config()
+
+	referencesSince, ok := configService.String("oss.garbage_collector.references_since")
+	if !ok {
+		log.Println("orphaned files: oss.garbage_collector.references_since is not set, skipping")
+
+		return
+	}
+
+	createdAfter, err := time.Parse(helper.TimeLayoutYMDHMS, referencesSince)
+	if err != nil {
+		panic("oss.garbage_collector.references_since is invalid: " + err.Error())
+	}
+
+	ttl := time.Duration(configService.DefInt("oss.garbage_collector.ttl", 24*60*60)) * time.Second
+	dryRun := configService.DefBool("oss.garbage_collector.dry_run", true)
+	deleteQuarantined := configService.DefBool("oss.garbage_collector.delete_quarantined", false)
+
+	report := file.CollectGarbage(ctx, createdAfter, service.DI().Clock().Now().Add(-ttl), dryRun, deleteQuarantined)
+
+	if dryRun {
+		log.Printf("orphaned files: %d can be deleted, %d bytes can be reclaimed", report.Files, report.Size)
+
+		return
+	}
+
+	log.Printf("orphaned files: %d deleted, %d bytes reclaimed, %d failed", report.Files, report.Size, report.Failed)
+}
+
+func (script *DeleteOrphanedFiles) Interval() time.Duration {
+	return time.Hour
+}
+
+func (script *DeleteOrphanedFiles) Unique() bool {
+	return true
+}
+
+func (script *DeleteOrphanedFiles) Description() string {
+	return "delete orphaned files"
+}
@@ -0,0 +1,81 @@
+package scripts
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/coretrix/hitrix/pkg/queue"
+	"github.com/coretrix/hitrix/pkg/queue/consumers"
+	"github.com/coretrix/hitrix/service"
+	"github.com/coretrix/hitrix/service/component/app"
+	"github.com/coretrix/hitrix/service/component/oss"
+)
+
+// ExportJobConsumer generates async crud exports. It uses crud.export.namespace, crud.export.page_size,
+// crud.export.max_rows_per_file, crud.export.url_ttl (seconds, 24 hours by default) and
+// crud.export.processing_timeout (seconds, 1 hour by default) config keys.
+// Jobs stuck in processing status longer than processing timeout are published again
+type ExportJobConsumer struct {
+	NotifyFunc consumers.ExportNotifyFunc
+}
+
+func (script *ExportJobConsumer) Run(ctx context.Context, _ app.IExit) {
+	configService := service.DI().Config()
+
+	namespace, ok := configService.String("crud.export.namespace")
+	if !ok {
+		panic("missing crud.export.namespace")
+	}
+
+	options := consumers.ExportJobOptions{
+		Namespace:         oss.Namespace(namespace),
+		PageSize:          configService.DefInt("crud.export.page_size", 1000),
+		MaxRowsPerFile:    configService.DefInt("crud.export.max_rows_per_file", 0),
+		URLExpiration:     time.Duration(configService.DefInt("crud.export.url_ttl", 24*60*60)) * time.Second,
+		ProcessingTimeout: time.Duration(configService.DefInt("crud.export.processing_timeout", 60*60)) * time.Second,
+	}
+
+	exportJobConsumer := consumers.NewExportJobConsumer(
+		service.DI().OrmEngine(),
+		service.DI().Crud(),
+		service.DI().Exporter(),
+		service.DI().OSService(),
+		service.DI().Clock(),
+		script.NotifyFunc,
+		options,
+	)
+
+	go script.recoverStuckJobs(ctx, exportJobConsumer, options.ProcessingTimeout)
+
+	queue.NewConsumerRunner(ctx).RunConsumerOne(exportJobConsumer, nil, 1)
+}
+
+func (script *ExportJobConsumer) recoverStuckJobs(ctx context.Context, exportJobConsumer *consumers.ExportJobConsumer, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if recovered := exportJobConsumer.RecoverStuckJobs(); recovered > 0 {
+			log.Printf("export job consumer published %d stuck jobs again", recovered)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (script *ExportJobConsumer) Infinity() bool {
+	return true
+}
+
+func (script *ExportJobConsumer) Unique() bool {
+	return true
+}
+
+func (script *ExportJobConsumer) Description() string {
+	return "crud export job consumer"
+}
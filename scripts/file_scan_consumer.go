@@ -0,0 +1,33 @@
+package scripts
+
+import (
+	"context"
+
+	"github.com/coretrix/hitrix/pkg/queue"
+	"github.com/coretrix/hitrix/pkg/queue/consumers"
+	"github.com/coretrix/hitrix/service"
+	"github.com/coretrix/hitrix/service/component/app"
+)
+
+type FileScanConsumer struct {
+}
+
+func (script *FileScanConsumer) Run(ctx context.Context, _ app.IExit) {
+	ormService := service.DI().OrmEngine()
+	ossService := service.DI().OSService()
+	validatorService := service.DI().FileValidator()
+
+	queue.NewConsumerRunner(ctx).RunConsumerOne(consumers.NewFileScanConsumer(ormService, ossService, validatorService), nil, 1)
+}
+
+func (script *FileScanConsumer) Infinity() bool {
+	return true
+}
+
+func (script *FileScanConsumer) Unique() bool {
+	return true
+}
+
+func (script *FileScanConsumer) Description() string {
+	return "file scan consumer"
+}
@@ -0,0 +1,56 @@
+package scripts
+
+import (
+	"context"
+	"time"
+
+	"github.com/coretrix/hitrix/pkg/queue"
+	"github.com/coretrix/hitrix/pkg/queue/consumers"
+	"github.com/coretrix/hitrix/service"
+	"github.com/coretrix/hitrix/service/component/app"
+	"github.com/coretrix/hitrix/service/component/oss"
+)
+
+// ImportJobConsumer runs async imports. It uses importer.namespace (OSS namespace of error reports),
+// importer.url_ttl (seconds, 24 hours by default) and importer.download_timeout (seconds, 10 minutes by default) config keys
+type ImportJobConsumer struct {
+	NotifyFunc consumers.ImportNotifyFunc
+}
+
+func (script *ImportJobConsumer) Run(ctx context.Context, _ app.IExit) {
+	configService := service.DI().Config()
+
+	namespace, ok := configService.String("importer.namespace")
+	if !ok {
+		panic("missing importer.namespace")
+	}
+
+	options := consumers.ImportJobOptions{
+		Namespace:       oss.Namespace(namespace),
+		URLExpiration:   time.Duration(configService.DefInt("importer.url_ttl", 24*60*60)) * time.Second,
+		DownloadTimeout: time.Duration(configService.DefInt("importer.download_timeout", 10*60)) * time.Second,
+	}
+
+	importJobConsumer := consumers.NewImportJobConsumer(
+		service.DI().OrmEngine(),
+		service.DI().Importer(),
+		service.DI().OSService(),
+		service.DI().Clock(),
+		script.NotifyFunc,
+		options,
+	)
+
+	queue.NewConsumerRunner(ctx).RunConsumerOne(importJobConsumer, nil, 1)
+}
+
+func (script *ImportJobConsumer) Infinity() bool {
+	return true
+}
+
+func (script *ImportJobConsumer) Unique() bool {
+	return true
+}
+
+func (script *ImportJobConsumer) Description() string {
+	return "import job consumer"
+}
@@ -0,0 +1,94 @@
+package scripts
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/sarulabs/di"
+
+	"github.com/coretrix/hitrix/service"
+	"github.com/coretrix/hitrix/service/component/app"
+	persistedquery "github.com/coretrix/hitrix/service/component/persisted_query"
+)
+
+// PersistedQueryManifest extracts operations from client code into the manifest, run it in the build of the client:
+// -run-script=persisted-query-manifest -persisted-query-src=../web/src -persisted-query-out=manifest.json
+func PersistedQueryManifest() *service.DefinitionGlobal {
+	return &service.DefinitionGlobal{
+		Name:   "persisted-query-manifest",
+		Script: true,
+		Build: func(ctn di.Container) (interface{}, error) {
+			return &PersistedQueryManifestScript{}, nil
+		},
+		Flags: func(registry *app.FlagsRegistry) {
+			registry.String("persisted-query-src", "", "comma separated client code directories with graphql operations")
+			registry.String("persisted-query-out", "", "persisted query manifest file")
+			registry.Bool("persisted-query-publish", false, "store manifest in server.graphql.persisted_queries.manifest_redis_key")
+		},
+	}
+}
+
+type PersistedQueryManifestScript struct {
+}
+
+func (script *PersistedQueryManifestScript) Run(_ context.Context, exit app.IExit) {
+	flags := service.DI().App().Flags
+
+	src := flags.String("persisted-query-src")
+	if src == "" {
+		log.Println("persisted-query-src flag is required")
+		exit.Error()
+
+		return
+	}
+
+	manifest, err := persistedquery.ExtractManifest(strings.Split(src, ",")...)
+	if err != nil {
+		log.Println(err)
+		exit.Error()
+
+		return
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		log.Println(err)
+		exit.Error()
+
+		return
+	}
+
+	if out := flags.String("persisted-query-out"); out != "" {
+		if err := os.WriteFile(out, data, 0600); err != nil {
+			log.Println(err)
+			exit.Error()
+
+			return
+		}
+	}
+
+	if flags.Bool("persisted-query-publish") {
+		redisKey, ok := service.DI().Config().String("server.graphql.persisted_queries.manifest_redis_key")
+		if !ok {
+			log.Println("server.graphql.persisted_queries.manifest_redis_key is not set")
+			exit.Error()
+
+			return
+		}
+
+		service.DI().OrmEngine().GetRedis().Set(redisKey, string(data), 0)
+	}
+
+	log.Printf("%d persisted operations extracted", len(manifest.Operations))
+}
+
+func (script *PersistedQueryManifestScript) Unique() bool {
+	return true
+}
+
+func (script *PersistedQueryManifestScript) Description() string {
+	return "extract graphql operations from client code into persisted query manifest"
+}
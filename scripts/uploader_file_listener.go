@@ -0,0 +1,30 @@
+package scripts
+
+import (
+	"context"
+
+	"github.com/coretrix/hitrix/service"
+	"github.com/coretrix/hitrix/service/component/app"
+)
+
+// UploaderFileListener should run in the same process as the uploader because tusd notifies about uploads using channels
+type UploaderFileListener struct {
+}
+
+func (script *UploaderFileListener) Run(ctx context.Context, _ app.IExit) {
+	if err := service.DI().UploaderFileListener().Listen(ctx, service.DI().Uploader()); err != nil {
+		panic(err)
+	}
+}
+
+func (script *UploaderFileListener) Infinity() bool {
+	return true
+}
+
+func (script *UploaderFileListener) Unique() bool {
+	return false
+}
+
+func (script *UploaderFileListener) Description() string {
+	return "uploader file listener"
+}
@@ -54,6 +54,16 @@ func (h *Hitrix) RunServer(
 	appService := service.DI().App()
 	defer appService.CancelContext()
 
+	if service.HasService(service.SubscriptionBrokerService) {
+		// websocket connections are hijacked, so Shutdown does not wait for them
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := service.DI().SubscriptionBroker().Close(ctx); err != nil {
+			log.Println("Subscription broker forced to close")
+		}
+
+		cancel()
+	}
+
 	if err := srv.Shutdown(appService.GlobalContext); err != nil {
 		log.Println("Server forced to shutdown")
 	}
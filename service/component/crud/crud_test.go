@@ -7,6 +7,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/xorcare/pointer"
+
+	"github.com/coretrix/hitrix/datalayer"
+	"github.com/coretrix/hitrix/pkg/entity"
 )
 
 func columns() []*Column {
@@ -53,6 +56,7 @@ func columns() []*Column {
 			Sortable:                 true,
 			Visible:                  true,
 			DataStringKeyStringValue: nil,
+			FullTextSearch:           true,
 		}, {
 			Key:                      "ArrayStringType",
 			FilterType:               ArrayStringType,
@@ -154,6 +158,9 @@ func TestExtractListParams(t *testing.T) {
 			RangeDateFilters:     map[string][]time.Time{},
 			BooleanFilters:       map[string]bool{},
 			Sort:                 map[string]bool{},
+			SortOrder:            []string{},
+			FullTextFields:       map[string]bool{},
+			UniqueSortFields:     map[string]bool{},
 		}
 		assert.Equal(t, expected, searchParam)
 	})
@@ -183,6 +190,9 @@ func TestExtractListParams(t *testing.T) {
 			RangeDateFilters:     map[string][]time.Time{},
 			BooleanFilters:       map[string]bool{},
 			Sort:                 map[string]bool{},
+			SortOrder:            []string{},
+			FullTextFields:       map[string]bool{},
+			UniqueSortFields:     map[string]bool{},
 		}
 		assert.Equal(t, expected, searchParam)
 	})
@@ -212,6 +222,9 @@ func TestExtractListParams(t *testing.T) {
 			RangeDateFilters:     map[string][]time.Time{},
 			BooleanFilters:       map[string]bool{},
 			Sort:                 map[string]bool{},
+			SortOrder:            []string{},
+			FullTextFields:       map[string]bool{},
+			UniqueSortFields:     map[string]bool{},
 		}
 		assert.Equal(t, expected, searchParam)
 	})
@@ -240,6 +253,9 @@ func TestExtractListParams(t *testing.T) {
 			RangeDateFilters:     map[string][]time.Time{},
 			BooleanFilters:       map[string]bool{},
 			Sort:                 map[string]bool{},
+			SortOrder:            []string{},
+			FullTextFields:       map[string]bool{},
+			UniqueSortFields:     map[string]bool{},
 		}
 		assert.Equal(t, expected, searchParam)
 	})
@@ -268,6 +284,9 @@ func TestExtractListParams(t *testing.T) {
 			RangeDateFilters:     map[string][]time.Time{},
 			BooleanFilters:       map[string]bool{},
 			Sort:                 map[string]bool{},
+			SortOrder:            []string{},
+			FullTextFields:       map[string]bool{},
+			UniqueSortFields:     map[string]bool{},
 		}
 		assert.Equal(t, expected, searchParam)
 	})
@@ -296,6 +315,9 @@ func TestExtractListParams(t *testing.T) {
 			RangeDateFilters:     map[string][]time.Time{},
 			BooleanFilters:       map[string]bool{},
 			Sort:                 map[string]bool{},
+			SortOrder:            []string{},
+			FullTextFields:       map[string]bool{},
+			UniqueSortFields:     map[string]bool{},
 		}
 		assert.Equal(t, expected, searchParam)
 	})
@@ -324,7 +346,423 @@ func TestExtractListParams(t *testing.T) {
 			RangeDateFilters:     map[string][]time.Time{},
 			BooleanFilters:       map[string]bool{},
 			Sort:                 map[string]bool{},
+			SortOrder:            []string{},
+			FullTextFields:       map[string]bool{},
+			UniqueSortFields:     map[string]bool{},
 		}
 		assert.Equal(t, expected, searchParam)
 	})
+	t.Run("Sort by multiple columns", func(t *testing.T) {
+		searchParam := crud.ExtractListParams(columns(), &ListRequest{
+			Sort: map[string]interface{}{
+				"InputTypeNumber":            "desc",
+				"StringTypeSearchable":       "asc",
+				"MultiSelectTypeArrayNumber": "asc",
+			},
+			SortOrder: []string{"StringTypeSearchable", "Missing"},
+		})
+		assert.Equal(t, map[string]bool{"InputTypeNumber": false, "StringTypeSearchable": true}, searchParam.Sort)
+		assert.Equal(t, []string{"StringTypeSearchable", "InputTypeNumber"}, searchParam.SortOrder)
+
+		request := &ListRequest{
+			Sort:      map[string]interface{}{"InputTypeNumber": "desc", "StringTypeSearchable": "asc"},
+			SortOrder: []string{"StringTypeSearchable"},
+		}
+
+		_, dropped := crud.SanitizeListRequest(columns(), request)
+		assert.Empty(t, dropped)
+
+		sanitized, dropped := crud.SanitizeListRedisSearchRequest(columns(), request)
+		assert.Equal(t, map[string]interface{}{"StringTypeSearchable": "asc"}, sanitized.Sort)
+		assert.Equal(t, []string{"StringTypeSearchable"}, sanitized.SortOrder)
+		assert.Equal(t, []string{"InputTypeNumber"}, dropped)
+	})
+	t.Run("Full text fields", func(t *testing.T) {
+		searchParam := crud.ExtractListParams(columns(), &ListRequest{
+			Search:   map[string]interface{}{"StringTypeSearchable": "john", "StringTypeFilterable": "doe"},
+			SearchOR: map[string]interface{}{"StringTypeSearchable": "jo"},
+		})
+		assert.Equal(t, map[string]bool{"StringTypeSearchable": true}, searchParam.FullTextFields)
+	})
+}
+
+func TestSanitizeListRequest(t *testing.T) {
+	crud := &Crud{}
+	pageSize := 20
+	page := 3
+	after := "cursor"
+
+	sanitized, dropped := crud.SanitizeListRequest(columns(), &ListRequest{
+		Page:     &page,
+		PageSize: &pageSize,
+		After:    &after,
+		Search: map[string]interface{}{
+			"InputTypeNumber":      float64(10),
+			"StringTypeFilterable": "",
+			"Removed":              "john",
+		},
+		SearchOR:  map[string]interface{}{"StringTypeSearchable": "jo", "RemovedOR": "jo"},
+		Sort:      map[string]interface{}{"InputTypeNumber": "desc", "Removed": "asc"},
+		SortOrder: []string{"Removed", "InputTypeNumber"},
+	})
+
+	assert.Equal(t, &ListRequest{
+		PageSize:  &pageSize,
+		Search:    map[string]interface{}{"InputTypeNumber": float64(10)},
+		SearchOR:  map[string]interface{}{"StringTypeSearchable": "jo"},
+		Sort:      map[string]interface{}{"InputTypeNumber": "desc"},
+		SortOrder: []string{"InputTypeNumber"},
+	}, sanitized)
+	assert.Equal(t, []string{"Removed", "RemovedOR"}, dropped)
+}
+
+func TestGenerateListMysqlQuery(t *testing.T) {
+	crud := &Crud{}
+
+	day := time.Date(2022, 5, 10, 0, 0, 0, 0, time.UTC)
+	dateTime := time.Date(2022, 5, 10, 12, 30, 0, 0, time.UTC)
+
+	where := crud.GenerateListMysqlQuery(SearchParams{
+		NumberFilters:        map[string]int64{"Number": 1},
+		ArrayNumberFilters:   map[string][]int64{"ArrayNumber": {1, 2}},
+		RangeNumberFilters:   map[string][]int64{"RangeNumber": {5, 10}},
+		DateTimeFilters:      map[string]time.Time{"DateTime": dateTime},
+		DateFilters:          map[string]time.Time{"Date": day},
+		RangeDateTimeFilters: map[string][]time.Time{"RangeDateTime": {day, dateTime}},
+		RangeDateFilters:     map[string][]time.Time{"RangeDate": {day, day.AddDate(0, 0, 2)}},
+		TagFilters:           map[string]string{"Tag": "active"},
+		ArrayStringFilters:   map[string][]string{"ArrayString": {"a", "b"}},
+		BooleanFilters:       map[string]bool{"Boolean": true},
+		StringFilters:        map[string]string{"Name": "10%_", "Description": "red +car-"},
+		StringORFilters:      map[string]string{"FirstName": "jo", "LastName": "do", "Nickname": "  "},
+		Sort:                 map[string]bool{"Name": true, "ID": false, "CreatedAt": false},
+		SortOrder:            []string{"Name", "ID"},
+		FullTextFields:       map[string]bool{"Description": true, "LastName": true},
+	})
+
+	assert.Equal(t, "1 AND `Number` = ?"+
+		" AND `ArrayNumber` IN (?,?)"+
+		" AND `RangeNumber` BETWEEN ? AND ?"+
+		" AND `DateTime` = ?"+
+		" AND `Date` >= ? AND `Date` < ?"+
+		" AND `RangeDateTime` BETWEEN ? AND ?"+
+		" AND `RangeDate` >= ? AND `RangeDate` < ?"+
+		" AND `Tag` = ?"+
+		" AND `ArrayString` IN (?,?)"+
+		" AND `Boolean` = ?"+
+		" AND MATCH(`Description`) AGAINST(? IN BOOLEAN MODE)"+
+		" AND `Name` LIKE ?"+
+		" AND (`FirstName` LIKE ? OR MATCH(`LastName`) AGAINST(? IN BOOLEAN MODE))"+
+		" ORDER BY `Name` ASC, `ID` DESC, `CreatedAt` DESC", where.String())
+
+	assert.Equal(t, []interface{}{
+		int64(1),
+		int64(1), int64(2),
+		int64(5), int64(10),
+		dateTime,
+		day, day.AddDate(0, 0, 1),
+		day, dateTime,
+		day, day.AddDate(0, 0, 3),
+		"active",
+		"a", "b",
+		true,
+		"+red* +car*",
+		"10\\%\\_%",
+		"jo%", "+do*",
+	}, where.GetParameters())
+
+	assert.Equal(t, "1", crud.GenerateListMysqlQuery(SearchParams{}).String())
+}
+
+func TestCursor(t *testing.T) {
+	createdAt := time.Date(2022, 5, 10, 12, 30, 0, 0, time.UTC)
+
+	for _, value := range []interface{}{nil, createdAt, "name", true, 1.5, int64(-5), uint64(5)} {
+		cursor, err := DecodeCursor(EncodeCursor(&Cursor{Field: "Field", Asc: true, Value: value, ID: 10}))
+		assert.Nil(t, err)
+		assert.Equal(t, &Cursor{Field: "Field", Asc: true, Value: value, ID: 10}, cursor)
+	}
+
+	cursor, err := DecodeCursor(EncodeCursor(&Cursor{Field: "Field", Value: 5}))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), cursor.Value)
+
+	_, err = DecodeCursor("invalid")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = DecodeCursor("eyJmIjoiIn0")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestGenerateListMysqlKeysetQuery(t *testing.T) {
+	crud := &Crud{}
+
+	createdAt := time.Date(2022, 5, 10, 12, 30, 0, 0, time.UTC)
+	row := &struct {
+		ID        uint64
+		CreatedAt *time.Time
+	}{ID: 7, CreatedAt: &createdAt}
+
+	params := SearchParams{
+		PageSize:      10,
+		NumberFilters: map[string]int64{"Number": 1},
+		Sort:          map[string]bool{"CreatedAt": false},
+	}
+
+	where, err := crud.GenerateListMysqlKeysetQuery(params)
+	assert.Nil(t, err)
+	assert.Equal(t, "1 AND `Number` = ? ORDER BY `CreatedAt` DESC, `ID` DESC", where.String())
+	assert.Equal(t, 11, crud.KeysetPager(params).PageSize)
+
+	params.After = crud.NewCursor(params, row)
+
+	where, err = crud.GenerateListMysqlKeysetQuery(params)
+	assert.Nil(t, err)
+	assert.Equal(t, "1 AND `Number` = ? AND (`CreatedAt` < ? OR (`CreatedAt` = ? AND `ID` < ?)) ORDER BY `CreatedAt` DESC, `ID` DESC", where.String())
+	assert.Equal(t, []interface{}{int64(1), createdAt, createdAt, uint64(7)}, where.GetParameters())
+
+	_, err = crud.GenerateListRedisSearchKeysetQuery(params)
+	assert.ErrorIs(t, err, ErrCursorSortNotUnique)
+
+	params.UniqueSortFields = map[string]bool{"CreatedAt": true}
+
+	_, err = crud.GenerateListRedisSearchKeysetQuery(params)
+	assert.Nil(t, err)
+
+	idParams := SearchParams{PageSize: 10}
+	idParams.After = crud.NewCursor(idParams, row)
+
+	where, err = crud.GenerateListMysqlKeysetQuery(idParams)
+	assert.Nil(t, err)
+	assert.Equal(t, "1 AND `ID` > ? ORDER BY `ID` ASC", where.String())
+	assert.Equal(t, []interface{}{uint64(7)}, where.GetParameters())
+
+	params.Sort = map[string]bool{"CreatedAt": true}
+
+	_, err = crud.GenerateListMysqlKeysetQuery(params)
+	assert.ErrorIs(t, err, ErrCursorSortChanged)
+
+	params.Sort = map[string]bool{"CreatedAt": false, "Name": true}
+
+	_, err = crud.GenerateListMysqlKeysetQuery(params)
+	assert.ErrorIs(t, err, ErrMultipleSortFields)
+
+	_, err = crud.GenerateListRedisSearchKeysetQuery(params)
+	assert.ErrorIs(t, err, ErrMultipleSortFields)
+
+	params.Sort = map[string]bool{"CreatedAt": false}
+	params.After = crud.NewCursor(params, &struct {
+		ID        uint64
+		CreatedAt *time.Time
+	}{ID: 8})
+
+	_, err = crud.GenerateListMysqlKeysetQuery(params)
+	assert.ErrorIs(t, err, ErrCursorNullValue)
+
+	nameParams := SearchParams{PageSize: 10, Sort: map[string]bool{"Name": true}, UniqueSortFields: map[string]bool{"Name": true}}
+	nameParams.After = EncodeCursor(&Cursor{Field: "Name", Asc: true, Value: "john", ID: 7})
+
+	_, err = crud.GenerateListRedisSearchKeysetQuery(nameParams)
+	assert.ErrorIs(t, err, ErrCursorNotNumeric)
+
+	uniqueParams := crud.ExtractListParams(
+		[]*Column{{Key: "CreatedAt", Sortable: true, UniqueSort: true}, {Key: "Name", Sortable: true}},
+		&ListRequest{Sort: map[string]interface{}{"CreatedAt": "desc"}},
+	)
+	assert.Equal(t, map[string]bool{"CreatedAt": true}, uniqueParams.UniqueSortFields)
+}
+
+func TestFacets(t *testing.T) {
+	crud := &Crud{}
+
+	cols := []*Column{
+		{
+			Key:                      "Status",
+			FilterType:               SelectTypeStringString,
+			Searchable:               true,
+			DataStringKeyStringValue: []*StringKeyStringValue{{Key: "active", Label: "Active"}, {Key: "blocked", Label: "Blocked"}},
+		},
+		{
+			Key:                   "Type",
+			FilterType:            SelectTypeIntString,
+			Searchable:            true,
+			DataIntKeyStringValue: []*IntKeyStringValue{{Key: 1, Label: "One"}},
+		},
+		{Key: "Enabled", FilterType: CheckboxTypeBoolean, Searchable: true},
+		{Key: "Price", FilterType: RangeSliderTypeArrayNumber, Searchable: true},
+		{Key: "Name", FilterType: InputTypeString, Searchable: true},
+		{Key: "Hidden", FilterType: CheckboxTypeBoolean},
+	}
+
+	facetColumns := crud.FacetColumns(cols)
+	assert.Len(t, facetColumns, 4)
+	assert.Equal(t, "Price", facetColumns[3].Key)
+
+	params := SearchParams{
+		TagFilters:         map[string]string{"Status": "active"},
+		RangeNumberFilters: map[string][]int64{"Price": {10, 20}},
+		StringORFilters:    map[string]string{"Status": "act"},
+	}
+
+	query, parameters := crud.GenerateListMysqlFacetQuery("products", cols[0], params)
+	assert.Equal(t, "SELECT `Status`, COUNT(*) FROM `products` WHERE 1 AND `Price` BETWEEN ? AND ? AND (`Status` LIKE ?) "+
+		"GROUP BY `Status` ORDER BY COUNT(*) DESC LIMIT 1000", query)
+	assert.Equal(t, []interface{}{int64(10), int64(20), "act%"}, parameters)
+	assert.Equal(t, "active", params.TagFilters["Status"])
+
+	query, parameters = crud.GenerateListMysqlFacetQuery("products", cols[3], params)
+	assert.Equal(t, "SELECT MIN(`Price`), MAX(`Price`), AVG(`Price`), COUNT(`Price`) FROM `products` WHERE 1 AND `Status` = ? AND (`Status` LIKE ?)", query)
+	assert.Equal(t, []interface{}{"active", "act%"}, parameters)
+
+	assert.NotNil(t, crud.GenerateListRedisSearchFacetQuery(cols[0], params))
+	assert.NotNil(t, crud.GenerateListRedisSearchFacetQuery(cols[3], params))
+
+	assert.Equal(t, []*FacetCount{
+		{Value: "active", Label: "Active", Count: 5},
+		{Value: "blocked", Label: "Blocked", Count: 0},
+		{Value: "new", Count: 7},
+		{Value: "old", Count: 2},
+	}, facetCounts(cols[0], map[string]uint64{"ACTIVE": 5, "old": 2, "new": 7}))
+
+	assert.Equal(t, []*FacetCount{
+		{Value: uint64(1), Label: "One", Count: 3},
+		{Value: uint64(2), Count: 1},
+	}, facetCounts(cols[1], map[string]uint64{"1": 3, "2": 1}))
+
+	assert.Equal(t, []*FacetCount{
+		{Value: true, Count: 4},
+		{Value: false, Count: 0},
+	}, facetCounts(cols[2], map[string]uint64{"1": 4}))
+}
+
+func TestExportPages(t *testing.T) {
+	calls := 0
+
+	crud := &Crud{ExportConfigs: []ExportConfig{{
+		ID: "users",
+		Handler: func(_ entity.TranslationTextLang, _ *datalayer.ORM, request *ListRequest, _ uint64, _ map[string]string) ([]string, [][]interface{}, error) {
+			calls++
+
+			from := (*request.Page - 1) * *request.PageSize
+			rows := make([][]interface{}, 0)
+
+			for i := from; i < from+*request.PageSize && i < 5; i++ {
+				rows = append(rows, []interface{}{i})
+			}
+
+			return []string{"ID"}, rows, nil
+		},
+	}}}
+
+	exported := make([]interface{}, 0)
+
+	err := crud.ExportPages("users", "", nil, &ListRequest{}, 0, nil, 2, func(columns []string, rows [][]interface{}) error {
+		assert.Equal(t, []string{"ID"}, columns)
+
+		for _, row := range rows {
+			exported = append(exported, row[0])
+		}
+
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{0, 1, 2, 3, 4}, exported)
+	assert.Equal(t, 3, calls)
+
+	assert.NotNil(t, crud.ExportPages("missing", "", nil, &ListRequest{}, 0, nil, 2, nil))
+}
+
+func TestExportPagesStopsOnRepeatedPage(t *testing.T) {
+	calls := 0
+
+	crud := &Crud{ExportConfigs: []ExportConfig{{
+		ID: "users",
+		Handler: func(_ entity.TranslationTextLang, _ *datalayer.ORM, _ *ListRequest, _ uint64, _ map[string]string) ([]string, [][]interface{}, error) {
+			calls++
+
+			return []string{"ID"}, [][]interface{}{{1}, {2}}, nil
+		},
+	}}}
+
+	exported := 0
+
+	err := crud.ExportPages("users", "", nil, &ListRequest{}, 0, nil, 2, func(_ []string, rows [][]interface{}) error {
+		exported += len(rows)
+
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, exported)
+	assert.Equal(t, 2, calls)
+}
+
+func TestExportPagesMaxPages(t *testing.T) {
+	crud := &Crud{ExportConfigs: []ExportConfig{{
+		ID: "users",
+		Handler: func(_ entity.TranslationTextLang, _ *datalayer.ORM, request *ListRequest, _ uint64, _ map[string]string) ([]string, [][]interface{}, error) {
+			return []string{"ID"}, [][]interface{}{{*request.Page}}, nil
+		},
+	}}}
+
+	err := crud.ExportPages("users", "", nil, &ListRequest{}, 0, nil, 1, func(_ []string, _ [][]interface{}) error {
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrExportTooManyPages)
+}
+
+func TestExportKeysetPages(t *testing.T) {
+	cursors := make([]string, 0)
+
+	crud := &Crud{ExportConfigs: []ExportConfig{{
+		ID: "users",
+		KeysetHandler: func(
+			_ entity.TranslationTextLang,
+			_ *datalayer.ORM,
+			request *ListRequest,
+			_ uint64,
+			_ map[string]string,
+		) ([]string, [][]interface{}, string, error) {
+			assert.Nil(t, request.Page)
+
+			from := 0
+
+			if request.After != nil {
+				cursors = append(cursors, *request.After)
+
+				cursor, err := DecodeCursor(*request.After)
+				if err != nil {
+					return nil, nil, "", err
+				}
+
+				from = int(cursor.ID)
+			}
+
+			rows := make([][]interface{}, 0)
+			next := ""
+
+			for i := from; i < from+*request.PageSize && i < 5; i++ {
+				rows = append(rows, []interface{}{i})
+			}
+
+			if from+len(rows) < 5 {
+				next = EncodeCursor(&Cursor{Field: cursorIDField, Asc: true, ID: uint64(from + len(rows))})
+			}
+
+			return []string{"ID"}, rows, next, nil
+		},
+	}}}
+
+	exported := make([]interface{}, 0)
+	page := 3
+
+	err := crud.ExportPages("users", "", nil, &ListRequest{Page: &page}, 0, nil, 2, func(_ []string, rows [][]interface{}) error {
+		for _, row := range rows {
+			exported = append(exported, row[0])
+		}
+
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{0, 1, 2, 3, 4}, exported)
+	assert.Len(t, cursors, 2)
 }
@@ -0,0 +1,281 @@
+package crud
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	redisearch "github.com/coretrix/beeorm-redisearch-plugin"
+	"github.com/latolukasz/beeorm/v2"
+)
+
+const (
+	cursorIDField   = "ID"
+	cursorTypeInt   = "int"
+	cursorTypeUint  = "uint"
+	cursorTypeFloat = "float"
+	cursorTypeBool  = "bool"
+	cursorTypeTime  = "time"
+	cursorTypeStr   = "string"
+)
+
+var (
+	ErrInvalidCursor       = errors.New("invalid cursor")
+	ErrCursorSortChanged   = errors.New("cursor does not match the sort")
+	ErrCursorNotNumeric    = errors.New("redis search cursor requires numeric or date sort field")
+	ErrCursorSortNotUnique = errors.New("redis search cursor requires sort field with unique values")
+	ErrCursorNullValue     = errors.New("cursor requires NOT NULL sort field")
+)
+
+// Cursor points to the row after which the next page starts. It is sent to the clients as opaque string
+type Cursor struct {
+	Field string
+	Asc   bool
+	Value interface{}
+	ID    uint64
+}
+
+type cursorPayload struct {
+	Field string `json:"f"`
+	Asc   bool   `json:"a"`
+	Type  string `json:"t,omitempty"`
+	Value string `json:"v,omitempty"`
+	ID    uint64 `json:"i"`
+}
+
+func EncodeCursor(cursor *Cursor) string {
+	payload := &cursorPayload{Field: cursor.Field, Asc: cursor.Asc, ID: cursor.ID}
+
+	switch value := cursor.Value.(type) {
+	case nil:
+	case time.Time:
+		payload.Type = cursorTypeTime
+		payload.Value = value.UTC().Format(time.RFC3339Nano)
+	case string:
+		payload.Type = cursorTypeStr
+		payload.Value = value
+	case bool:
+		payload.Type = cursorTypeBool
+		payload.Value = strconv.FormatBool(value)
+	case float32, float64:
+		payload.Type = cursorTypeFloat
+		payload.Value = strconv.FormatFloat(reflect.ValueOf(value).Float(), 'f', -1, 64)
+	default:
+		reflectValue := reflect.ValueOf(value)
+
+		if reflectValue.CanInt() {
+			payload.Type = cursorTypeInt
+			payload.Value = strconv.FormatInt(reflectValue.Int(), 10)
+		} else if reflectValue.CanUint() {
+			payload.Type = cursorTypeUint
+			payload.Value = strconv.FormatUint(reflectValue.Uint(), 10)
+		} else {
+			payload.Type = cursorTypeStr
+			payload.Value = fmt.Sprintf("%v", value)
+		}
+	}
+
+	encoded, _ := json.Marshal(payload)
+
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+func DecodeCursor(encoded string) (*Cursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	payload := &cursorPayload{}
+	if err := json.Unmarshal(decoded, payload); err != nil || payload.Field == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor := &Cursor{Field: payload.Field, Asc: payload.Asc, ID: payload.ID}
+
+	switch payload.Type {
+	case "":
+	case cursorTypeTime:
+		cursor.Value, err = time.Parse(time.RFC3339Nano, payload.Value)
+	case cursorTypeStr:
+		cursor.Value = payload.Value
+	case cursorTypeBool:
+		cursor.Value, err = strconv.ParseBool(payload.Value)
+	case cursorTypeFloat:
+		cursor.Value, err = strconv.ParseFloat(payload.Value, 64)
+	case cursorTypeInt:
+		cursor.Value, err = strconv.ParseInt(payload.Value, 10, 64)
+	case cursorTypeUint:
+		cursor.Value, err = strconv.ParseUint(payload.Value, 10, 64)
+	default:
+		err = ErrInvalidCursor
+	}
+
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+// NewCursor creates cursor from the sort field and ID of the row, row can be entity or any struct with ID field
+func (c *Crud) NewCursor(params SearchParams, row interface{}) string {
+	field, asc := params.keysetSort()
+	value := reflect.Indirect(reflect.ValueOf(row))
+
+	cursor := &Cursor{Field: field, Asc: asc, ID: value.FieldByName(cursorIDField).Uint()}
+
+	if field != cursorIDField {
+		fieldValue := value.FieldByName(field)
+		if fieldValue.Kind() == reflect.Pointer {
+			fieldValue = reflect.Indirect(fieldValue)
+		}
+
+		if fieldValue.IsValid() {
+			cursor.Value = fieldValue.Interface()
+		}
+	}
+
+	return EncodeCursor(cursor)
+}
+
+// KeysetPager loads one more row than the page size, so HasNextPage can be detected
+func (c *Crud) KeysetPager(params SearchParams) *beeorm.Pager {
+	return beeorm.NewPager(1, params.PageSize+1)
+}
+
+// GenerateListMysqlKeysetQuery returns rows after the cursor sorted by the sort field and ID.
+// Sort field has to be NOT NULL column
+func (c *Crud) GenerateListMysqlKeysetQuery(params SearchParams) (*beeorm.Where, error) {
+	cursor, err := params.cursor()
+	if err != nil {
+		return nil, err
+	}
+
+	where := generateMysqlFilters(params)
+	field, asc := params.keysetSort()
+
+	operator := ">"
+	if !asc {
+		operator = "<"
+	}
+
+	if cursor != nil {
+		if field == cursorIDField {
+			where.Append("AND `ID` "+operator+" ?", cursor.ID)
+		} else {
+			where.Append(
+				"AND (`"+field+"` "+operator+" ? OR (`"+field+"` = ? AND `ID` "+operator+" ?))",
+				cursor.Value, cursor.Value, cursor.ID,
+			)
+		}
+	}
+
+	if field == cursorIDField {
+		where.Append("ORDER BY `ID` " + sortDirection(asc))
+	} else {
+		where.Append("ORDER BY `" + field + "` " + sortDirection(asc) + ", `ID` " + sortDirection(asc))
+	}
+
+	return where, nil
+}
+
+// GenerateListRedisSearchKeysetQuery returns rows after the cursor using range filter.
+// RedisSearch sorts by one field only and rows with the same value would be skipped or repeated,
+// so sort field has to be ID or numeric or date column with UniqueSort, and ID has to be searchable
+func (c *Crud) GenerateListRedisSearchKeysetQuery(params SearchParams) (*redisearch.RedisSearchQuery, error) {
+	field, asc := params.keysetSort()
+	if field != cursorIDField && !params.UniqueSortFields[field] {
+		return nil, ErrCursorSortNotUnique
+	}
+
+	cursor, err := params.cursor()
+	if err != nil {
+		return nil, err
+	}
+
+	query := generateRedisSearchFilters(params)
+
+	if cursor != nil {
+		value := strconv.FormatUint(cursor.ID, 10)
+
+		if field != cursorIDField {
+			value, err = redisSearchNumber(cursor.Value)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		query.AppendQueryRaw(" " + redisSearchRangeAfter(field, value, asc))
+	}
+
+	query.Sort(field, !asc)
+
+	return query, nil
+}
+
+// keysetSort returns the field with the highest sort priority, rows are sorted by ID when sort is not set
+func (p SearchParams) keysetSort() (string, bool) {
+	if field, asc, ok := p.sortField(); ok {
+		return field, asc
+	}
+
+	return cursorIDField, true
+}
+
+// cursor returns decoded After cursor, keyset queries support one sort field only
+func (p SearchParams) cursor() (*Cursor, error) {
+	if len(p.Sort) > 1 {
+		return nil, ErrMultipleSortFields
+	}
+
+	if p.After == "" {
+		return nil, nil
+	}
+
+	cursor, err := DecodeCursor(p.After)
+	if err != nil {
+		return nil, err
+	}
+
+	field, asc := p.keysetSort()
+	if cursor.Field != field || cursor.Asc != asc {
+		return nil, ErrCursorSortChanged
+	}
+
+	if field != cursorIDField && cursor.Value == nil {
+		return nil, ErrCursorNullValue
+	}
+
+	return cursor, nil
+}
+
+func redisSearchRangeAfter(field, value string, asc bool) string {
+	if asc {
+		return "@" + field + ":[(" + value + " +inf]"
+	}
+
+	return "@" + field + ":[-inf (" + value + "]"
+}
+
+// redisSearchNumber converts the value the same way as RedisSearch plugin indexes it
+func redisSearchNumber(value interface{}) (string, error) {
+	switch value := value.(type) {
+	case time.Time:
+		return strconv.FormatInt(time.Date(
+			value.Year(), value.Month(), value.Day(), value.Hour(), value.Minute(), value.Second(), 0, time.UTC,
+		).Unix(), 10), nil
+	case int64:
+		return strconv.FormatInt(value, 10), nil
+	case uint64:
+		return strconv.FormatUint(value, 10), nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	default:
+		return "", ErrCursorNotNumeric
+	}
+}
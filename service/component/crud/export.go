@@ -0,0 +1,119 @@
+package crud
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/coretrix/hitrix/datalayer"
+	"github.com/coretrix/hitrix/pkg/entity"
+)
+
+const (
+	DefaultExportPageSize = 1000
+	// MaxExportPages stops the export of handler which never returns the last page
+	MaxExportPages = 100000
+)
+
+var ErrExportTooManyPages = errors.New("export exceeded maximum number of pages")
+
+type ExportJobDTO struct {
+	ExportJobEntityID uint64
+}
+
+// ExportPages calls export handler page by page, so the whole dataset is never loaded in memory, and passes every page to the callback.
+// KeysetHandler is preferred and it is called with the cursor of the previous page until it returns empty cursor.
+// Handler must respect Page and PageSize of the request. Handler which returns more rows than PageSize is treated as not paginated
+// and it is called only once, page which repeats the previous one ends the export as well.
+// The first page is passed to the callback even when it is empty, so the columns are always known
+func (c *Crud) ExportPages(
+	id string,
+	lang entity.TranslationTextLang,
+	ormService *datalayer.ORM,
+	request *ListRequest,
+	userID uint64,
+	extraArgs map[string]string,
+	pageSize int,
+	callback func(columns []string, rows [][]interface{}) error,
+) error {
+	config, ok := c.GetExportConfig(id)
+	if !ok || (config.Handler == nil && config.KeysetHandler == nil) {
+		return fmt.Errorf("export %s not found", id)
+	}
+
+	if pageSize <= 0 {
+		pageSize = DefaultExportPageSize
+	}
+
+	pageRequest := *request
+	pageRequest.PageSize = &pageSize
+
+	if config.KeysetHandler != nil {
+		return exportKeysetPages(config.KeysetHandler, lang, ormService, &pageRequest, userID, extraArgs, callback)
+	}
+
+	var previousRows [][]interface{}
+
+	for page := 1; page <= MaxExportPages; page++ {
+		currentPage := page
+		pageRequest.Page = &currentPage
+
+		columns, rows, err := config.Handler(lang, ormService, &pageRequest, userID, extraArgs)
+		if err != nil {
+			return err
+		}
+
+		if page > 1 && len(rows) > 0 && reflect.DeepEqual(rows, previousRows) {
+			return nil
+		}
+
+		if page == 1 || len(rows) > 0 {
+			if err := callback(columns, rows); err != nil {
+				return err
+			}
+		}
+
+		if len(rows) != pageSize {
+			return nil
+		}
+
+		previousRows = rows
+	}
+
+	return ErrExportTooManyPages
+}
+
+func exportKeysetPages(
+	handler ExportKeysetHandler,
+	lang entity.TranslationTextLang,
+	ormService *datalayer.ORM,
+	request *ListRequest,
+	userID uint64,
+	extraArgs map[string]string,
+	callback func(columns []string, rows [][]interface{}) error,
+) error {
+	request.Page = nil
+	request.After = nil
+
+	for page := 1; page <= MaxExportPages; page++ {
+		columns, rows, next, err := handler(lang, ormService, request, userID, extraArgs)
+		if err != nil {
+			return err
+		}
+
+		if page == 1 || len(rows) > 0 {
+			if err := callback(columns, rows); err != nil {
+				return err
+			}
+		}
+
+		if next == "" || len(rows) == 0 || (request.After != nil && *request.After == next) {
+			return nil
+		}
+
+		cursor := next
+		request.After = &cursor
+	}
+
+	return ErrExportTooManyPages
+}
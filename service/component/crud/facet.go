@@ -0,0 +1,339 @@
+package crud
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	redisearch "github.com/coretrix/beeorm-redisearch-plugin"
+	"github.com/latolukasz/beeorm/v2"
+
+	"github.com/coretrix/hitrix/datalayer"
+)
+
+const (
+	facetCountAlias = "count"
+	facetMinAlias   = "min"
+	facetMaxAlias   = "max"
+	facetAvgAlias   = "avg"
+	facetNullValue  = "NULL"
+	maxFacetValues  = 1000
+)
+
+// FacetCount is number of rows with the value. Value is string for SelectTypeStringString,
+// uint64 for SelectTypeIntString and bool for CheckboxTypeBoolean columns
+type FacetCount struct {
+	Value interface{}
+	Label string `json:",omitempty"`
+	Count uint64
+}
+
+// RangeStats are stats of RangeSliderTypeArrayNumber column values
+type RangeStats struct {
+	Min   float64
+	Max   float64
+	Avg   float64
+	Count uint64
+}
+
+// Facets are calculated with all active filters except the filter of the faceted column,
+// so the other values of the column can still be selected
+type Facets struct {
+	Counts map[string][]*FacetCount
+	Ranges map[string]*RangeStats
+}
+
+// FacetColumns returns searchable columns which have facets
+func (c *Crud) FacetColumns(cols []*Column) []*Column {
+	facetColumns := make([]*Column, 0)
+
+	for _, column := range cols {
+		if column.Searchable && (isCountFacet(column) || column.FilterType == RangeSliderTypeArrayNumber) {
+			facetColumns = append(facetColumns, column)
+		}
+	}
+
+	return facetColumns
+}
+
+// GenerateListMysqlFacetQuery returns GROUP BY query for count facet or MIN/MAX/AVG query for range column
+func (c *Crud) GenerateListMysqlFacetQuery(tableName string, column *Column, params SearchParams) (string, []interface{}) {
+	return mysqlFacetQuery(tableName, column, generateMysqlFilters(params.withoutFilter(column.Key)))
+}
+
+// GenerateListRedisSearchFacetQuery returns FT.AGGREGATE GROUPBY query for count facet or REDUCE MIN/MAX/AVG query for range column
+func (c *Crud) GenerateListRedisSearchFacetQuery(column *Column, params SearchParams) *redisearch.RedisSearchAggregation {
+	aggregation := generateRedisSearchFilters(params.withoutFilter(column.Key)).Aggregate()
+
+	if isCountFacet(column) {
+		return aggregation.
+			GroupByField("@"+column.Key, redisearch.NewAggregateReduceCount(facetCountAlias)).
+			Sort(redisearch.RedisSearchAggregationSort{Field: "@" + facetCountAlias, Desc: true})
+	}
+
+	return aggregation.GroupByFields(
+		[]string{},
+		redisearch.NewAggregateReduceMin("@"+column.Key, facetMinAlias),
+		redisearch.NewAggregateReduceMax("@"+column.Key, facetMaxAlias),
+		redisearch.NewAggregateReduceAvg("@"+column.Key, facetAvgAlias),
+		redisearch.NewAggregateReduceCount(facetCountAlias),
+	)
+}
+
+// MysqlFacets calculates facets with MySQL GROUP BY queries, use it for entities without RedisSearch index
+func (c *Crud) MysqlFacets(ormService *datalayer.ORM, entity beeorm.Entity, cols []*Column, params SearchParams) *Facets {
+	entitySchema := ormService.GetRegistry().GetEntitySchemaForEntity(entity)
+	db := entitySchema.GetMysql(ormService.Engine)
+	facets := newFacets()
+
+	for _, column := range c.FacetColumns(cols) {
+		where := runEntitySearchPlugins(ormService, entitySchema, generateMysqlFilters(params.withoutFilter(column.Key)))
+		query, parameters := mysqlFacetQuery(entitySchema.GetTableName(), column, where)
+
+		rows, closeRows := db.Query(query, parameters...)
+		values := map[string]uint64{}
+
+		for rows.Next() {
+			if isCountFacet(column) {
+				var value sql.NullString
+
+				var count uint64
+
+				rows.Scan(&value, &count)
+
+				if value.Valid {
+					values[value.String] = count
+				}
+
+				continue
+			}
+
+			var minValue, maxValue, avgValue sql.NullFloat64
+
+			var count uint64
+
+			rows.Scan(&minValue, &maxValue, &avgValue, &count)
+
+			if count > 0 {
+				facets.Ranges[column.Key] = &RangeStats{Min: minValue.Float64, Max: maxValue.Float64, Avg: avgValue.Float64, Count: count}
+			}
+		}
+
+		closeRows()
+
+		if isCountFacet(column) {
+			facets.Counts[column.Key] = facetCounts(column, values)
+		}
+	}
+
+	return facets
+}
+
+// RedisSearchFacets calculates facets with RedisSearch FT.AGGREGATE, faceted columns have to be sortable in the index
+func (c *Crud) RedisSearchFacets(ormService *datalayer.ORM, entity beeorm.Entity, cols []*Column, params SearchParams) (*Facets, error) {
+	facets := newFacets()
+
+	for _, column := range c.FacetColumns(cols) {
+		rows, err := redisSearchAggregate(ormService, entity, c.GenerateListRedisSearchFacetQuery(column, params))
+		if err != nil {
+			return nil, err
+		}
+
+		if isCountFacet(column) {
+			values := map[string]uint64{}
+
+			for _, row := range rows {
+				value, ok := row[column.Key]
+				if !ok || value == "" || value == facetNullValue {
+					continue
+				}
+
+				values[value], err = strconv.ParseUint(row[facetCountAlias], 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("facet %s count: %w", column.Key, err)
+				}
+			}
+
+			facets.Counts[column.Key] = facetCounts(column, values)
+
+			continue
+		}
+
+		if len(rows) == 0 {
+			continue
+		}
+
+		count, err := strconv.ParseUint(rows[0][facetCountAlias], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("facet %s count: %w", column.Key, err)
+		}
+
+		if count == 0 {
+			continue
+		}
+
+		stats := &RangeStats{Count: count}
+
+		for alias, value := range map[string]*float64{facetMinAlias: &stats.Min, facetMaxAlias: &stats.Max, facetAvgAlias: &stats.Avg} {
+			*value, err = strconv.ParseFloat(rows[0][alias], 64)
+			if err != nil {
+				return nil, fmt.Errorf("facet %s %s: %w", column.Key, alias, err)
+			}
+		}
+
+		facets.Ranges[column.Key] = stats
+	}
+
+	return facets, nil
+}
+
+// redisSearchAggregate converts panic of the plugin, e.g. missing index or redis error, to error
+func redisSearchAggregate(ormService *datalayer.ORM, entity beeorm.Entity, query *redisearch.RedisSearchAggregation) (rows []map[string]string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			rows = nil
+			err = fmt.Errorf("redis search aggregate: %v", r)
+		}
+	}()
+
+	// second value is total number of rows, errors are reported by panic
+	rows, _ = ormService.RedisSearchAggregate(entity, query, beeorm.NewPager(1, maxFacetValues))
+
+	return rows, nil
+}
+
+func newFacets() *Facets {
+	return &Facets{Counts: map[string][]*FacetCount{}, Ranges: map[string]*RangeStats{}}
+}
+
+func isCountFacet(column *Column) bool {
+	return column.FilterType == SelectTypeStringString || column.FilterType == SelectTypeIntString || column.FilterType == CheckboxTypeBoolean
+}
+
+func mysqlFacetQuery(tableName string, column *Column, where *beeorm.Where) (string, []interface{}) {
+	field := "`" + column.Key + "`"
+
+	/* #nosec */
+	if isCountFacet(column) {
+		return "SELECT " + field + ", COUNT(*) FROM `" + tableName + "` WHERE " + where.String() +
+			" GROUP BY " + field + " ORDER BY COUNT(*) DESC LIMIT " + strconv.Itoa(maxFacetValues), where.GetParameters()
+	}
+
+	/* #nosec */
+	return "SELECT MIN(" + field + "), MAX(" + field + "), AVG(" + field + "), COUNT(" + field + ") FROM `" + tableName +
+		"` WHERE " + where.String(), where.GetParameters()
+}
+
+// runEntitySearchPlugins applies plugins like fake delete the same way as orm Search does
+func runEntitySearchPlugins(ormService *datalayer.ORM, entitySchema beeorm.EntitySchema, where *beeorm.Where) *beeorm.Where {
+	registry := ormService.GetRegistry()
+
+	for _, code := range registry.GetPlugins() {
+		if plugin, ok := registry.GetPlugin(code).(beeorm.PluginInterfaceEntitySearch); ok {
+			where = plugin.PluginInterfaceEntitySearch(ormService.Engine, entitySchema, where)
+		}
+	}
+
+	return where
+}
+
+// facetCounts converts raw values to column values. Options of the column are returned in their order
+// including options without rows, other values follow sorted by count
+func facetCounts(column *Column, values map[string]uint64) []*FacetCount {
+	counts := make([]*FacetCount, 0, len(values))
+	used := map[string]bool{}
+
+	take := func(raw string) uint64 {
+		for value, count := range values {
+			if !used[value] && strings.EqualFold(value, raw) {
+				used[value] = true
+
+				return count
+			}
+		}
+
+		return 0
+	}
+
+	switch column.FilterType {
+	case SelectTypeStringString:
+		for _, option := range column.DataStringKeyStringValue {
+			counts = append(counts, &FacetCount{Value: option.Key, Label: option.Label, Count: take(option.Key)})
+		}
+	case SelectTypeIntString:
+		for _, option := range column.DataIntKeyStringValue {
+			counts = append(counts, &FacetCount{Value: option.Key, Label: option.Label, Count: take(strconv.FormatUint(option.Key, 10))})
+		}
+	case CheckboxTypeBoolean:
+		trueCount := take("true") + take("1")
+		falseCount := take("false") + take("0")
+		counts = append(counts, &FacetCount{Value: true, Count: trueCount}, &FacetCount{Value: false, Count: falseCount})
+	}
+
+	rest := make([]*FacetCount, 0)
+
+	for value, count := range values {
+		if used[value] {
+			continue
+		}
+
+		facetCount := &FacetCount{Value: value, Count: count}
+
+		if column.FilterType == SelectTypeIntString {
+			if number, err := strconv.ParseUint(value, 10, 64); err == nil {
+				facetCount.Value = number
+			}
+		}
+
+		rest = append(rest, facetCount)
+	}
+
+	sort.Slice(rest, func(i, j int) bool {
+		if rest[i].Count != rest[j].Count {
+			return rest[i].Count > rest[j].Count
+		}
+
+		return toFacetString(rest[i].Value) < toFacetString(rest[j].Value)
+	})
+
+	return append(counts, rest...)
+}
+
+func toFacetString(value interface{}) string {
+	if number, ok := value.(uint64); ok {
+		return strconv.FormatUint(number, 10)
+	}
+
+	return value.(string)
+}
+
+// withoutFilter returns params without the filters of the field, search box filter is kept
+func (p SearchParams) withoutFilter(field string) SearchParams {
+	value := reflect.ValueOf(&p).Elem()
+
+	for i := 0; i < value.NumField(); i++ {
+		name := value.Type().Field(i).Name
+		filters := value.Field(i)
+
+		if filters.Kind() != reflect.Map || name == "StringORFilters" || !strings.HasSuffix(name, "Filters") ||
+			!filters.MapIndex(reflect.ValueOf(field)).IsValid() {
+			continue
+		}
+
+		copied := reflect.MakeMapWithSize(filters.Type(), filters.Len())
+		iterator := filters.MapRange()
+
+		for iterator.Next() {
+			if iterator.Key().String() != field {
+				copied.SetMapIndex(iterator.Key(), iterator.Value())
+			}
+		}
+
+		filters.Set(copied)
+	}
+
+	return p
+}
@@ -2,8 +2,10 @@ package crud
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -17,6 +19,9 @@ import (
 	"github.com/coretrix/hitrix/service/component/translation"
 )
 
+// ErrMultipleSortFields is returned by keyset queries for more sort fields, because the cursor keeps value of one field only
+var ErrMultipleSortFields = errors.New("only one sort field is supported")
+
 type SearchParams struct {
 	Page                 int
 	PageSize             int
@@ -33,6 +38,14 @@ type SearchParams struct {
 	RangeDateFilters     map[string][]time.Time
 	BooleanFilters       map[string]bool
 	Sort                 map[string]bool
+	// SortOrder is the priority of the Sort fields
+	SortOrder []string
+	// FullTextFields are string fields matched with MySQL FULLTEXT index instead of LIKE
+	FullTextFields map[string]bool
+	// UniqueSortFields are sort fields with unique values, RedisSearch keyset query requires one
+	UniqueSortFields map[string]bool
+	// After is the cursor of the last loaded row used by keyset queries
+	After string
 }
 
 type Column struct {
@@ -48,6 +61,11 @@ type Column struct {
 	DataMapStringStringKeyStringValue map[string][]*StringKeyStringValue `json:",omitempty"`
 	DataStringKeyStringValue          []*StringKeyStringValue            `json:",omitempty"`
 	DataIntKeyStringValue             []*IntKeyStringValue               `json:",omitempty"`
+
+	// FullTextSearch should be set when the column has MySQL FULLTEXT index
+	FullTextSearch bool `json:"-"`
+	// UniqueSort should be set when the sortable column has unique values, dates are compared with second precision in RedisSearch
+	UniqueSort bool `json:"-"`
 }
 
 type IntKeyStringValue struct {
@@ -56,7 +74,9 @@ type IntKeyStringValue struct {
 }
 
 type ExportConfig struct {
-	Handler          ExportHandler
+	Handler ExportHandler
+	// KeysetHandler is used by ExportPages instead of Handler when it is set, so large exports do not use OFFSET
+	KeysetHandler    ExportKeysetHandler
 	ID               string
 	AllowedExtraArgs []string
 	Resource         string
@@ -65,6 +85,16 @@ type ExportConfig struct {
 
 type ExportHandler func(entity.TranslationTextLang, *datalayer.ORM, *ListRequest, uint64, map[string]string) ([]string, [][]interface{}, error)
 
+// ExportKeysetHandler returns rows after ListRequest.After cursor and the cursor of the last returned row.
+// Empty cursor is returned for the last page
+type ExportKeysetHandler func(
+	entity.TranslationTextLang,
+	*datalayer.ORM,
+	*ListRequest,
+	uint64,
+	map[string]string,
+) ([]string, [][]interface{}, string, error)
+
 type StringKeyStringValue struct {
 	Key   string
 	Label string
@@ -76,6 +106,10 @@ type ListRequest struct {
 	Search   map[string]interface{}
 	SearchOR map[string]interface{}
 	Sort     map[string]interface{}
+	// SortOrder defines the priority of Sort fields, because JSON object keys are not ordered
+	SortOrder []string
+	// After is the cursor of the last loaded row. Page is ignored by keyset queries
+	After *string
 }
 
 type groupedFilterTypes struct {
@@ -92,6 +126,8 @@ type groupedFilterTypes struct {
 	rangeDateTimeFilters   []string
 	rangeDateFilters       []string
 	sortables              []string
+	fullTextFields         map[string]bool
+	uniqueSortFields       map[string]bool
 }
 
 type Crud struct {
@@ -315,12 +351,51 @@ mainLoop:
 		}
 	}
 
-	if len(request.Sort) == 1 {
-		for field, mode := range request.Sort {
-			stringVal, ok := mode.(string)
-			if ok && helper.StringInArray(field, filterTypes.sortables...) && helper.StringInArray(stringVal, "asc", "desc") {
-				selectedSort[field] = stringVal == "asc"
-			}
+	for field, mode := range request.Sort {
+		stringVal, ok := mode.(string)
+		if ok && helper.StringInArray(field, filterTypes.sortables...) && helper.StringInArray(stringVal, "asc", "desc") {
+			selectedSort[field] = stringVal == "asc"
+		}
+	}
+
+	var selectedSortOrder = make([]string, 0, len(selectedSort))
+
+	for _, field := range request.SortOrder {
+		if _, ok := selectedSort[field]; ok && !helper.StringInArray(field, selectedSortOrder...) {
+			selectedSortOrder = append(selectedSortOrder, field)
+		}
+	}
+
+	for _, field := range sortedKeys(selectedSort) {
+		if !helper.StringInArray(field, selectedSortOrder...) {
+			selectedSortOrder = append(selectedSortOrder, field)
+		}
+	}
+
+	after := ""
+	if request.After != nil {
+		after = *request.After
+	}
+
+	var selectedUniqueSortFields = make(map[string]bool)
+
+	for field := range selectedSort {
+		if filterTypes.uniqueSortFields[field] {
+			selectedUniqueSortFields[field] = true
+		}
+	}
+
+	var selectedFullTextFields = make(map[string]bool)
+
+	for field := range selectedStringStartsWithFilters {
+		if filterTypes.fullTextFields[field] {
+			selectedFullTextFields[field] = true
+		}
+	}
+
+	for field := range selectedORFilters {
+		if filterTypes.fullTextFields[field] {
+			selectedFullTextFields[field] = true
 		}
 	}
 
@@ -340,9 +415,95 @@ mainLoop:
 		RangeDateFilters:     selectedRangeDateFilters,
 		BooleanFilters:       selectedBooleanFilters,
 		Sort:                 selectedSort,
+		SortOrder:            selectedSortOrder,
+		FullTextFields:       selectedFullTextFields,
+		UniqueSortFields:     selectedUniqueSortFields,
+		After:                after,
 	}
 }
 
+// SanitizeListRequest keeps only filters and sort accepted by ExtractListParams for current columns.
+// It returns the request without page and cursor and the dropped fields, empty values are removed without being reported
+func (c *Crud) SanitizeListRequest(cols []*Column, request *ListRequest) (*ListRequest, []string) {
+	searchParams := c.ExtractListParams(cols, request)
+
+	accepted := map[string]bool{}
+	for _, filters := range []interface{}{
+		searchParams.StringFilters,
+		searchParams.TagFilters,
+		searchParams.ArrayStringFilters,
+		searchParams.NumberFilters,
+		searchParams.ArrayNumberFilters,
+		searchParams.RangeNumberFilters,
+		searchParams.DateTimeFilters,
+		searchParams.DateFilters,
+		searchParams.RangeDateTimeFilters,
+		searchParams.RangeDateFilters,
+		searchParams.BooleanFilters,
+	} {
+		for _, field := range sortedKeys(filters) {
+			accepted[field] = true
+		}
+	}
+
+	sanitized := &ListRequest{
+		PageSize:  request.PageSize,
+		Search:    map[string]interface{}{},
+		SearchOR:  map[string]interface{}{},
+		Sort:      map[string]interface{}{},
+		SortOrder: searchParams.SortOrder,
+	}
+
+	dropped := make([]string, 0)
+
+	for _, field := range sortedKeys(request.Search) {
+		if request.Search[field] == nil || request.Search[field] == "" {
+			continue
+		}
+
+		if accepted[field] {
+			sanitized.Search[field] = request.Search[field]
+		} else {
+			dropped = append(dropped, field)
+		}
+	}
+
+	for _, field := range sortedKeys(request.SearchOR) {
+		if _, ok := searchParams.StringORFilters[field]; ok {
+			sanitized.SearchOR[field] = request.SearchOR[field]
+		} else if !helper.StringInArray(field, dropped...) {
+			dropped = append(dropped, field)
+		}
+	}
+
+	for _, field := range sortedKeys(request.Sort) {
+		if _, ok := searchParams.Sort[field]; ok {
+			sanitized.Sort[field] = request.Sort[field]
+		} else if !helper.StringInArray(field, dropped...) {
+			dropped = append(dropped, field)
+		}
+	}
+
+	return sanitized, dropped
+}
+
+// SanitizeListRedisSearchRequest works like SanitizeListRequest, but keeps only the sort field with the highest priority,
+// because RedisSearch sorts by one field only. Other sort fields are reported as dropped
+func (c *Crud) SanitizeListRedisSearchRequest(cols []*Column, request *ListRequest) (*ListRequest, []string) {
+	sanitized, dropped := c.SanitizeListRequest(cols, request)
+
+	if len(sanitized.SortOrder) > 1 {
+		for _, field := range sanitized.SortOrder[1:] {
+			delete(sanitized.Sort, field)
+			dropped = append(dropped, field)
+		}
+
+		sanitized.SortOrder = sanitized.SortOrder[:1]
+	}
+
+	return sanitized, dropped
+}
+
 func groupColumnNamesByFilterType(cols []*Column) groupedFilterTypes {
 	var stringStartsWithSearch = make([]string, 0)
 	var arrayStringFilters = make([]string, 0)
@@ -357,16 +518,26 @@ func groupColumnNamesByFilterType(cols []*Column) groupedFilterTypes {
 	var rangeDateTimeFilters = make([]string, 0)
 	var rangeDateFilters = make([]string, 0)
 	var sortables = make([]string, 0)
+	var fullTextFields = make(map[string]bool)
+	var uniqueSortFields = make(map[string]bool)
 
 	for _, column := range cols {
 		if column.Sortable {
 			sortables = append(sortables, column.Key)
+
+			if column.UniqueSort {
+				uniqueSortFields[column.Key] = true
+			}
 		}
 
 		if column.Searchable {
 			switch column.FilterType {
 			case InputTypeString:
 				stringStartsWithSearch = append(stringStartsWithSearch, column.Key)
+
+				if column.FullTextSearch {
+					fullTextFields[column.Key] = true
+				}
 			case ArrayStringType:
 				arrayStringFilters = append(arrayStringFilters, column.Key)
 			case CheckboxTypeBoolean:
@@ -407,11 +578,23 @@ func groupColumnNamesByFilterType(cols []*Column) groupedFilterTypes {
 		rangeDateTimeFilters:   rangeDateTimeFilters,
 		rangeDateFilters:       rangeDateFilters,
 		sortables:              sortables,
+		fullTextFields:         fullTextFields,
+		uniqueSortFields:       uniqueSortFields,
 	}
 }
 
 // GenerateListRedisSearchQuery TODO : add full text queries when supported by hitrix
 func (c *Crud) GenerateListRedisSearchQuery(params SearchParams) *redisearch.RedisSearchQuery {
+	query := generateRedisSearchFilters(params)
+
+	if field, asc, ok := params.sortField(); ok {
+		query.Sort(field, !asc)
+	}
+
+	return query
+}
+
+func generateRedisSearchFilters(params SearchParams) *redisearch.RedisSearchQuery {
 	query := &redisearch.RedisSearchQuery{}
 	for field, value := range params.NumberFilters {
 		query.FilterInt(field, value)
@@ -474,44 +657,98 @@ func (c *Crud) GenerateListRedisSearchQuery(params SearchParams) *redisearch.Red
 		query.AppendQueryRaw("(" + strings.Join(orStatements, "|") + ")")
 	}
 
-	if len(params.Sort) == 1 {
-		for field, mode := range params.Sort {
-			query.Sort(field, !mode)
+	return query
+}
+
+// GenerateListMysqlQuery supports the same filters as GenerateListRedisSearchQuery.
+// Date filters match the whole day, ranges are inclusive and fields are sorted in SortOrder
+func (c *Crud) GenerateListMysqlQuery(params SearchParams) *beeorm.Where {
+	where := generateMysqlFilters(params)
+
+	if sortFields := params.sortFields(); len(sortFields) > 0 {
+		sortStatements := make([]string, len(sortFields))
+
+		for i, field := range sortFields {
+			sortStatements[i] = "`" + field + "` " + sortDirection(params.Sort[field])
 		}
+
+		where.Append("ORDER BY " + strings.Join(sortStatements, ", "))
 	}
 
-	return query
+	return where
 }
 
-func (c *Crud) GenerateListMysqlQuery(params SearchParams) *beeorm.Where {
+func generateMysqlFilters(params SearchParams) *beeorm.Where {
 	where := beeorm.NewWhere("1")
-	for field, value := range params.NumberFilters {
-		where.Append("AND `"+field+"` = ?", value)
+	for _, field := range sortedKeys(params.NumberFilters) {
+		where.Append("AND `"+field+"` = ?", params.NumberFilters[field])
 	}
 
-	for field, value := range params.TagFilters {
-		where.Append("AND `"+field+"` = ?", value)
+	for _, field := range sortedKeys(params.ArrayNumberFilters) {
+		if len(params.ArrayNumberFilters[field]) > 0 {
+			where.Append("AND `"+field+"` IN ?", params.ArrayNumberFilters[field])
+		}
 	}
 
-	for field, value := range params.BooleanFilters {
-		where.Append("AND `"+field+"` = ?", value)
+	for _, field := range sortedKeys(params.RangeNumberFilters) {
+		value := params.RangeNumberFilters[field]
+		where.Append("AND `"+field+"` BETWEEN ? AND ?", value[0], value[1])
 	}
 
-	// TODO : use full text search
-	for field, value := range params.StringFilters {
-		where.Append("AND `"+field+"` LIKE ?", value+"%")
+	for _, field := range sortedKeys(params.DateTimeFilters) {
+		where.Append("AND `"+field+"` = ?", params.DateTimeFilters[field])
+	}
+
+	for _, field := range sortedKeys(params.DateFilters) {
+		day := params.DateFilters[field]
+		where.Append("AND `"+field+"` >= ? AND `"+field+"` < ?", day, day.AddDate(0, 0, 1))
+	}
+
+	for _, field := range sortedKeys(params.RangeDateTimeFilters) {
+		value := params.RangeDateTimeFilters[field]
+		where.Append("AND `"+field+"` BETWEEN ? AND ?", value[0], value[1])
+	}
+
+	for _, field := range sortedKeys(params.RangeDateFilters) {
+		value := params.RangeDateFilters[field]
+		where.Append("AND `"+field+"` >= ? AND `"+field+"` < ?", value[0], value[1].AddDate(0, 0, 1))
+	}
+
+	for _, field := range sortedKeys(params.TagFilters) {
+		where.Append("AND `"+field+"` = ?", params.TagFilters[field])
+	}
+
+	for _, field := range sortedKeys(params.ArrayStringFilters) {
+		if len(params.ArrayStringFilters[field]) > 0 {
+			where.Append("AND `"+field+"` IN ?", params.ArrayStringFilters[field])
+		}
+	}
+
+	for _, field := range sortedKeys(params.BooleanFilters) {
+		where.Append("AND `"+field+"` = ?", params.BooleanFilters[field])
+	}
+
+	for _, field := range sortedKeys(params.StringFilters) {
+		if strings.TrimSpace(params.StringFilters[field]) == "" {
+			continue
+		}
+
+		statement, variable := stringMatchStatement(field, params.StringFilters[field], params.FullTextFields[field])
+		where.Append("AND "+statement, variable)
 	}
 
 	orStatements := make([]string, 0)
 	orStatementsVariables := make([]interface{}, 0)
 
-	for field, value := range params.StringORFilters {
-		orStatements = append(orStatements, fmt.Sprintf(
-			"`%s` LIKE ?",
-			field,
-		))
+	for _, field := range sortedKeys(params.StringORFilters) {
+		if strings.TrimSpace(params.StringORFilters[field]) == "" {
+			continue
+		}
 
-		orStatementsVariables = append(orStatementsVariables, value+"%")
+		statement, variable := stringMatchStatement(field, params.StringORFilters[field], params.FullTextFields[field])
+
+		orStatements = append(orStatements, statement)
+		orStatementsVariables = append(orStatementsVariables, variable)
 	}
 
 	if len(orStatements) > 0 {
@@ -521,22 +758,79 @@ func (c *Crud) GenerateListMysqlQuery(params SearchParams) *beeorm.Where {
 		)
 	}
 
-	if len(params.Sort) == 1 {
-		sortQuery := "ORDER BY "
+	return where
+}
+
+func sortDirection(asc bool) string {
+	if asc {
+		return "ASC"
+	}
+
+	return "DESC"
+}
+
+// sortField returns the Sort field with the highest priority, it is used by RedisSearch which sorts by one field only
+func (p SearchParams) sortField() (string, bool, bool) {
+	if sortFields := p.sortFields(); len(sortFields) > 0 {
+		return sortFields[0], p.Sort[sortFields[0]], true
+	}
+
+	return "", false, false
+}
+
+// sortFields returns Sort fields in SortOrder, fields missing in SortOrder are sorted by name
+func (p SearchParams) sortFields() []string {
+	fields := make([]string, 0, len(p.Sort))
+
+	for _, field := range p.SortOrder {
+		if _, ok := p.Sort[field]; ok && !helper.StringInArray(field, fields...) {
+			fields = append(fields, field)
+		}
+	}
+
+	for _, field := range sortedKeys(p.Sort) {
+		if !helper.StringInArray(field, fields...) {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+// stringMatchStatement returns prefix match using FULLTEXT index in boolean mode or LIKE when the field has no FULLTEXT index
+func stringMatchStatement(field, value string, fullText bool) (string, string) {
+	if fullText {
+		words := strings.Fields(fullTextOperatorsReplacer.Replace(value))
 
-		for field, mode := range params.Sort {
-			sort := "ASC"
-			if !mode {
-				sort = "DESC"
+		if len(words) > 0 {
+			for i, word := range words {
+				words[i] = "+" + word + "*"
 			}
 
-			sortQuery += "`" + field + "` " + sort
+			return "MATCH(`" + field + "`) AGAINST(? IN BOOLEAN MODE)", strings.Join(words, " ")
 		}
+	}
 
-		where.Append(sortQuery)
+	return "`" + field + "` LIKE ?", likeEscapeReplacer.Replace(value) + "%"
+}
+
+var fullTextOperatorsReplacer = strings.NewReplacer(
+	"+", " ", "-", " ", "<", " ", ">", " ", "(", " ", ")", " ", "~", " ", "*", " ", "\"", " ", "@", " ",
+)
+
+var likeEscapeReplacer = strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_")
+
+func sortedKeys(m interface{}) []string {
+	keys := reflect.ValueOf(m).MapKeys()
+	result := make([]string, len(keys))
+
+	for i, key := range keys {
+		result[i] = key.String()
 	}
 
-	return where
+	sort.Strings(result)
+
+	return result
 }
 
 func (c *Crud) GetExportHandler(id string) (ExportHandler, bool) {
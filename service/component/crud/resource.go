@@ -0,0 +1,521 @@
+package crud
+
+import (
+	"context"
+	goErrors "errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/latolukasz/beeorm/v2"
+
+	"github.com/coretrix/hitrix/datalayer"
+	errorhandling "github.com/coretrix/hitrix/pkg/error_handling"
+	"github.com/coretrix/hitrix/pkg/errors"
+	"github.com/coretrix/hitrix/pkg/helper"
+	"github.com/coretrix/hitrix/pkg/response"
+)
+
+type Action string
+
+func (a Action) String() string {
+	return string(a)
+}
+
+const (
+	ActionList   Action = "list"
+	ActionGet    Action = "get"
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+
+	DefaultResourcePageSizeMin = 1
+	DefaultResourcePageSizeMax = 100
+)
+
+var ErrDuplicatedEntry = goErrors.New("entry already exists")
+
+// ACLFunc returns true when the user of the request has the permission to the resource, see acl.ResourceACL
+type ACLFunc func(c *gin.Context, ormService *datalayer.ORM, resource, permission string) bool
+
+type ResourceOptions struct {
+	// ORM returns orm for the request, usually service.DI().OrmEngineForContext
+	ORM func(ctx context.Context) *datalayer.ORM
+	// ACL is called before every action and it is required, all actions are denied when it is nil
+	ACL ACLFunc
+	// Resource is ACL resource name, table name is used by default
+	Resource string
+	// Permissions maps the actions to ACL permissions, action name is used by default
+	Permissions map[Action]string
+	Labels      map[string]string
+	// Fields are exposed as columns. When it is empty only `searchable` and `sortable` fields are exposed. ID is always exposed
+	Fields []string
+	// Exclude removes the fields from the columns
+	Exclude     []string
+	PageSizeMin int
+	PageSizeMax int
+	// NewRequest returns pointer to create and update request struct validated with `binding` tags.
+	// Fields are copied to the entity fields with the same name, nil pointers are skipped.
+	// Create and update handlers are not registered when it is nil
+	NewRequest func() interface{}
+	// BeforeSave is called before created or updated entity is flushed
+	BeforeSave func(c *gin.Context, ormService *datalayer.ORM, entity beeorm.Entity, request interface{}) error
+	// ToRow converts the entity to list and get response, map of the column values is returned by default
+	ToRow func(entity beeorm.Entity) interface{}
+	// Delete registers delete handler
+	Delete bool
+	// Facets adds facet counts and range stats of the columns to the list response
+	Facets bool
+}
+
+type ResourceList struct {
+	Rows    []interface{}
+	Total   int
+	Columns []*Column
+	Facets  *Facets `json:",omitempty"`
+}
+
+type resourceID struct {
+	ID uint64 `uri:"ID" binding:"required"`
+}
+
+// Resource derives list columns from the orm tags of the entity and serves list/get/create/update/delete handlers
+type Resource struct {
+	entityType  reflect.Type
+	options     *ResourceOptions
+	columnsOnce sync.Once
+	columns     []*Column
+}
+
+// ResourceFor creates resource of the entity. `searchable` fields become filters, `sortable` fields can be sorted
+// and `enum` and `set` fields get select options. Other fields are exposed only when they are listed in Fields
+func ResourceFor(entity beeorm.Entity, options *ResourceOptions) *Resource {
+	if options == nil {
+		options = &ResourceOptions{}
+	}
+
+	return &Resource{entityType: reflect.TypeOf(entity).Elem(), options: options}
+}
+
+// Register adds resource handlers to the router group. Delete handler is registered only when Delete option is set
+func (r *Resource) Register(router gin.IRoutes) {
+	if r.options.ACL == nil {
+		panic("crud.ResourceOptions.ACL is required")
+	}
+
+	router.POST("/list/", r.ListHandler)
+	router.GET("/:ID/", r.GetHandler)
+
+	if r.options.NewRequest != nil {
+		router.POST("/", r.CreateHandler)
+		router.PUT("/:ID/", r.UpdateHandler)
+	}
+
+	if r.options.Delete {
+		router.DELETE("/:ID/", r.DeleteHandler)
+	}
+}
+
+// Columns returns copy of the columns, so labels can be translated
+func (r *Resource) Columns(registry beeorm.ValidatedRegistry) []*Column {
+	r.columnsOnce.Do(func() {
+		r.columns = r.buildColumns(registry)
+	})
+
+	columns := make([]*Column, len(r.columns))
+
+	for i, column := range r.columns {
+		columnCopy := *column
+		columns[i] = &columnCopy
+	}
+
+	return columns
+}
+
+func (r *Resource) ListHandler(c *gin.Context) {
+	ormService, ok := r.authorize(c, ActionList)
+	if !ok {
+		return
+	}
+
+	request := &ListRequest{}
+	if errorhandling.HandleError(c, bindJSON(c, request)) {
+		return
+	}
+
+	if errorhandling.HandleError(c, r.validatePageSize(request)) {
+		return
+	}
+
+	crudService := &Crud{}
+	columns := r.Columns(ormService.GetRegistry())
+
+	searchParams := crudService.ExtractListParams(columns, request)
+	query := crudService.GenerateListMysqlQuery(searchParams)
+
+	if len(searchParams.Sort) == 0 {
+		query.Append("ORDER BY `ID` DESC")
+	}
+
+	entities := reflect.New(reflect.SliceOf(reflect.PtrTo(r.entityType)))
+	total := ormService.SearchWithCount(query, beeorm.NewPager(searchParams.Page, searchParams.PageSize), entities.Interface())
+
+	rows := make([]interface{}, entities.Elem().Len())
+
+	for i := range rows {
+		rows[i] = r.toRow(columns, entities.Elem().Index(i).Interface().(beeorm.Entity))
+	}
+
+	list := &ResourceList{Rows: rows, Total: total, Columns: columns}
+
+	if r.options.Facets {
+		list.Facets = crudService.MysqlFacets(ormService, r.newEntity(), columns, searchParams)
+	}
+
+	response.SuccessResponse(c, list)
+}
+
+func (r *Resource) GetHandler(c *gin.Context) {
+	ormService, ok := r.authorize(c, ActionGet)
+	if !ok {
+		return
+	}
+
+	entity, ok := r.load(c, ormService)
+	if !ok {
+		return
+	}
+
+	response.SuccessResponse(c, r.toRow(r.Columns(ormService.GetRegistry()), entity))
+}
+
+func (r *Resource) CreateHandler(c *gin.Context) {
+	ormService, ok := r.authorize(c, ActionCreate)
+	if !ok {
+		return
+	}
+
+	r.save(c, ormService, r.newEntity())
+}
+
+func (r *Resource) UpdateHandler(c *gin.Context) {
+	ormService, ok := r.authorize(c, ActionUpdate)
+	if !ok {
+		return
+	}
+
+	entity, ok := r.load(c, ormService)
+	if !ok {
+		return
+	}
+
+	r.save(c, ormService, entity)
+}
+
+func (r *Resource) DeleteHandler(c *gin.Context) {
+	ormService, ok := r.authorize(c, ActionDelete)
+	if !ok {
+		return
+	}
+
+	entity, ok := r.load(c, ormService)
+	if !ok {
+		return
+	}
+
+	ormService.Delete(entity)
+
+	response.SuccessResponse(c, nil)
+}
+
+func (r *Resource) authorize(c *gin.Context, action Action) (*datalayer.ORM, bool) {
+	ormService := r.options.ORM(c.Request.Context())
+
+	permission := action.String()
+	if customPermission, ok := r.options.Permissions[action]; ok {
+		permission = customPermission
+	}
+
+	resource := r.options.Resource
+	if resource == "" {
+		resource = ormService.GetRegistry().GetEntitySchemaForEntity(r.newEntity()).GetTableName()
+	}
+
+	if r.options.ACL == nil || !r.options.ACL(c, ormService, resource, permission) {
+		response.ErrorResponseGlobal(c, &errors.PermissionError{Message: "permission denied"}, nil)
+
+		return nil, false
+	}
+
+	return ormService, true
+}
+
+func (r *Resource) load(c *gin.Context, ormService *datalayer.ORM) (beeorm.Entity, bool) {
+	request := &resourceID{}
+
+	if err := c.ShouldBindUri(request); err != nil {
+		if fieldErrors := errors.HandleErrors(err); fieldErrors != nil {
+			err = fieldErrors
+		}
+
+		errorhandling.HandleError(c, err)
+
+		return nil, false
+	}
+
+	entity := r.newEntity()
+	if !ormService.LoadByID(request.ID, entity) {
+		response.NotFoundResponse(c)
+
+		return nil, false
+	}
+
+	return entity, true
+}
+
+func (r *Resource) save(c *gin.Context, ormService *datalayer.ORM, entity beeorm.Entity) {
+	request := r.options.NewRequest()
+	if errorhandling.HandleError(c, bindJSON(c, request)) {
+		return
+	}
+
+	copyRequestFields(request, entity)
+
+	if r.options.BeforeSave != nil {
+		if errorhandling.HandleError(c, r.options.BeforeSave(c, ormService, entity, request)) {
+			return
+		}
+	}
+
+	if err := ormService.FlushWithCheck(entity); err != nil {
+		errorhandling.HandleError(c, errors.HandleFlushWithCheckError(err, ErrDuplicatedEntry))
+
+		return
+	}
+
+	response.SuccessResponse(c, r.toRow(r.Columns(ormService.GetRegistry()), entity))
+}
+
+func (r *Resource) validatePageSize(request *ListRequest) error {
+	pageSizeMin := r.options.PageSizeMin
+	if pageSizeMin == 0 {
+		pageSizeMin = DefaultResourcePageSizeMin
+	}
+
+	pageSizeMax := r.options.PageSizeMax
+	if pageSizeMax == 0 {
+		pageSizeMax = DefaultResourcePageSizeMax
+	}
+
+	if request.PageSize == nil {
+		return nil
+	}
+
+	if *request.PageSize < pageSizeMin {
+		return fmt.Errorf("page size must be greater than or equal to %v", pageSizeMin)
+	}
+
+	if *request.PageSize > pageSizeMax {
+		return fmt.Errorf("page size must be less than or equal to %v", pageSizeMax)
+	}
+
+	return nil
+}
+
+func (r *Resource) newEntity() beeorm.Entity {
+	return reflect.New(r.entityType).Interface().(beeorm.Entity)
+}
+
+// toRow returns values of the columns only, so excluded fields are never sent. References are returned as IDs
+func (r *Resource) toRow(columns []*Column, entity beeorm.Entity) interface{} {
+	if r.options.ToRow != nil {
+		return r.options.ToRow(entity)
+	}
+
+	entityValue := reflect.ValueOf(entity).Elem()
+	row := make(map[string]interface{}, len(columns))
+
+	for _, column := range columns {
+		value := entityValue.FieldByName(column.Key)
+
+		if _, ok := value.Interface().(beeorm.Entity); ok {
+			if value.IsNil() {
+				row[column.Key] = nil
+			} else {
+				row[column.Key] = value.Elem().FieldByName("ID").Uint()
+			}
+
+			continue
+		}
+
+		row[column.Key] = value.Interface()
+	}
+
+	return row
+}
+
+func (r *Resource) buildColumns(registry beeorm.ValidatedRegistry) []*Column {
+	entitySchema := registry.GetEntitySchemaForEntity(r.newEntity())
+	columns := make([]*Column, 0, r.entityType.NumField())
+
+	for i := 0; i < r.entityType.NumField(); i++ {
+		field := r.entityType.Field(i)
+
+		if field.Anonymous || !field.IsExported() || field.Name == "FakeDelete" || helper.StringInArray(field.Name, r.options.Exclude...) {
+			continue
+		}
+
+		searchable := entitySchema.GetTag(field.Name, "searchable", "true", "") == "true"
+		sortable := entitySchema.GetTag(field.Name, "sortable", "true", "") == "true"
+
+		if !r.isExposed(field.Name, searchable || sortable) {
+			continue
+		}
+
+		column := &Column{
+			Key:      field.Name,
+			Label:    field.Name,
+			Sortable: sortable,
+			Visible:  true,
+		}
+
+		if label, ok := r.options.Labels[field.Name]; ok {
+			column.Label = label
+		}
+
+		if field.Type.Kind() == reflect.Bool {
+			column.FieldType = FieldTypeBool
+		}
+
+		if searchable {
+			column.FilterType = resourceFilterType(registry, entitySchema, field, column)
+			column.Searchable = column.FilterType != ""
+		}
+
+		columns = append(columns, column)
+	}
+
+	return columns
+}
+
+// isExposed keeps the columns opt-in, so fields added to the entity later are not sent until they are tagged or listed in Fields
+func (r *Resource) isExposed(fieldName string, tagged bool) bool {
+	if fieldName == "ID" {
+		return true
+	}
+
+	if len(r.options.Fields) > 0 {
+		return helper.StringInArray(fieldName, r.options.Fields...)
+	}
+
+	return tagged
+}
+
+func resourceFilterType(registry beeorm.ValidatedRegistry, entitySchema beeorm.EntitySchema, field reflect.StructField, column *Column) string {
+	for _, tag := range []string{"enum", "set"} {
+		code := entitySchema.GetTag(field.Name, tag, "", "")
+		if code == "" {
+			continue
+		}
+
+		column.DataStringKeyStringValue = make([]*StringKeyStringValue, 0)
+
+		if enum := registry.GetEnum(code); enum != nil {
+			for _, value := range enum.GetFields() {
+				column.DataStringKeyStringValue = append(column.DataStringKeyStringValue, &StringKeyStringValue{Key: value, Label: value})
+			}
+		}
+
+		if tag == "set" {
+			return ArrayStringType
+		}
+
+		return SelectTypeStringString
+	}
+
+	fieldType := field.Type
+	if fieldType.Kind() == reflect.Pointer {
+		if fieldType.Implements(reflect.TypeOf((*beeorm.Entity)(nil)).Elem()) {
+			return InputTypeNumber
+		}
+
+		fieldType = fieldType.Elem()
+	}
+
+	if fieldType == reflect.TypeOf(time.Time{}) {
+		if entitySchema.GetTag(field.Name, "time", "true", "") == "true" {
+			return RangeDateTimePickerTypeArrayDateTime
+		}
+
+		return RangeDatePickerTypeArrayDate
+	}
+
+	switch fieldType.Kind() {
+	case reflect.String:
+		return InputTypeString
+	case reflect.Bool:
+		return CheckboxTypeBoolean
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if field.Name == "ID" {
+			return InputTypeNumber
+		}
+
+		return RangeSliderTypeArrayNumber
+	default:
+		return ""
+	}
+}
+
+// copyRequestFields sets entity fields from request fields with the same name. Pointer request fields are used for optional values
+func copyRequestFields(request interface{}, entity beeorm.Entity) {
+	requestValue := reflect.Indirect(reflect.ValueOf(request))
+	entityValue := reflect.ValueOf(entity).Elem()
+
+	for i := 0; i < requestValue.NumField(); i++ {
+		field := requestValue.Type().Field(i)
+		if !field.IsExported() || field.Name == "ID" {
+			continue
+		}
+
+		entityField := entityValue.FieldByName(field.Name)
+		if !entityField.IsValid() || !entityField.CanSet() {
+			continue
+		}
+
+		value := requestValue.Field(i)
+
+		if value.Kind() == reflect.Pointer && entityField.Kind() != reflect.Pointer {
+			if value.IsNil() {
+				continue
+			}
+
+			value = value.Elem()
+		}
+
+		if value.Type().AssignableTo(entityField.Type()) {
+			entityField.Set(value)
+		} else if value.Kind() == entityField.Kind() && value.Type().ConvertibleTo(entityField.Type()) {
+			entityField.Set(value.Convert(entityField.Type()))
+		}
+	}
+}
+
+func bindJSON(c *gin.Context, form interface{}) error {
+	if c.Request.Body == nil {
+		return fmt.Errorf("body cannot be nil")
+	}
+
+	if err := c.ShouldBindBodyWith(form, binding.JSON); err != nil {
+		if fieldErrors := errors.HandleErrors(err); fieldErrors != nil {
+			return fieldErrors
+		}
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,268 @@
+package crud
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/latolukasz/beeorm/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/xorcare/pointer"
+
+	"github.com/coretrix/hitrix/datalayer"
+)
+
+type resourceReferenceEntity struct {
+	beeorm.ORM
+	ID uint64
+}
+
+type resourceEntity struct {
+	beeorm.ORM `orm:"table=resources"`
+	ID         uint64                   `orm:"sortable;searchable"`
+	Name       string                   `orm:"searchable;sortable"`
+	Status     string                   `orm:"enum=entity.StatusAll;searchable"`
+	Tags       []string                 `orm:"set=entity.StatusAll;searchable"`
+	Active     bool                     `orm:"searchable"`
+	Parent     *resourceReferenceEntity `orm:"searchable"`
+	CreatedAt  time.Time                `orm:"time=true;searchable"`
+	Birthday   *time.Time               `orm:"searchable"`
+	Price      float64                  `orm:"searchable"`
+	Stock      uint32                   `orm:"searchable"`
+	Note       string
+	Secret     string
+	FakeDelete bool
+}
+
+type fakeEnum struct {
+	beeorm.Enum
+	fields []string
+}
+
+func (e *fakeEnum) GetFields() []string {
+	return e.fields
+}
+
+type fakeEntitySchema struct {
+	beeorm.EntitySchema
+	entityType reflect.Type
+}
+
+func (s *fakeEntitySchema) GetTag(field, key, trueValue, defaultValue string) string {
+	structField, _ := s.entityType.FieldByName(field)
+
+	for _, tag := range strings.Split(structField.Tag.Get("orm"), ";") {
+		parts := strings.Split(tag, "=")
+		if parts[0] != key {
+			continue
+		}
+
+		if len(parts) == 1 || parts[1] == "true" {
+			return trueValue
+		}
+
+		return parts[1]
+	}
+
+	return defaultValue
+}
+
+type fakeRegistry struct {
+	beeorm.ValidatedRegistry
+}
+
+func (r *fakeRegistry) GetEntitySchemaForEntity(entity beeorm.Entity) beeorm.EntitySchema {
+	return &fakeEntitySchema{entityType: reflect.TypeOf(entity).Elem()}
+}
+
+func (r *fakeRegistry) GetEnum(code string) beeorm.Enum {
+	if code != "entity.StatusAll" {
+		return nil
+	}
+
+	return &fakeEnum{fields: []string{"new", "done"}}
+}
+
+func TestResourceColumns(t *testing.T) {
+	resource := ResourceFor(&resourceEntity{}, &ResourceOptions{
+		Labels:  map[string]string{"Name": "Full name"},
+		Exclude: []string{"Price"},
+	})
+
+	columns := resource.Columns(&fakeRegistry{})
+
+	keys := make([]string, len(columns))
+	filterTypes := map[string]string{}
+
+	for i, column := range columns {
+		keys[i] = column.Key
+		filterTypes[column.Key] = column.FilterType
+
+		assert.True(t, column.Visible)
+		assert.Equal(t, column.FilterType != "", column.Searchable)
+	}
+
+	// fields without searchable or sortable tag are not exposed
+	assert.Equal(t, []string{"ID", "Name", "Status", "Tags", "Active", "Parent", "CreatedAt", "Birthday", "Stock"}, keys)
+	assert.Equal(t, map[string]string{
+		"ID":        InputTypeNumber,
+		"Name":      InputTypeString,
+		"Status":    SelectTypeStringString,
+		"Tags":      ArrayStringType,
+		"Active":    CheckboxTypeBoolean,
+		"Parent":    InputTypeNumber,
+		"CreatedAt": RangeDateTimePickerTypeArrayDateTime,
+		"Birthday":  RangeDatePickerTypeArrayDate,
+		"Stock":     RangeSliderTypeArrayNumber,
+	}, filterTypes)
+
+	assert.Equal(t, "Full name", columns[1].Label)
+	assert.True(t, columns[1].Sortable)
+	assert.False(t, columns[2].Sortable)
+	assert.Equal(t, []*StringKeyStringValue{{Key: "new", Label: "new"}, {Key: "done", Label: "done"}}, columns[2].DataStringKeyStringValue)
+	assert.Equal(t, FieldTypeBool, columns[4].FieldType)
+
+	facetKeys := make([]string, 0)
+	for _, column := range (&Crud{}).FacetColumns(columns) {
+		facetKeys = append(facetKeys, column.Key)
+	}
+
+	assert.Equal(t, []string{"Status", "Active", "Stock"}, facetKeys)
+
+	columns[1].Label = "Translated"
+	assert.Equal(t, "Full name", resource.Columns(&fakeRegistry{})[1].Label)
+
+	searchParams := (&Crud{}).ExtractListParams(columns, &ListRequest{
+		Search: map[string]interface{}{"Status": "done", "Name": "jo", "Parent": float64(2)},
+		Sort:   map[string]interface{}{"Name": "asc"},
+	})
+	assert.Equal(t, map[string]string{"Status": "done"}, searchParams.TagFilters)
+	assert.Equal(t, map[string]string{"Name": "jo"}, searchParams.StringFilters)
+	assert.Equal(t, map[string]int64{"Parent": 2}, searchParams.NumberFilters)
+	assert.Equal(t, map[string]bool{"Name": true}, searchParams.Sort)
+}
+
+func TestCopyRequestFields(t *testing.T) {
+	type status string
+
+	entity := &resourceEntity{ID: 5, Name: "old", Note: "note", Active: true}
+
+	copyRequestFields(&struct {
+		ID     uint64
+		Name   string
+		Status status
+		Note   *string
+		Active *bool
+		Price  int
+	}{ID: 10, Name: "new", Status: "done", Active: pointer.Bool(false), Price: 5}, entity)
+
+	assert.Equal(t, uint64(5), entity.ID)
+	assert.Equal(t, "new", entity.Name)
+	assert.Equal(t, "done", entity.Status)
+	assert.Equal(t, "note", entity.Note)
+	assert.False(t, entity.Active)
+	assert.Equal(t, float64(0), entity.Price)
+}
+
+func TestResourceACL(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newResource := func(acl ACLFunc, deleteEnabled bool) *Resource {
+		return ResourceFor(&resourceEntity{}, &ResourceOptions{
+			ORM: func(_ context.Context) *datalayer.ORM {
+				return nil
+			},
+			ACL:      acl,
+			Resource: "resources",
+			NewRequest: func() interface{} {
+				return &struct{ Name string }{}
+			},
+			Delete: deleteEnabled,
+		})
+	}
+
+	assert.PanicsWithValue(t, "crud.ResourceOptions.ACL is required", func() {
+		newResource(nil, true).Register(gin.New())
+	})
+
+	permissions := make([]string, 0)
+
+	ginEngine := gin.New()
+	newResource(func(_ *gin.Context, _ *datalayer.ORM, resource, permission string) bool {
+		assert.Equal(t, "resources", resource)
+
+		permissions = append(permissions, permission)
+
+		return false
+	}, true).Register(ginEngine.Group("/resources"))
+
+	send := func(ginEngine *gin.Engine, method, path, body string) int {
+		recorder := httptest.NewRecorder()
+		ginEngine.ServeHTTP(recorder, httptest.NewRequest(method, path, strings.NewReader(body)))
+
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, send(ginEngine, http.MethodPost, "/resources/list/", "{}"))
+	assert.Equal(t, http.StatusForbidden, send(ginEngine, http.MethodGet, "/resources/1/", ""))
+	assert.Equal(t, http.StatusForbidden, send(ginEngine, http.MethodPost, "/resources/", `{"Name": "new"}`))
+	assert.Equal(t, http.StatusForbidden, send(ginEngine, http.MethodPut, "/resources/1/", `{"Name": "new"}`))
+	assert.Equal(t, http.StatusForbidden, send(ginEngine, http.MethodDelete, "/resources/1/", ""))
+	assert.Equal(t, []string{"list", "get", "create", "update", "delete"}, permissions)
+
+	ginEngine = gin.New()
+	newResource(func(_ *gin.Context, _ *datalayer.ORM, _, _ string) bool {
+		return false
+	}, false).Register(ginEngine.Group("/resources"))
+
+	assert.Equal(t, http.StatusNotFound, send(ginEngine, http.MethodDelete, "/resources/1/", ""))
+
+	assert.Equal(t, http.StatusForbidden, send(ginEngine, http.MethodGet, "/resources/1/", ""))
+
+	denied := httptest.NewRecorder()
+	ginContext, _ := gin.CreateTestContext(denied)
+	ginContext.Request = httptest.NewRequest(http.MethodDelete, "/resources/1/", nil)
+
+	newResource(nil, true).DeleteHandler(ginContext)
+	assert.Equal(t, http.StatusForbidden, denied.Code)
+}
+
+func TestResourceToRow(t *testing.T) {
+	resource := ResourceFor(&resourceEntity{}, nil)
+	createdAt := time.Unix(1, 0)
+
+	row := resource.toRow(resource.Columns(&fakeRegistry{}), &resourceEntity{
+		ID:        5,
+		Name:      "john",
+		Parent:    &resourceReferenceEntity{ID: 2},
+		CreatedAt: createdAt,
+		Note:      "note",
+		Secret:    "secret",
+	})
+
+	assert.Equal(t, map[string]interface{}{
+		"ID":        uint64(5),
+		"Name":      "john",
+		"Status":    "",
+		"Tags":      []string(nil),
+		"Active":    false,
+		"Parent":    uint64(2),
+		"CreatedAt": createdAt,
+		"Birthday":  (*time.Time)(nil),
+		"Price":     float64(0),
+		"Stock":     uint32(0),
+	}, row)
+
+	row = resource.toRow(resource.Columns(&fakeRegistry{}), &resourceEntity{ID: 6})
+	assert.Nil(t, row.(map[string]interface{})["Parent"])
+
+	resource = ResourceFor(&resourceEntity{}, &ResourceOptions{Fields: []string{"Name", "Note"}})
+
+	row = resource.toRow(resource.Columns(&fakeRegistry{}), &resourceEntity{ID: 7, Name: "john", Note: "note", Secret: "secret"})
+	assert.Equal(t, map[string]interface{}{"ID": uint64(7), "Name": "john", "Note": "note"}, row)
+}
@@ -2,6 +2,7 @@ package exporter
 
 import (
 	"errors"
+	"io"
 	"strconv"
 	"strings"
 )
@@ -11,6 +12,7 @@ type IExporter interface {
 	XLSXExportToByte(sheet string, columns []string, rows [][]interface{}) ([]byte, error)
 	CSVExportToFile(columns []string, rows [][]interface{}, filePath string) error
 	CSVExportToByte(columns []string, rows [][]interface{}) ([]byte, error)
+	NewExportWriter(format Format, writer io.Writer, sheet string, columns []string) (ExportWriter, error)
 }
 
 type Exporter struct {
@@ -41,6 +43,10 @@ func (e *Exporter) CSVExportToByte(columns []string, rows [][]interface{}) ([]by
 	return e.csvExporter.exportToByte(columns, rows)
 }
 
+func (e *Exporter) NewExportWriter(format Format, writer io.Writer, sheet string, columns []string) (ExportWriter, error) {
+	return NewExportWriter(format, writer, sheet, columns)
+}
+
 func verifyRows(columns []string, rows [][]interface{}) error {
 	dataErrors := make([]string, 0, 1)
 
@@ -1,6 +1,12 @@
 package mocks
 
-import "github.com/stretchr/testify/mock"
+import (
+	"io"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/coretrix/hitrix/service/component/exporter"
+)
 
 type FakeExporter struct {
 	mock.Mock
@@ -25,3 +31,9 @@ func (e *FakeExporter) CSVExportToByte(columns []string, rows [][]interface{}) (
 
 	return args.Get(0).([]byte), args.Error(1)
 }
+
+func (e *FakeExporter) NewExportWriter(format exporter.Format, writer io.Writer, sheet string, columns []string) (exporter.ExportWriter, error) {
+	args := e.Called(format, writer, sheet, columns)
+
+	return args.Get(0).(exporter.ExportWriter), args.Error(1)
+}
@@ -0,0 +1,273 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/tealeg/xlsx"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+type Format string
+
+func (f Format) String() string {
+	return string(f)
+}
+
+const (
+	FormatCSV        Format = "csv"
+	FormatXLSX       Format = "xlsx"
+	FormatJSONLines  Format = "jsonl"
+	FormatParquet    Format = "parquet"
+	MaxXLSXSheetRows        = 1048576
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+var parquetColumnNameReplacer = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// ExportWriter writes rows one by one so the whole dataset never has to be loaded in memory.
+// Close finishes the document but does not close the underlying io.Writer
+type ExportWriter interface {
+	WriteRow(row []interface{}) error
+	Close() error
+}
+
+// NewExportWriter creates streaming writer for the format. Sheet name is used only by XLSX format
+func NewExportWriter(format Format, w io.Writer, sheet string, columns []string) (ExportWriter, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatXLSX:
+		return newXLSXWriter(w, sheet, columns)
+	case FormatJSONLines:
+		return &jsonLinesWriter{writer: w, columns: columns}, nil
+	case FormatParquet:
+		return newParquetWriter(w, columns)
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+}
+
+type csvWriter struct {
+	writer  *csv.Writer
+	columns []string
+	rows    int
+}
+
+func newCSVWriter(w io.Writer, columns []string) (ExportWriter, error) {
+	csvWriter := &csvWriter{writer: csv.NewWriter(w), columns: columns}
+
+	if err := csvWriter.writer.Write(columns); err != nil {
+		return nil, err
+	}
+
+	return csvWriter, nil
+}
+
+func (w *csvWriter) WriteRow(row []interface{}) error {
+	if err := verifyRow(w.columns, row, w.rows); err != nil {
+		return err
+	}
+
+	w.rows++
+
+	return w.writer.Write(rowToStrings(row))
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+
+	return w.writer.Error()
+}
+
+type xlsxWriter struct {
+	file    *xlsx.StreamFile
+	columns []string
+	rows    int
+}
+
+func newXLSXWriter(w io.Writer, sheet string, columns []string) (ExportWriter, error) {
+	builder := xlsx.NewStreamFileBuilder(w)
+
+	if err := builder.AddSheet(sheet, columns, nil); err != nil {
+		return nil, err
+	}
+
+	file, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return &xlsxWriter{file: file, columns: columns}, nil
+}
+
+func (w *xlsxWriter) WriteRow(row []interface{}) error {
+	if err := verifyRow(w.columns, row, w.rows); err != nil {
+		return err
+	}
+
+	if w.rows+1 >= MaxXLSXSheetRows {
+		return fmt.Errorf("xlsx sheet can not contain more than %d rows", MaxXLSXSheetRows)
+	}
+
+	w.rows++
+
+	return w.file.Write(rowToStrings(row))
+}
+
+func (w *xlsxWriter) Close() error {
+	return w.file.Close()
+}
+
+type jsonLinesWriter struct {
+	writer  io.Writer
+	columns []string
+	rows    int
+}
+
+func (w *jsonLinesWriter) WriteRow(row []interface{}) error {
+	if err := verifyRow(w.columns, row, w.rows); err != nil {
+		return err
+	}
+
+	w.rows++
+
+	line := &bytes.Buffer{}
+	line.WriteByte('{')
+
+	for i, column := range w.columns {
+		if i > 0 {
+			line.WriteByte(',')
+		}
+
+		key, err := json.Marshal(column)
+		if err != nil {
+			return err
+		}
+
+		value, err := json.Marshal(row[i])
+		if err != nil {
+			return err
+		}
+
+		line.Write(key)
+		line.WriteByte(':')
+		line.Write(value)
+	}
+
+	line.WriteString("}\n")
+
+	_, err := w.writer.Write(line.Bytes())
+
+	return err
+}
+
+func (w *jsonLinesWriter) Close() error {
+	return nil
+}
+
+type parquetWriter struct {
+	writer  *writer.CSVWriter
+	columns []string
+	rows    int
+}
+
+// newParquetWriter stores all columns as optional UTF8 strings.
+// Column names are converted to identifiers because parquet schema does not allow spaces and punctuation in them
+func newParquetWriter(w io.Writer, columns []string) (ExportWriter, error) {
+	metadata := make([]string, len(columns))
+	names := make(map[string]int, len(columns))
+
+	for i, column := range columns {
+		name := parquetColumnNameReplacer.ReplaceAllString(column, "_")
+		if name == "" || name == "_" {
+			name = "Column"
+		}
+
+		names[name]++
+		if names[name] > 1 {
+			name += "_" + strconv.Itoa(names[name])
+		}
+
+		metadata[i] = "name=" + name + ", type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"
+	}
+
+	csvWriter, err := writer.NewCSVWriterFromWriter(metadata, w, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	csvWriter.RowGroupSize = 16 * 1024 * 1024
+
+	return &parquetWriter{writer: csvWriter, columns: columns}, nil
+}
+
+func (w *parquetWriter) WriteRow(row []interface{}) error {
+	if err := verifyRow(w.columns, row, w.rows); err != nil {
+		return err
+	}
+
+	w.rows++
+
+	values := make([]*string, len(row))
+
+	for i, cell := range row {
+		if cell == nil {
+			continue
+		}
+
+		value := cellToString(cell)
+		values[i] = &value
+	}
+
+	return w.writer.WriteString(values)
+}
+
+func (w *parquetWriter) Close() error {
+	return w.writer.WriteStop()
+}
+
+func verifyRow(columns []string, row []interface{}, rowID int) error {
+	if len(row) != len(columns) {
+		return errors.New("Different column count for row[" + strconv.Itoa(rowID) + "]")
+	}
+
+	return nil
+}
+
+func rowToStrings(row []interface{}) []string {
+	record := make([]string, len(row))
+
+	for i, cell := range row {
+		record[i] = cellToString(cell)
+	}
+
+	return record
+}
+
+func cellToString(cell interface{}) string {
+	switch value := cell.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case time.Time:
+		return value.Format(time.RFC3339)
+	case *time.Time:
+		if value == nil {
+			return ""
+		}
+
+		return value.Format(time.RFC3339)
+	}
+
+	return fmt.Sprint(cell)
+}
@@ -0,0 +1,50 @@
+package exporter_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tealeg/xlsx"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+
+	"github.com/coretrix/hitrix/service/component/exporter"
+)
+
+func writeRows(t *testing.T, format exporter.Format) []byte {
+	output := &bytes.Buffer{}
+
+	writer, err := exporter.NewExportWriter(format, output, "Sheet", []string{"Name", "Total amount"})
+	assert.Nil(t, err)
+
+	assert.Nil(t, writer.WriteRow([]interface{}{"first", 10}))
+	assert.Nil(t, writer.WriteRow([]interface{}{"second", nil}))
+	assert.NotNil(t, writer.WriteRow([]interface{}{"third"}))
+	assert.Nil(t, writer.Close())
+
+	return output.Bytes()
+}
+
+func TestExportWriter(t *testing.T) {
+	assert.Equal(t, "Name,Total amount\nfirst,10\nsecond,\n", string(writeRows(t, exporter.FormatCSV)))
+
+	assert.Equal(
+		t,
+		"{\"Name\":\"first\",\"Total amount\":10}\n{\"Name\":\"second\",\"Total amount\":null}\n",
+		string(writeRows(t, exporter.FormatJSONLines)),
+	)
+
+	xlsxFile, err := xlsx.OpenBinary(writeRows(t, exporter.FormatXLSX))
+	assert.Nil(t, err)
+	assert.Len(t, xlsxFile.Sheets[0].Rows, 3)
+	assert.Equal(t, "Total amount", xlsxFile.Sheets[0].Rows[0].Cells[1].Value)
+	assert.Equal(t, "10", xlsxFile.Sheets[0].Rows[1].Cells[1].Value)
+
+	parquetReader, err := reader.NewParquetReader(buffer.NewBufferFileFromBytes(writeRows(t, exporter.FormatParquet)), nil, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), parquetReader.GetNumRows())
+
+	_, err = exporter.NewExportWriter("pdf", &bytes.Buffer{}, "", []string{"Name"})
+	assert.ErrorIs(t, err, exporter.ErrUnsupportedFormat)
+}
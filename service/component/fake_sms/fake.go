@@ -0,0 +1,170 @@
+package fakesms
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/coretrix/hitrix/service/component/clock"
+	"github.com/coretrix/hitrix/service/component/generator"
+	"github.com/coretrix/hitrix/service/component/otp"
+	"github.com/coretrix/hitrix/service/component/sms"
+)
+
+const Name = "fake"
+
+const (
+	TypeSMS      = "sms"
+	TypeOTP      = "otp"
+	TypeCall     = "call"
+	TypeWhatsApp = "whatsapp"
+)
+
+var ErrInjectedFailure = errors.New("fake sms: injected failure")
+
+type Message struct {
+	Type   string
+	Number string
+	Text   string
+	Code   string
+	SentAt time.Time
+}
+
+// IFake is sms and otp gateway which keeps sent messages for tests and dev panel
+type IFake interface {
+	sms.IProvider
+	otp.IOTPWhatsAppGateway
+	otp.IOTPChannelGateway
+	Inbox(number string) []*Message
+	LastMessageTo(number string) *Message
+	FailNextSends(number string, times int)
+	FailAllSends(number string)
+	StopFailing(number string)
+	Clear()
+}
+
+// Fake is in-process gateway which implements both sms.IProvider and otp.IOTPSMSGateway.
+// Nothing leaves the process, every message is saved in the store
+type Fake struct {
+	Store            IStore
+	ClockService     clock.IClock
+	GeneratorService generator.IGenerator
+	OTPLength        int
+}
+
+func NewFake(store IStore, clockService clock.IClock, generatorService generator.IGenerator, otpLength int) *Fake {
+	return &Fake{
+		Store:            store,
+		ClockService:     clockService,
+		GeneratorService: generatorService,
+		OTPLength:        otpLength,
+	}
+}
+
+func (f *Fake) GetName() string {
+	return Name
+}
+
+func (f *Fake) SendSMSMessage(message *sms.Message) (string, string, error) {
+	if f.Store.ConsumeFailure(message.Number) {
+		return ErrInjectedFailure.Error(), "", ErrInjectedFailure
+	}
+
+	f.push(TypeSMS, message.Number, message.Text, "")
+
+	return sms.Success, "", nil
+}
+
+func (f *Fake) GetCode() string {
+	otpLength := f.OTPLength
+	if otpLength == 0 {
+		otpLength = 5
+	}
+
+	min := int64(math.Pow(10, float64(otpLength-1)))
+	max := int64(math.Pow(10, float64(otpLength))) - 1
+
+	return strconv.FormatInt(f.GeneratorService.GenerateRandomRangeNumber(min, max), 10)
+}
+
+func (f *Fake) GetPhonePrefixes() []string {
+	return nil
+}
+
+func (f *Fake) SendOTP(phone *otp.Phone, code string) (string, string, error) {
+	if f.Store.ConsumeFailure(phone.Number) {
+		return phone.Number, ErrInjectedFailure.Error(), ErrInjectedFailure
+	}
+
+	f.push(TypeOTP, phone.Number, "", code)
+
+	return phone.Number, "", nil
+}
+
+func (f *Fake) Call(phone *otp.Phone, code string, customMessage string) (string, string, error) {
+	if f.Store.ConsumeFailure(phone.Number) {
+		return phone.Number, ErrInjectedFailure.Error(), ErrInjectedFailure
+	}
+
+	f.push(TypeCall, phone.Number, customMessage, code)
+
+	return phone.Number, "", nil
+}
+
+func (f *Fake) GetChannels() []string {
+	return []string{otp.ChannelSMS, otp.ChannelCallout, otp.ChannelWhatsApp}
+}
+
+func (f *Fake) SendWhatsAppOTP(phone *otp.Phone, code string) (string, string, error) {
+	if f.Store.ConsumeFailure(phone.Number) {
+		return phone.Number, ErrInjectedFailure.Error(), ErrInjectedFailure
+	}
+
+	f.push(TypeWhatsApp, phone.Number, "", code)
+
+	return phone.Number, "", nil
+}
+
+func (f *Fake) VerifyOTP(_ *otp.Phone, code, generatedCode string) (string, string, bool, bool, error) {
+	return "", "", true, code == generatedCode, nil
+}
+
+func (f *Fake) Inbox(number string) []*Message {
+	return f.Store.List(number)
+}
+
+func (f *Fake) LastMessageTo(number string) *Message {
+	messages := f.Store.List(number)
+	if len(messages) == 0 {
+		return nil
+	}
+
+	return messages[0]
+}
+
+func (f *Fake) FailNextSends(number string, times int) {
+	f.Store.SetFailure(number, times)
+}
+
+func (f *Fake) FailAllSends(number string) {
+	f.Store.SetFailure(number, -1)
+}
+
+func (f *Fake) StopFailing(number string) {
+	f.Store.ClearFailure(number)
+}
+
+func (f *Fake) Clear() {
+	f.Store.Clear()
+}
+
+func (f *Fake) push(typ, number, text, code string) {
+	f.Store.Push(&Message{
+		Type:   typ,
+		Number: number,
+		Text:   text,
+		Code:   code,
+		SentAt: f.ClockService.Now(),
+	})
+}
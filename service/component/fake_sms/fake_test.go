@@ -0,0 +1,76 @@
+package fakesms_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	clockMocks "github.com/coretrix/hitrix/service/component/clock/mocks"
+	fakesms "github.com/coretrix/hitrix/service/component/fake_sms"
+	"github.com/coretrix/hitrix/service/component/generator"
+	"github.com/coretrix/hitrix/service/component/otp"
+	"github.com/coretrix/hitrix/service/component/sms"
+)
+
+func TestFakeStoresMessages(t *testing.T) {
+	fakeClock := &clockMocks.FakeSysClock{}
+	fakeClock.On("Now").Return(time.Unix(1, 0))
+
+	fake := fakesms.NewFake(fakesms.NewMemoryStore(), fakeClock, &generator.SimpleGenerator{}, 4)
+
+	status, _, err := fake.SendSMSMessage(&sms.Message{Number: "+359888111222", Text: "hello"})
+	assert.Nil(t, err)
+	assert.Equal(t, sms.Success, status)
+
+	code := fake.GetCode()
+	assert.Len(t, code, 4)
+
+	_, _, err = fake.SendOTP(&otp.Phone{Number: "+359888111222"}, code)
+	assert.Nil(t, err)
+
+	messages := fake.Inbox("+359888111222")
+	assert.Len(t, messages, 2)
+	assert.Equal(t, fakesms.TypeOTP, messages[0].Type)
+	assert.Equal(t, code, messages[0].Code)
+	assert.Equal(t, "hello", messages[1].Text)
+
+	assert.Nil(t, fake.LastMessageTo("+359888000000"))
+
+	_, _, requestValid, codeValid, err := fake.VerifyOTP(&otp.Phone{Number: "+359888111222"}, code, code)
+	assert.Nil(t, err)
+	assert.True(t, requestValid)
+	assert.True(t, codeValid)
+}
+
+func TestFakeFailureInjection(t *testing.T) {
+	fakeClock := &clockMocks.FakeSysClock{}
+	fakeClock.On("Now").Return(time.Unix(1, 0))
+
+	fake := fakesms.NewFake(fakesms.NewMemoryStore(), fakeClock, &generator.SimpleGenerator{}, 0)
+	phone := &otp.Phone{Number: "+359888111222"}
+
+	fake.FailNextSends(phone.Number, 2)
+
+	_, _, err := fake.SendOTP(phone, "1234")
+	assert.ErrorIs(t, err, fakesms.ErrInjectedFailure)
+
+	_, _, err = fake.SendSMSMessage(&sms.Message{Number: phone.Number})
+	assert.ErrorIs(t, err, fakesms.ErrInjectedFailure)
+
+	_, _, err = fake.SendOTP(phone, "1234")
+	assert.Nil(t, err)
+
+	fake.FailAllSends(phone.Number)
+
+	for i := 0; i < 3; i++ {
+		_, _, err = fake.SendOTP(phone, "1234")
+		assert.ErrorIs(t, err, fakesms.ErrInjectedFailure)
+	}
+
+	fake.StopFailing(phone.Number)
+
+	_, _, err = fake.SendOTP(phone, "1234")
+	assert.Nil(t, err)
+	assert.Len(t, fake.Inbox(phone.Number), 2)
+}
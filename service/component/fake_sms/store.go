@@ -0,0 +1,183 @@
+package fakesms
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/latolukasz/beeorm/v2"
+)
+
+const (
+	maxStoredMessages = 1000
+
+	redisKeyPrefix   = "hitrix_fake_sms:"
+	redisInboxKey    = redisKeyPrefix + "inbox"
+	redisFailuresKey = redisKeyPrefix + "failures"
+)
+
+type IStore interface {
+	Push(message *Message)
+	// List returns messages sent to number, newest first. Empty number returns all messages
+	List(number string) []*Message
+	Clear()
+	// SetFailure makes next times sends to number fail. Negative times fails all sends until ClearFailure
+	SetFailure(number string, times int)
+	ClearFailure(number string)
+	// ConsumeFailure returns true if the send to number should fail
+	ConsumeFailure(number string) bool
+}
+
+type MemoryStore struct {
+	mutex    sync.Mutex
+	messages []*Message
+	failures map[string]int
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		messages: make([]*Message, 0),
+		failures: map[string]int{},
+	}
+}
+
+func (s *MemoryStore) Push(message *Message) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.messages = append([]*Message{message}, s.messages...)
+
+	if len(s.messages) > maxStoredMessages {
+		s.messages = s.messages[:maxStoredMessages]
+	}
+}
+
+func (s *MemoryStore) List(number string) []*Message {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	messages := make([]*Message, 0)
+
+	for _, message := range s.messages {
+		if number == "" || message.Number == number {
+			messages = append(messages, message)
+		}
+	}
+
+	return messages
+}
+
+func (s *MemoryStore) Clear() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.messages = make([]*Message, 0)
+	s.failures = map[string]int{}
+}
+
+func (s *MemoryStore) SetFailure(number string, times int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.failures[number] = times
+}
+
+func (s *MemoryStore) ClearFailure(number string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.failures, number)
+}
+
+func (s *MemoryStore) ConsumeFailure(number string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	times, has := s.failures[number]
+	if !has {
+		return false
+	}
+
+	if times < 0 {
+		return true
+	}
+
+	times--
+	if times <= 0 {
+		delete(s.failures, number)
+	} else {
+		s.failures[number] = times
+	}
+
+	return true
+}
+
+// RedisStore shares messages between processes, so messages sent by consumers are visible in dev panel
+type RedisStore struct {
+	redis beeorm.RedisCache
+}
+
+func NewRedisStore(redis beeorm.RedisCache) *RedisStore {
+	return &RedisStore{redis: redis}
+}
+
+func (s *RedisStore) Push(message *Message) {
+	value, err := json.Marshal(message)
+	if err != nil {
+		panic(err)
+	}
+
+	s.redis.LPush(redisInboxKey, string(value))
+	s.redis.Ltrim(redisInboxKey, 0, maxStoredMessages-1)
+}
+
+func (s *RedisStore) List(number string) []*Message {
+	messages := make([]*Message, 0)
+
+	for _, value := range s.redis.LRange(redisInboxKey, 0, -1) {
+		message := &Message{}
+		if err := json.Unmarshal([]byte(value), message); err != nil {
+			continue
+		}
+
+		if number == "" || message.Number == number {
+			messages = append(messages, message)
+		}
+	}
+
+	return messages
+}
+
+func (s *RedisStore) Clear() {
+	s.redis.Del(redisInboxKey, redisFailuresKey)
+}
+
+func (s *RedisStore) SetFailure(number string, times int) {
+	s.redis.HSet(redisFailuresKey, number, times)
+}
+
+func (s *RedisStore) ClearFailure(number string) {
+	s.redis.HDel(redisFailuresKey, number)
+}
+
+// consumeFailureScript checks and decrements the counter in one step, so pods sending to the same number
+// never consume more failures than were set
+const consumeFailureScript = `
+local times = tonumber(redis.call('HGET', KEYS[1], ARGV[1]))
+if not times then
+	return 0
+end
+if times < 0 then
+	return 1
+end
+if times <= 1 then
+	redis.call('HDEL', KEYS[1], ARGV[1])
+else
+	redis.call('HINCRBY', KEYS[1], ARGV[1], -1)
+end
+return 1`
+
+func (s *RedisStore) ConsumeFailure(number string) bool {
+	result, _ := s.redis.Eval(consumeFailureScript, []string{redisFailuresKey}, number).(int64)
+
+	return result == 1
+}
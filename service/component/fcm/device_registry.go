@@ -0,0 +1,297 @@
+package fcm
+
+import (
+	"context"
+	"strings"
+
+	"firebase.google.com/go/messaging"
+	"github.com/latolukasz/beeorm/v2"
+
+	"github.com/coretrix/hitrix/datalayer"
+	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/service/component/clock"
+)
+
+const (
+	// multicast and topic management accept max 500 tokens per request
+	maxTokensPerRequest = 500
+	// topic management reports errors only as text reason
+	registrationTokenNotRegistered = "registration-token-not-registered"
+	searchPageSize                 = 1000
+)
+
+type IDeviceRegistry interface {
+	Register(ctx context.Context, ormService *datalayer.ORM, userID uint64, platform, token, appVersion string) (*entity.DeviceTokenEntity, error)
+	Unregister(ctx context.Context, ormService *datalayer.ORM, token string) error
+	GetUserTokens(ormService *datalayer.ORM, userID uint64) []*entity.DeviceTokenEntity
+	SendToUser(ctx context.Context, ormService *datalayer.ORM, userID uint64, message *messaging.MulticastMessage) (*messaging.BatchResponse, error)
+	SubscribeUserToTopic(ctx context.Context, ormService *datalayer.ORM, userID uint64, topic string) error
+	UnsubscribeUserFromTopic(ctx context.Context, ormService *datalayer.ORM, userID uint64, topic string) error
+	ResyncUserTopics(ctx context.Context, ormService *datalayer.ORM, userID uint64) error
+}
+
+// DeviceRegistry keeps device tokens per user and deletes tokens which FCM reports as not registered
+type DeviceRegistry struct {
+	FCMService   FCM
+	ClockService clock.IClock
+}
+
+func NewDeviceRegistry(fcmService FCM, clockService clock.IClock) *DeviceRegistry {
+	return &DeviceRegistry{FCMService: fcmService, ClockService: clockService}
+}
+
+// Register saves the token or moves it to the user if it was registered by another user (logout/login on the same device).
+// Moved token is unsubscribed from topics of the previous user first, it is not moved when FCM request fails
+func (r *DeviceRegistry) Register(
+	ctx context.Context,
+	ormService *datalayer.ORM,
+	userID uint64,
+	platform,
+	token,
+	appVersion string,
+) (*entity.DeviceTokenEntity, error) {
+	now := r.ClockService.Now()
+
+	deviceTokenEntity := &entity.DeviceTokenEntity{}
+	if found := ormService.SearchOne(beeorm.NewWhere("`Token` = ?", token), deviceTokenEntity); !found {
+		deviceTokenEntity.Token = token
+		deviceTokenEntity.CreatedAt = now
+	} else if deviceTokenEntity.UserID != userID {
+		if err := r.unsubscribeFromUserTopics(ctx, ormService, deviceTokenEntity); err != nil {
+			return nil, err
+		}
+	}
+
+	deviceTokenEntity.UserID = userID
+	deviceTokenEntity.Platform = platform
+	deviceTokenEntity.AppVersion = appVersion
+	deviceTokenEntity.LastSeenAt = now
+
+	ormService.Flush(deviceTokenEntity)
+
+	return deviceTokenEntity, nil
+}
+
+// Unregister unsubscribes the token from topics of its user and deletes it. The token is kept when FCM request fails,
+// so the device does not receive topic messages after logout
+func (r *DeviceRegistry) Unregister(ctx context.Context, ormService *datalayer.ORM, token string) error {
+	deviceTokenEntity := &entity.DeviceTokenEntity{}
+	if found := ormService.SearchOne(beeorm.NewWhere("`Token` = ?", token), deviceTokenEntity); !found {
+		return nil
+	}
+
+	if err := r.unsubscribeFromUserTopics(ctx, ormService, deviceTokenEntity); err != nil {
+		return err
+	}
+
+	ormService.Delete(deviceTokenEntity)
+
+	return nil
+}
+
+func (r *DeviceRegistry) GetUserTokens(ormService *datalayer.ORM, userID uint64) []*entity.DeviceTokenEntity {
+	deviceTokenEntities := make([]*entity.DeviceTokenEntity, 0)
+
+	where := beeorm.NewWhere("`UserID` = ? AND `ID` > ? ORDER BY `ID`", userID, 0)
+	pager := beeorm.NewPager(1, searchPageSize)
+
+	for {
+		var page []*entity.DeviceTokenEntity
+		ormService.Search(where, pager, &page)
+
+		deviceTokenEntities = append(deviceTokenEntities, page...)
+
+		if len(page) < searchPageSize {
+			return deviceTokenEntities
+		}
+
+		where.SetParameter(2, page[len(page)-1].ID)
+	}
+}
+
+// SendToUser sends the message to all devices of the user. message.Tokens is ignored
+func (r *DeviceRegistry) SendToUser(
+	ctx context.Context,
+	ormService *datalayer.ORM,
+	userID uint64,
+	message *messaging.MulticastMessage,
+) (*messaging.BatchResponse, error) {
+	deviceTokenEntities := r.GetUserTokens(ormService, userID)
+
+	result := &messaging.BatchResponse{Responses: make([]*messaging.SendResponse, 0, len(deviceTokenEntities))}
+
+	for _, chunk := range chunkDeviceTokens(deviceTokenEntities) {
+		multicastMessage := *message
+		multicastMessage.Tokens = make([]string, len(chunk))
+
+		for i, deviceTokenEntity := range chunk {
+			multicastMessage.Tokens[i] = deviceTokenEntity.Token
+		}
+
+		batchResponse, err := r.FCMService.SendMulticast(ctx, &multicastMessage)
+		if err != nil {
+			return result, err
+		}
+
+		result.SuccessCount += batchResponse.SuccessCount
+		result.FailureCount += batchResponse.FailureCount
+		result.Responses = append(result.Responses, batchResponse.Responses...)
+
+		flusher := ormService.NewFlusher()
+
+		for i, sendResponse := range batchResponse.Responses {
+			if isRegistrationTokenNotRegistered(sendResponse.Error) {
+				flusher.Delete(chunk[i])
+			}
+		}
+
+		flusher.Flush()
+	}
+
+	return result, nil
+}
+
+func (r *DeviceRegistry) SubscribeUserToTopic(ctx context.Context, ormService *datalayer.ORM, userID uint64, topic string) error {
+	subscriptionEntity := &entity.FCMTopicSubscriptionEntity{}
+
+	found := ormService.SearchOne(beeorm.NewWhere("`UserID` = ? AND `Topic` = ?", userID, topic), subscriptionEntity)
+	if !found {
+		subscriptionEntity.UserID = userID
+		subscriptionEntity.Topic = topic
+		subscriptionEntity.CreatedAt = r.ClockService.Now()
+
+		ormService.Flush(subscriptionEntity)
+	}
+
+	return r.subscribe(ctx, ormService, r.GetUserTokens(ormService, userID), topic)
+}
+
+func (r *DeviceRegistry) UnsubscribeUserFromTopic(ctx context.Context, ormService *datalayer.ORM, userID uint64, topic string) error {
+	subscriptionEntity := &entity.FCMTopicSubscriptionEntity{}
+
+	if found := ormService.SearchOne(beeorm.NewWhere("`UserID` = ? AND `Topic` = ?", userID, topic), subscriptionEntity); found {
+		ormService.Delete(subscriptionEntity)
+	}
+
+	for _, chunk := range chunkDeviceTokens(r.GetUserTokens(ormService, userID)) {
+		if _, err := r.FCMService.UnsubscribeFromTopic(ctx, getTokens(chunk), topic); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ResyncUserTopics subscribes all devices of the user to saved topics, call it after Register
+func (r *DeviceRegistry) ResyncUserTopics(ctx context.Context, ormService *datalayer.ORM, userID uint64) error {
+	subscriptionEntities := r.getUserTopics(ormService, userID)
+
+	if len(subscriptionEntities) == 0 {
+		return nil
+	}
+
+	deviceTokenEntities := r.GetUserTokens(ormService, userID)
+
+	for _, subscriptionEntity := range subscriptionEntities {
+		if err := r.subscribe(ctx, ormService, deviceTokenEntities, subscriptionEntity.Topic); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *DeviceRegistry) getUserTopics(ormService *datalayer.ORM, userID uint64) []*entity.FCMTopicSubscriptionEntity {
+	subscriptionEntities := make([]*entity.FCMTopicSubscriptionEntity, 0)
+
+	where := beeorm.NewWhere("`UserID` = ? AND `ID` > ? ORDER BY `ID`", userID, 0)
+	pager := beeorm.NewPager(1, searchPageSize)
+
+	for {
+		var page []*entity.FCMTopicSubscriptionEntity
+		ormService.Search(where, pager, &page)
+
+		subscriptionEntities = append(subscriptionEntities, page...)
+
+		if len(page) < searchPageSize {
+			return subscriptionEntities
+		}
+
+		where.SetParameter(2, page[len(page)-1].ID)
+	}
+}
+
+func (r *DeviceRegistry) unsubscribeFromUserTopics(
+	ctx context.Context,
+	ormService *datalayer.ORM,
+	deviceTokenEntity *entity.DeviceTokenEntity,
+) error {
+	for _, subscriptionEntity := range r.getUserTopics(ormService, deviceTokenEntity.UserID) {
+		if _, err := r.FCMService.UnsubscribeFromTopic(ctx, []string{deviceTokenEntity.Token}, subscriptionEntity.Topic); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *DeviceRegistry) subscribe(
+	ctx context.Context,
+	ormService *datalayer.ORM,
+	deviceTokenEntities []*entity.DeviceTokenEntity,
+	topic string,
+) error {
+	for _, chunk := range chunkDeviceTokens(deviceTokenEntities) {
+		topicManagementResponse, err := r.FCMService.SubscribeToTopic(ctx, getTokens(chunk), topic)
+		if err != nil {
+			return err
+		}
+
+		flusher := ormService.NewFlusher()
+
+		for _, topicError := range topicManagementResponse.Errors {
+			if strings.HasSuffix(topicError.Reason, registrationTokenNotRegistered) {
+				flusher.Delete(chunk[topicError.Index])
+			}
+		}
+
+		flusher.Flush()
+	}
+
+	return nil
+}
+
+// isRegistrationTokenNotRegistered accepts also errors which carry the code only in the text, the same way
+// as topic management reports it
+func isRegistrationTokenNotRegistered(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	return messaging.IsRegistrationTokenNotRegistered(err) || strings.HasSuffix(err.Error(), registrationTokenNotRegistered)
+}
+
+func chunkDeviceTokens(deviceTokenEntities []*entity.DeviceTokenEntity) [][]*entity.DeviceTokenEntity {
+	chunks := make([][]*entity.DeviceTokenEntity, 0)
+
+	for start := 0; start < len(deviceTokenEntities); start += maxTokensPerRequest {
+		end := start + maxTokensPerRequest
+		if end > len(deviceTokenEntities) {
+			end = len(deviceTokenEntities)
+		}
+
+		chunks = append(chunks, deviceTokenEntities[start:end])
+	}
+
+	return chunks
+}
+
+func getTokens(deviceTokenEntities []*entity.DeviceTokenEntity) []string {
+	tokens := make([]string, len(deviceTokenEntities))
+
+	for i, deviceTokenEntity := range deviceTokenEntities {
+		tokens[i] = deviceTokenEntity.Token
+	}
+
+	return tokens
+}
@@ -0,0 +1,111 @@
+package filevalidator
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/coretrix/hitrix/service/component/config"
+)
+
+const clamAVChunkSize = 64 * 1024
+
+// ClamAVScanner sends the content to clamd with INSTREAM command
+type ClamAVScanner struct {
+	Network string
+	Address string
+	Timeout time.Duration
+}
+
+// NewClamAVScanner reads file_validator.clamav.address, for example unix:/var/run/clamav/clamd.ctl or tcp:127.0.0.1:3310
+func NewClamAVScanner(configService config.IConfig) (IScanner, error) {
+	address, ok := configService.String("file_validator.clamav.address")
+	if !ok {
+		return nil, errors.New("missing file_validator.clamav.address")
+	}
+
+	network, address, found := strings.Cut(address, ":")
+	if !found || (network != "unix" && network != "tcp") {
+		return nil, errors.New("file_validator.clamav.address should start with unix: or tcp:")
+	}
+
+	return &ClamAVScanner{
+		Network: network,
+		Address: address,
+		Timeout: time.Duration(configService.DefInt("file_validator.clamav.timeout", 60)) * time.Second,
+	}, nil
+}
+
+func (s *ClamAVScanner) Scan(ctx context.Context, reader io.Reader) (*ScanResult, error) {
+	dialer := &net.Dialer{Timeout: s.Timeout}
+
+	connection, err := dialer.DialContext(ctx, s.Network, s.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	defer connection.Close()
+
+	if err := connection.SetDeadline(time.Now().Add(s.Timeout)); err != nil {
+		return nil, err
+	}
+
+	if _, err := connection.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, err
+	}
+
+	chunk := make([]byte, clamAVChunkSize)
+	size := make([]byte, 4)
+
+	for {
+		n, err := reader.Read(chunk)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+
+			if _, err := connection.Write(append(size, chunk[:n]...)); err != nil {
+				return nil, err
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	binary.BigEndian.PutUint32(size, 0)
+
+	if _, err := connection.Write(size); err != nil {
+		return nil, err
+	}
+
+	response, err := bufio.NewReader(connection).ReadString(0)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return parseClamAVResponse(strings.TrimRight(response, "\x00\n"))
+}
+
+// response is "stream: OK", "stream: Eicar-Signature FOUND" or "... ERROR"
+func parseClamAVResponse(response string) (*ScanResult, error) {
+	result := strings.TrimSpace(strings.TrimPrefix(response, "stream:"))
+
+	switch {
+	case result == "OK":
+		return &ScanResult{}, nil
+	case strings.HasSuffix(result, " FOUND"):
+		return &ScanResult{Infected: true, Signature: strings.TrimSuffix(result, " FOUND")}, nil
+	default:
+		return nil, fmt.Errorf("clamav: %s", result)
+	}
+}
@@ -0,0 +1,24 @@
+package filevalidator
+
+import (
+	"context"
+	"io"
+
+	"github.com/coretrix/hitrix/service/component/config"
+)
+
+type ScanResult struct {
+	Infected bool
+	// Signature is the name of detected malware
+	Signature string
+}
+
+type FileScanDTO struct {
+	FileEntityID uint64
+}
+
+type IScanner interface {
+	Scan(ctx context.Context, reader io.Reader) (*ScanResult, error)
+}
+
+type NewScannerFunc func(configService config.IConfig) (IScanner, error)
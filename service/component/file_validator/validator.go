@@ -0,0 +1,206 @@
+package filevalidator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	// register image decoders used to read image dimensions
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"strings"
+
+	"github.com/h2non/filetype"
+)
+
+// headerSize is enough for filetype to detect all supported types by magic bytes
+const headerSize = 8192
+
+var (
+	ErrFileTooLarge        = errors.New("file is too large")
+	ErrMimeTypeNotAllowed  = errors.New("file type is not allowed")
+	ErrImageTooLarge       = errors.New("image dimensions are too large")
+	ErrImageTooSmall       = errors.New("image dimensions are too small")
+	ErrScannerNotAvailable = errors.New("file scanner is not registered")
+)
+
+// Policy is applied to files uploaded into namespace. Zero value disables the limit
+type Policy struct {
+	AllowedMimeTypes []string `json:"allowed_mime_types"`
+	MaxSize          int64    `json:"max_size"`
+	MinWidth         int      `json:"min_width"`
+	MinHeight        int      `json:"min_height"`
+	MaxWidth         int      `json:"max_width"`
+	MaxHeight        int      `json:"max_height"`
+}
+
+type Policies map[string]*Policy
+
+// FileInfo is detected from file content, the extension from file name is never trusted
+type FileInfo struct {
+	MimeType  string
+	Extension string
+	Size      int64
+	Width     int
+	Height    int
+}
+
+type IValidator interface {
+	Validate(namespace string, content []byte) (*FileInfo, error)
+	ValidateFile(namespace, path string) (*FileInfo, error)
+	ValidateReader(namespace string, reader io.Reader) (*FileInfo, error)
+	GetPolicy(namespace string) *Policy
+	HasScanner() bool
+	Scan(ctx context.Context, reader io.Reader) (*ScanResult, error)
+}
+
+type Validator struct {
+	Policies Policies
+	Scanner  IScanner
+}
+
+func NewValidator(policies Policies, scanner IScanner) *Validator {
+	if policies == nil {
+		policies = Policies{}
+	}
+
+	return &Validator{Policies: policies, Scanner: scanner}
+}
+
+func (v *Validator) GetPolicy(namespace string) *Policy {
+	return v.Policies[namespace]
+}
+
+func (v *Validator) ValidateFile(namespace, path string) (*FileInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	return v.ValidateReader(namespace, file)
+}
+
+// Validate detects the mime type by magic bytes and checks it against the namespace policy
+func (v *Validator) Validate(namespace string, content []byte) (*FileInfo, error) {
+	return v.ValidateReader(namespace, bytes.NewReader(content))
+}
+
+// ValidateReader works like Validate but streams the content, only the header is kept in memory.
+// Reading stops once the content exceeds max size of the namespace policy
+func (v *Validator) ValidateReader(namespace string, reader io.Reader) (*FileInfo, error) {
+	policy, hasPolicy := v.Policies[namespace]
+
+	if hasPolicy && policy.MaxSize > 0 {
+		reader = io.LimitReader(reader, policy.MaxSize+1)
+	}
+
+	counter := &countingReader{reader: reader}
+	bufferedReader := bufio.NewReaderSize(counter, headerSize)
+
+	header, err := bufferedReader.Peek(headerSize)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	fileType, err := filetype.Match(header)
+	if err != nil {
+		return nil, err
+	}
+
+	fileInfo := &FileInfo{
+		MimeType:  fileType.MIME.Value,
+		Extension: fileType.Extension,
+	}
+
+	if fileType == filetype.Unknown {
+		fileInfo.MimeType = "application/octet-stream"
+		fileInfo.Extension = ""
+	}
+
+	if strings.HasPrefix(fileInfo.MimeType, "image/") {
+		if imageConfig, _, err := image.DecodeConfig(bufferedReader); err == nil {
+			fileInfo.Width = imageConfig.Width
+			fileInfo.Height = imageConfig.Height
+		}
+	}
+
+	if _, err := io.Copy(io.Discard, bufferedReader); err != nil {
+		return nil, err
+	}
+
+	fileInfo.Size = counter.size
+
+	if !hasPolicy {
+		return fileInfo, nil
+	}
+
+	if policy.MaxSize > 0 && fileInfo.Size > policy.MaxSize {
+		return fileInfo, fmt.Errorf("%w: max %d bytes", ErrFileTooLarge, policy.MaxSize)
+	}
+
+	if !policy.IsMimeTypeAllowed(fileInfo.MimeType) {
+		return fileInfo, fmt.Errorf("%w: %s", ErrMimeTypeNotAllowed, fileInfo.MimeType)
+	}
+
+	if fileInfo.Width > 0 {
+		if (policy.MaxWidth > 0 && fileInfo.Width > policy.MaxWidth) || (policy.MaxHeight > 0 && fileInfo.Height > policy.MaxHeight) {
+			return fileInfo, fmt.Errorf("%w: max %dx%d", ErrImageTooLarge, policy.MaxWidth, policy.MaxHeight)
+		}
+
+		if fileInfo.Width < policy.MinWidth || fileInfo.Height < policy.MinHeight {
+			return fileInfo, fmt.Errorf("%w: min %dx%d", ErrImageTooSmall, policy.MinWidth, policy.MinHeight)
+		}
+	}
+
+	return fileInfo, nil
+}
+
+func (v *Validator) HasScanner() bool {
+	return v.Scanner != nil
+}
+
+func (v *Validator) Scan(ctx context.Context, reader io.Reader) (*ScanResult, error) {
+	if v.Scanner == nil {
+		return nil, ErrScannerNotAvailable
+	}
+
+	return v.Scanner.Scan(ctx, reader)
+}
+
+// IsMimeTypeAllowed supports wildcards like image/*
+func (p *Policy) IsMimeTypeAllowed(mimeType string) bool {
+	if len(p.AllowedMimeTypes) == 0 {
+		return true
+	}
+
+	for _, allowedMimeType := range p.AllowedMimeTypes {
+		if allowedMimeType == mimeType {
+			return true
+		}
+
+		if strings.HasSuffix(allowedMimeType, "/*") && strings.HasPrefix(mimeType, strings.TrimSuffix(allowedMimeType, "*")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type countingReader struct {
+	reader io.Reader
+	size   int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.size += int64(n)
+
+	return n, err
+}
@@ -0,0 +1,152 @@
+package filevalidator_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/png"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	filevalidator "github.com/coretrix/hitrix/service/component/file_validator"
+)
+
+func createPNG(t *testing.T, width, height int) []byte {
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, png.Encode(buffer, image.NewNRGBA(image.Rect(0, 0, width, height))))
+
+	return buffer.Bytes()
+}
+
+func TestValidate(t *testing.T) {
+	validator := filevalidator.NewValidator(filevalidator.Policies{
+		"avatar": {AllowedMimeTypes: []string{"image/*"}, MaxSize: 1024 * 1024, MinWidth: 10, MinHeight: 10, MaxWidth: 100, MaxHeight: 100},
+		"pdf":    {AllowedMimeTypes: []string{"application/pdf"}},
+		"small":  {MaxSize: 10},
+	}, nil)
+
+	fileInfo, err := validator.Validate("avatar", createPNG(t, 50, 40))
+	assert.Nil(t, err)
+	assert.Equal(t, "image/png", fileInfo.MimeType)
+	assert.Equal(t, "png", fileInfo.Extension)
+	assert.Equal(t, 50, fileInfo.Width)
+	assert.Equal(t, 40, fileInfo.Height)
+
+	_, err = validator.Validate("avatar", createPNG(t, 101, 40))
+	assert.ErrorIs(t, err, filevalidator.ErrImageTooLarge)
+
+	_, err = validator.Validate("avatar", createPNG(t, 5, 40))
+	assert.ErrorIs(t, err, filevalidator.ErrImageTooSmall)
+
+	_, err = validator.Validate("avatar", []byte("plain text"))
+	assert.ErrorIs(t, err, filevalidator.ErrMimeTypeNotAllowed)
+
+	_, err = validator.Validate("pdf", createPNG(t, 50, 40))
+	assert.ErrorIs(t, err, filevalidator.ErrMimeTypeNotAllowed)
+
+	_, err = validator.Validate("small", createPNG(t, 50, 40))
+	assert.ErrorIs(t, err, filevalidator.ErrFileTooLarge)
+
+	fileInfo, err = validator.Validate("without_policy", []byte("plain text"))
+	assert.Nil(t, err)
+	assert.Equal(t, "application/octet-stream", fileInfo.MimeType)
+	assert.Equal(t, int64(10), fileInfo.Size)
+
+	largeContent := append(createPNG(t, 50, 40), make([]byte, 2*1024*1024)...)
+
+	fileInfo, err = validator.ValidateReader("avatar", bytes.NewReader(largeContent))
+	assert.ErrorIs(t, err, filevalidator.ErrFileTooLarge)
+	assert.Equal(t, int64(1024*1024+1), fileInfo.Size)
+
+	fileInfo, err = validator.ValidateReader("without_policy", bytes.NewReader(largeContent))
+	assert.Nil(t, err)
+	assert.Equal(t, "image/png", fileInfo.MimeType)
+	assert.Equal(t, 50, fileInfo.Width)
+	assert.Equal(t, int64(len(largeContent)), fileInfo.Size)
+
+	assert.False(t, validator.HasScanner())
+
+	_, err = validator.Scan(context.Background(), bytes.NewReader([]byte("plain text")))
+	assert.ErrorIs(t, err, filevalidator.ErrScannerNotAvailable)
+}
+
+func runFakeClamd(t *testing.T, response string) (string, chan []byte) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	received := make(chan []byte, 1)
+
+	go func() {
+		connection, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		defer connection.Close()
+
+		reader := bufio.NewReader(connection)
+
+		if _, err := reader.ReadString(0); err != nil {
+			return
+		}
+
+		content := &bytes.Buffer{}
+		size := make([]byte, 4)
+
+		for {
+			if _, err := io.ReadFull(reader, size); err != nil {
+				return
+			}
+
+			length := binary.BigEndian.Uint32(size)
+			if length == 0 {
+				break
+			}
+
+			if _, err := io.CopyN(content, reader, int64(length)); err != nil {
+				return
+			}
+		}
+
+		received <- content.Bytes()
+
+		_, _ = connection.Write([]byte(response + "\x00"))
+	}()
+
+	return listener.Addr().String(), received
+}
+
+func TestClamAVScanner(t *testing.T) {
+	address, received := runFakeClamd(t, "stream: OK")
+	scanner := &filevalidator.ClamAVScanner{Network: "tcp", Address: address, Timeout: time.Second}
+
+	result, err := scanner.Scan(context.Background(), bytes.NewReader([]byte("clean content")))
+	assert.Nil(t, err)
+	assert.False(t, result.Infected)
+	assert.Equal(t, []byte("clean content"), <-received)
+
+	address, _ = runFakeClamd(t, "stream: Eicar-Signature FOUND")
+	validator := filevalidator.NewValidator(nil, &filevalidator.ClamAVScanner{Network: "tcp", Address: address, Timeout: time.Second})
+	assert.True(t, validator.HasScanner())
+
+	result, err = validator.Scan(context.Background(), bytes.NewReader([]byte("infected content")))
+	assert.Nil(t, err)
+	assert.True(t, result.Infected)
+	assert.Equal(t, "Eicar-Signature", result.Signature)
+
+	address, _ = runFakeClamd(t, "INSTREAM size limit exceeded. ERROR")
+	scanner = &filevalidator.ClamAVScanner{Network: "tcp", Address: address, Timeout: time.Second}
+
+	_, err = scanner.Scan(context.Background(), bytes.NewReader([]byte("large content")))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,78 @@
+package importer
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var timeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+// setField converts the cell value into the field type. Empty value keeps zero value of the field
+func setField(field reflect.Value, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	if field.Kind() == reflect.Pointer {
+		pointer := reflect.New(field.Type().Elem())
+
+		if err := setField(pointer.Elem(), value); err != nil {
+			return err
+		}
+
+		field.Set(pointer)
+
+		return nil
+	}
+
+	if field.Type() == reflect.TypeOf(time.Time{}) {
+		for _, layout := range timeLayouts {
+			if parsed, err := time.Parse(layout, value); err == nil {
+				field.Set(reflect.ValueOf(parsed))
+
+				return nil
+			}
+		}
+
+		return errors.New("invalid date")
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("invalid boolean")
+		}
+
+		field.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return errors.New("invalid number")
+		}
+
+		field.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return errors.New("invalid number")
+		}
+
+		field.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return errors.New("invalid number")
+		}
+
+		field.SetFloat(parsed)
+	default:
+		return errors.New("unsupported field type " + field.Type().String())
+	}
+
+	return nil
+}
@@ -0,0 +1,413 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/latolukasz/beeorm/v2"
+
+	"github.com/coretrix/hitrix/datalayer"
+	hitrixErrors "github.com/coretrix/hitrix/pkg/errors"
+)
+
+type Format string
+
+func (f Format) String() string {
+	return string(f)
+}
+
+const (
+	FormatCSV        Format = "csv"
+	FormatXLSX       Format = "xlsx"
+	DefaultBatchSize        = 100
+	DefaultMaxErrors        = 100
+	rowErrorKey             = "Row"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported import format")
+	ErrNoEntity          = errors.New("row was not converted to entity")
+)
+
+// StructValidator validates the row struct using `binding` tags, registry uses binding.NewValidator()
+type StructValidator interface {
+	ValidateStruct(s interface{}) error
+}
+
+// ToEntityFunc converts valid row into the entity tracked by the flusher. Load existing entity and update it to make upsert.
+// Returned errors.FieldErrors are reported per field, any other error is reported for the whole row.
+// Nil entity is reported for the whole row too, unless the import is dry run
+type ToEntityFunc func(ormService *datalayer.ORM, row interface{}) (beeorm.Entity, error)
+
+type Definition struct {
+	ID string
+	// Mapping maps column header to the field of the row struct
+	Mapping map[string]string
+	// NewRow returns pointer to the row struct. Fields are validated with `binding` tags
+	NewRow    func() interface{}
+	ToEntity  ToEntityFunc
+	BatchSize int
+}
+
+type Options struct {
+	// DryRun validates rows and calls ToEntity without flushing the entities
+	DryRun bool
+	// Comma is CSV separator, comma by default
+	Comma rune
+	// ErrorReport receives failed rows as CSV with additional Errors column
+	ErrorReport io.Writer
+	// MaxErrors is the number of row errors kept in the report
+	MaxErrors int
+}
+
+type ImportJobDTO struct {
+	ImportJobEntityID uint64
+}
+
+type RowError struct {
+	Row    int
+	Errors map[string]string
+}
+
+type Report struct {
+	Rows     int
+	Imported int
+	Failed   int
+	Errors   []*RowError
+}
+
+type IImporter interface {
+	GetDefinition(id string) (*Definition, bool)
+	Import(ormService *datalayer.ORM, id string, format Format, reader io.Reader, options *Options) (*Report, error)
+}
+
+type Importer struct {
+	validator   StructValidator
+	definitions map[string]*Definition
+}
+
+func NewImporter(validator StructValidator, definitions ...*Definition) *Importer {
+	importer := &Importer{validator: validator, definitions: map[string]*Definition{}}
+
+	for _, definition := range definitions {
+		importer.definitions[definition.ID] = definition
+	}
+
+	return importer
+}
+
+func (i *Importer) GetDefinition(id string) (*Definition, bool) {
+	definition, ok := i.definitions[id]
+
+	return definition, ok
+}
+
+type pendingRow struct {
+	line   int
+	record []string
+	entity beeorm.Entity
+}
+
+type importRun struct {
+	definition  *Definition
+	options     *Options
+	ormService  *datalayer.ORM
+	flusher     beeorm.Flusher
+	report      *Report
+	errorReport *csv.Writer
+	columns     map[string]string
+	pending     []*pendingRow
+}
+
+// Import streams rows from CSV or XLSX file, validates them and flushes the entities in batches.
+// XLSX is read from io.ReaderAt when reader is *os.File or *bytes.Reader, otherwise it is loaded in memory.
+// When the batch flush fails rows are flushed one by one to find the failing ones
+func (i *Importer) Import(ormService *datalayer.ORM, id string, format Format, reader io.Reader, options *Options) (*Report, error) {
+	definition, ok := i.GetDefinition(id)
+	if !ok {
+		return nil, fmt.Errorf("import %s not found", id)
+	}
+
+	if options == nil {
+		options = &Options{}
+	}
+
+	rows, err := newRowReader(format, reader, options)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	header, _, err := rows.Read()
+	if err == io.EOF {
+		return nil, errors.New("missing header")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	header = append([]string{}, header...)
+
+	for column := range definition.Mapping {
+		if !containsColumn(header, column) {
+			return nil, fmt.Errorf("missing column %s", column)
+		}
+	}
+
+	run := &importRun{
+		definition: definition,
+		options:    options,
+		ormService: ormService,
+		report:     &Report{Errors: make([]*RowError, 0)},
+		columns:    map[string]string{},
+	}
+
+	for column, field := range definition.Mapping {
+		run.columns[field] = column
+	}
+
+	if !options.DryRun {
+		run.flusher = ormService.NewFlusher()
+	}
+
+	if options.ErrorReport != nil {
+		run.errorReport = csv.NewWriter(options.ErrorReport)
+
+		if err := run.errorReport.Write(append(append([]string{}, header...), "Errors")); err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		record, line, err := rows.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return run.report, err
+		}
+
+		if isEmptyRecord(record) {
+			continue
+		}
+
+		run.report.Rows++
+
+		row := definition.NewRow()
+		fieldErrors := bindRow(row, header, definition.Mapping, record)
+
+		if len(fieldErrors) == 0 {
+			fieldErrors = i.validate(row)
+		}
+
+		var entity beeorm.Entity
+
+		if len(fieldErrors) == 0 {
+			entity, err = definition.ToEntity(ormService, row)
+			fieldErrors = toFieldErrors(err)
+
+			if len(fieldErrors) == 0 && !options.DryRun && isNilEntity(entity) {
+				fieldErrors = map[string]string{rowErrorKey: ErrNoEntity.Error()}
+			}
+		}
+
+		if len(fieldErrors) > 0 {
+			if err := run.fail(line, record, fieldErrors); err != nil {
+				return run.report, err
+			}
+
+			continue
+		}
+
+		if options.DryRun {
+			run.report.Imported++
+
+			continue
+		}
+
+		run.pending = append(run.pending, &pendingRow{line: line, record: record, entity: entity})
+		run.flusher.Track(entity)
+
+		batchSize := definition.BatchSize
+		if batchSize <= 0 {
+			batchSize = DefaultBatchSize
+		}
+
+		if len(run.pending) >= batchSize {
+			if err := run.flush(); err != nil {
+				return run.report, err
+			}
+		}
+	}
+
+	if err := run.flush(); err != nil {
+		return run.report, err
+	}
+
+	if run.errorReport != nil {
+		run.errorReport.Flush()
+
+		return run.report, run.errorReport.Error()
+	}
+
+	return run.report, nil
+}
+
+func (i *Importer) validate(row interface{}) map[string]string {
+	if i.validator == nil {
+		return nil
+	}
+
+	return toFieldErrors(i.validator.ValidateStruct(row))
+}
+
+func (r *importRun) flush() error {
+	if len(r.pending) == 0 {
+		return nil
+	}
+
+	pending := r.pending
+	r.pending = nil
+
+	if err := r.flusher.FlushWithFullCheck(); err == nil {
+		r.report.Imported += len(pending)
+
+		return nil
+	}
+
+	r.flusher.Clear()
+
+	for _, row := range pending {
+		if err := r.flusher.Track(row.entity).FlushWithFullCheck(); err != nil {
+			r.flusher.Clear()
+
+			if err := r.fail(row.line, row.record, map[string]string{rowErrorKey: err.Error()}); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		r.report.Imported++
+	}
+
+	return nil
+}
+
+func (r *importRun) fail(line int, record []string, fieldErrors map[string]string) error {
+	r.report.Failed++
+
+	columnErrors := make(map[string]string, len(fieldErrors))
+
+	for field, message := range fieldErrors {
+		if column, ok := r.columns[field]; ok {
+			field = column
+		}
+
+		columnErrors[field] = message
+	}
+
+	maxErrors := r.options.MaxErrors
+	if maxErrors == 0 {
+		maxErrors = DefaultMaxErrors
+	}
+
+	if len(r.report.Errors) < maxErrors {
+		r.report.Errors = append(r.report.Errors, &RowError{Row: line, Errors: columnErrors})
+	}
+
+	if r.errorReport == nil {
+		return nil
+	}
+
+	columns := make([]string, 0, len(columnErrors))
+	for column := range columnErrors {
+		columns = append(columns, column)
+	}
+
+	sort.Strings(columns)
+
+	messages := make([]string, len(columns))
+	for i, column := range columns {
+		messages[i] = column + ": " + strings.TrimSpace(columnErrors[column])
+	}
+
+	return r.errorReport.Write(append(append([]string{}, record...), strings.Join(messages, "; ")))
+}
+
+func toFieldErrors(err error) map[string]string {
+	if err == nil {
+		return nil
+	}
+
+	var fieldErrors hitrixErrors.FieldErrors
+	if errors.As(err, &fieldErrors) {
+		return fieldErrors
+	}
+
+	return map[string]string{rowErrorKey: err.Error()}
+}
+
+// bindRow sets the fields of the row struct from the mapped columns
+func bindRow(row interface{}, header []string, mapping map[string]string, record []string) map[string]string {
+	fieldErrors := map[string]string{}
+	rowValue := reflect.ValueOf(row).Elem()
+
+	for index, column := range header {
+		field, ok := mapping[column]
+		if !ok || index >= len(record) {
+			continue
+		}
+
+		fieldValue := rowValue.FieldByName(field)
+		if !fieldValue.IsValid() {
+			fieldErrors[field] = "unknown field " + field
+
+			continue
+		}
+
+		if err := setField(fieldValue, strings.TrimSpace(record[index])); err != nil {
+			fieldErrors[field] = err.Error()
+		}
+	}
+
+	return fieldErrors
+}
+
+func isNilEntity(entity beeorm.Entity) bool {
+	if entity == nil {
+		return true
+	}
+
+	value := reflect.ValueOf(entity)
+
+	return value.Kind() == reflect.Pointer && value.IsNil()
+}
+
+func containsColumn(header []string, column string) bool {
+	for _, value := range header {
+		if value == column {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isEmptyRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,135 @@
+package importer_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/latolukasz/beeorm/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/tealeg/xlsx"
+
+	"github.com/coretrix/hitrix/datalayer"
+	"github.com/coretrix/hitrix/pkg/binding"
+	"github.com/coretrix/hitrix/service/component/exporter"
+	"github.com/coretrix/hitrix/service/component/importer"
+)
+
+type userRow struct {
+	Email  string `binding:"required,email"`
+	Age    *int   `binding:"omitempty,gte=18"`
+	Active bool
+}
+
+func newImporter(toEntity importer.ToEntityFunc) *importer.Importer {
+	return importer.NewImporter(binding.NewValidator(), &importer.Definition{
+		ID:       "users",
+		Mapping:  map[string]string{"E-mail": "Email", "Age": "Age", "Active": "Active"},
+		NewRow:   func() interface{} { return &userRow{} },
+		ToEntity: toEntity,
+	})
+}
+
+func TestImportCSV(t *testing.T) {
+	imported := make([]*userRow, 0)
+
+	importerService := newImporter(func(_ *datalayer.ORM, row interface{}) (beeorm.Entity, error) {
+		if row.(*userRow).Email == "blocked@example.com" {
+			return nil, errors.New("user is blocked")
+		}
+
+		imported = append(imported, row.(*userRow))
+
+		return nil, nil
+	})
+
+	content := "E-mail,Age,Active,Ignored\n" +
+		"john@example.com,20,true,x\n" +
+		"invalid,20,true,x\n" +
+		"\n" +
+		"jane@example.com,,false,x\n" +
+		"young@example.com,10,true,x\n" +
+		"old@example.com,abc,true,x\n" +
+		"blocked@example.com,30,true,x\n"
+
+	errorReport := &bytes.Buffer{}
+
+	report, err := importerService.Import(nil, "users", importer.FormatCSV, strings.NewReader(content), &importer.Options{
+		DryRun:      true,
+		ErrorReport: errorReport,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 6, report.Rows)
+	assert.Equal(t, 2, report.Imported)
+	assert.Equal(t, 4, report.Failed)
+
+	assert.Len(t, imported, 2)
+	assert.Equal(t, 20, *imported[0].Age)
+	assert.True(t, imported[0].Active)
+	assert.Nil(t, imported[1].Age)
+
+	assert.Equal(t, 3, report.Errors[0].Row)
+	assert.Contains(t, report.Errors[0].Errors, "E-mail")
+	assert.Contains(t, report.Errors[1].Errors, "Age")
+	assert.Equal(t, "invalid number", report.Errors[2].Errors["Age"])
+	assert.Equal(t, "user is blocked", report.Errors[3].Errors["Row"])
+
+	lines := strings.Split(strings.TrimSpace(errorReport.String()), "\n")
+	assert.Len(t, lines, 5)
+	assert.Equal(t, "E-mail,Age,Active,Ignored,Errors", lines[0])
+	assert.Equal(t, "old@example.com,abc,true,x,Age: invalid number", lines[3])
+
+	_, err = importerService.Import(nil, "users", importer.FormatCSV, strings.NewReader("E-mail,Active\n"), nil)
+	assert.EqualError(t, err, "missing column Age")
+
+	_, err = importerService.Import(nil, "missing", importer.FormatCSV, strings.NewReader(content), nil)
+	assert.NotNil(t, err)
+
+	_, err = importerService.Import(nil, "users", "pdf", strings.NewReader(content), nil)
+	assert.ErrorIs(t, err, importer.ErrUnsupportedFormat)
+}
+
+func TestImportXLSX(t *testing.T) {
+	imported := make([]string, 0)
+
+	importerService := newImporter(func(_ *datalayer.ORM, row interface{}) (beeorm.Entity, error) {
+		imported = append(imported, row.(*userRow).Email)
+
+		return nil, nil
+	})
+
+	streamed := &bytes.Buffer{}
+
+	writer, err := exporter.NewExportWriter(exporter.FormatXLSX, streamed, "Users", []string{"E-mail", "Age", "Active"})
+	assert.Nil(t, err)
+	assert.Nil(t, writer.WriteRow([]interface{}{"john@example.com", 20, true}))
+	assert.Nil(t, writer.WriteRow([]interface{}{"invalid", 20, true}))
+	assert.Nil(t, writer.Close())
+
+	report, err := importerService.Import(nil, "users", importer.FormatXLSX, bytes.NewReader(streamed.Bytes()), &importer.Options{DryRun: true})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, report.Rows)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 3, report.Errors[0].Row)
+
+	xlsxFile := xlsx.NewFile()
+	sheet, err := xlsxFile.AddSheet("Users")
+	assert.Nil(t, err)
+
+	for _, values := range [][]interface{}{{"E-mail", "Age", "Active"}, {"jane@example.com", 30, false}} {
+		row := sheet.AddRow()
+
+		for _, value := range values {
+			row.AddCell().SetValue(value)
+		}
+	}
+
+	sharedStrings := &bytes.Buffer{}
+	assert.Nil(t, xlsxFile.Write(sharedStrings))
+
+	report, err = importerService.Import(nil, "users", importer.FormatXLSX, sharedStrings, &importer.Options{DryRun: true})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, report.Imported)
+	assert.Equal(t, []string{"john@example.com", "jane@example.com"}, imported)
+}
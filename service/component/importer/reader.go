@@ -0,0 +1,295 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// rowReader returns records one by one together with the line number in the file. io.EOF is returned after the last record
+type rowReader interface {
+	Read() (record []string, line int, err error)
+	Close() error
+}
+
+func newRowReader(format Format, reader io.Reader, options *Options) (rowReader, error) {
+	switch format {
+	case FormatCSV:
+		csvReader := csv.NewReader(reader)
+		csvReader.FieldsPerRecord = -1
+
+		if options.Comma != 0 {
+			csvReader.Comma = options.Comma
+		}
+
+		return &csvRowReader{reader: csvReader}, nil
+	case FormatXLSX:
+		return newXLSXRowReader(reader)
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+}
+
+type csvRowReader struct {
+	reader *csv.Reader
+}
+
+func (r *csvRowReader) Read() ([]string, int, error) {
+	record, err := r.reader.Read()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	line, _ := r.reader.FieldPos(0)
+
+	return record, line, nil
+}
+
+func (r *csvRowReader) Close() error {
+	return nil
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxRichText struct {
+	T string `xml:"t"`
+	R []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t *xlsxRichText) String() string {
+	text := t.T
+
+	for _, run := range t.R {
+		text += run.T
+	}
+
+	return text
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+type xlsxRow struct {
+	R     int `xml:"r,attr"`
+	Cells []struct {
+		R  string        `xml:"r,attr"`
+		T  string        `xml:"t,attr"`
+		V  string        `xml:"v"`
+		Is *xlsxRichText `xml:"is"`
+	} `xml:"c"`
+}
+
+// xlsxRowReader reads the first sheet row by row, so only shared strings are kept in memory
+type xlsxRowReader struct {
+	sheet         io.ReadCloser
+	decoder       *xml.Decoder
+	sharedStrings []string
+	line          int
+}
+
+func newXLSXRowReader(reader io.Reader) (*xlsxRowReader, error) {
+	readerAt, size, err := toReaderAt(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	zipReader, err := zip.NewReader(readerAt, size)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]*zip.File{}
+	for _, file := range zipReader.File {
+		files[file.Name] = file
+	}
+
+	sharedStrings := &xlsxSharedStrings{}
+	if err := decodeZipFile(files["xl/sharedStrings.xml"], sharedStrings); err != nil {
+		return nil, err
+	}
+
+	sheetPath, err := getFirstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	sheetFile, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("xlsx sheet %s not found", sheetPath)
+	}
+
+	sheet, err := sheetFile.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	xlsxReader := &xlsxRowReader{sheet: sheet, decoder: xml.NewDecoder(sheet)}
+
+	for i := range sharedStrings.Items {
+		xlsxReader.sharedStrings = append(xlsxReader.sharedStrings, sharedStrings.Items[i].String())
+	}
+
+	return xlsxReader, nil
+}
+
+func (r *xlsxRowReader) Read() ([]string, int, error) {
+	for {
+		token, err := r.decoder.Token()
+		if err != nil {
+			return nil, 0, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+
+		row := &xlsxRow{}
+		if err := r.decoder.DecodeElement(row, &start); err != nil {
+			return nil, 0, err
+		}
+
+		r.line++
+		if row.R > 0 {
+			r.line = row.R
+		}
+
+		record := make([]string, 0, len(row.Cells))
+
+		for _, cell := range row.Cells {
+			if cell.R != "" {
+				for column := getColumnIndex(cell.R); len(record) < column; {
+					record = append(record, "")
+				}
+			}
+
+			value := cell.V
+
+			switch cell.T {
+			case "s":
+				index, err := strconv.Atoi(cell.V)
+				if err != nil || index >= len(r.sharedStrings) {
+					return nil, 0, fmt.Errorf("invalid shared string %s in cell %s", cell.V, cell.R)
+				}
+
+				value = r.sharedStrings[index]
+			case "inlineStr":
+				if cell.Is != nil {
+					value = cell.Is.String()
+				}
+			case "b":
+				value = strconv.FormatBool(cell.V == "1")
+			}
+
+			record = append(record, value)
+		}
+
+		return record, r.line, nil
+	}
+}
+
+func (r *xlsxRowReader) Close() error {
+	return r.sheet.Close()
+}
+
+func getFirstSheetPath(files map[string]*zip.File) (string, error) {
+	workbook := &xlsxWorkbook{}
+	if err := decodeZipFile(files["xl/workbook.xml"], workbook); err != nil {
+		return "", err
+	}
+
+	relationships := &xlsxRelationships{}
+	if err := decodeZipFile(files["xl/_rels/workbook.xml.rels"], relationships); err != nil {
+		return "", err
+	}
+
+	if len(workbook.Sheets) > 0 {
+		for _, relationship := range relationships.Relationships {
+			if relationship.ID != workbook.Sheets[0].ID {
+				continue
+			}
+
+			if strings.HasPrefix(relationship.Target, "/") {
+				return strings.TrimPrefix(relationship.Target, "/"), nil
+			}
+
+			return path.Join("xl", relationship.Target), nil
+		}
+	}
+
+	return "xl/worksheets/sheet1.xml", nil
+}
+
+func decodeZipFile(file *zip.File, value interface{}) error {
+	if file == nil {
+		return nil
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+
+	defer reader.Close()
+
+	return xml.NewDecoder(reader).Decode(value)
+}
+
+// getColumnIndex converts cell reference like AB12 into zero based column index
+func getColumnIndex(reference string) int {
+	index := 0
+
+	for _, char := range reference {
+		if char < 'A' || char > 'Z' {
+			break
+		}
+
+		index = index*26 + int(char-'A'+1)
+	}
+
+	return index - 1
+}
+
+func toReaderAt(reader io.Reader) (io.ReaderAt, int64, error) {
+	switch value := reader.(type) {
+	case *os.File:
+		info, err := value.Stat()
+		if err != nil {
+			return nil, 0, err
+		}
+
+		return value, info.Size(), nil
+	case *bytes.Reader:
+		return value, value.Size(), nil
+	case *strings.Reader:
+		return value, value.Size(), nil
+	}
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return bytes.NewReader(content), int64(len(content)), nil
+}
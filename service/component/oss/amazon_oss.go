@@ -3,12 +3,21 @@ package oss
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"io"
+	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
@@ -25,6 +34,7 @@ type AmazonOSS struct {
 	ctx          context.Context
 	buckets      bucketsConfig
 	namespaces   namespacesConfig
+	images       ImagePipelines
 }
 
 func NewAmazonOSS(configService config.IConfig, clockService clock.IClock, namespaces Namespaces) (IProvider, error) {
@@ -68,12 +78,18 @@ func NewAmazonOSS(configService config.IConfig, clockService clock.IClock, names
 
 	bucketsConfiguration, namespacesConfiguration := loadConfig(configService, namespaces)
 
+	imagePipelines, err := loadImagePipelines(configService)
+	if err != nil {
+		return nil, err
+	}
+
 	return &AmazonOSS{
 		client:       s3.New(newSession),
 		clockService: clockService,
 		ctx:          context.Background(),
 		buckets:      bucketsConfiguration,
 		namespaces:   namespacesConfiguration,
+		images:       imagePipelines,
 	}, nil
 }
 
@@ -146,8 +162,41 @@ func (ossStorage *AmazonOSS) GetObjectSignedURL(namespace Namespace, object *ent
 	return req.Presign(expires.Sub(now))
 }
 
-func (ossStorage *AmazonOSS) GetObjectBase64Content(_ Namespace, _ *entity.FileObject) (string, error) {
-	panic("not implemented")
+func (ossStorage *AmazonOSS) GetObjectBase64Content(namespace Namespace, object *entity.FileObject) (string, error) {
+	reader, err := ossStorage.GetObjectReader(namespace, object)
+	if err != nil {
+		return "", err
+	}
+
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(content), nil
+}
+
+func (ossStorage *AmazonOSS) GetObjectReader(namespace Namespace, object *entity.FileObject) (io.ReadCloser, error) {
+	if object == nil {
+		return nil, errors.New("nil file object")
+	}
+
+	bucketConfig, err := ossStorage.namespaces.getBucketConfig(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := ossStorage.client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(bucketConfig.Name),
+		Key:    aws.String(object.StorageKey),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output.Body, nil
 }
 
 func (ossStorage *AmazonOSS) UploadObjectFromByte(
@@ -155,6 +204,15 @@ func (ossStorage *AmazonOSS) UploadObjectFromByte(
 	namespace Namespace,
 	objectContent []byte,
 	extension string,
+) (entity.FileObject, error) {
+	return ossStorage.UploadObjectFromReader(ormService, namespace, bytes.NewReader(objectContent), extension)
+}
+
+func (ossStorage *AmazonOSS) UploadObjectFromReader(
+	ormService *datalayer.ORM,
+	namespace Namespace,
+	reader io.ReadSeeker,
+	extension string,
 ) (entity.FileObject, error) {
 	bucketConfig, err := ossStorage.namespaces.getBucketConfig(namespace)
 	if err != nil {
@@ -166,7 +224,7 @@ func (ossStorage *AmazonOSS) UploadObjectFromByte(
 	objectKey := ossStorage.getObjectKey(namespace, storageCounter, extension)
 
 	putObjectInput := &s3.PutObjectInput{
-		Body:   bytes.NewReader(objectContent),
+		Body:   reader,
 		Bucket: aws.String(bucketConfig.Name),
 		Key:    aws.String(objectKey),
 	}
@@ -220,7 +278,12 @@ func (ossStorage *AmazonOSS) GetNamespaceBucketConfig(namespace Namespace) (*Buc
 }
 
 func (ossStorage *AmazonOSS) UploadImageFromFile(ormService *datalayer.ORM, namespace Namespace, localFile string) (entity.FileObject, error) {
-	return ossStorage.UploadObjectFromFile(ormService, namespace, localFile)
+	fileContent, ext, err := readContentFile(localFile)
+	if err != nil {
+		return entity.FileObject{}, err
+	}
+
+	return uploadImage(ossStorage, ossStorage.images, ormService, namespace, fileContent, ext)
 }
 
 func (ossStorage *AmazonOSS) UploadImageFromBase64(
@@ -234,7 +297,7 @@ func (ossStorage *AmazonOSS) UploadImageFromBase64(
 		return entity.FileObject{}, err
 	}
 
-	return ossStorage.UploadObjectFromByte(ormService, namespace, byteData, extension)
+	return uploadImage(ossStorage, ossStorage.images, ormService, namespace, byteData, extension)
 }
 
 func (ossStorage *AmazonOSS) DeleteObject(namespace Namespace, object *entity.FileObject) error {
@@ -256,3 +319,154 @@ func (ossStorage *AmazonOSS) DeleteObject(namespace Namespace, object *entity.Fi
 func (ossStorage *AmazonOSS) getObjectKey(namespace Namespace, storageCounter uint64, fileExtension string) string {
 	return namespace.String() + "/" + strconv.FormatUint(storageCounter, 10) + "." + fileExtension
 }
+
+// CreatePresignedUpload returns POST policy which limits the size and the content type of the object
+func (ossStorage *AmazonOSS) CreatePresignedUpload(
+	ormService *datalayer.ORM,
+	namespace Namespace,
+	contentType string,
+	maxSize int64,
+) (*PresignedUpload, error) {
+	if err := validatePresignedUpload(contentType, maxSize); err != nil {
+		return nil, err
+	}
+
+	bucketConfig, err := ossStorage.namespaces.getBucketConfig(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	awsCredentials, err := ossStorage.client.Config.Credentials.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	storageCounter := getStorageCounter(ossStorage.ctx, ormService, bucketConfig)
+	objectKey := ossStorage.getObjectKey(namespace, storageCounter, getExtensionForContentType(contentType))
+
+	now := ossStorage.clockService.Now().UTC()
+	expiresAt := now.Add(PresignedUploadExpiration)
+
+	fields, err := createPostPolicy(
+		awsCredentials,
+		aws.StringValue(ossStorage.client.Config.Region),
+		bucketConfig,
+		objectKey,
+		contentType,
+		maxSize,
+		now,
+		expiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PresignedUpload{
+		Object:    entity.FileObject{ID: storageCounter, StorageKey: objectKey},
+		Method:    http.MethodPost,
+		URL:       strings.TrimRight(ossStorage.client.Endpoint, "/") + "/" + bucketConfig.Name,
+		Fields:    fields,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// createPostPolicy returns form fields of POST policy signed with AWS Signature Version 4
+func createPostPolicy(
+	awsCredentials credentials.Value,
+	region string,
+	bucketConfig *BucketConfig,
+	objectKey string,
+	contentType string,
+	maxSize int64,
+	now time.Time,
+	expiresAt time.Time,
+) (map[string]string, error) {
+	date := now.Format("20060102")
+	credential := awsCredentials.AccessKeyID + "/" + date + "/" + region + "/s3/aws4_request"
+
+	fields := map[string]string{
+		"key":              objectKey,
+		"Content-Type":     contentType,
+		"x-amz-algorithm":  "AWS4-HMAC-SHA256",
+		"x-amz-credential": credential,
+		"x-amz-date":       now.Format("20060102T150405Z"),
+	}
+
+	if bucketConfig.ACL != "" {
+		fields["acl"] = bucketConfig.ACL
+	}
+
+	if awsCredentials.SessionToken != "" {
+		fields["x-amz-security-token"] = awsCredentials.SessionToken
+	}
+
+	conditions := []interface{}{
+		map[string]string{"bucket": bucketConfig.Name},
+		[]interface{}{"content-length-range", 0, maxSize},
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		conditions = append(conditions, map[string]string{name: fields[name]})
+	}
+
+	policy, err := json.Marshal(map[string]interface{}{
+		"expiration": expiresAt.UTC().Format("2006-01-02T15:04:05.000Z"),
+		"conditions": conditions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fields["policy"] = base64.StdEncoding.EncodeToString(policy)
+	fields["x-amz-signature"] = hex.EncodeToString(hmacSHA256(getSigningKey(awsCredentials.SecretAccessKey, date, region, "s3"), fields["policy"]))
+
+	return fields, nil
+}
+
+// getSigningKey derives AWS Signature Version 4 signing key
+func getSigningKey(secretAccessKey, date, region, serviceName string) []byte {
+	signingKey := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, serviceName)
+
+	return hmacSHA256(signingKey, "aws4_request")
+}
+
+func (ossStorage *AmazonOSS) GetObjectAttributes(namespace Namespace, object *entity.FileObject) (*ObjectAttributes, error) {
+	if object == nil {
+		return nil, errors.New("nil file object")
+	}
+
+	bucketConfig, err := ossStorage.namespaces.getBucketConfig(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := ossStorage.client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(bucketConfig.Name),
+		Key:    aws.String(object.StorageKey),
+	})
+	if err != nil {
+		if awsError, ok := err.(awserr.RequestFailure); ok && awsError.StatusCode() == http.StatusNotFound {
+			return nil, ErrObjectNotFound
+		}
+
+		return nil, err
+	}
+
+	return &ObjectAttributes{Size: aws.Int64Value(output.ContentLength), ContentType: aws.StringValue(output.ContentType)}, nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
@@ -1,6 +1,7 @@
 package oss
 
 import (
+	"bytes"
 	"encoding/base64"
 	"errors"
 	"io"
@@ -29,6 +30,7 @@ type GoogleOSS struct {
 	namespaces   namespacesConfig
 	accessID     string
 	privateKey   []byte
+	images       ImagePipelines
 }
 
 func NewGoogleOSS(configService config.IConfig, clockService clock.IClock, namespaces Namespaces) (IProvider, error) {
@@ -54,6 +56,11 @@ func NewGoogleOSS(configService config.IConfig, clockService clock.IClock, names
 
 	bucketsConfiguration, namespacesConfiguration := loadConfig(configService, namespaces)
 
+	imagePipelines, err := loadImagePipelines(configService)
+	if err != nil {
+		return nil, err
+	}
+
 	return &GoogleOSS{
 		client:       client,
 		clockService: clockService,
@@ -62,6 +69,7 @@ func NewGoogleOSS(configService config.IConfig, clockService clock.IClock, names
 		namespaces:   namespacesConfiguration,
 		accessID:     jwtConfig.Email,
 		privateKey:   jwtConfig.PrivateKey,
+		images:       imagePipelines,
 	}, nil
 }
 
@@ -149,19 +157,12 @@ func (ossStorage *GoogleOSS) GetObjectSignedURL(namespace Namespace, object *ent
 }
 
 func (ossStorage *GoogleOSS) GetObjectBase64Content(namespace Namespace, object *entity.FileObject) (string, error) {
-	if object == nil {
-		return "", errors.New("nil file object")
-	}
-
-	bucketConfig, err := ossStorage.namespaces.getBucketConfig(namespace)
+	reader, err := ossStorage.GetObjectReader(namespace, object)
 	if err != nil {
 		return "", err
 	}
 
-	reader, err := ossStorage.client.Bucket(bucketConfig.Name).Object(object.StorageKey).NewReader(context.Background())
-	if err != nil {
-		return "", err
-	}
+	defer reader.Close()
 
 	content, err := io.ReadAll(reader)
 	if err != nil {
@@ -171,6 +172,19 @@ func (ossStorage *GoogleOSS) GetObjectBase64Content(namespace Namespace, object
 	return base64.StdEncoding.EncodeToString(content), nil
 }
 
+func (ossStorage *GoogleOSS) GetObjectReader(namespace Namespace, object *entity.FileObject) (io.ReadCloser, error) {
+	if object == nil {
+		return nil, errors.New("nil file object")
+	}
+
+	bucketConfig, err := ossStorage.namespaces.getBucketConfig(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return ossStorage.client.Bucket(bucketConfig.Name).Object(object.StorageKey).NewReader(context.Background())
+}
+
 func (ossStorage *GoogleOSS) UploadObjectFromFile(ormService *datalayer.ORM, namespace Namespace, localFile string) (entity.FileObject, error) {
 	fileContent, ext, err := readContentFile(localFile)
 	if err != nil {
@@ -205,11 +219,16 @@ func (ossStorage *GoogleOSS) UploadImageFromBase64(
 		return entity.FileObject{}, err
 	}
 
-	return ossStorage.UploadObjectFromByte(ormService, namespace, byteData, extension)
+	return uploadImage(ossStorage, ossStorage.images, ormService, namespace, byteData, extension)
 }
 
 func (ossStorage *GoogleOSS) UploadImageFromFile(ormService *datalayer.ORM, namespace Namespace, localFile string) (entity.FileObject, error) {
-	return ossStorage.UploadObjectFromFile(ormService, namespace, localFile)
+	fileContent, ext, err := readContentFile(localFile)
+	if err != nil {
+		return entity.FileObject{}, err
+	}
+
+	return uploadImage(ossStorage, ossStorage.images, ormService, namespace, fileContent, ext)
 }
 
 func (ossStorage *GoogleOSS) UploadObjectFromByte(
@@ -217,6 +236,15 @@ func (ossStorage *GoogleOSS) UploadObjectFromByte(
 	namespace Namespace,
 	objectContent []byte,
 	extension string,
+) (entity.FileObject, error) {
+	return ossStorage.UploadObjectFromReader(ormService, namespace, bytes.NewReader(objectContent), extension)
+}
+
+func (ossStorage *GoogleOSS) UploadObjectFromReader(
+	ormService *datalayer.ORM,
+	namespace Namespace,
+	reader io.ReadSeeker,
+	extension string,
 ) (entity.FileObject, error) {
 	bucketConfig, err := ossStorage.namespaces.getBucketConfig(namespace)
 	if err != nil {
@@ -232,7 +260,7 @@ func (ossStorage *GoogleOSS) UploadObjectFromByte(
 	//TODO Remove
 	ossStorage.setObjectContentType(ossBucketObject, extension)
 
-	_, err = ossBucketObject.Write(objectContent)
+	_, err = io.Copy(ossBucketObject, reader)
 	if err != nil {
 		return entity.FileObject{}, err
 	}
@@ -271,3 +299,72 @@ func (ossStorage *GoogleOSS) setObjectContentType(writer *storage.Writer, extens
 		writer.ObjectAttrs.ContentType = "image/svg+xml"
 	}
 }
+
+// CreatePresignedUpload returns signed PUT url. x-goog-content-length-range header limits the size of the object
+func (ossStorage *GoogleOSS) CreatePresignedUpload(
+	ormService *datalayer.ORM,
+	namespace Namespace,
+	contentType string,
+	maxSize int64,
+) (*PresignedUpload, error) {
+	if err := validatePresignedUpload(contentType, maxSize); err != nil {
+		return nil, err
+	}
+
+	bucketConfig, err := ossStorage.namespaces.getBucketConfig(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	storageCounter := getStorageCounter(ossStorage.ctx, ormService, bucketConfig)
+	objectKey := ossStorage.getObjectKey(namespace, storageCounter, getExtensionForContentType(contentType))
+
+	expiresAt := ossStorage.clockService.Now().Add(PresignedUploadExpiration)
+	contentLengthRange := "0," + strconv.FormatInt(maxSize, 10)
+
+	signedURL, err := storage.SignedURL(
+		bucketConfig.Name,
+		objectKey,
+		&storage.SignedURLOptions{
+			GoogleAccessID: ossStorage.accessID,
+			PrivateKey:     ossStorage.privateKey,
+			Method:         http.MethodPut,
+			ContentType:    contentType,
+			Headers:        []string{"x-goog-content-length-range:" + contentLengthRange},
+			Expires:        expiresAt,
+			Scheme:         storage.SigningSchemeV4,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PresignedUpload{
+		Object:    entity.FileObject{ID: storageCounter, StorageKey: objectKey},
+		Method:    http.MethodPut,
+		URL:       signedURL,
+		Headers:   map[string]string{"Content-Type": contentType, "x-goog-content-length-range": contentLengthRange},
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (ossStorage *GoogleOSS) GetObjectAttributes(namespace Namespace, object *entity.FileObject) (*ObjectAttributes, error) {
+	if object == nil {
+		return nil, errors.New("nil file object")
+	}
+
+	bucketConfig, err := ossStorage.namespaces.getBucketConfig(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs, err := ossStorage.client.Bucket(bucketConfig.Name).Object(object.StorageKey).Attrs(ossStorage.ctx)
+	if err != nil {
+		if err == storage.ErrObjectNotExist {
+			return nil, ErrObjectNotFound
+		}
+
+		return nil, err
+	}
+
+	return &ObjectAttributes{Size: attrs.Size, ContentType: attrs.ContentType}, nil
+}
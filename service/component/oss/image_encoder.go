@@ -0,0 +1,98 @@
+package oss
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type ImageEncoder interface {
+	Encode(writer io.Writer, img image.Image, quality int) error
+}
+
+type jpegEncoder struct{}
+
+func (e *jpegEncoder) Encode(writer io.Writer, img image.Image, quality int) error {
+	return jpeg.Encode(writer, img, &jpeg.Options{Quality: quality})
+}
+
+type pngEncoder struct{}
+
+func (e *pngEncoder) Encode(writer io.Writer, img image.Image, _ int) error {
+	return png.Encode(writer, img)
+}
+
+// CommandEncoder encodes image with external binary. The image is passed as png file.
+// Placeholders {input}, {output} and {quality} in Args are replaced before the command is executed
+type CommandEncoder struct {
+	Command string
+	Args    []string
+}
+
+// NewWebPCommandEncoder uses cwebp from libwebp
+func NewWebPCommandEncoder() *CommandEncoder {
+	return &CommandEncoder{Command: "cwebp", Args: []string{"-quiet", "-q", "{quality}", "{input}", "-o", "{output}"}}
+}
+
+// NewAVIFCommandEncoder uses avifenc from libavif
+func NewAVIFCommandEncoder() *CommandEncoder {
+	return &CommandEncoder{Command: "avifenc", Args: []string{"-q", "{quality}", "{input}", "{output}"}}
+}
+
+func (e *CommandEncoder) Encode(writer io.Writer, img image.Image, quality int) error {
+	folder, err := os.MkdirTemp("", "hitrix_image_")
+	if err != nil {
+		return err
+	}
+
+	defer os.RemoveAll(folder)
+
+	input := filepath.Join(folder, "input.png")
+	output := filepath.Join(folder, "output")
+
+	inputFile, err := os.Create(input)
+	if err != nil {
+		return err
+	}
+
+	err = png.Encode(inputFile, img)
+	_ = inputFile.Close()
+
+	if err != nil {
+		return err
+	}
+
+	replacer := strings.NewReplacer("{input}", input, "{output}", output, "{quality}", strconv.Itoa(quality))
+
+	args := make([]string, len(e.Args))
+	for i, arg := range e.Args {
+		args[i] = replacer.Replace(arg)
+	}
+
+	stderr := &bytes.Buffer{}
+
+	//nolint //G204 command is set by the developer, not by the user
+	command := exec.Command(e.Command, args...)
+	command.Stderr = stderr
+
+	if err := command.Run(); err != nil {
+		return fmt.Errorf("%s: %w: %s", e.Command, err, stderr.String())
+	}
+
+	content, err := os.ReadFile(output)
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(content)
+
+	return err
+}
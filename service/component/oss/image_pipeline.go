@@ -0,0 +1,281 @@
+package oss
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	// register gif decoder, jpeg and png are registered by encoders
+	_ "image/gif"
+
+	"github.com/coretrix/hitrix/datalayer"
+	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/service/component/config"
+)
+
+const (
+	ImageFormatJPEG = "jpeg"
+	ImageFormatPNG  = "png"
+	ImageFormatWebP = "webp"
+	ImageFormatAVIF = "avif"
+
+	imageDefaultQuality = 85
+	// 50 megapixels decoded to RGBA take 200MB of memory
+	imageDefaultMaxPixels = 50_000_000
+)
+
+// ErrImageTooLarge is returned before the image is decoded, so small files with huge dimensions do not exhaust memory
+var ErrImageTooLarge = errors.New("image dimensions are too large")
+
+// ImageVariant is additional image generated from the original, for example thumb or medium
+type ImageVariant struct {
+	MaxWidth  int    `json:"max_width"`
+	MaxHeight int    `json:"max_height"`
+	Format    string `json:"format"`
+	Quality   int    `json:"quality"`
+}
+
+// ImagePipeline is applied to images uploaded with UploadImageFromFile and UploadImageFromBase64.
+// The image is rotated by EXIF orientation, resized to fit max dimensions and re-encoded which strips EXIF and GPS data.
+// Empty format keeps the original format. Images with more than MaxPixels (width x height) of the upload are rejected
+type ImagePipeline struct {
+	MaxWidth  int                      `json:"max_width"`
+	MaxHeight int                      `json:"max_height"`
+	MaxPixels int64                    `json:"max_pixels"`
+	Format    string                   `json:"format"`
+	Quality   int                      `json:"quality"`
+	Variants  map[string]*ImageVariant `json:"variants"`
+}
+
+type ImagePipelines map[Namespace]*ImagePipeline
+
+// ImageVariantObject is stored in FileObject.Data under "variants" key
+type ImageVariantObject struct {
+	ID         uint64
+	StorageKey string
+	Width      int
+	Height     int
+}
+
+type ImageData struct {
+	Width    int
+	Height   int
+	Variants map[string]*ImageVariantObject
+}
+
+// ImageEncoders are used to encode processed images. JPEG and PNG are registered by default.
+// Register WebP and AVIF encoders (for example NewWebPCommandEncoder) before you use those formats
+var ImageEncoders = map[string]ImageEncoder{
+	ImageFormatJPEG: &jpegEncoder{},
+	ImageFormatPNG:  &pngEncoder{},
+}
+
+func loadImagePipelines(configService config.IConfig) (ImagePipelines, error) {
+	imagePipelines := ImagePipelines{}
+
+	if _, ok := configService.Get("oss.images"); !ok {
+		return imagePipelines, nil
+	}
+
+	if err := configService.MapStruct("oss.images", &imagePipelines); err != nil {
+		return nil, err
+	}
+
+	return imagePipelines, nil
+}
+
+type ProcessedImage struct {
+	Content   []byte
+	Extension string
+	Width     int
+	Height    int
+}
+
+// Process returns processed original image and variants
+func (p *ImagePipeline) Process(content []byte) (*ProcessedImage, map[string]*ProcessedImage, error) {
+	imageConfig, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	maxPixels := p.MaxPixels
+	if maxPixels == 0 {
+		maxPixels = imageDefaultMaxPixels
+	}
+
+	if int64(imageConfig.Width)*int64(imageConfig.Height) > maxPixels {
+		return nil, nil, ErrImageTooLarge
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if format == "jpeg" {
+		img = applyOrientation(img, readJPEGOrientation(content))
+	}
+
+	original, err := encodeImage(resizeImage(img, p.MaxWidth, p.MaxHeight), p.getFormat(format), p.Quality)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	variants := make(map[string]*ProcessedImage, len(p.Variants))
+
+	for name, variant := range p.Variants {
+		variantFormat := variant.Format
+		if variantFormat == "" {
+			variantFormat = p.getFormat(format)
+		}
+
+		quality := variant.Quality
+		if quality == 0 {
+			quality = p.Quality
+		}
+
+		variants[name], err = encodeImage(resizeImage(img, variant.MaxWidth, variant.MaxHeight), variantFormat, quality)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return original, variants, nil
+}
+
+func (p *ImagePipeline) getFormat(originalFormat string) string {
+	if p.Format != "" {
+		return p.Format
+	}
+
+	if originalFormat == ImageFormatJPEG || originalFormat == ImageFormatPNG {
+		return originalFormat
+	}
+
+	return ImageFormatPNG
+}
+
+func encodeImage(img image.Image, format string, quality int) (*ProcessedImage, error) {
+	encoder, ok := ImageEncoders[format]
+	if !ok {
+		return nil, fmt.Errorf("image encoder for format %s not registered", format)
+	}
+
+	if quality == 0 {
+		quality = imageDefaultQuality
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := encoder.Encode(buffer, img, quality); err != nil {
+		return nil, err
+	}
+
+	extension := format
+	if format == ImageFormatJPEG {
+		extension = "jpg"
+	}
+
+	return &ProcessedImage{
+		Content:   buffer.Bytes(),
+		Extension: extension,
+		Width:     img.Bounds().Dx(),
+		Height:    img.Bounds().Dy(),
+	}, nil
+}
+
+// uploadImage runs the namespace pipeline and uploads the original and the variants. Without pipeline the content is uploaded as is
+func uploadImage(
+	provider IProvider,
+	imagePipelines ImagePipelines,
+	ormService *datalayer.ORM,
+	namespace Namespace,
+	content []byte,
+	extension string,
+) (entity.FileObject, error) {
+	imagePipeline, ok := imagePipelines[namespace]
+	if !ok {
+		return provider.UploadObjectFromByte(ormService, namespace, content, extension)
+	}
+
+	original, variants, err := imagePipeline.Process(content)
+	if err != nil {
+		return entity.FileObject{}, err
+	}
+
+	fileObject, err := provider.UploadObjectFromByte(ormService, namespace, original.Content, original.Extension)
+	if err != nil {
+		return entity.FileObject{}, err
+	}
+
+	imageData := &ImageData{
+		Width:    original.Width,
+		Height:   original.Height,
+		Variants: make(map[string]*ImageVariantObject, len(variants)),
+	}
+
+	uploadedObjects := []entity.FileObject{fileObject}
+
+	for name, variant := range variants {
+		variantObject, err := provider.UploadObjectFromByte(ormService, namespace, variant.Content, variant.Extension)
+		if err != nil {
+			// the image is not returned to the caller, so nothing would ever reference uploaded objects
+			for i := range uploadedObjects {
+				_ = provider.DeleteObject(namespace, &uploadedObjects[i])
+			}
+
+			return entity.FileObject{}, err
+		}
+
+		uploadedObjects = append(uploadedObjects, variantObject)
+
+		imageData.Variants[name] = &ImageVariantObject{
+			ID:         variantObject.ID,
+			StorageKey: variantObject.StorageKey,
+			Width:      variant.Width,
+			Height:     variant.Height,
+		}
+	}
+
+	fileObject.Data = imageData
+
+	return fileObject, nil
+}
+
+// GetImageData returns image data stored by the image pipeline. It works with FileObject loaded from database too
+func GetImageData(object *entity.FileObject) (*ImageData, bool) {
+	if object == nil || object.Data == nil {
+		return nil, false
+	}
+
+	if imageData, ok := object.Data.(*ImageData); ok {
+		return imageData, true
+	}
+
+	content, err := json.Marshal(object.Data)
+	if err != nil {
+		return nil, false
+	}
+
+	imageData := &ImageData{}
+	if err := json.Unmarshal(content, imageData); err != nil || imageData.Variants == nil {
+		return nil, false
+	}
+
+	return imageData, true
+}
+
+// GetImageVariantURL returns url of the image variant. Original image url is returned if the variant does not exist
+func GetImageVariantURL(provider IProvider, namespace Namespace, object *entity.FileObject, variant string) (string, error) {
+	if object == nil {
+		return "", errors.New("nil file object")
+	}
+
+	if imageData, ok := GetImageData(object); ok {
+		if variantObject, ok := imageData.Variants[variant]; ok {
+			return provider.GetObjectURL(namespace, &entity.FileObject{ID: variantObject.ID, StorageKey: variantObject.StorageKey})
+		}
+	}
+
+	return provider.GetObjectURL(namespace, object)
+}
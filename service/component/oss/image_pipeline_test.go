@@ -0,0 +1,125 @@
+package oss_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/service/component/oss"
+	"github.com/coretrix/hitrix/service/component/oss/mocks"
+)
+
+func TestImagePipelineProcess(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	for x := 0; x < 400; x++ {
+		for y := 0; y < 200; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x % 256), G: uint8(y), B: 100, A: 255})
+		}
+	}
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, jpeg.Encode(buffer, img, nil))
+
+	// orientation 6 means the image has to be rotated 90 degrees clockwise
+	content := withEXIFOrientation(buffer.Bytes(), 6)
+
+	imagePipeline := &oss.ImagePipeline{
+		MaxWidth: 100,
+		Variants: map[string]*oss.ImageVariant{
+			"thumb": {MaxWidth: 50, MaxHeight: 50, Format: oss.ImageFormatPNG},
+		},
+	}
+
+	original, variants, err := imagePipeline.Process(content)
+	assert.Nil(t, err)
+	assert.Equal(t, "jpg", original.Extension)
+	assert.Equal(t, 100, original.Width)
+	assert.Equal(t, 200, original.Height)
+	assert.False(t, bytes.Contains(original.Content, []byte("Exif")))
+
+	assert.Equal(t, "png", variants["thumb"].Extension)
+	assert.Equal(t, 25, variants["thumb"].Width)
+	assert.Equal(t, 50, variants["thumb"].Height)
+
+	thumb, err := png.Decode(bytes.NewReader(variants["thumb"].Content))
+	assert.Nil(t, err)
+	assert.Equal(t, image.Rect(0, 0, 25, 50), thumb.Bounds())
+
+	_, _, err = (&oss.ImagePipeline{Format: oss.ImageFormatAVIF}).Process(content)
+	assert.NotNil(t, err)
+
+	_, _, err = (&oss.ImagePipeline{MaxPixels: 400*200 - 1}).Process(content)
+	assert.ErrorIs(t, err, oss.ErrImageTooLarge)
+}
+
+func TestImagePipelineRejectsHugeDimensions(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, png.Encode(buffer, image.NewGray(image.Rect(0, 0, 1, 1))))
+
+	content := buffer.Bytes()
+	// IHDR width and height follow the 8 bytes signature and 8 bytes chunk header, the checksum covers type and data
+	binary.BigEndian.PutUint32(content[16:], 100000)
+	binary.BigEndian.PutUint32(content[20:], 100000)
+	binary.BigEndian.PutUint32(content[29:], crc32.ChecksumIEEE(content[12:29]))
+
+	_, _, err := (&oss.ImagePipeline{}).Process(content)
+	assert.ErrorIs(t, err, oss.ErrImageTooLarge)
+}
+
+func TestGetImageVariantURL(t *testing.T) {
+	fakeOSS := &mocks.FakeOSSClient{}
+	fakeOSS.On("GetObjectURL", oss.Namespace("products"), &entity.FileObject{ID: 2, StorageKey: "products/2.jpg"}).Return("thumb-url")
+	fakeOSS.On("GetObjectURL", oss.Namespace("products"), mock.Anything).Return("original-url")
+
+	data, _ := json.Marshal(&oss.ImageData{
+		Width:    100,
+		Height:   200,
+		Variants: map[string]*oss.ImageVariantObject{"thumb": {ID: 2, StorageKey: "products/2.jpg", Width: 25, Height: 50}},
+	})
+
+	// FileObject loaded from database holds generic map
+	object := &entity.FileObject{ID: 1, StorageKey: "products/1.jpg"}
+	assert.Nil(t, json.Unmarshal(data, &object.Data))
+
+	imageData, ok := oss.GetImageData(object)
+	assert.True(t, ok)
+	assert.Equal(t, 25, imageData.Variants["thumb"].Width)
+
+	url, err := oss.GetImageVariantURL(fakeOSS, "products", object, "thumb")
+	assert.Nil(t, err)
+	assert.Equal(t, "thumb-url", url)
+
+	url, err = oss.GetImageVariantURL(fakeOSS, "products", object, "medium")
+	assert.Nil(t, err)
+	assert.Equal(t, "original-url", url)
+}
+
+func withEXIFOrientation(content []byte, orientation uint16) []byte {
+	tiff := &bytes.Buffer{}
+	tiff.WriteString("MM")
+	_ = binary.Write(tiff, binary.BigEndian, uint16(42))
+	_ = binary.Write(tiff, binary.BigEndian, uint32(8))
+	_ = binary.Write(tiff, binary.BigEndian, uint16(1))
+	_ = binary.Write(tiff, binary.BigEndian, []uint16{0x0112, 3})
+	_ = binary.Write(tiff, binary.BigEndian, uint32(1))
+	_ = binary.Write(tiff, binary.BigEndian, []uint16{orientation, 0})
+	_ = binary.Write(tiff, binary.BigEndian, uint32(0))
+
+	segment := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+
+	result := []byte{0xFF, 0xD8, 0xFF, 0xE1}
+	result = binary.BigEndian.AppendUint16(result, uint16(len(segment)+2))
+	result = append(result, segment...)
+
+	return append(result, content[2:]...)
+}
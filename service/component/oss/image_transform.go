@@ -0,0 +1,207 @@
+package oss
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+)
+
+// resizeImage scales the image down to fit max dimensions keeping aspect ratio. Zero means no limit, images are never upscaled
+func resizeImage(img image.Image, maxWidth, maxHeight int) image.Image {
+	width := img.Bounds().Dx()
+	height := img.Bounds().Dy()
+
+	scale := 1.0
+
+	if maxWidth > 0 && width > maxWidth {
+		scale = float64(maxWidth) / float64(width)
+	}
+
+	if maxHeight > 0 && height > maxHeight && float64(maxHeight)/float64(height) < scale {
+		scale = float64(maxHeight) / float64(height)
+	}
+
+	if scale == 1.0 {
+		return img
+	}
+
+	newWidth := maxInt(1, int(float64(width)*scale+0.5))
+	newHeight := maxInt(1, int(float64(height)*scale+0.5))
+
+	source := toNRGBA(img)
+	destination := image.NewNRGBA(image.Rect(0, 0, newWidth, newHeight))
+
+	// area averaging gives good quality for downscaling
+	for y := 0; y < newHeight; y++ {
+		sourceY0 := y * height / newHeight
+		sourceY1 := maxInt(sourceY0+1, (y+1)*height/newHeight)
+
+		for x := 0; x < newWidth; x++ {
+			sourceX0 := x * width / newWidth
+			sourceX1 := maxInt(sourceX0+1, (x+1)*width/newWidth)
+
+			var r, g, b, a, count uint64
+
+			for sourceY := sourceY0; sourceY < sourceY1; sourceY++ {
+				offset := source.PixOffset(sourceX0, sourceY)
+
+				for sourceX := sourceX0; sourceX < sourceX1; sourceX++ {
+					pixelA := uint64(source.Pix[offset+3])
+					r += uint64(source.Pix[offset]) * pixelA
+					g += uint64(source.Pix[offset+1]) * pixelA
+					b += uint64(source.Pix[offset+2]) * pixelA
+					a += pixelA
+					count++
+					offset += 4
+				}
+			}
+
+			offset := destination.PixOffset(x, y)
+
+			if a > 0 {
+				destination.Pix[offset] = uint8(r / a)
+				destination.Pix[offset+1] = uint8(g / a)
+				destination.Pix[offset+2] = uint8(b / a)
+			}
+
+			destination.Pix[offset+3] = uint8(a / count)
+		}
+	}
+
+	return destination
+}
+
+// applyOrientation rotates and flips the image by EXIF orientation tag
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	source := toNRGBA(img)
+	width := source.Bounds().Dx()
+	height := source.Bounds().Dy()
+
+	newWidth, newHeight := width, height
+	if orientation >= 5 {
+		newWidth, newHeight = height, width
+	}
+
+	destination := image.NewNRGBA(image.Rect(0, 0, newWidth, newHeight))
+
+	for y := 0; y < newHeight; y++ {
+		for x := 0; x < newWidth; x++ {
+			var sourceX, sourceY int
+
+			switch orientation {
+			case 2:
+				sourceX, sourceY = width-1-x, y
+			case 3:
+				sourceX, sourceY = width-1-x, height-1-y
+			case 4:
+				sourceX, sourceY = x, height-1-y
+			case 5:
+				sourceX, sourceY = y, x
+			case 6:
+				sourceX, sourceY = y, height-1-x
+			case 7:
+				sourceX, sourceY = width-1-y, height-1-x
+			case 8:
+				sourceX, sourceY = width-1-y, x
+			}
+
+			copy(destination.Pix[destination.PixOffset(x, y):destination.PixOffset(x, y)+4], source.Pix[source.PixOffset(sourceX, sourceY):])
+		}
+	}
+
+	return destination
+}
+
+// readJPEGOrientation returns orientation tag from EXIF APP1 segment or 0 if it's missing
+func readJPEGOrientation(content []byte) int {
+	if len(content) < 4 || content[0] != 0xFF || content[1] != 0xD8 {
+		return 0
+	}
+
+	position := 2
+
+	for position+4 <= len(content) {
+		if content[position] != 0xFF {
+			return 0
+		}
+
+		marker := content[position+1]
+		segmentLength := int(binary.BigEndian.Uint16(content[position+2:]))
+
+		// start of scan, metadata is always before the image data
+		if marker == 0xDA || segmentLength < 2 || position+2+segmentLength > len(content) {
+			return 0
+		}
+
+		segment := content[position+4 : position+2+segmentLength]
+
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return readTIFFOrientation(segment[6:])
+		}
+
+		position += 2 + segmentLength
+	}
+
+	return 0
+}
+
+func readTIFFOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var byteOrder binary.ByteOrder
+
+	switch string(tiff[:2]) {
+	case "II":
+		byteOrder = binary.LittleEndian
+	case "MM":
+		byteOrder = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifdOffset := int(byteOrder.Uint32(tiff[4:]))
+	if ifdOffset+2 > len(tiff) {
+		return 0
+	}
+
+	entries := int(byteOrder.Uint16(tiff[ifdOffset:]))
+
+	for i := 0; i < entries; i++ {
+		entryOffset := ifdOffset + 2 + i*12
+		if entryOffset+12 > len(tiff) {
+			return 0
+		}
+
+		if byteOrder.Uint16(tiff[entryOffset:]) == 0x0112 {
+			return int(byteOrder.Uint16(tiff[entryOffset+8:]))
+		}
+	}
+
+	return 0
+}
+
+func toNRGBA(img image.Image) *image.NRGBA {
+	if nrgba, ok := img.(*image.NRGBA); ok && nrgba.Rect.Min == (image.Point{}) {
+		return nrgba
+	}
+
+	nrgba := image.NewNRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(nrgba, nrgba.Bounds(), img, img.Bounds().Min, draw.Src)
+
+	return nrgba
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,533 @@
+package oss
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/h2non/filetype"
+
+	"github.com/coretrix/hitrix/datalayer"
+	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/service/component/clock"
+	"github.com/coretrix/hitrix/service/component/config"
+)
+
+var (
+	ErrInvalidSignature = errors.New("oss: invalid signature")
+	ErrURLExpired       = errors.New("oss: url expired")
+	ErrInvalidObjectKey = errors.New("oss: invalid object key")
+)
+
+// LocalClient is returned by LocalOSS.GetClient. Every bucket is a folder inside Root
+type LocalClient struct {
+	Root string
+}
+
+func (c *LocalClient) GetBucketPath(bucket string) string {
+	return filepath.Join(c.Root, bucket)
+}
+
+// LocalOSS stores objects on the filesystem and serves them with middleware.OSSRouter.
+// It is meant for local development and CI
+type LocalOSS struct {
+	client       *LocalClient
+	clockService clock.IClock
+	url          string
+	secret       []byte
+	buckets      bucketsConfig
+	namespaces   namespacesConfig
+	images       ImagePipelines
+}
+
+func NewLocalOSS(configService config.IConfig, clockService clock.IClock, namespaces Namespaces) (IProvider, error) {
+	root, ok := configService.String("oss.local.root")
+	if !ok {
+		return nil, errors.New("missing oss.local.root")
+	}
+
+	objectURL, ok := configService.String("oss.local.url")
+	if !ok {
+		return nil, errors.New("missing oss.local.url")
+	}
+
+	secret, ok := configService.String("oss.local.secret")
+	if !ok {
+		return nil, errors.New("missing oss.local.secret")
+	}
+
+	bucketsConfiguration, namespacesConfiguration := loadConfig(configService, namespaces)
+
+	imagePipelines, err := loadImagePipelines(configService)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, bucketConfig := range bucketsConfiguration {
+		if err := os.MkdirAll(filepath.Join(root, bucketConfig.Name), 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	return &LocalOSS{
+		client:       &LocalClient{Root: root},
+		clockService: clockService,
+		url:          strings.TrimRight(objectURL, "/"),
+		secret:       []byte(secret),
+		buckets:      bucketsConfiguration,
+		namespaces:   namespacesConfiguration,
+		images:       imagePipelines,
+	}, nil
+}
+
+func (ossStorage *LocalOSS) GetBucketConfig(bucket Bucket) *BucketConfig {
+	return getBucketConfig(ossStorage.buckets[bucket])
+}
+
+func (ossStorage *LocalOSS) GetClient() interface{} {
+	return ossStorage.client
+}
+
+func (ossStorage *LocalOSS) GetObjectURL(namespace Namespace, object *entity.FileObject) (string, error) {
+	cdnURL, err := ossStorage.GetObjectCDNURL(namespace, object)
+	if err != nil {
+		return "", err
+	}
+
+	if cdnURL != "" {
+		return cdnURL, nil
+	}
+
+	return ossStorage.GetObjectOSSURL(namespace, object)
+}
+
+// GetObjectOSSURL returns not signed url, it works only for public bucket
+func (ossStorage *LocalOSS) GetObjectOSSURL(namespace Namespace, object *entity.FileObject) (string, error) {
+	if object == nil {
+		return "", errors.New("nil file object")
+	}
+
+	bucketConfig, err := ossStorage.namespaces.getBucketConfig(namespace)
+	if err != nil {
+		return "", err
+	}
+
+	return ossStorage.url + "/" + bucketConfig.Name + "/" + object.StorageKey, nil
+}
+
+func (ossStorage *LocalOSS) GetObjectCDNURL(namespace Namespace, object *entity.FileObject) (string, error) {
+	if object == nil {
+		return "", errors.New("nil file object")
+	}
+
+	bucketConfig, err := ossStorage.namespaces.getBucketConfig(namespace)
+	if err != nil {
+		return "", err
+	}
+
+	return getObjectCDNURL(bucketConfig, object.StorageKey), nil
+}
+
+func (ossStorage *LocalOSS) GetObjectSignedURL(namespace Namespace, object *entity.FileObject, expires time.Time) (string, error) {
+	if object == nil {
+		return "", errors.New("nil file object")
+	}
+
+	if ossStorage.clockService.Now().After(expires) {
+		return "", errors.New("expire time is before now")
+	}
+
+	objectURL, err := ossStorage.GetObjectOSSURL(namespace, object)
+	if err != nil {
+		return "", err
+	}
+
+	bucketConfig, _ := ossStorage.namespaces.getBucketConfig(namespace)
+	expiresUnix := strconv.FormatInt(expires.Unix(), 10)
+
+	query := url.Values{}
+	query.Set("expires", expiresUnix)
+	query.Set("signature", ossStorage.sign(bucketConfig.Name, object.StorageKey, expiresUnix))
+
+	return objectURL + "?" + query.Encode(), nil
+}
+
+func (ossStorage *LocalOSS) GetObjectBase64Content(namespace Namespace, object *entity.FileObject) (string, error) {
+	if object == nil {
+		return "", errors.New("nil file object")
+	}
+
+	objectPath, err := ossStorage.getNamespaceObjectPath(namespace, object.StorageKey)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := os.ReadFile(objectPath)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(content), nil
+}
+
+func (ossStorage *LocalOSS) GetObjectReader(namespace Namespace, object *entity.FileObject) (io.ReadCloser, error) {
+	if object == nil {
+		return nil, errors.New("nil file object")
+	}
+
+	objectPath, err := ossStorage.getNamespaceObjectPath(namespace, object.StorageKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.Open(objectPath)
+}
+
+func (ossStorage *LocalOSS) GetNamespaceBucketConfig(namespace Namespace) (*BucketConfig, error) {
+	return ossStorage.namespaces.getBucketConfig(namespace)
+}
+
+func (ossStorage *LocalOSS) UploadObjectFromByte(
+	ormService *datalayer.ORM,
+	namespace Namespace,
+	objectContent []byte,
+	extension string,
+) (entity.FileObject, error) {
+	return ossStorage.UploadObjectFromReader(ormService, namespace, bytes.NewReader(objectContent), extension)
+}
+
+func (ossStorage *LocalOSS) UploadObjectFromReader(
+	ormService *datalayer.ORM,
+	namespace Namespace,
+	reader io.ReadSeeker,
+	extension string,
+) (entity.FileObject, error) {
+	bucketConfig, err := ossStorage.namespaces.getBucketConfig(namespace)
+	if err != nil {
+		return entity.FileObject{}, err
+	}
+
+	storageCounter := getStorageCounter(context.Background(), ormService, bucketConfig)
+
+	objectKey := namespace.String() + "/" + strconv.FormatUint(storageCounter, 10) + "." + extension
+
+	objectPath, err := ossStorage.getObjectPath(bucketConfig.Name, objectKey)
+	if err != nil {
+		return entity.FileObject{}, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(objectPath), 0755); err != nil {
+		return entity.FileObject{}, err
+	}
+
+	objectFile, err := os.OpenFile(objectPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return entity.FileObject{}, err
+	}
+
+	_, err = io.Copy(objectFile, reader)
+	if closeErr := objectFile.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return entity.FileObject{}, err
+	}
+
+	return entity.FileObject{
+		ID:         storageCounter,
+		StorageKey: objectKey,
+	}, nil
+}
+
+func (ossStorage *LocalOSS) UploadObjectFromFile(ormService *datalayer.ORM, namespace Namespace, localFile string) (entity.FileObject, error) {
+	fileContent, ext, err := readContentFile(localFile)
+	if err != nil {
+		return entity.FileObject{}, err
+	}
+
+	return ossStorage.UploadObjectFromByte(ormService, namespace, fileContent, ext)
+}
+
+func (ossStorage *LocalOSS) UploadObjectFromBase64(
+	ormService *datalayer.ORM,
+	namespace Namespace,
+	content string,
+	extension string,
+) (entity.FileObject, error) {
+	byteData, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return entity.FileObject{}, err
+	}
+
+	return ossStorage.UploadObjectFromByte(ormService, namespace, byteData, extension)
+}
+
+func (ossStorage *LocalOSS) UploadImageFromFile(ormService *datalayer.ORM, namespace Namespace, localFile string) (entity.FileObject, error) {
+	fileContent, ext, err := readContentFile(localFile)
+	if err != nil {
+		return entity.FileObject{}, err
+	}
+
+	return uploadImage(ossStorage, ossStorage.images, ormService, namespace, fileContent, ext)
+}
+
+func (ossStorage *LocalOSS) UploadImageFromBase64(
+	ormService *datalayer.ORM,
+	namespace Namespace,
+	image string,
+	extension string,
+) (entity.FileObject, error) {
+	byteData, err := base64.StdEncoding.DecodeString(image)
+	if err != nil {
+		return entity.FileObject{}, err
+	}
+
+	return uploadImage(ossStorage, ossStorage.images, ormService, namespace, byteData, extension)
+}
+
+func (ossStorage *LocalOSS) DeleteObject(namespace Namespace, object *entity.FileObject) error {
+	objectPath, err := ossStorage.getNamespaceObjectPath(namespace, object.StorageKey)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(objectPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	return err
+}
+
+// GetServedObjectPath verifies the request for an object and returns path to the file.
+// Objects from public bucket can be served without signature
+func (ossStorage *LocalOSS) GetServedObjectPath(bucketName, storageKey, expires, signature string) (string, error) {
+	var bucketConfig *BucketConfig
+
+	for _, bucket := range ossStorage.buckets {
+		if bucket.Name == bucketName {
+			bucketConfig = bucket
+
+			break
+		}
+	}
+
+	if bucketConfig == nil {
+		return "", errors.New("oss: bucket " + bucketName + " not found")
+	}
+
+	if bucketConfig.Type != BucketPublic || signature != "" {
+		if !hmac.Equal([]byte(ossStorage.sign(bucketName, storageKey, expires)), []byte(signature)) {
+			return "", ErrInvalidSignature
+		}
+
+		expiresUnix, err := strconv.ParseInt(expires, 10, 64)
+		if err != nil {
+			return "", ErrInvalidSignature
+		}
+
+		if ossStorage.clockService.Now().Unix() > expiresUnix {
+			return "", ErrURLExpired
+		}
+	}
+
+	return ossStorage.getObjectPath(bucketName, storageKey)
+}
+
+func (ossStorage *LocalOSS) getNamespaceObjectPath(namespace Namespace, storageKey string) (string, error) {
+	bucketConfig, err := ossStorage.namespaces.getBucketConfig(namespace)
+	if err != nil {
+		return "", err
+	}
+
+	return ossStorage.getObjectPath(bucketConfig.Name, storageKey)
+}
+
+func (ossStorage *LocalOSS) getObjectPath(bucketName, storageKey string) (string, error) {
+	cleanKey := path.Clean("/" + storageKey)
+	if cleanKey == "/" || cleanKey != "/"+strings.TrimPrefix(storageKey, "/") {
+		return "", ErrInvalidObjectKey
+	}
+
+	return filepath.Join(ossStorage.client.GetBucketPath(bucketName), filepath.FromSlash(cleanKey)), nil
+}
+
+func (ossStorage *LocalOSS) sign(bucketName, storageKey, expires string) string {
+	mac := hmac.New(sha256.New, ossStorage.secret)
+	mac.Write([]byte(bucketName + "/" + strings.TrimPrefix(storageKey, "/") + ":" + expires))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// CreatePresignedUpload returns signed PUT url served by middleware.OSSRouter
+func (ossStorage *LocalOSS) CreatePresignedUpload(
+	ormService *datalayer.ORM,
+	namespace Namespace,
+	contentType string,
+	maxSize int64,
+) (*PresignedUpload, error) {
+	if err := validatePresignedUpload(contentType, maxSize); err != nil {
+		return nil, err
+	}
+
+	bucketConfig, err := ossStorage.namespaces.getBucketConfig(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	storageCounter := getStorageCounter(context.Background(), ormService, bucketConfig)
+	objectKey := namespace.String() + "/" + strconv.FormatUint(storageCounter, 10) + "." + getExtensionForContentType(contentType)
+
+	expiresAt := ossStorage.clockService.Now().Add(PresignedUploadExpiration)
+	expiresUnix := strconv.FormatInt(expiresAt.Unix(), 10)
+	maxSizeString := strconv.FormatInt(maxSize, 10)
+
+	query := url.Values{}
+	query.Set("expires", expiresUnix)
+	query.Set("max_size", maxSizeString)
+	query.Set("content_type", contentType)
+	query.Set("signature", ossStorage.signUpload(bucketConfig.Name, objectKey, expiresUnix, maxSizeString, contentType))
+
+	return &PresignedUpload{
+		Object:    entity.FileObject{ID: storageCounter, StorageKey: objectKey},
+		Method:    http.MethodPut,
+		URL:       ossStorage.url + "/" + bucketConfig.Name + "/" + objectKey + "?" + query.Encode(),
+		Headers:   map[string]string{"Content-Type": contentType},
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (ossStorage *LocalOSS) GetObjectAttributes(namespace Namespace, object *entity.FileObject) (*ObjectAttributes, error) {
+	if object == nil {
+		return nil, errors.New("nil file object")
+	}
+
+	objectPath, err := ossStorage.getNamespaceObjectPath(namespace, object.StorageKey)
+	if err != nil {
+		return nil, err
+	}
+
+	objectFile, err := os.Open(objectPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrObjectNotFound
+		}
+
+		return nil, err
+	}
+
+	defer objectFile.Close()
+
+	fileInfo, err := objectFile.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	contentType, err := detectContentType(objectFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ObjectAttributes{Size: fileInfo.Size(), ContentType: contentType}, nil
+}
+
+// detectContentType sniffs the content type from the beginning of the content because the local storage
+// keeps only the content sent by the client
+func detectContentType(reader io.Reader) (string, error) {
+	header := make([]byte, 8192)
+
+	n, err := io.ReadFull(reader, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+
+	header = header[:n]
+
+	if fileType, _ := filetype.Match(header); fileType != filetype.Unknown {
+		return fileType.MIME.Value, nil
+	}
+
+	return http.DetectContentType(header), nil
+}
+
+// SaveUploadedObject verifies presigned upload request and stores the body as the object.
+// contentType is the media type of the request without parameters
+func (ossStorage *LocalOSS) SaveUploadedObject(bucketName, storageKey string, query url.Values, contentType string, body io.Reader) error {
+	expires := query.Get("expires")
+	maxSizeString := query.Get("max_size")
+	signedContentType := query.Get("content_type")
+
+	signature := ossStorage.signUpload(bucketName, storageKey, expires, maxSizeString, signedContentType)
+	if !hmac.Equal([]byte(signature), []byte(query.Get("signature"))) {
+		return ErrInvalidSignature
+	}
+
+	expiresUnix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	if ossStorage.clockService.Now().Unix() > expiresUnix {
+		return ErrURLExpired
+	}
+
+	if signedMediaType, _, _ := mime.ParseMediaType(signedContentType); contentType != signedMediaType {
+		return ErrInvalidContentType
+	}
+
+	maxSize, err := strconv.ParseInt(maxSizeString, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	objectPath, err := ossStorage.getObjectPath(bucketName, storageKey)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(objectPath), 0755); err != nil {
+		return err
+	}
+
+	tempFile, err := os.CreateTemp(filepath.Dir(objectPath), ".upload-*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tempFile.Name())
+
+	written, err := io.Copy(tempFile, io.LimitReader(body, maxSize+1))
+
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if written > maxSize {
+		return ErrObjectTooLarge
+	}
+
+	return os.Rename(tempFile.Name(), objectPath)
+}
+
+func (ossStorage *LocalOSS) signUpload(bucketName, storageKey, expires, maxSize, contentType string) string {
+	return ossStorage.sign(bucketName, storageKey, strings.Join([]string{http.MethodPut, expires, maxSize, contentType}, ":"))
+}
@@ -0,0 +1,164 @@
+package oss_test
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/coretrix/hitrix/pkg/entity"
+	clockMocks "github.com/coretrix/hitrix/service/component/clock/mocks"
+	"github.com/coretrix/hitrix/service/component/config"
+	"github.com/coretrix/hitrix/service/component/oss"
+)
+
+const localOSSConfig = `
+oss:
+  local:
+    root: %s
+    url: http://localhost:8080/v1/oss/
+    secret: secret
+  buckets:
+    public:
+      name: public-bucket
+    private:
+      name: private-bucket
+`
+
+func TestLocalOSSSignedURL(t *testing.T) {
+	folder := t.TempDir()
+	root := filepath.Join(folder, "storage")
+
+	assert.Nil(t, os.MkdirAll(filepath.Join(folder, "app"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(folder, "app", "config.yaml"), []byte(""), 0600))
+	assert.Nil(t, os.WriteFile(filepath.Join(folder, "hitrix.yaml"), []byte(fmt.Sprintf(localOSSConfig, root)), 0600))
+
+	configService, err := config.NewConfig("app", "test", folder)
+	assert.Nil(t, err)
+
+	now := time.Unix(1700000000, 0)
+	fakeClock := &clockMocks.FakeSysClock{}
+	fakeClock.On("Now").Return(now).Once()
+
+	provider, err := oss.NewLocalOSS(configService, fakeClock, oss.Namespaces{"avatar": oss.BucketPublic, "invoice": oss.BucketPrivate})
+	assert.Nil(t, err)
+	assert.DirExists(t, filepath.Join(root, "private-bucket"))
+
+	localOSS := provider.(*oss.LocalOSS)
+	object := &entity.FileObject{ID: 1, StorageKey: "invoice/1.pdf"}
+
+	objectURL, err := localOSS.GetObjectURL("avatar", &entity.FileObject{ID: 1, StorageKey: "avatar/1.png"})
+	assert.Nil(t, err)
+	assert.Equal(t, "http://localhost:8080/v1/oss/public-bucket/avatar/1.png", objectURL)
+
+	signedURL, err := localOSS.GetObjectSignedURL("invoice", object, now.Add(time.Minute))
+	assert.Nil(t, err)
+
+	parsedURL, err := url.Parse(signedURL)
+	assert.Nil(t, err)
+	assert.Equal(t, "/v1/oss/private-bucket/invoice/1.pdf", parsedURL.Path)
+
+	expires := parsedURL.Query().Get("expires")
+	signature := parsedURL.Query().Get("signature")
+
+	fakeClock.On("Now").Return(now.Add(30 * time.Second)).Once()
+
+	objectPath, err := localOSS.GetServedObjectPath("private-bucket", "/invoice/1.pdf", expires, signature)
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(root, "private-bucket", "invoice", "1.pdf"), objectPath)
+
+	_, err = localOSS.GetServedObjectPath("private-bucket", "/invoice/2.pdf", expires, signature)
+	assert.Equal(t, oss.ErrInvalidSignature, err)
+
+	_, err = localOSS.GetServedObjectPath("private-bucket", "/invoice/1.pdf", "", "")
+	assert.Equal(t, oss.ErrInvalidSignature, err)
+
+	_, err = localOSS.GetServedObjectPath("public-bucket", "/../private-bucket/invoice/1.pdf", "", "")
+	assert.Equal(t, oss.ErrInvalidObjectKey, err)
+
+	fakeClock.On("Now").Return(now.Add(2 * time.Minute)).Once()
+
+	_, err = localOSS.GetServedObjectPath("private-bucket", "/invoice/1.pdf", expires, signature)
+	assert.Equal(t, oss.ErrURLExpired, err)
+}
+
+func TestLocalOSSSaveUploadedObject(t *testing.T) {
+	folder := t.TempDir()
+	root := filepath.Join(folder, "storage")
+
+	assert.Nil(t, os.MkdirAll(filepath.Join(folder, "app"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(folder, "app", "config.yaml"), []byte(""), 0600))
+	assert.Nil(t, os.WriteFile(filepath.Join(folder, "hitrix.yaml"), []byte(fmt.Sprintf(localOSSConfig, root)), 0600))
+
+	configService, err := config.NewConfig("app", "test", folder)
+	assert.Nil(t, err)
+
+	now := time.Unix(1700000000, 0)
+	fakeClock := &clockMocks.FakeSysClock{}
+	fakeClock.On("Now").Return(now)
+
+	provider, err := oss.NewLocalOSS(configService, fakeClock, oss.Namespaces{"avatar": oss.BucketPublic})
+	assert.Nil(t, err)
+
+	localOSS := provider.(*oss.LocalOSS)
+
+	// HMAC-SHA256 of "public-bucket/avatar/1.png:PUT:1700003600:100:image/png" with "secret" key
+	query := url.Values{
+		"expires":      {"1700003600"},
+		"max_size":     {"100"},
+		"content_type": {"image/png"},
+		"signature":    {"2deeca2e0ba386e53a988e4f0291870c4cb3dc3336bd38f81223393eee04e915"},
+	}
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, png.Encode(buffer, image.NewGray(image.Rect(0, 0, 1, 1))))
+	content := buffer.Bytes()
+
+	tamperedQuery := url.Values{}
+	for key, values := range query {
+		tamperedQuery[key] = values
+	}
+
+	tamperedQuery.Set("max_size", "1000")
+
+	err = localOSS.SaveUploadedObject("public-bucket", "avatar/1.png", tamperedQuery, "image/png", bytes.NewReader(content))
+	assert.Equal(t, oss.ErrInvalidSignature, err)
+
+	err = localOSS.SaveUploadedObject("public-bucket", "avatar/1.png", query, "text/plain", bytes.NewReader(content))
+	assert.Equal(t, oss.ErrInvalidContentType, err)
+
+	err = localOSS.SaveUploadedObject("public-bucket", "avatar/1.png", query, "image/png", bytes.NewReader(make([]byte, 101)))
+	assert.Equal(t, oss.ErrObjectTooLarge, err)
+
+	err = localOSS.SaveUploadedObject("public-bucket", "avatar/1.png", query, "image/png", bytes.NewReader(content))
+	assert.Nil(t, err)
+
+	attributes, err := localOSS.GetObjectAttributes("avatar", &entity.FileObject{ID: 1, StorageKey: "avatar/1.png"})
+	assert.Nil(t, err)
+	assert.Equal(t, &oss.ObjectAttributes{Size: int64(len(content)), ContentType: "image/png"}, attributes)
+
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "public-bucket", "avatar", "2.png"), []byte("plain text"), 0600))
+
+	attributes, err = localOSS.GetObjectAttributes("avatar", &entity.FileObject{ID: 2, StorageKey: "avatar/2.png"})
+	assert.Nil(t, err)
+	assert.Equal(t, "text/plain; charset=utf-8", attributes.ContentType)
+
+	_, err = localOSS.GetObjectAttributes("avatar", &entity.FileObject{ID: 3, StorageKey: "avatar/3.png"})
+	assert.Equal(t, oss.ErrObjectNotFound, err)
+
+	expiredClock := &clockMocks.FakeSysClock{}
+	expiredClock.On("Now").Return(now.Add(2 * time.Hour))
+
+	provider, err = oss.NewLocalOSS(configService, expiredClock, oss.Namespaces{"avatar": oss.BucketPublic})
+	assert.Nil(t, err)
+
+	err = provider.(*oss.LocalOSS).SaveUploadedObject("public-bucket", "avatar/1.png", query, "image/png", bytes.NewReader(content))
+	assert.Equal(t, oss.ErrURLExpired, err)
+}
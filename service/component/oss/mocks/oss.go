@@ -83,3 +83,19 @@ func (t *FakeOSSClient) GetBucketConfig(bucket oss.Bucket) *oss.BucketConfig {
 func (t *FakeOSSClient) GetNamespaceBucketConfig(namespace oss.Namespace) (*oss.BucketConfig, error) {
 	return t.Called(namespace).Get(0).(*oss.BucketConfig), nil
 }
+
+func (t *FakeOSSClient) CreatePresignedUpload(
+	_ *datalayer.ORM,
+	namespace oss.Namespace,
+	contentType string,
+	maxSize int64,
+) (*oss.PresignedUpload, error) {
+	return t.Called(namespace, contentType, maxSize).Get(0).(*oss.PresignedUpload), nil
+}
+
+func (t *FakeOSSClient) GetObjectAttributes(namespace oss.Namespace, object *entity.FileObject) (*oss.ObjectAttributes, error) {
+	args := t.Called(namespace, object)
+	attributes, _ := args.Get(0).(*oss.ObjectAttributes)
+
+	return attributes, args.Error(1)
+}
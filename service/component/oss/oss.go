@@ -2,7 +2,9 @@ package oss
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -78,6 +80,52 @@ type IProvider interface {
 	UploadImageFromFile(ormService *datalayer.ORM, namespace Namespace, localFile string) (entity.FileObject, error)
 	UploadImageFromBase64(ormService *datalayer.ORM, namespace Namespace, image, extension string) (entity.FileObject, error)
 	DeleteObject(namespace Namespace, object *entity.FileObject) error
+	CreatePresignedUpload(ormService *datalayer.ORM, namespace Namespace, contentType string, maxSize int64) (*PresignedUpload, error)
+	GetObjectAttributes(namespace Namespace, object *entity.FileObject) (*ObjectAttributes, error)
+}
+
+// IObjectReader is implemented by providers which can stream the object content without loading it into memory
+type IObjectReader interface {
+	GetObjectReader(namespace Namespace, object *entity.FileObject) (io.ReadCloser, error)
+}
+
+// IObjectWriter is implemented by providers which can upload the object content without loading it into memory
+type IObjectWriter interface {
+	UploadObjectFromReader(ormService *datalayer.ORM, namespace Namespace, reader io.ReadSeeker, extension string) (entity.FileObject, error)
+}
+
+// UploadObjectFromReader streams the content when the provider supports it and falls back to UploadObjectFromByte otherwise
+func UploadObjectFromReader(
+	ossService IProvider,
+	ormService *datalayer.ORM,
+	namespace Namespace,
+	reader io.ReadSeeker,
+	extension string,
+) (entity.FileObject, error) {
+	if objectWriter, ok := ossService.(IObjectWriter); ok {
+		return objectWriter.UploadObjectFromReader(ormService, namespace, reader, extension)
+	}
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return entity.FileObject{}, err
+	}
+
+	return ossService.UploadObjectFromByte(ormService, namespace, content, extension)
+}
+
+// GetObjectReader streams the object when the provider supports it and falls back to GetObjectBase64Content otherwise
+func GetObjectReader(ossService IProvider, namespace Namespace, object *entity.FileObject) (io.ReadCloser, error) {
+	if objectReader, ok := ossService.(IObjectReader); ok {
+		return objectReader.GetObjectReader(namespace, object)
+	}
+
+	base64Content, err := ossService.GetObjectBase64Content(namespace, object)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(base64.NewDecoder(base64.StdEncoding, strings.NewReader(base64Content))), nil
 }
 
 func loadConfig(configService config.IConfig, namespaces Namespaces) (bucketsConfig, namespacesConfig) {
@@ -0,0 +1,78 @@
+package oss
+
+import (
+	"errors"
+	"mime"
+	"sort"
+	"time"
+
+	"github.com/h2non/filetype"
+	"github.com/h2non/filetype/types"
+
+	"github.com/coretrix/hitrix/pkg/entity"
+)
+
+var (
+	ErrObjectNotFound     = errors.New("oss: object not found")
+	ErrObjectTooLarge     = errors.New("oss: object is too large")
+	ErrInvalidContentType = errors.New("oss: invalid content type")
+)
+
+// PresignedUploadExpiration is the time the client has to upload the object after the presigned upload is created
+var PresignedUploadExpiration = time.Hour
+
+// PresignedUpload describes the request the client should send to upload the object directly to the bucket.
+// For POST requests Fields should be sent as multipart form fields before the file field named `file`.
+// For PUT requests Headers should be sent with the object as request body
+type PresignedUpload struct {
+	Object    entity.FileObject
+	Method    string
+	URL       string
+	Fields    map[string]string
+	Headers   map[string]string
+	ExpiresAt time.Time
+}
+
+type ObjectAttributes struct {
+	Size        int64
+	ContentType string
+}
+
+func validatePresignedUpload(contentType string, maxSize int64) error {
+	if maxSize <= 0 {
+		return errors.New("max size should be greater than 0")
+	}
+
+	if _, _, err := mime.ParseMediaType(contentType); err != nil {
+		return ErrInvalidContentType
+	}
+
+	return nil
+}
+
+// getExtensionForContentType returns extension used in the storage key of presigned upload
+func getExtensionForContentType(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	extensions := make([]string, 0)
+
+	filetype.Types.Range(func(extension, fileType interface{}) bool {
+		if fileType.(types.Type).MIME.Value == mediaType {
+			extensions = append(extensions, extension.(string))
+		}
+
+		return true
+	})
+
+	if len(extensions) == 0 {
+		if mimeExtensions, _ := mime.ExtensionsByType(mediaType); len(mimeExtensions) > 0 {
+			return mimeExtensions[0][1:]
+		}
+
+		return "bin"
+	}
+
+	sort.Strings(extensions)
+
+	return extensions[0]
+}
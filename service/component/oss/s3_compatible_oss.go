@@ -0,0 +1,110 @@
+package oss
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/service/component/clock"
+	"github.com/coretrix/hitrix/service/component/config"
+)
+
+const s3CompatibleDefaultRegion = "us-east-1"
+
+// S3CompatibleOSS works with any storage which implements S3 API with custom endpoint (MinIO, Ceph, Cloudflare R2...)
+type S3CompatibleOSS struct {
+	*AmazonOSS
+	endpoint string
+}
+
+func NewS3CompatibleOSS(configService config.IConfig, clockService clock.IClock, namespaces Namespaces) (IProvider, error) {
+	endpoint, ok := configService.String("oss.s3_compatible.endpoint")
+	if !ok {
+		return nil, errors.New("missing oss.s3_compatible.endpoint")
+	}
+
+	accessKeyID, ok := configService.String("oss.s3_compatible.access_key_id")
+	if !ok {
+		return nil, errors.New("missing oss.s3_compatible.access_key_id")
+	}
+
+	secretAccessKey, ok := configService.String("oss.s3_compatible.secret_access_key")
+	if !ok {
+		return nil, errors.New("missing oss.s3_compatible.secret_access_key")
+	}
+
+	region := configService.DefString("oss.s3_compatible.region", s3CompatibleDefaultRegion)
+	disableSSL, _ := configService.Bool("oss.s3_compatible.disable_ssl")
+
+	newSession, err := session.NewSession(&aws.Config{
+		Credentials:      credentials.NewStaticCredentials(accessKeyID, secretAccessKey, ""),
+		Endpoint:         aws.String(endpoint),
+		S3ForcePathStyle: aws.Bool(true),
+		Region:           aws.String(region),
+		DisableSSL:       aws.Bool(disableSSL),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	bucketsConfiguration, namespacesConfiguration := loadConfig(configService, namespaces)
+
+	imagePipelines, err := loadImagePipelines(configService)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.Contains(endpoint, "://") {
+		if disableSSL {
+			endpoint = "http://" + endpoint
+		} else {
+			endpoint = "https://" + endpoint
+		}
+	}
+
+	return &S3CompatibleOSS{
+		AmazonOSS: &AmazonOSS{
+			client:       s3.New(newSession),
+			clockService: clockService,
+			ctx:          context.Background(),
+			buckets:      bucketsConfiguration,
+			namespaces:   namespacesConfiguration,
+			images:       imagePipelines,
+		},
+		endpoint: strings.TrimRight(endpoint, "/"),
+	}, nil
+}
+
+func (ossStorage *S3CompatibleOSS) GetObjectURL(namespace Namespace, object *entity.FileObject) (string, error) {
+	cdnURL, err := ossStorage.GetObjectCDNURL(namespace, object)
+	if err != nil {
+		return "", err
+	}
+
+	if cdnURL != "" {
+		return cdnURL, nil
+	}
+
+	return ossStorage.GetObjectOSSURL(namespace, object)
+}
+
+// GetObjectOSSURL returns path style url, the object is accessible only if the bucket allows anonymous read
+func (ossStorage *S3CompatibleOSS) GetObjectOSSURL(namespace Namespace, object *entity.FileObject) (string, error) {
+	if object == nil {
+		return "", errors.New("nil file object")
+	}
+
+	bucketConfig, err := ossStorage.namespaces.getBucketConfig(namespace)
+	if err != nil {
+		return "", err
+	}
+
+	return ossStorage.endpoint + "/" + bucketConfig.Name + "/" + object.StorageKey, nil
+}
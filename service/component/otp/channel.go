@@ -0,0 +1,88 @@
+package otp
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/coretrix/hitrix/pkg/entity"
+)
+
+const (
+	ChannelSMS      = entity.OTPTrackerTypeSMS
+	ChannelCallout  = entity.OTPTrackerTypeCallout
+	ChannelWhatsApp = entity.OTPTrackerTypeWhatsApp
+)
+
+var ErrNoGatewayForChannel = errors.New("OTP: no gateway supports the channel")
+
+// IOTPChannelGateway is implemented by gateways which do not support both sms and callout
+// or support other channels. Gateways without it support ChannelSMS and ChannelCallout
+type IOTPChannelGateway interface {
+	GetChannels() []string
+}
+
+type IOTPWhatsAppGateway interface {
+	IOTPSMSGateway
+	SendWhatsAppOTP(phone *Phone, code string) (string, string, error)
+}
+
+// ChannelRouter picks preferred channel by phone prefix when the caller does not ask for a channel
+type ChannelRouter struct {
+	prefixes []string
+	channels map[string]string
+}
+
+func NewChannelRouter(channelPerPrefix map[string]string) *ChannelRouter {
+	router := &ChannelRouter{
+		prefixes: make([]string, 0, len(channelPerPrefix)),
+		channels: channelPerPrefix,
+	}
+
+	for prefix := range channelPerPrefix {
+		router.prefixes = append(router.prefixes, prefix)
+	}
+
+	sort.Slice(router.prefixes, func(i, j int) bool {
+		return len(router.prefixes[i]) > len(router.prefixes[j])
+	})
+
+	return router
+}
+
+func (r *ChannelRouter) GetChannel(phone *Phone) string {
+	for _, prefix := range r.prefixes {
+		if strings.HasPrefix(phone.Number, prefix) {
+			return r.channels[prefix]
+		}
+	}
+
+	return ChannelSMS
+}
+
+func supportsChannel(gateway IOTPSMSGateway, channel string) bool {
+	channelGateway, ok := gateway.(IOTPChannelGateway)
+	if !ok {
+		return channel == ChannelSMS || channel == ChannelCallout
+	}
+
+	for _, gatewayChannel := range channelGateway.GetChannels() {
+		if gatewayChannel == channel {
+			return true
+		}
+	}
+
+	return false
+}
+
+func filterGatewaysByChannel(gateways []IOTPSMSGateway, channel string) []IOTPSMSGateway {
+	result := make([]IOTPSMSGateway, 0, len(gateways))
+
+	for _, gateway := range gateways {
+		if supportsChannel(gateway, channel) {
+			result = append(result, gateway)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,133 @@
+package otp
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/latolukasz/beeorm/v2"
+
+	"github.com/coretrix/hitrix/pkg/helper"
+)
+
+var (
+	ErrLocked             = errors.New("OTP: too many invalid codes, try again later")
+	ErrTooManyAttempts    = errors.New("OTP: too many invalid codes")
+	ErrResendCooldown     = errors.New("OTP: code was sent recently, try again later")
+	ErrDailyLimitPerPhone = errors.New("OTP: daily limit for this phone reached")
+	ErrDailyLimitPerIP    = errors.New("OTP: daily limit for this ip reached")
+)
+
+// Limits protects against code guessing and sms pumping. Zero value disables the limit
+type Limits struct {
+	MaxVerifyAttempts  int
+	LockoutDuration    time.Duration
+	ResendCooldown     time.Duration
+	DailyLimitPerPhone int64
+	DailyLimitPerIP    int64
+}
+
+func (o *OTP) checkLocked(redis beeorm.RedisCache, phone *Phone) error {
+	if o.Limits == nil || o.Limits.MaxVerifyAttempts == 0 {
+		return nil
+	}
+
+	if _, has := redis.Get(o.getRedisKey("lock", phone.Number)); has {
+		return ErrLocked
+	}
+
+	return nil
+}
+
+func (o *OTP) lock(redis beeorm.RedisCache, phone *Phone) {
+	lockoutDuration := o.Limits.LockoutDuration
+	if lockoutDuration == 0 {
+		lockoutDuration = helper.Hour * time.Second
+	}
+
+	redis.Set(o.getRedisKey("lock", phone.Number), 1, lockoutDuration)
+	redis.Del(o.getRedisKey("code", phone.Number))
+}
+
+// sendWithLimits consumes send limits before the code is sent, so concurrent requests can not pass them together.
+// When no code will be delivered the limits are given back, so gateway outage does not lock users out.
+// Retried code is still delivered, so its limits are kept
+func (o *OTP) sendWithLimits(redis beeorm.RedisCache, phone *Phone, send func() (string, bool, error)) (string, error) {
+	release, err := o.reserveSendLimits(redis, phone)
+	if err != nil {
+		return "", err
+	}
+
+	code, retryQueued, err := send()
+	if err != nil && !retryQueued {
+		release()
+	}
+
+	return code, err
+}
+
+func (o *OTP) reserveSendLimits(redis beeorm.RedisCache, phone *Phone) (func(), error) {
+	if err := o.checkLocked(redis, phone); err != nil {
+		return nil, err
+	}
+
+	releases := make([]func(), 0, 3)
+	release := func() {
+		for _, release := range releases {
+			release()
+		}
+	}
+
+	if o.Limits == nil {
+		return release, nil
+	}
+
+	if o.Limits.ResendCooldown > 0 {
+		cooldownKey := o.getRedisKey("cooldown", phone.Number)
+
+		if !redis.SetNX(cooldownKey, 1, o.Limits.ResendCooldown) {
+			return nil, ErrResendCooldown
+		}
+
+		releases = append(releases, func() {
+			redis.Del(cooldownKey)
+		})
+	}
+
+	day := o.ClockService.Now().Format(helper.TimeLayoutYMD)
+
+	if o.Limits.DailyLimitPerPhone > 0 {
+		phoneKey := o.getRedisKey("daily_phone_"+day, phone.Number)
+
+		releases = append(releases, func() {
+			redis.IncrBy(phoneKey, -1)
+		})
+
+		if redis.IncrWithExpire(phoneKey, helper.Day*time.Second) > o.Limits.DailyLimitPerPhone {
+			release()
+
+			return nil, ErrDailyLimitPerPhone
+		}
+	}
+
+	if o.Limits.DailyLimitPerIP > 0 && phone.IP != "" {
+		ipKey := o.getRedisKey("daily_ip_"+day, phone.IP)
+
+		releases = append(releases, func() {
+			redis.IncrBy(ipKey, -1)
+		})
+
+		if redis.IncrWithExpire(ipKey, helper.Day*time.Second) > o.Limits.DailyLimitPerIP {
+			release()
+
+			return nil, ErrDailyLimitPerIP
+		}
+	}
+
+	return release, nil
+}
+
+func (o *OTP) getRedisKey(prefix, value string) string {
+	return fmt.Sprintf("otp_%s:%x", prefix, sha256.Sum256([]byte(value)))
+}
@@ -0,0 +1,157 @@
+package otp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coretrix/hitrix/pkg/helper"
+	"github.com/coretrix/hitrix/service/component/generator"
+)
+
+const (
+	OTPProviderMetaWhatsApp = "MetaWhatsApp"
+
+	metaGraphURL = "https://graph.facebook.com/v18.0/"
+)
+
+// MetaWhatsApp sends the code as WhatsApp authentication template using Meta Cloud API.
+// It supports only ChannelWhatsApp, the code is verified locally
+type MetaWhatsApp struct {
+	accessToken      string
+	phoneNumberID    string
+	templateName     string
+	language         string
+	otpLength        int
+	phonePrefixes    []string
+	generatorService generator.IGenerator
+}
+
+func NewMetaWhatsAppOTPProvider(
+	accessToken string,
+	phoneNumberID string,
+	templateName string,
+	language string,
+	otpLength int,
+	phonePrefixes []string,
+	generatorService generator.IGenerator,
+) *MetaWhatsApp {
+	return &MetaWhatsApp{
+		accessToken:      accessToken,
+		phoneNumberID:    phoneNumberID,
+		templateName:     templateName,
+		language:         language,
+		otpLength:        otpLength,
+		phonePrefixes:    phonePrefixes,
+		generatorService: generatorService,
+	}
+}
+
+func (m *MetaWhatsApp) GetName() string {
+	return OTPProviderMetaWhatsApp
+}
+
+func (m *MetaWhatsApp) GetCode() string {
+	var code int64
+	if m.otpLength == 0 {
+		code = m.generatorService.GenerateRandomRangeNumber(10000, 99999)
+	} else {
+		min := int64(math.Pow(10, float64(m.otpLength-1)))
+		max := int64(math.Pow(10, float64(m.otpLength))) - 1
+		code = m.generatorService.GenerateRandomRangeNumber(min, max)
+	}
+
+	return strconv.FormatInt(code, 10)
+}
+
+func (m *MetaWhatsApp) GetPhonePrefixes() []string {
+	return m.phonePrefixes
+}
+
+func (m *MetaWhatsApp) GetChannels() []string {
+	return []string{ChannelWhatsApp}
+}
+
+func (m *MetaWhatsApp) SendOTP(_ *Phone, _ string) (string, string, error) {
+	return "", "", errors.New("sms is not supported by " + OTPProviderMetaWhatsApp)
+}
+
+func (m *MetaWhatsApp) Call(_ *Phone, _ string, _ string) (string, string, error) {
+	return "", "", errors.New("callout is not supported by " + OTPProviderMetaWhatsApp)
+}
+
+func (m *MetaWhatsApp) SendWhatsAppOTP(phone *Phone, code string) (string, string, error) {
+	type parameter struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+
+	type component struct {
+		Type       string       `json:"type"`
+		SubType    string       `json:"sub_type,omitempty"`
+		Index      string       `json:"index,omitempty"`
+		Parameters []*parameter `json:"parameters"`
+	}
+
+	body := &struct {
+		MessagingProduct string `json:"messaging_product"`
+		To               string `json:"to"`
+		Type             string `json:"type"`
+		Template         struct {
+			Name     string `json:"name"`
+			Language struct {
+				Code string `json:"code"`
+			} `json:"language"`
+			Components []*component `json:"components"`
+		} `json:"template"`
+	}{
+		MessagingProduct: "whatsapp",
+		To:               strings.TrimPrefix(phone.Number, "+"),
+		Type:             "template",
+	}
+
+	body.Template.Name = m.templateName
+	body.Template.Language.Code = m.language
+	body.Template.Components = []*component{
+		{Type: "body", Parameters: []*parameter{{Type: "text", Text: code}}},
+		// authentication templates have copy code button which needs the code as well
+		{Type: "button", SubType: "url", Index: "0", Parameters: []*parameter{{Type: "text", Text: code}}},
+	}
+
+	request, err := json.Marshal(body)
+	if err != nil {
+		return "", "", err
+	}
+
+	responseBody, _, statusCode, err := helper.Call(
+		context.Background(),
+		"POST",
+		metaGraphURL+m.phoneNumberID+"/messages",
+		map[string]string{
+			"Content-Type":  "application/json",
+			"Authorization": "Bearer " + m.accessToken,
+		},
+		30*time.Second,
+		body,
+		nil)
+
+	if err != nil {
+		return string(request), string(responseBody), err
+	}
+
+	if statusCode != http.StatusOK {
+		return string(request), string(responseBody), fmt.Errorf("expected status code OK, but got %v", statusCode)
+	}
+
+	return string(request), string(responseBody), nil
+}
+
+func (m *MetaWhatsApp) VerifyOTP(_ *Phone, code, generatedCode string) (string, string, bool, bool, error) {
+	return "", "", true, code == generatedCode, nil
+}
@@ -51,6 +51,26 @@ func (o *OTPService) SendSMS(ormService *datalayer.ORM, phone *otp.Phone) (strin
 	return r0, r1
 }
 
+func (o *OTPService) Send(ormService *datalayer.ORM, phone *otp.Phone, channel string) (string, error) {
+	ret := o.Called(ormService, phone, channel)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(*datalayer.ORM, *otp.Phone, string) string); ok {
+		r0 = rf(ormService, phone, channel)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*datalayer.ORM, *otp.Phone, string) error); ok {
+		r1 = rf(ormService, phone, channel)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 func (o *OTPService) VerifyOTP(ormService *datalayer.ORM, phone *otp.Phone, code string) (bool, bool, error) {
 	ret := o.Called(ormService, phone, code)
 
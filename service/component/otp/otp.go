@@ -1,8 +1,6 @@
 package otp
 
 import (
-	//nolint //G501: Blocklisted import crypto/md5: weak cryptographic primitive
-	"crypto/md5"
 	"errors"
 	"fmt"
 	"regexp"
@@ -15,33 +13,42 @@ import (
 	"github.com/coretrix/hitrix/pkg/entity"
 	"github.com/coretrix/hitrix/pkg/helper"
 	"github.com/coretrix/hitrix/pkg/queue/streams"
+	"github.com/coretrix/hitrix/service/component/clock"
 )
 
 type IOTP interface {
 	SendSMS(ormService *datalayer.ORM, phone *Phone) (string, error)
+	Send(ormService *datalayer.ORM, phone *Phone, channel string) (string, error)
 	VerifyOTP(ormService *datalayer.ORM, phone *Phone, code string) (bool, bool, error)
 	Call(ormService *datalayer.ORM, phone *Phone, customMessage string) (string, error)
 	GetGatewayRegistry() map[string]IOTPSMSGateway
 }
 
 type OTP struct {
+	ClockService            clock.IClock
 	GatewayPriority         []IOTPSMSGateway
 	GatewayName             map[string]IOTPSMSGateway
 	GatewayPhonePrefixRegex map[*regexp.Regexp]IOTPSMSGateway
 	RetryOTP                bool
+	Limits                  *Limits
+	ChannelRouter           *ChannelRouter
 }
 
 type Phone struct {
 	Number  string
 	ISO3166 phonenumber.ISO3166
+	// IP of the client which requested the code. Used for Limits.DailyLimitPerIP
+	IP string
 }
 
-func NewOTP(retryOTP bool, gateways ...IOTPSMSGateway) *OTP {
+func NewOTP(clockService clock.IClock, retryOTP bool, limits *Limits, gateways ...IOTPSMSGateway) *OTP {
 	otp := &OTP{
+		ClockService:            clockService,
 		GatewayPriority:         make([]IOTPSMSGateway, 0),
 		GatewayName:             map[string]IOTPSMSGateway{},
 		GatewayPhonePrefixRegex: map[*regexp.Regexp]IOTPSMSGateway{},
 		RetryOTP:                retryOTP,
+		Limits:                  limits,
 	}
 
 	for _, gateway := range gateways {
@@ -78,35 +85,100 @@ func NewOTP(retryOTP bool, gateways ...IOTPSMSGateway) *OTP {
 }
 
 func (o *OTP) SendSMS(ormService *datalayer.ORM, phone *Phone) (string, error) {
+	return o.sendWithLimits(ormService.GetRedis(), phone, func() (string, bool, error) {
+		return o.send(ormService, phone, ChannelSMS, "")
+	})
+}
+
+func (o *OTP) Call(ormService *datalayer.ORM, phone *Phone, customMessage string) (string, error) {
+	return o.sendWithLimits(ormService.GetRedis(), phone, func() (string, bool, error) {
+		return o.send(ormService, phone, ChannelCallout, customMessage)
+	})
+}
+
+// Send delivers the code using channel. If channel is empty ChannelRouter decides.
+// When channel other than sms fails we fall back to sms
+func (o *OTP) Send(ormService *datalayer.ORM, phone *Phone, channel string) (string, error) {
+	if channel == "" {
+		channel = ChannelSMS
+
+		if o.ChannelRouter != nil {
+			channel = o.ChannelRouter.GetChannel(phone)
+		}
+	}
+
+	switch channel {
+	case ChannelSMS, ChannelCallout, ChannelWhatsApp:
+	default:
+		return "", fmt.Errorf("OTP: unknown channel %s", channel)
+	}
+
+	return o.sendWithLimits(ormService.GetRedis(), phone, func() (string, bool, error) {
+		code, retryQueued, err := o.send(ormService, phone, channel, "")
+		if err != nil && channel != ChannelSMS {
+			return o.send(ormService, phone, ChannelSMS, "")
+		}
+
+		return code, retryQueued, err
+	})
+}
+
+// send returns retryQueued when sending failed but sms will be sent again by StreamMsgRetryOTP consumer
+func (o *OTP) send(ormService *datalayer.ORM, phone *Phone, channel string, customMessage string) (string, bool, error) {
 	var code string
+	var retryQueued bool
 	var err error
 
-	gatewayPriority := make([]IOTPSMSGateway, 0)
+	var gatewayPriority []IOTPSMSGateway
+
+	if channel == ChannelCallout {
+		gatewayPriority = filterGatewaysByChannel(o.GatewayPriority, channel)
+	} else {
+		gatewayPriority = make([]IOTPSMSGateway, 0)
+
+		for regex, gateway := range o.GatewayPhonePrefixRegex {
+			if regex.MatchString(phone.Number) && supportsChannel(gateway, channel) {
+				gatewayPriority = append(gatewayPriority, gateway)
+			}
+		}
 
-	for regex, gateway := range o.GatewayPhonePrefixRegex {
-		if regex.MatchString(phone.Number) {
-			gatewayPriority = append(gatewayPriority, gateway)
+		if len(gatewayPriority) == 0 {
+			gatewayPriority = filterGatewaysByChannel(o.GatewayPriority, channel)
 		}
 	}
 
 	if len(gatewayPriority) == 0 {
-		gatewayPriority = o.GatewayPriority
+		return "", false, ErrNoGatewayForChannel
 	}
 
 	for priority, gateway := range gatewayPriority {
 		code = gateway.GetCode()
 
 		otpTrackerEntity := &entity.OTPTrackerEntity{
-			Type:              entity.OTPTrackerTypeSMS,
+			Type:              channel,
 			To:                phone.Number,
 			Code:              code,
 			GatewayName:       gateway.GetName(),
 			GatewayPriority:   uint8(priority),
 			GatewaySendStatus: entity.OTPTrackerGatewaySendStatusNew,
-			SentAt:            time.Now(), // TODO ClockService
+			SentAt:            o.ClockService.Now(),
 		}
 
-		otpTrackerEntity.GatewaySendRequest, otpTrackerEntity.GatewaySendResponse, err = gateway.SendOTP(phone, code)
+		switch channel {
+		case ChannelCallout:
+			otpTrackerEntity.GatewaySendRequest, otpTrackerEntity.GatewaySendResponse, err = gateway.Call(phone, code, customMessage)
+		case ChannelWhatsApp:
+			whatsAppGateway, ok := gateway.(IOTPWhatsAppGateway)
+			if !ok {
+				err = fmt.Errorf("OTP: gateway %s does not implement IOTPWhatsAppGateway", gateway.GetName())
+
+				break
+			}
+
+			otpTrackerEntity.GatewaySendRequest, otpTrackerEntity.GatewaySendResponse, err = whatsAppGateway.SendWhatsAppOTP(phone, code)
+		default:
+			otpTrackerEntity.GatewaySendRequest, otpTrackerEntity.GatewaySendResponse, err = gateway.SendOTP(phone, code)
+		}
 
 		if err != nil {
 			otpTrackerEntity.GatewaySendStatus = entity.OTPTrackerGatewaySendStatusGatewayError
@@ -117,60 +189,29 @@ func (o *OTP) SendSMS(ormService *datalayer.ORM, phone *Phone) (string, error) {
 		ormService.Flush(otpTrackerEntity)
 
 		if err == nil {
-			ormService.GetRedis().Set(o.getRedisKey(phone), otpTrackerEntity.ID, helper.Hour*time.Second)
+			ormService.GetRedis().Set(o.getRedisKey("code", phone.Number), otpTrackerEntity.ID, helper.Hour*time.Second)
 
 			break
-		} else if o.RetryOTP {
+		} else if o.RetryOTP && channel == ChannelSMS {
 			ormService.GetEventBroker().Publish(streams.StreamMsgRetryOTP, &RetryDTO{
 				Code:               code,
 				Phone:              phone,
 				OTPTrackerEntityID: otpTrackerEntity.ID,
 				Gateway:            gateway.GetName(),
 			}, nil)
-		}
-	}
-
-	return code, err
-}
-
-func (o *OTP) Call(ormService *datalayer.ORM, phone *Phone, customMessage string) (string, error) {
-	var code string
-	var err error
 
-	for priority, gateway := range o.GatewayPriority {
-		code = gateway.GetCode()
-
-		otpTrackerEntity := &entity.OTPTrackerEntity{
-			Type:              entity.OTPTrackerTypeCallout,
-			To:                phone.Number,
-			Code:              code,
-			GatewayName:       gateway.GetName(),
-			GatewayPriority:   uint8(priority),
-			GatewaySendStatus: entity.OTPTrackerGatewaySendStatusNew,
-			SentAt:            time.Now(), // TODO ClockService
-		}
-
-		otpTrackerEntity.GatewaySendRequest, otpTrackerEntity.GatewaySendResponse, err = gateway.Call(phone, code, customMessage)
-
-		if err != nil {
-			otpTrackerEntity.GatewaySendStatus = entity.OTPTrackerGatewaySendStatusGatewayError
-		} else {
-			otpTrackerEntity.GatewaySendStatus = entity.OTPTrackerGatewaySendStatusSent
-		}
-
-		ormService.Flush(otpTrackerEntity)
-
-		if err == nil {
-			ormService.GetRedis().Set(o.getRedisKey(phone), otpTrackerEntity.ID, helper.Hour*time.Second)
-
-			break
+			retryQueued = true
 		}
 	}
 
-	return code, err
+	return code, retryQueued, err
 }
 
 func (o *OTP) VerifyOTP(ormService *datalayer.ORM, phone *Phone, code string) (bool, bool, error) {
+	if err := o.checkLocked(ormService.GetRedis(), phone); err != nil {
+		return false, false, err
+	}
+
 	otpTrackerEntity, err := o.getOTPTrackerEntity(ormService, phone)
 	if err != nil {
 		return false, false, err
@@ -195,6 +236,16 @@ func (o *OTP) VerifyOTP(ormService *datalayer.ORM, phone *Phone, code string) (b
 		otpTrackerEntity.GatewayVerifyStatus = entity.OTPTrackerGatewayVerifyStatusSuccess
 	}
 
+	otpTrackerEntity.VerifyAttempts++
+
+	if err == nil && otpRequestValid && !otpCodeValid &&
+		o.Limits != nil && o.Limits.MaxVerifyAttempts > 0 && otpTrackerEntity.VerifyAttempts >= o.Limits.MaxVerifyAttempts {
+		otpTrackerEntity.GatewayVerifyStatus = entity.OTPTrackerGatewayVerifyStatusTooManyAttempts
+		err = ErrTooManyAttempts
+
+		o.lock(ormService.GetRedis(), phone)
+	}
+
 	ormService.Flush(otpTrackerEntity)
 
 	return otpRequestValid, otpCodeValid, err
@@ -205,7 +256,7 @@ func (o *OTP) GetGatewayRegistry() map[string]IOTPSMSGateway {
 }
 
 func (o *OTP) getOTPTrackerEntity(ormService *datalayer.ORM, phone *Phone) (*entity.OTPTrackerEntity, error) {
-	otpTrackerEntityIDString, has := ormService.GetRedis().Get(o.getRedisKey(phone))
+	otpTrackerEntityIDString, has := ormService.GetRedis().Get(o.getRedisKey("code", phone.Number))
 
 	if !has {
 		return nil, errors.New("OTP: redis key expired")
@@ -226,11 +277,6 @@ func (o *OTP) getOTPTrackerEntity(ormService *datalayer.ORM, phone *Phone) (*ent
 	return otpTrackerEntity, nil
 }
 
-func (o *OTP) getRedisKey(phone *Phone) string {
-	// #nosec
-	return fmt.Sprintf("%x", md5.Sum([]byte(phone.Number)))
-}
-
 type RetryDTO struct {
 	Code               string
 	Phone              *Phone
@@ -37,10 +37,22 @@ func (t *Twilio) GetPhonePrefixes() []string {
 	return nil
 }
 
+func (t *Twilio) GetChannels() []string {
+	return []string{ChannelSMS, ChannelCallout, ChannelWhatsApp}
+}
+
 func (t *Twilio) SendOTP(phone *Phone, _ string) (string, string, error) {
+	return t.sendVerification(phone, "sms")
+}
+
+func (t *Twilio) SendWhatsAppOTP(phone *Phone, _ string) (string, string, error) {
+	return t.sendVerification(phone, "whatsapp")
+}
+
+func (t *Twilio) sendVerification(phone *Phone, channel string) (string, string, error) {
 	createVerificationParams := &openapi.CreateVerificationParams{}
 
-	createVerificationParams.SetChannel("sms")
+	createVerificationParams.SetChannel(channel)
 	createVerificationParams.SetTo(phone.Number)
 
 	request, jsonError := t.toJSON(createVerificationParams)
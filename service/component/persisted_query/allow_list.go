@@ -0,0 +1,132 @@
+package persistedquery
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+const ErrPersistedQueryNotAllowed = "PERSISTED_QUERY_NOT_ALLOWED"
+
+// AllowList executes only operations from the manifest. Clients send the hash in APQ extension or the exact body of the operation
+type AllowList struct {
+	PersistedQuery IPersistedQuery
+	// OnReject is called when the operation is not in the manifest
+	OnReject func(ctx context.Context, operationName string, err *gqlerror.Error)
+	// AllowServiceSDL executes federation `_service { sdl }` queries, gateway needs them to compose the supergraph
+	AllowServiceSDL bool
+	// AllowEntities executes federation `_entities` queries. The gateway builds them from client queries, so they can not
+	// be in the manifest. Enable it only when the subgraph is not reachable by other clients than the gateway
+	AllowEntities bool
+}
+
+var _ interface {
+	graphql.OperationParameterMutator
+	graphql.HandlerExtension
+} = &AllowList{}
+
+func (a *AllowList) ExtensionName() string {
+	return "PersistedQueryAllowList"
+}
+
+func (a *AllowList) Validate(_ graphql.ExecutableSchema) error {
+	if a.PersistedQuery == nil {
+		return fmt.Errorf("AllowList.PersistedQuery can not be nil")
+	}
+
+	return nil
+}
+
+func (a *AllowList) MutateOperationParameters(ctx context.Context, rawParams *graphql.RawParams) *gqlerror.Error {
+	if a.isFederationQuery(rawParams.Query) {
+		return nil
+	}
+
+	hash := requestHash(rawParams)
+	if hash == "" {
+		hash = Hash(rawParams.Query)
+	}
+
+	operation, ok := a.PersistedQuery.Operation(hash)
+	if !ok {
+		err := gqlerror.Errorf("operation is not in the persisted query manifest")
+		errcode.Set(err, ErrPersistedQueryNotAllowed)
+
+		if a.OnReject != nil {
+			operationName := rawParams.OperationName
+			if operationName == "" {
+				operationName = hash
+			}
+
+			a.OnReject(ctx, operationName, err)
+		}
+
+		return err
+	}
+
+	// body from the manifest is executed even when the client sent the query, so only reviewed operations run
+	rawParams.Query = operation.Body
+
+	if rawParams.OperationName == "" {
+		rawParams.OperationName = operation.Name
+	}
+
+	return nil
+}
+
+func requestHash(rawParams *graphql.RawParams) string {
+	extension, ok := rawParams.Extensions["persistedQuery"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	hash, _ := extension["sha256Hash"].(string)
+
+	return hash
+}
+
+// isFederationQuery returns true when all operations select only allowed federation fields and __typename
+func (a *AllowList) isFederationQuery(query string) bool {
+	if query == "" || (!a.AllowServiceSDL && !a.AllowEntities) {
+		return false
+	}
+
+	document, err := parser.ParseQuery(&ast.Source{Input: query})
+	if err != nil || len(document.Operations) == 0 {
+		return false
+	}
+
+	for _, operation := range document.Operations {
+		if operation.Operation != ast.Query {
+			return false
+		}
+
+		for _, selection := range operation.SelectionSet {
+			field, ok := selection.(*ast.Field)
+			if !ok {
+				return false
+			}
+
+			switch field.Name {
+			case "__typename":
+			case "_service":
+				if !a.AllowServiceSDL {
+					return false
+				}
+			case "_entities":
+				if !a.AllowEntities {
+					return false
+				}
+			default:
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,37 @@
+package persistedquery
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/latolukasz/beeorm/v2"
+)
+
+const apqKeyPrefix = "graphql_apq:"
+
+// RedisAPQCache stores automatic persisted queries in redis, so the query sent to one instance is known by all of them
+type RedisAPQCache struct {
+	Redis beeorm.RedisCache
+	TTL   time.Duration
+}
+
+var _ graphql.Cache = &RedisAPQCache{}
+
+func (c *RedisAPQCache) Get(_ context.Context, key string) (interface{}, bool) {
+	query, has := c.Redis.Get(apqKeyPrefix + key)
+	if !has {
+		return nil, false
+	}
+
+	return query, true
+}
+
+func (c *RedisAPQCache) Add(_ context.Context, key string, value interface{}) {
+	query, ok := value.(string)
+	if !ok {
+		return
+	}
+
+	c.Redis.Set(apqKeyPrefix+key, query, c.TTL)
+}
@@ -0,0 +1,177 @@
+package persistedquery
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+var (
+	schemaFileExtensions = map[string]bool{".graphql": true, ".gql": true}
+	clientFileExtensions = map[string]bool{".js": true, ".jsx": true, ".ts": true, ".tsx": true, ".mjs": true, ".vue": true, ".svelte": true}
+	skippedDirectories   = map[string]bool{"node_modules": true, "vendor": true}
+
+	taggedTemplateRegexp = regexp.MustCompile("(?:gql|graphql)\\s*(?:\\(\\s*)?`([^`]*)`")
+	interpolationRegexp  = regexp.MustCompile(`\$\{[^}]*\}`)
+)
+
+// ExtractManifest finds named operations in .graphql files and gql or graphql tagged templates of client code.
+// Fragments can be defined in any file, the body of the operation contains the operation and all used fragments
+func ExtractManifest(paths ...string) (*Manifest, error) {
+	operations := map[string]*ast.OperationDefinition{}
+	fragments := map[string]*ast.FragmentDefinition{}
+
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(filePath string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if entry.IsDir() {
+				if filePath != path && (skippedDirectories[entry.Name()] || strings.HasPrefix(entry.Name(), ".")) {
+					return filepath.SkipDir
+				}
+
+				return nil
+			}
+
+			return extractFile(filePath, operations, fragments)
+		})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	names := make([]string, 0, len(operations))
+	for name := range operations {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	result := make([]*Operation, 0, len(operations))
+
+	for _, name := range names {
+		operation := operations[name]
+
+		doc := &ast.QueryDocument{Operations: ast.OperationList{operation}}
+
+		used := map[string]bool{}
+		if err := collectFragments(operation.SelectionSet, fragments, used); err != nil {
+			return nil, fmt.Errorf("operation %s: %w", name, err)
+		}
+
+		for fragmentName := range used {
+			doc.Fragments = append(doc.Fragments, fragments[fragmentName])
+		}
+
+		sort.Slice(doc.Fragments, func(i, j int) bool {
+			return doc.Fragments[i].Name < doc.Fragments[j].Name
+		})
+
+		var body bytes.Buffer
+		formatter.NewFormatter(&body).FormatQueryDocument(doc)
+
+		result = append(result, &Operation{
+			ID:   Hash(body.String()),
+			Name: name,
+			Type: string(operation.Operation),
+			Body: body.String(),
+		})
+	}
+
+	return NewManifest(result), nil
+}
+
+func extractFile(filePath string, operations map[string]*ast.OperationDefinition, fragments map[string]*ast.FragmentDefinition) error {
+	extension := strings.ToLower(filepath.Ext(filePath))
+	if !schemaFileExtensions[extension] && !clientFileExtensions[extension] {
+		return nil
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	sources := []string{string(content)}
+
+	if clientFileExtensions[extension] {
+		sources = sources[:0]
+
+		for _, match := range taggedTemplateRegexp.FindAllStringSubmatch(string(content), -1) {
+			sources = append(sources, interpolationRegexp.ReplaceAllString(match[1], ""))
+		}
+	}
+
+	for _, source := range sources {
+		doc, err := parser.ParseQuery(&ast.Source{Name: filePath, Input: source})
+		if err != nil {
+			return fmt.Errorf("%s: %w", filePath, err)
+		}
+
+		for _, operation := range doc.Operations {
+			if operation.Name == "" {
+				return fmt.Errorf("%s: persisted operations must be named", filePath)
+			}
+
+			if _, ok := operations[operation.Name]; ok {
+				return fmt.Errorf("%s: operation %s is defined more than once", filePath, operation.Name)
+			}
+
+			operations[operation.Name] = operation
+		}
+
+		for _, fragment := range doc.Fragments {
+			if _, ok := fragments[fragment.Name]; ok {
+				return fmt.Errorf("%s: fragment %s is defined more than once", filePath, fragment.Name)
+			}
+
+			fragments[fragment.Name] = fragment
+		}
+	}
+
+	return nil
+}
+
+func collectFragments(selections ast.SelectionSet, fragments map[string]*ast.FragmentDefinition, used map[string]bool) error {
+	for _, selection := range selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if err := collectFragments(selection.SelectionSet, fragments, used); err != nil {
+				return err
+			}
+		case *ast.InlineFragment:
+			if err := collectFragments(selection.SelectionSet, fragments, used); err != nil {
+				return err
+			}
+		case *ast.FragmentSpread:
+			if used[selection.Name] {
+				continue
+			}
+
+			fragment, ok := fragments[selection.Name]
+			if !ok {
+				return fmt.Errorf("fragment %s not found", selection.Name)
+			}
+
+			used[selection.Name] = true
+
+			if err := collectFragments(fragment.SelectionSet, fragments, used); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
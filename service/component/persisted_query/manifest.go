@@ -0,0 +1,54 @@
+package persistedquery
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// ManifestFormat is the format of apollo persisted query manifest, so client tools can read the generated manifest
+const ManifestFormat = "apollo-persisted-query-manifest"
+
+type Manifest struct {
+	Format     string       `json:"format"`
+	Version    int          `json:"version"`
+	Operations []*Operation `json:"operations"`
+}
+
+type Operation struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Body string `json:"body"`
+}
+
+func NewManifest(operations []*Operation) *Manifest {
+	return &Manifest{Format: ManifestFormat, Version: 1, Operations: operations}
+}
+
+func ParseManifest(data []byte) (*Manifest, error) {
+	manifest := &Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("invalid persisted query manifest: %w", err)
+	}
+
+	if manifest.Format != ManifestFormat || manifest.Version != 1 {
+		return nil, fmt.Errorf("unsupported persisted query manifest %s version %d", manifest.Format, manifest.Version)
+	}
+
+	for _, operation := range manifest.Operations {
+		if operation.ID != Hash(operation.Body) {
+			return nil, fmt.Errorf("persisted query %s has invalid id", operation.Name)
+		}
+	}
+
+	return manifest, nil
+}
+
+// Hash returns sha256 hash of the query, the same hash is sent by APQ clients
+func Hash(query string) string {
+	hash := sha256.Sum256([]byte(query))
+
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,209 @@
+package persistedquery
+
+import (
+	"errors"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/latolukasz/beeorm/v2"
+
+	"github.com/coretrix/hitrix/service/component/clock"
+)
+
+type IPersistedQuery interface {
+	// Operation returns operation of the hash from the manifest
+	Operation(hash string) (*Operation, bool)
+	// Operations returns all operations of the manifest
+	Operations() []*Operation
+	// AllowList is true when queries which are not in the manifest have to be rejected
+	AllowList() bool
+	// APQCache is automatic persisted query cache shared by all instances
+	APQCache() graphql.Cache
+	// Reload loads the manifest again, current manifest is kept when loading fails
+	Reload() error
+}
+
+// ManifestLoader returns manifest JSON, see FileManifestLoader and RedisManifestLoader
+type ManifestLoader func() ([]byte, error)
+
+func FileManifestLoader(path string) ManifestLoader {
+	return func() ([]byte, error) {
+		return os.ReadFile(path)
+	}
+}
+
+func RedisManifestLoader(redis beeorm.RedisCache, key string) ManifestLoader {
+	return func() ([]byte, error) {
+		manifest, has := redis.Get(key)
+		if !has {
+			return nil, errors.New("persisted query manifest not found in redis key " + key)
+		}
+
+		return []byte(manifest), nil
+	}
+}
+
+type persistedQuery struct {
+	loader    ManifestLoader
+	allowList bool
+	apqCache  graphql.Cache
+	clock     clock.IClock
+	refresh   time.Duration
+
+	mutex      sync.RWMutex
+	operations []*Operation
+	byHash     map[string]*Operation
+	loadedAt   time.Time
+	reloading  bool
+	failures   int
+	retryAt    time.Time
+}
+
+// minReloadRetry is the delay after the first failed refresh, it doubles with every next failure up to refresh duration
+const minReloadRetry = time.Second
+
+// NewPersistedQuery loads the manifest with the loader, loader can be nil when only APQ cache is used.
+// Manifest is loaded again in background after refresh duration, 0 means never
+func NewPersistedQuery(
+	loader ManifestLoader,
+	allowList bool,
+	apqCache graphql.Cache,
+	clock clock.IClock,
+	refresh time.Duration,
+) (IPersistedQuery, error) {
+	if allowList && loader == nil {
+		return nil, errors.New("persisted query allow list requires manifest")
+	}
+
+	p := &persistedQuery{
+		loader:    loader,
+		allowList: allowList,
+		apqCache:  apqCache,
+		clock:     clock,
+		refresh:   refresh,
+		byHash:    map[string]*Operation{},
+	}
+
+	if err := p.Reload(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (p *persistedQuery) Operation(hash string) (*Operation, bool) {
+	p.refreshIfExpired()
+
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	operation, ok := p.byHash[hash]
+
+	return operation, ok
+}
+
+func (p *persistedQuery) Operations() []*Operation {
+	p.refreshIfExpired()
+
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.operations
+}
+
+func (p *persistedQuery) AllowList() bool {
+	return p.allowList
+}
+
+func (p *persistedQuery) APQCache() graphql.Cache {
+	return p.apqCache
+}
+
+func (p *persistedQuery) Reload() error {
+	now := p.clock.Now()
+
+	if p.loader == nil {
+		p.mutex.Lock()
+		p.loadedAt = now
+		p.mutex.Unlock()
+
+		return nil
+	}
+
+	data, err := p.loader()
+	if err != nil {
+		return err
+	}
+
+	manifest, err := ParseManifest(data)
+	if err != nil {
+		return err
+	}
+
+	byHash := make(map[string]*Operation, len(manifest.Operations))
+	for _, operation := range manifest.Operations {
+		byHash[operation.ID] = operation
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.operations = manifest.Operations
+	p.byHash = byHash
+	p.loadedAt = now
+	p.failures = 0
+	p.retryAt = time.Time{}
+
+	return nil
+}
+
+// refreshIfExpired starts one background reload when the manifest is expired, requests keep using the current manifest
+func (p *persistedQuery) refreshIfExpired() {
+	if p.refresh <= 0 || p.loader == nil {
+		return
+	}
+
+	now := p.clock.Now()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.reloading || now.Sub(p.loadedAt) < p.refresh || now.Before(p.retryAt) {
+		return
+	}
+
+	p.reloading = true
+
+	go p.backgroundReload()
+}
+
+func (p *persistedQuery) backgroundReload() {
+	err := p.Reload()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.reloading = false
+
+	if err == nil {
+		return
+	}
+
+	p.failures++
+
+	retry := minReloadRetry
+	for i := 1; i < p.failures && retry < p.refresh; i++ {
+		retry *= 2
+	}
+
+	if retry > p.refresh {
+		retry = p.refresh
+	}
+
+	p.retryAt = p.clock.Now().Add(retry)
+
+	log.Printf("persisted query manifest reload failed %d times, next try in %s: %s", p.failures, retry, err.Error())
+}
@@ -0,0 +1,255 @@
+package persistedquery_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/stretchr/testify/assert"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/coretrix/hitrix/service/component/clock/mocks"
+	persistedquery "github.com/coretrix/hitrix/service/component/persisted_query"
+)
+
+func writeClientCode(t *testing.T) string {
+	dir := t.TempDir()
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "fragments.graphql"), []byte(`
+fragment UserFields on User {
+	id
+	name
+}`), 0600))
+
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "src"), 0700))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "src", "user.tsx"), []byte(`
+import { gql } from "@apollo/client";
+
+export const GET_USER = gql`+"`"+`
+	query GetUser($id: ID!) {
+		user(id: $id) {
+			...UserFields
+		}
+	}
+	${USER_FIELDS}
+`+"`"+`;
+
+export const DELETE_USER = gql(`+"`"+`mutation DeleteUser($id: ID!) { deleteUser(id: $id) }`+"`"+`);
+`), 0600))
+
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "node_modules", "lib"), 0700))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "node_modules", "lib", "index.js"), []byte("gql`{ ignored }`"), 0600))
+
+	return dir
+}
+
+func TestExtractManifest(t *testing.T) {
+	manifest, err := persistedquery.ExtractManifest(writeClientCode(t))
+	assert.Nil(t, err)
+	assert.Equal(t, persistedquery.ManifestFormat, manifest.Format)
+	assert.Len(t, manifest.Operations, 2)
+
+	assert.Equal(t, "DeleteUser", manifest.Operations[0].Name)
+	assert.Equal(t, "mutation", manifest.Operations[0].Type)
+
+	getUser := manifest.Operations[1]
+	assert.Equal(t, "GetUser", getUser.Name)
+	assert.Equal(t, "query", getUser.Type)
+	assert.Contains(t, getUser.Body, "fragment UserFields on User")
+	assert.Equal(t, persistedquery.Hash(getUser.Body), getUser.ID)
+
+	data, err := json.Marshal(manifest)
+	assert.Nil(t, err)
+
+	parsed, err := persistedquery.ParseManifest(data)
+	assert.Nil(t, err)
+	assert.Equal(t, manifest, parsed)
+
+	getUser.Body += " "
+	data, _ = json.Marshal(manifest)
+
+	_, err = persistedquery.ParseManifest(data)
+	assert.NotNil(t, err)
+
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "anonymous.graphql"), []byte("{ user { id } }"), 0600))
+
+	_, err = persistedquery.ExtractManifest(dir)
+	assert.NotNil(t, err)
+}
+
+func TestAllowList(t *testing.T) {
+	manifest, err := persistedquery.ExtractManifest(writeClientCode(t))
+	assert.Nil(t, err)
+
+	manifestData, _ := json.Marshal(manifest)
+	var loads int32
+
+	loader := func() ([]byte, error) {
+		if atomic.AddInt32(&loads, 1) > 2 {
+			return nil, errors.New("redis is down")
+		}
+
+		return manifestData, nil
+	}
+
+	fakeClock := &mocks.FakeSysClock{}
+	fakeClock.On("Now").Return(time.Unix(0, 0)).Once()
+	fakeClock.On("Now").Return(time.Unix(100, 0))
+
+	_, err = persistedquery.NewPersistedQuery(nil, true, lru.New(10), fakeClock, 0)
+	assert.NotNil(t, err)
+
+	persistedQueryService, err := persistedquery.NewPersistedQuery(loader, true, lru.New(10), fakeClock, time.Minute)
+	assert.Nil(t, err)
+	assert.True(t, persistedQueryService.AllowList())
+
+	var rejected []string
+
+	allowList := &persistedquery.AllowList{
+		PersistedQuery: persistedQueryService,
+		OnReject: func(_ context.Context, operationName string, _ *gqlerror.Error) {
+			rejected = append(rejected, operationName)
+		},
+	}
+	assert.Nil(t, allowList.Validate(nil))
+
+	getUser := manifest.Operations[1]
+	params := &graphql.RawParams{
+		Extensions: map[string]interface{}{"persistedQuery": map[string]interface{}{"version": float64(1), "sha256Hash": getUser.ID}},
+	}
+	assert.Nil(t, allowList.MutateOperationParameters(context.Background(), params))
+	assert.Equal(t, getUser.Body, params.Query)
+	assert.Equal(t, "GetUser", params.OperationName)
+
+	// expired manifest is reloaded in background
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&loads) == 2
+	}, time.Second, time.Millisecond)
+
+	params = &graphql.RawParams{Query: getUser.Body}
+	assert.Nil(t, allowList.MutateOperationParameters(context.Background(), params))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&loads))
+
+	params = &graphql.RawParams{Query: "query Evil { users { id } }", OperationName: "Evil"}
+	err = allowList.MutateOperationParameters(context.Background(), params)
+	assert.NotNil(t, err)
+	assert.Equal(t, persistedquery.ErrPersistedQueryNotAllowed, err.(*gqlerror.Error).Extensions["code"])
+	assert.Equal(t, []string{"Evil"}, rejected)
+
+	serviceSDL := &graphql.RawParams{Query: "{ _service { sdl } }"}
+	assert.NotNil(t, allowList.MutateOperationParameters(context.Background(), serviceSDL))
+
+	allowList.AllowServiceSDL = true
+	assert.Nil(t, allowList.MutateOperationParameters(context.Background(), serviceSDL))
+	assert.Equal(t, "{ _service { sdl } }", serviceSDL.Query)
+
+	params = &graphql.RawParams{Query: "{ _service { sdl } users { id } }"}
+	assert.NotNil(t, allowList.MutateOperationParameters(context.Background(), params))
+
+	entities := &graphql.RawParams{
+		Query: "query($representations: [_Any!]!) { _entities(representations: $representations) { ... on User { name } } }",
+	}
+	assert.NotNil(t, allowList.MutateOperationParameters(context.Background(), entities))
+
+	allowList.AllowEntities = true
+	assert.Nil(t, allowList.MutateOperationParameters(context.Background(), entities))
+
+	params = &graphql.RawParams{Query: "{ _entities(representations: []) { __typename } users { id } }"}
+	assert.NotNil(t, allowList.MutateOperationParameters(context.Background(), params))
+
+	allowList.AllowServiceSDL = false
+	assert.NotNil(t, allowList.MutateOperationParameters(context.Background(), serviceSDL))
+
+	assert.NotNil(t, persistedQueryService.Reload())
+	assert.Len(t, persistedQueryService.Operations(), 2)
+}
+
+type testClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.now
+}
+
+func (c *testClock) NowPointer() *time.Time {
+	now := c.Now()
+
+	return &now
+}
+
+func (c *testClock) Set(seconds float64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = time.Unix(0, 0).Add(time.Duration(seconds * float64(time.Second)))
+}
+
+func TestPersistedQueryRefreshBackoff(t *testing.T) {
+	manifest, err := persistedquery.ExtractManifest(writeClientCode(t))
+	assert.Nil(t, err)
+
+	manifestData, _ := json.Marshal(manifest)
+
+	var loads int32
+
+	loader := func() ([]byte, error) {
+		if atomic.AddInt32(&loads, 1) > 1 {
+			return nil, errors.New("redis is down")
+		}
+
+		return manifestData, nil
+	}
+
+	testClock := &testClock{}
+	testClock.Set(0)
+
+	persistedQueryService, err := persistedquery.NewPersistedQuery(loader, true, lru.New(10), testClock, time.Minute)
+	assert.Nil(t, err)
+
+	assertLoads := func(expected int32) {
+		assert.Eventually(t, func() bool {
+			persistedQueryService.Operations()
+
+			return atomic.LoadInt32(&loads) == expected
+		}, time.Second, time.Millisecond)
+
+		assert.Never(t, func() bool {
+			persistedQueryService.Operations()
+
+			return atomic.LoadInt32(&loads) != expected
+		}, 50*time.Millisecond, time.Millisecond)
+	}
+
+	testClock.Set(30)
+	assertLoads(1)
+
+	// failed reload is tried again after 1s, 2s, 4s...
+	testClock.Set(60)
+	assertLoads(2)
+
+	testClock.Set(60.5)
+	assertLoads(2)
+
+	testClock.Set(61)
+	assertLoads(3)
+
+	testClock.Set(62.5)
+	assertLoads(3)
+
+	testClock.Set(63)
+	assertLoads(4)
+}
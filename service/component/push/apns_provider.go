@@ -0,0 +1,229 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/coretrix/hitrix/service/component/clock"
+	"github.com/coretrix/hitrix/service/component/config"
+)
+
+const (
+	APNs = "apns"
+
+	apnsProductionEndpoint  = "https://api.push.apple.com"
+	apnsDevelopmentEndpoint = "https://api.sandbox.push.apple.com"
+	apnsMaxPayloadSize      = 4096
+	// Apple rejects provider tokens older than one hour and refreshing them more often than every 20 minutes
+	apnsTokenLifetime = 50 * time.Minute
+)
+
+// APNsProvider sends notifications directly to Apple over HTTP/2 using token based (.p8) authentication
+type APNsProvider struct {
+	KeyID        string
+	TeamID       string
+	Topic        string
+	Endpoint     string
+	PrivateKey   *ecdsa.PrivateKey
+	ClockService clock.IClock
+	Client       *http.Client
+
+	mutex          sync.Mutex
+	token          string
+	tokenCreatedAt time.Time
+}
+
+func NewAPNsProvider(configService config.IConfig, clockService clock.IClock) (IProvider, error) {
+	keyID, ok := configService.String("push.apns.key_id")
+	if !ok {
+		return nil, errors.New("missing push.apns.key_id")
+	}
+
+	teamID, ok := configService.String("push.apns.team_id")
+	if !ok {
+		return nil, errors.New("missing push.apns.team_id")
+	}
+
+	topic, ok := configService.String("push.apns.topic")
+	if !ok {
+		return nil, errors.New("missing push.apns.topic")
+	}
+
+	keyFile, ok := configService.String("push.apns.key_file")
+	if !ok {
+		return nil, errors.New("missing push.apns.key_file")
+	}
+
+	key, err := os.ReadFile(configService.GetFolderPath() + "/" + keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, err := jwt.ParseECPrivateKeyFromPEM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := apnsDevelopmentEndpoint
+	if production, _ := configService.Bool("push.apns.production"); production {
+		endpoint = apnsProductionEndpoint
+	}
+
+	return &APNsProvider{
+		KeyID:        keyID,
+		TeamID:       teamID,
+		Topic:        topic,
+		Endpoint:     endpoint,
+		PrivateKey:   privateKey,
+		ClockService: clockService,
+		Client:       &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (p *APNsProvider) GetName() string {
+	return APNs
+}
+
+func (p *APNsProvider) Send(ctx context.Context, token string, message *Message) (string, error) {
+	payload, err := p.getPayload(message)
+	if err != nil {
+		return "", err
+	}
+
+	authorizationToken, err := p.getAuthorizationToken()
+	if err != nil {
+		return "", err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, p.Endpoint+"/3/device/"+token, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+
+	request.Header.Set("authorization", "bearer "+authorizationToken)
+	request.Header.Set("apns-topic", p.Topic)
+	request.Header.Set("apns-push-type", "alert")
+	request.Header.Set("content-type", "application/json")
+
+	if message.CollapseKey != "" {
+		request.Header.Set("apns-collapse-id", message.CollapseKey)
+	}
+
+	if message.TTL > 0 {
+		request.Header.Set("apns-expiration", strconv.FormatInt(p.ClockService.Now().Add(message.TTL).Unix(), 10))
+	}
+
+	response, err := p.Client.Do(request)
+	if err != nil {
+		return "", err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusOK {
+		return response.Header.Get("apns-id"), nil
+	}
+
+	body, _ := io.ReadAll(response.Body)
+
+	apnsError := &struct {
+		Reason string `json:"reason"`
+	}{}
+
+	_ = json.Unmarshal(body, apnsError)
+
+	if response.StatusCode == http.StatusGone || apnsError.Reason == "BadDeviceToken" || apnsError.Reason == "Unregistered" {
+		return "", ErrTokenNotRegistered
+	}
+
+	return "", fmt.Errorf("apns error: status %d, reason %s", response.StatusCode, apnsError.Reason)
+}
+
+// SendDryRun validates the payload. APNs has no validate only endpoint so the token is not checked
+func (p *APNsProvider) SendDryRun(_ context.Context, token string, message *Message) (string, error) {
+	if token == "" {
+		return "", errors.New("apns: missing token")
+	}
+
+	if _, err := p.getPayload(message); err != nil {
+		return "", err
+	}
+
+	return "", nil
+}
+
+func (p *APNsProvider) getPayload(message *Message) ([]byte, error) {
+	aps := map[string]interface{}{
+		"alert": map[string]string{
+			"title": message.Title,
+			"body":  message.Body,
+		},
+	}
+
+	if message.Badge != nil {
+		aps["badge"] = *message.Badge
+	}
+
+	if message.Sound != "" {
+		aps["sound"] = message.Sound
+	}
+
+	payload := map[string]interface{}{}
+
+	for key, value := range message.Data {
+		payload[key] = value
+	}
+
+	payload["aps"] = aps
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(body) > apnsMaxPayloadSize {
+		return nil, fmt.Errorf("apns: payload size %d exceeds %d bytes", len(body), apnsMaxPayloadSize)
+	}
+
+	return body, nil
+}
+
+func (p *APNsProvider) getAuthorizationToken() (string, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := p.ClockService.Now()
+
+	if p.token != "" && now.Sub(p.tokenCreatedAt) < apnsTokenLifetime {
+		return p.token, nil
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": p.TeamID,
+		"iat": now.Unix(),
+	})
+
+	token.Header["kid"] = p.KeyID
+
+	signedToken, err := token.SignedString(p.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+
+	p.token = signedToken
+	p.tokenCreatedAt = now
+
+	return signedToken, nil
+}
@@ -0,0 +1,89 @@
+package push
+
+import (
+	"context"
+	"strconv"
+	"sync"
+)
+
+// SentMessage is a message recorded by FakeProvider
+type SentMessage struct {
+	Token   string
+	Message *Message
+	DryRun  bool
+}
+
+// FakeProvider records messages in memory instead of sending them. Use it in tests and local environment
+type FakeProvider struct {
+	Name string
+
+	mutex         sync.Mutex
+	sent          []*SentMessage
+	notRegistered map[string]bool
+}
+
+func NewFakeProvider(name string) *FakeProvider {
+	return &FakeProvider{Name: name, notRegistered: map[string]bool{}}
+}
+
+func (p *FakeProvider) GetName() string {
+	return p.Name
+}
+
+func (p *FakeProvider) Send(_ context.Context, token string, message *Message) (string, error) {
+	return p.record(token, message, false)
+}
+
+func (p *FakeProvider) SendDryRun(_ context.Context, token string, message *Message) (string, error) {
+	return p.record(token, message, true)
+}
+
+// MarkTokenNotRegistered makes next sends to the token fail with ErrTokenNotRegistered
+func (p *FakeProvider) MarkTokenNotRegistered(token string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.notRegistered[token] = true
+}
+
+// Sent returns all recorded messages in the order they were sent
+func (p *FakeProvider) Sent() []*SentMessage {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return append([]*SentMessage{}, p.sent...)
+}
+
+// SentTo returns messages sent to the token, dry run messages are skipped
+func (p *FakeProvider) SentTo(token string) []*Message {
+	messages := make([]*Message, 0)
+
+	for _, sentMessage := range p.Sent() {
+		if sentMessage.Token == token && !sentMessage.DryRun {
+			messages = append(messages, sentMessage.Message)
+		}
+	}
+
+	return messages
+}
+
+func (p *FakeProvider) Clear() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.sent = nil
+	p.notRegistered = map[string]bool{}
+}
+
+func (p *FakeProvider) record(token string, message *Message, dryRun bool) (string, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.notRegistered[token] {
+		return "", ErrTokenNotRegistered
+	}
+
+	p.sent = append(p.sent, &SentMessage{Token: token, Message: message, DryRun: dryRun})
+
+	return p.Name + "-" + strconv.Itoa(len(p.sent)), nil
+}
@@ -0,0 +1,83 @@
+package push
+
+import (
+	"context"
+
+	"firebase.google.com/go/messaging"
+
+	"github.com/coretrix/hitrix/service/component/fcm"
+)
+
+const FCM = "fcm"
+
+type FCMProvider struct {
+	FCMService fcm.FCM
+}
+
+func NewFCMProvider(fcmService fcm.FCM) *FCMProvider {
+	return &FCMProvider{FCMService: fcmService}
+}
+
+func (p *FCMProvider) GetName() string {
+	return FCM
+}
+
+func (p *FCMProvider) Send(ctx context.Context, token string, message *Message) (string, error) {
+	messageID, err := p.FCMService.Send(ctx, p.getMessage(token, message))
+
+	return messageID, p.mapError(err)
+}
+
+func (p *FCMProvider) SendDryRun(ctx context.Context, token string, message *Message) (string, error) {
+	messageID, err := p.FCMService.SendDryRun(ctx, p.getMessage(token, message))
+
+	return messageID, p.mapError(err)
+}
+
+func (p *FCMProvider) getMessage(token string, message *Message) *messaging.Message {
+	fcmMessage := &messaging.Message{
+		Token: token,
+		Data:  message.Data,
+		Notification: &messaging.Notification{
+			Title: message.Title,
+			Body:  message.Body,
+		},
+		Android: &messaging.AndroidConfig{
+			CollapseKey: message.CollapseKey,
+			Notification: &messaging.AndroidNotification{
+				Sound: message.Sound,
+			},
+		},
+		APNS: &messaging.APNSConfig{
+			Payload: &messaging.APNSPayload{
+				Aps: &messaging.Aps{
+					Badge: message.Badge,
+					Sound: message.Sound,
+				},
+			},
+		},
+	}
+
+	if message.TTL > 0 {
+		ttl := message.TTL
+		fcmMessage.Android.TTL = &ttl
+	}
+
+	if message.CollapseKey != "" {
+		fcmMessage.APNS.Headers = map[string]string{"apns-collapse-id": message.CollapseKey}
+	}
+
+	if message.Badge != nil {
+		fcmMessage.Android.Notification.NotificationCount = message.Badge
+	}
+
+	return fcmMessage
+}
+
+func (p *FCMProvider) mapError(err error) error {
+	if err != nil && messaging.IsRegistrationTokenNotRegistered(err) {
+		return ErrTokenNotRegistered
+	}
+
+	return err
+}
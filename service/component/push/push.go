@@ -0,0 +1,90 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/service/component/clock"
+	"github.com/coretrix/hitrix/service/component/config"
+)
+
+// ErrTokenNotRegistered is returned when the device token is expired or the app was uninstalled. The token should be removed
+var ErrTokenNotRegistered = errors.New("push: token not registered")
+
+type NewProviderFunc func(configService config.IConfig, clockService clock.IClock) (IProvider, error)
+
+// Message is the common message model. Providers map the fields to their own payload
+type Message struct {
+	Title       string
+	Body        string
+	Data        map[string]string
+	Badge       *int
+	Sound       string
+	CollapseKey string
+	// TTL is how long the message is stored if the device is offline. Zero means provider default
+	TTL time.Duration
+}
+
+type IProvider interface {
+	GetName() string
+	// Send returns the message id assigned by the provider
+	Send(ctx context.Context, token string, message *Message) (string, error)
+	// SendDryRun validates the message and the token without delivering it
+	SendDryRun(ctx context.Context, token string, message *Message) (string, error)
+}
+
+type IPush interface {
+	GetProvider(name string) IProvider
+	GetProviderForPlatform(platform string) (IProvider, error)
+}
+
+type Push struct {
+	Providers map[string]IProvider
+	// Platforms maps device platform to provider name
+	Platforms map[string]string
+}
+
+func NewPush(platforms map[string]string, providers ...IProvider) *Push {
+	p := &Push{
+		Providers: map[string]IProvider{},
+		Platforms: map[string]string{},
+	}
+
+	for _, provider := range providers {
+		p.Providers[provider.GetName()] = provider
+	}
+
+	p.Platforms[entity.DeviceTokenPlatformAndroid] = FCM
+	p.Platforms[entity.DeviceTokenPlatformIOS] = FCM
+	p.Platforms[entity.DeviceTokenPlatformWeb] = FCM
+
+	if _, ok := p.Providers[APNs]; ok {
+		p.Platforms[entity.DeviceTokenPlatformIOS] = APNs
+	}
+
+	if _, ok := p.Providers[WebPush]; ok {
+		p.Platforms[entity.DeviceTokenPlatformWeb] = WebPush
+	}
+
+	for platform, providerName := range platforms {
+		p.Platforms[platform] = providerName
+	}
+
+	return p
+}
+
+func (p *Push) GetProvider(name string) IProvider {
+	return p.Providers[name]
+}
+
+func (p *Push) GetProviderForPlatform(platform string) (IProvider, error) {
+	provider, ok := p.Providers[p.Platforms[platform]]
+	if !ok {
+		return nil, fmt.Errorf("push provider for platform %s not registered", platform)
+	}
+
+	return provider, nil
+}
@@ -0,0 +1,192 @@
+package push_test
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/coretrix/hitrix/pkg/entity"
+	clockMocks "github.com/coretrix/hitrix/service/component/clock/mocks"
+	"github.com/coretrix/hitrix/service/component/push"
+)
+
+func TestPushGetProviderForPlatform(t *testing.T) {
+	fcmProvider := push.NewFakeProvider(push.FCM)
+	apnsProvider := push.NewFakeProvider(push.APNs)
+
+	pushService := push.NewPush(nil, fcmProvider, apnsProvider)
+
+	provider, err := pushService.GetProviderForPlatform(entity.DeviceTokenPlatformIOS)
+	assert.Nil(t, err)
+	assert.Equal(t, push.APNs, provider.GetName())
+
+	provider, err = pushService.GetProviderForPlatform(entity.DeviceTokenPlatformWeb)
+	assert.Nil(t, err)
+	assert.Equal(t, push.FCM, provider.GetName())
+
+	pushService = push.NewPush(map[string]string{entity.DeviceTokenPlatformIOS: push.FCM}, fcmProvider, apnsProvider)
+
+	provider, err = pushService.GetProviderForPlatform(entity.DeviceTokenPlatformIOS)
+	assert.Nil(t, err)
+	assert.Equal(t, push.FCM, provider.GetName())
+
+	_, err = push.NewPush(nil, apnsProvider).GetProviderForPlatform(entity.DeviceTokenPlatformAndroid)
+	assert.NotNil(t, err)
+}
+
+func TestFakeProvider(t *testing.T) {
+	fakeProvider := push.NewFakeProvider(push.FCM)
+
+	_, err := fakeProvider.Send(context.Background(), "token-1", &push.Message{Title: "Hello"})
+	assert.Nil(t, err)
+
+	_, err = fakeProvider.SendDryRun(context.Background(), "token-1", &push.Message{Title: "Dry"})
+	assert.Nil(t, err)
+
+	assert.Len(t, fakeProvider.Sent(), 2)
+	assert.Len(t, fakeProvider.SentTo("token-1"), 1)
+	assert.Equal(t, "Hello", fakeProvider.SentTo("token-1")[0].Title)
+
+	fakeProvider.MarkTokenNotRegistered("token-1")
+
+	_, err = fakeProvider.Send(context.Background(), "token-1", &push.Message{Title: "Hello"})
+	assert.Equal(t, push.ErrTokenNotRegistered, err)
+}
+
+func TestAPNsProviderSend(t *testing.T) {
+	privateKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	fakeClock := &clockMocks.FakeSysClock{}
+	fakeClock.On("Now").Return(time.Unix(1700000000, 0))
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "HTTP/2.0", request.Proto)
+		assert.Equal(t, "com.example.app", request.Header.Get("apns-topic"))
+		assert.Equal(t, "chat", request.Header.Get("apns-collapse-id"))
+		assert.Contains(t, request.Header.Get("authorization"), "bearer ")
+
+		if request.URL.Path == "/3/device/dead" {
+			writer.WriteHeader(http.StatusGone)
+			_, _ = writer.Write([]byte(`{"reason":"Unregistered"}`))
+
+			return
+		}
+
+		payload := map[string]interface{}{}
+		_ = json.NewDecoder(request.Body).Decode(&payload)
+		assert.Equal(t, "value", payload["key"])
+		assert.Equal(t, float64(3), payload["aps"].(map[string]interface{})["badge"])
+
+		writer.Header().Set("apns-id", "apns-message-id")
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+
+	defer server.Close()
+
+	provider := &push.APNsProvider{
+		KeyID:        "key",
+		TeamID:       "team",
+		Topic:        "com.example.app",
+		Endpoint:     server.URL,
+		PrivateKey:   privateKey,
+		ClockService: fakeClock,
+		Client:       server.Client(),
+	}
+
+	badge := 3
+	message := &push.Message{Title: "Hello", Body: "World", Badge: &badge, CollapseKey: "chat", Data: map[string]string{"key": "value"}}
+
+	messageID, err := provider.Send(context.Background(), "token", message)
+	assert.Nil(t, err)
+	assert.Equal(t, "apns-message-id", messageID)
+
+	_, err = provider.Send(context.Background(), "dead", message)
+	assert.Equal(t, push.ErrTokenNotRegistered, err)
+}
+
+func TestWebPushProviderEncryptsPayload(t *testing.T) {
+	vapidPrivateKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	userAgentPrivateKey, userAgentX, userAgentY, _ := elliptic.GenerateKey(elliptic.P256(), rand.Reader)
+	userAgentPublicKey := elliptic.Marshal(elliptic.P256(), userAgentX, userAgentY)
+	authSecret := make([]byte, 16)
+	_, _ = rand.Read(authSecret)
+
+	fakeClock := &clockMocks.FakeSysClock{}
+	fakeClock.On("Now").Return(time.Unix(1700000000, 0))
+
+	var body []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "aes128gcm", request.Header.Get("Content-Encoding"))
+		assert.Equal(t, "60", request.Header.Get("TTL"))
+		assert.Contains(t, request.Header.Get("Authorization"), "vapid t=")
+
+		body, _ = io.ReadAll(request.Body)
+
+		writer.WriteHeader(http.StatusCreated)
+	}))
+
+	defer server.Close()
+
+	provider := &push.WebPushProvider{
+		Subject:      "mailto:push@example.com",
+		PublicKey:    base64.RawURLEncoding.EncodeToString(elliptic.Marshal(elliptic.P256(), vapidPrivateKey.X, vapidPrivateKey.Y)),
+		PrivateKey:   vapidPrivateKey,
+		ClockService: fakeClock,
+		Client:       server.Client(),
+	}
+
+	subscription := &push.Subscription{Endpoint: server.URL + "/push/1"}
+	subscription.Keys.P256dh = base64.RawURLEncoding.EncodeToString(userAgentPublicKey)
+	subscription.Keys.Auth = base64.RawURLEncoding.EncodeToString(authSecret)
+	token, _ := json.Marshal(subscription)
+
+	_, err := provider.Send(context.Background(), string(token), &push.Message{Title: "Hello", TTL: time.Minute})
+	assert.Nil(t, err)
+
+	// decrypt as the browser would do
+	salt := body[:16]
+	serverPublicKey := body[21 : 21+int(body[20])]
+	serverX, serverY := elliptic.Unmarshal(elliptic.P256(), serverPublicKey)
+	sharedX, _ := elliptic.P256().ScalarMult(serverX, serverY, userAgentPrivateKey)
+	sharedSecret := make([]byte, 32)
+	sharedX.FillBytes(sharedSecret)
+
+	keyInfo := append(append([]byte("WebPush: info\x00"), userAgentPublicKey...), serverPublicKey...)
+	ikm := make([]byte, 32)
+	_, _ = io.ReadFull(hkdf.New(sha256.New, sharedSecret, authSecret, keyInfo), ikm)
+
+	contentEncryptionKey := make([]byte, 16)
+	_, _ = io.ReadFull(hkdf.New(sha256.New, ikm, salt, []byte("Content-Encoding: aes128gcm\x00")), contentEncryptionKey)
+	nonce := make([]byte, 12)
+	_, _ = io.ReadFull(hkdf.New(sha256.New, ikm, salt, []byte("Content-Encoding: nonce\x00")), nonce)
+
+	block, _ := aes.NewCipher(contentEncryptionKey)
+	gcm, _ := cipher.NewGCM(block)
+
+	plaintext, err := gcm.Open(nil, nonce, body[21+len(serverPublicKey):], nil)
+	assert.Nil(t, err)
+	assert.Equal(t, byte(0x02), plaintext[len(plaintext)-1])
+
+	payload := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(plaintext[:len(plaintext)-1], &payload))
+	assert.Equal(t, "Hello", payload["title"])
+
+	_, err = provider.SendDryRun(context.Background(), "invalid", &push.Message{})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,300 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/coretrix/hitrix/service/component/clock"
+	"github.com/coretrix/hitrix/service/component/config"
+)
+
+const (
+	WebPush = "web_push"
+
+	webPushRecordSize = 4096
+	// one record minus header, padding delimiter and AEAD tag
+	webPushMaxPayloadSize = webPushRecordSize - 86 - 1 - 16
+	webPushDefaultTTL     = 4 * 7 * 24 * time.Hour
+	vapidTokenLifetime    = 12 * time.Hour
+)
+
+// Subscription is the browser PushSubscription serialized with JSON.stringify. It is used as device token
+type Subscription struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+// WebPushProvider sends notifications to browsers using VAPID (RFC 8292) and aes128gcm payload encryption (RFC 8291)
+type WebPushProvider struct {
+	Subject      string
+	PublicKey    string
+	PrivateKey   *ecdsa.PrivateKey
+	ClockService clock.IClock
+	Client       *http.Client
+}
+
+func NewWebPushProvider(configService config.IConfig, clockService clock.IClock) (IProvider, error) {
+	subject, ok := configService.String("push.web_push.subject")
+	if !ok {
+		return nil, errors.New("missing push.web_push.subject")
+	}
+
+	publicKey, ok := configService.String("push.web_push.vapid_public_key")
+	if !ok {
+		return nil, errors.New("missing push.web_push.vapid_public_key")
+	}
+
+	privateKey, ok := configService.String("push.web_push.vapid_private_key")
+	if !ok {
+		return nil, errors.New("missing push.web_push.vapid_private_key")
+	}
+
+	vapidPrivateKey, err := parseVAPIDKeys(publicKey, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebPushProvider{
+		Subject:      subject,
+		PublicKey:    publicKey,
+		PrivateKey:   vapidPrivateKey,
+		ClockService: clockService,
+		Client:       &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (p *WebPushProvider) GetName() string {
+	return WebPush
+}
+
+func (p *WebPushProvider) Send(ctx context.Context, token string, message *Message) (string, error) {
+	request, err := p.getRequest(ctx, token, message)
+	if err != nil {
+		return "", err
+	}
+
+	response, err := p.Client.Do(request)
+	if err != nil {
+		return "", err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusGone {
+		return "", ErrTokenNotRegistered
+	}
+
+	if response.StatusCode >= http.StatusBadRequest {
+		body, _ := io.ReadAll(response.Body)
+
+		return "", fmt.Errorf("web push error: status %d, %s", response.StatusCode, body)
+	}
+
+	return response.Header.Get("Location"), nil
+}
+
+// SendDryRun validates the subscription and encrypts the payload without sending it to the push service
+func (p *WebPushProvider) SendDryRun(ctx context.Context, token string, message *Message) (string, error) {
+	if _, err := p.getRequest(ctx, token, message); err != nil {
+		return "", err
+	}
+
+	return "", nil
+}
+
+func (p *WebPushProvider) getRequest(ctx context.Context, token string, message *Message) (*http.Request, error) {
+	subscription := &Subscription{}
+	if err := json.Unmarshal([]byte(token), subscription); err != nil {
+		return nil, fmt.Errorf("web push: invalid subscription: %w", err)
+	}
+
+	endpoint, err := url.Parse(subscription.Endpoint)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, errors.New("web push: invalid subscription endpoint")
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"title": message.Title,
+		"body":  message.Body,
+		"data":  message.Data,
+		"badge": message.Badge,
+		"sound": message.Sound,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payload) > webPushMaxPayloadSize {
+		return nil, fmt.Errorf("web push: payload size %d exceeds %d bytes", len(payload), webPushMaxPayloadSize)
+	}
+
+	body, err := encryptWebPushPayload(subscription, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	vapidToken, err := p.getVAPIDToken(endpoint.Scheme + "://" + endpoint.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := message.TTL
+	if ttl == 0 {
+		ttl = webPushDefaultTTL
+	}
+
+	request.Header.Set("Authorization", "vapid t="+vapidToken+", k="+p.PublicKey)
+	request.Header.Set("Content-Encoding", "aes128gcm")
+	request.Header.Set("Content-Type", "application/octet-stream")
+	request.Header.Set("TTL", strconv.Itoa(int(ttl.Seconds())))
+
+	if message.CollapseKey != "" {
+		request.Header.Set("Topic", message.CollapseKey)
+	}
+
+	return request, nil
+}
+
+func (p *WebPushProvider) getVAPIDToken(audience string) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": audience,
+		"exp": p.ClockService.Now().Add(vapidTokenLifetime).Unix(),
+		"sub": p.Subject,
+	})
+
+	return token.SignedString(p.PrivateKey)
+}
+
+func parseVAPIDKeys(publicKey, privateKey string) (*ecdsa.PrivateKey, error) {
+	publicKeyBytes, err := base64.RawURLEncoding.DecodeString(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid push.web_push.vapid_public_key: %w", err)
+	}
+
+	x, y := elliptic.Unmarshal(elliptic.P256(), publicKeyBytes)
+	if x == nil {
+		return nil, errors.New("invalid push.web_push.vapid_public_key")
+	}
+
+	privateKeyBytes, err := base64.RawURLEncoding.DecodeString(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid push.web_push.vapid_private_key: %w", err)
+	}
+
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y},
+		D:         new(big.Int).SetBytes(privateKeyBytes),
+	}, nil
+}
+
+func encryptWebPushPayload(subscription *Subscription, payload []byte) ([]byte, error) {
+	userAgentPublicKey, err := decodeBase64(subscription.Keys.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("web push: invalid p256dh key: %w", err)
+	}
+
+	authSecret, err := decodeBase64(subscription.Keys.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("web push: invalid auth secret: %w", err)
+	}
+
+	curve := elliptic.P256()
+
+	userAgentX, userAgentY := elliptic.Unmarshal(curve, userAgentPublicKey)
+	if userAgentX == nil {
+		return nil, errors.New("web push: invalid p256dh key")
+	}
+
+	serverPrivateKey, serverX, serverY, err := elliptic.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	serverPublicKey := elliptic.Marshal(curve, serverX, serverY)
+
+	sharedX, _ := curve.ScalarMult(userAgentX, userAgentY, serverPrivateKey)
+	sharedSecret := make([]byte, 32)
+	sharedX.FillBytes(sharedSecret)
+
+	keyInfo := append(append([]byte("WebPush: info\x00"), userAgentPublicKey...), serverPublicKey...)
+
+	ikm := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, sharedSecret, authSecret, keyInfo), ikm); err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	contentEncryptionKey := make([]byte, 16)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, ikm, salt, []byte("Content-Encoding: aes128gcm\x00")), contentEncryptionKey); err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, 12)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, ikm, salt, []byte("Content-Encoding: nonce\x00")), nonce); err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(contentEncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// 0x02 marks the last record
+	plaintext := append(append([]byte{}, payload...), 0x02)
+
+	header := make([]byte, 0, 16+4+1+len(serverPublicKey))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, webPushRecordSize)
+	header = append(header, byte(len(serverPublicKey)))
+	header = append(header, serverPublicKey...)
+
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+// browsers return keys in base64url but some clients send them padded or in standard encoding
+func decodeBase64(value string) ([]byte, error) {
+	for _, encoding := range []*base64.Encoding{base64.RawURLEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.StdEncoding} {
+		if decoded, err := encoding.DecodeString(value); err == nil {
+			return decoded, nil
+		}
+	}
+
+	return nil, errors.New("invalid base64 value")
+}
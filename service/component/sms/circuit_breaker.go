@@ -0,0 +1,91 @@
+package sms
+
+import (
+	"sync"
+	"time"
+
+	"github.com/coretrix/hitrix/service/component/clock"
+)
+
+const (
+	defaultCircuitBreakerThreshold = 5
+	defaultCircuitBreakerCooldown  = 60
+)
+
+// CircuitBreaker skips a provider for Cooldown after Threshold consecutive failures. After Cooldown one message
+// probes the provider, other messages skip it until the probe succeeds or fails
+type CircuitBreaker struct {
+	ClockService clock.IClock
+	Threshold    int
+	Cooldown     time.Duration
+
+	mutex     sync.Mutex
+	failures  map[string]int
+	openUntil map[string]time.Time
+	probes    map[string]time.Time
+}
+
+func NewCircuitBreaker(clockService clock.IClock, threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold <= 0 {
+		threshold = defaultCircuitBreakerThreshold
+	}
+
+	if cooldown <= 0 {
+		cooldown = defaultCircuitBreakerCooldown * time.Second
+	}
+
+	return &CircuitBreaker{
+		ClockService: clockService,
+		Threshold:    threshold,
+		Cooldown:     cooldown,
+		failures:     map[string]int{},
+		openUntil:    map[string]time.Time{},
+		probes:       map[string]time.Time{},
+	}
+}
+
+func (c *CircuitBreaker) IsOpen(providerName string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	openUntil, has := c.openUntil[providerName]
+	if !has {
+		return false
+	}
+
+	now := c.ClockService.Now()
+	if now.Before(openUntil) {
+		return true
+	}
+
+	// probe which did not report result within cooldown is replaced by a new one
+	if probeStarted, probing := c.probes[providerName]; probing && now.Before(probeStarted.Add(c.Cooldown)) {
+		return true
+	}
+
+	// cooldown passed, let this message probe the provider
+	c.probes[providerName] = now
+
+	return false
+}
+
+func (c *CircuitBreaker) Success(providerName string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.failures, providerName)
+	delete(c.openUntil, providerName)
+	delete(c.probes, providerName)
+}
+
+func (c *CircuitBreaker) Failure(providerName string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.failures[providerName]++
+	delete(c.probes, providerName)
+
+	if c.failures[providerName] >= c.Threshold {
+		c.openUntil[providerName] = c.ClockService.Now().Add(c.Cooldown)
+	}
+}
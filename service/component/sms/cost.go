@@ -0,0 +1,28 @@
+package sms
+
+import "strings"
+
+const DefaultCostPrefix = "default"
+
+// CostTable holds per-message cost by provider name and E.164 prefix.
+// DefaultCostPrefix is used when no prefix matches
+type CostTable map[string]map[string]float64
+
+func (c CostTable) Cost(providerName, number string) float64 {
+	prefixes, has := c[providerName]
+	if !has {
+		return 0
+	}
+
+	longestPrefix := ""
+	cost := prefixes[DefaultCostPrefix]
+
+	for prefix, prefixCost := range prefixes {
+		if len(prefix) > len(longestPrefix) && strings.HasPrefix(number, prefix) {
+			longestPrefix = prefix
+			cost = prefixCost
+		}
+	}
+
+	return cost
+}
@@ -0,0 +1,84 @@
+package sms
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/coretrix/hitrix/pkg/entity"
+)
+
+const (
+	DeliveryStatusDelivered = entity.SMSTrackerDeliveryStatusDelivered
+	DeliveryStatusFailed    = entity.SMSTrackerDeliveryStatusFailed
+	DeliveryStatusExpired   = entity.SMSTrackerDeliveryStatusExpired
+)
+
+// deliveryReceiptMaxAge is how far signed callback timestamp can be from now, older callbacks are treated as replayed
+const deliveryReceiptMaxAge = 5 * time.Minute
+
+var (
+	ErrInvalidDeliveryReceiptSignature = errors.New("invalid delivery receipt signature")
+	ErrExpiredDeliveryReceipt          = errors.New("expired delivery receipt")
+)
+
+type DeliveryReceipt struct {
+	ProviderMessageID string
+	// Status is one of DeliveryStatusDelivered, DeliveryStatusFailed or DeliveryStatusExpired.
+	// Empty status means intermediate state (queued, sent to carrier...) which is ignored
+	Status         string
+	ProviderStatus string
+}
+
+// IDeliveryReceiptProvider is implemented by providers which can report the delivery status.
+// ParseDeliveryReceipts must verify the request signature before parsing the body
+type IDeliveryReceiptProvider interface {
+	IProvider
+	ParseDeliveryReceipts(request *http.Request) ([]*DeliveryReceipt, error)
+}
+
+func readRequestBody(request *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+func verifyToken(expected, actual string) error {
+	if expected == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(actual)) != 1 {
+		return ErrInvalidDeliveryReceiptSignature
+	}
+
+	return nil
+}
+
+func verifyHMAC(expected, actual []byte) error {
+	if len(actual) == 0 || !hmac.Equal(expected, actual) {
+		return ErrInvalidDeliveryReceiptSignature
+	}
+
+	return nil
+}
+
+func verifyTimestamp(now time.Time, unixTimestamp string) error {
+	seconds, err := strconv.ParseInt(unixTimestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidDeliveryReceiptSignature
+	}
+
+	age := now.Sub(time.Unix(seconds, 0))
+	if age > deliveryReceiptMaxAge || age < -deliveryReceiptMaxAge {
+		return ErrExpiredDeliveryReceipt
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package sms_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	clockMocks "github.com/coretrix/hitrix/service/component/clock/mocks"
+	"github.com/coretrix/hitrix/service/component/sms"
+)
+
+func TestLinkMobilityParseDeliveryReceipts(t *testing.T) {
+	provider := &sms.LinkMobilityProvider{Secret: "secret"}
+	body := `[{"sms_id":"1","status":"DELIVERED"},{"sms_id":"2","status":"rejected"},{"sms_id":"3","status":"expired"},{"sms_id":"4","status":"queued"}]`
+
+	mac := hmac.New(sha512.New, []byte("secret"))
+	mac.Write([]byte(body))
+
+	request := httptest.NewRequest(http.MethodPost, "/sms/delivery-receipt/link_mobility", strings.NewReader(body))
+	request.Header.Set("x-api-sign", hex.EncodeToString(mac.Sum(nil)))
+
+	deliveryReceipts, err := provider.ParseDeliveryReceipts(request)
+	assert.Nil(t, err)
+	assert.Equal(t, []*sms.DeliveryReceipt{
+		{ProviderMessageID: "1", Status: sms.DeliveryStatusDelivered, ProviderStatus: "DELIVERED"},
+		{ProviderMessageID: "2", Status: sms.DeliveryStatusFailed, ProviderStatus: "rejected"},
+		{ProviderMessageID: "3", Status: sms.DeliveryStatusExpired, ProviderStatus: "expired"},
+		{ProviderMessageID: "4", Status: "", ProviderStatus: "queued"},
+	}, deliveryReceipts)
+
+	request = httptest.NewRequest(http.MethodPost, "/sms/delivery-receipt/link_mobility", strings.NewReader(body))
+	request.Header.Set("x-api-sign", hex.EncodeToString([]byte("wrong")))
+
+	_, err = provider.ParseDeliveryReceipts(request)
+	assert.ErrorIs(t, err, sms.ErrInvalidDeliveryReceiptSignature)
+
+	request = httptest.NewRequest(http.MethodPost, "/sms/delivery-receipt/link_mobility", strings.NewReader(body))
+
+	_, err = provider.ParseDeliveryReceipts(request)
+	assert.ErrorIs(t, err, sms.ErrInvalidDeliveryReceiptSignature)
+}
+
+func TestSinchParseDeliveryReceipts(t *testing.T) {
+	fakeClock := &clockMocks.FakeSysClock{}
+	fakeClock.On("Now").Return(time.Unix(1660000000, 0).Add(time.Minute)).Once()
+	fakeClock.On("Now").Return(time.Unix(1660000000, 0).Add(10 * time.Minute))
+
+	provider := &sms.SinchProvider{WebhookSecret: "secret", Clock: fakeClock}
+	body := `{"batch_id":"batch","message_id":"","status":"Aborted"}`
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(body + ".nonce.1660000000"))
+
+	newRequest := func(signature string) *http.Request {
+		request := httptest.NewRequest(http.MethodPost, "/sms/delivery-receipt/sinch", strings.NewReader(body))
+		request.Header.Set("x-sinch-webhook-signature-nonce", "nonce")
+		request.Header.Set("x-sinch-webhook-signature-timestamp", "1660000000")
+		request.Header.Set("x-sinch-webhook-signature", signature)
+
+		return request
+	}
+
+	deliveryReceipts, err := provider.ParseDeliveryReceipts(newRequest(base64.StdEncoding.EncodeToString(mac.Sum(nil))))
+	assert.Nil(t, err)
+	assert.Equal(t, []*sms.DeliveryReceipt{
+		{ProviderMessageID: "batch", Status: sms.DeliveryStatusFailed, ProviderStatus: "Aborted"},
+	}, deliveryReceipts)
+
+	_, err = provider.ParseDeliveryReceipts(newRequest(base64.StdEncoding.EncodeToString([]byte("wrong"))))
+	assert.ErrorIs(t, err, sms.ErrInvalidDeliveryReceiptSignature)
+
+	// same signed callback replayed later
+	_, err = provider.ParseDeliveryReceipts(newRequest(base64.StdEncoding.EncodeToString(mac.Sum(nil))))
+	assert.ErrorIs(t, err, sms.ErrExpiredDeliveryReceipt)
+
+	_, err = (&sms.SinchProvider{}).ParseDeliveryReceipts(newRequest(""))
+	assert.EqualError(t, err, "missing sms.sinch.webhook_secret")
+}
+
+func TestKavenegarParseDeliveryReceipts(t *testing.T) {
+	provider := &sms.KavenegarProvider{DeliveryReceiptToken: "token"}
+
+	newRequest := func(token, status string) *http.Request {
+		request := httptest.NewRequest(
+			http.MethodPost,
+			"/sms/delivery-receipt/kavenegar?token="+token,
+			strings.NewReader(url.Values{"messageid": {"12"}, "status": {status}}.Encode()),
+		)
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		return request
+	}
+
+	deliveryReceipts, err := provider.ParseDeliveryReceipts(newRequest("token", "10"))
+	assert.Nil(t, err)
+	assert.Equal(t, []*sms.DeliveryReceipt{{ProviderMessageID: "12", Status: sms.DeliveryStatusDelivered, ProviderStatus: "10"}}, deliveryReceipts)
+
+	deliveryReceipts, err = provider.ParseDeliveryReceipts(newRequest("token", "11"))
+	assert.Nil(t, err)
+	assert.Equal(t, sms.DeliveryStatusFailed, deliveryReceipts[0].Status)
+
+	deliveryReceipts, err = provider.ParseDeliveryReceipts(newRequest("token", "1"))
+	assert.Nil(t, err)
+	assert.Equal(t, "", deliveryReceipts[0].Status)
+
+	_, err = provider.ParseDeliveryReceipts(newRequest("wrong", "10"))
+	assert.ErrorIs(t, err, sms.ErrInvalidDeliveryReceiptSignature)
+
+	_, err = (&sms.KavenegarProvider{}).ParseDeliveryReceipts(newRequest("", "10"))
+	assert.ErrorIs(t, err, sms.ErrInvalidDeliveryReceiptSignature)
+}
+
+func TestMobicaParseDeliveryReceipts(t *testing.T) {
+	provider := &sms.MobicaProvider{DeliveryReceiptToken: "token"}
+
+	newRequest := func(token, status string) *http.Request {
+		return httptest.NewRequest(
+			http.MethodPost,
+			"/sms/delivery-receipt/mobica?token="+token,
+			strings.NewReader(`{"id":55,"status":"`+status+`"}`),
+		)
+	}
+
+	deliveryReceipts, err := provider.ParseDeliveryReceipts(newRequest("token", "Delivered"))
+	assert.Nil(t, err)
+	assert.Equal(t, []*sms.DeliveryReceipt{{ProviderMessageID: "55", Status: sms.DeliveryStatusDelivered, ProviderStatus: "Delivered"}}, deliveryReceipts)
+
+	deliveryReceipts, err = provider.ParseDeliveryReceipts(newRequest("token", "undelivered"))
+	assert.Nil(t, err)
+	assert.Equal(t, sms.DeliveryStatusFailed, deliveryReceipts[0].Status)
+
+	deliveryReceipts, err = provider.ParseDeliveryReceipts(newRequest("token", "expired"))
+	assert.Nil(t, err)
+	assert.Equal(t, sms.DeliveryStatusExpired, deliveryReceipts[0].Status)
+
+	_, err = provider.ParseDeliveryReceipts(newRequest("wrong", "Delivered"))
+	assert.ErrorIs(t, err, sms.ErrInvalidDeliveryReceiptSignature)
+}
+
+func TestTwilioParseDeliveryReceipts(t *testing.T) {
+	callbackURL := "https://api.example.com/sms/delivery-receipt/twilio"
+	provider := &sms.TwilioProvider{Token: "token", StatusCallbackURL: callbackURL}
+
+	newRequest := func(status, signature string) *http.Request {
+		form := url.Values{"MessageSid": {"SM1"}, "MessageStatus": {status}}
+
+		request := httptest.NewRequest(http.MethodPost, "/sms/delivery-receipt/twilio", strings.NewReader(form.Encode()))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request.Header.Set("X-Twilio-Signature", signature)
+
+		return request
+	}
+
+	sign := func(status string) string {
+		mac := hmac.New(sha1.New, []byte("token"))
+		mac.Write([]byte(callbackURL + "MessageSidSM1MessageStatus" + status))
+
+		return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+
+	deliveryReceipts, err := provider.ParseDeliveryReceipts(newRequest("delivered", sign("delivered")))
+	assert.Nil(t, err)
+	assert.Equal(t, []*sms.DeliveryReceipt{{ProviderMessageID: "SM1", Status: sms.DeliveryStatusDelivered, ProviderStatus: "delivered"}}, deliveryReceipts)
+
+	deliveryReceipts, err = provider.ParseDeliveryReceipts(newRequest("undelivered", sign("undelivered")))
+	assert.Nil(t, err)
+	assert.Equal(t, sms.DeliveryStatusFailed, deliveryReceipts[0].Status)
+
+	deliveryReceipts, err = provider.ParseDeliveryReceipts(newRequest("canceled", sign("canceled")))
+	assert.Nil(t, err)
+	assert.Equal(t, sms.DeliveryStatusFailed, deliveryReceipts[0].Status)
+
+	_, err = provider.ParseDeliveryReceipts(newRequest("delivered", sign("failed")))
+	assert.ErrorIs(t, err, sms.ErrInvalidDeliveryReceiptSignature)
+
+	_, err = (&sms.TwilioProvider{Token: "token"}).ParseDeliveryReceipts(newRequest("delivered", sign("delivered")))
+	assert.EqualError(t, err, "missing sms.twilio.status_callback_url")
+}
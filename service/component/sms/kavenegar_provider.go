@@ -3,6 +3,8 @@ package sms
 import (
 	"errors"
 	"fmt"
+	"net/http"
+	"strconv"
 
 	"github.com/kavenegar/kavenegar-go"
 
@@ -13,8 +15,9 @@ import (
 const Kavenegar = "kavenegar"
 
 type KavenegarProvider struct {
-	APIKey string
-	Sender string
+	APIKey               string
+	Sender               string
+	DeliveryReceiptToken string
 }
 
 func NewKavenegarProvider(configService config.IConfig, _ clock.IClock) (IProvider, error) {
@@ -31,9 +34,12 @@ func NewKavenegarProvider(configService config.IConfig, _ clock.IClock) (IProvid
 		return nil, errors.New("missing sms.kavenegar.sender")
 	}
 
+	deliveryReceiptToken, _ := configService.String("sms.kavenegar.delivery_receipt_token")
+
 	return &KavenegarProvider{
-		APIKey: apiKey,
-		Sender: sender,
+		APIKey:               apiKey,
+		Sender:               sender,
+		DeliveryReceiptToken: deliveryReceiptToken,
 	}, nil
 }
 
@@ -41,19 +47,50 @@ func (g *KavenegarProvider) GetName() string {
 	return Kavenegar
 }
 
-func (g *KavenegarProvider) SendSMSMessage(message *Message) (string, error) {
+func (g *KavenegarProvider) SendSMSMessage(message *Message) (string, string, error) {
 	api := kavenegar.New(g.APIKey)
 
 	res, err := api.Message.Send(g.Sender, []string{message.Number}, message.Text, nil)
 	if err != nil {
-		return err.Error(), err
+		return err.Error(), "", err
 	}
 
 	if len(res) < 1 {
 		e := fmt.Errorf("there was a problem sending sms")
 
-		return e.Error(), e
+		return e.Error(), "", e
+	}
+
+	return res[0].StatusText, strconv.Itoa(res[0].MessageID), nil
+}
+
+// ParseDeliveryReceipts handles kavenegar delivery callback.
+// Kavenegar does not sign callbacks so the callback url must contain ?token=sms.kavenegar.delivery_receipt_token
+func (g *KavenegarProvider) ParseDeliveryReceipts(request *http.Request) ([]*DeliveryReceipt, error) {
+	if err := verifyToken(g.DeliveryReceiptToken, request.URL.Query().Get("token")); err != nil {
+		return nil, err
+	}
+
+	if err := request.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	providerStatus := request.Form.Get("status")
+
+	var status string
+
+	switch providerStatus {
+	case "10":
+		status = DeliveryStatusDelivered
+	case "6", "11", "13", "14", "100":
+		status = DeliveryStatusFailed
 	}
 
-	return res[0].StatusText, nil
+	return []*DeliveryReceipt{
+		{
+			ProviderMessageID: request.Form.Get("messageid"),
+			Status:            status,
+			ProviderStatus:    providerStatus,
+		},
+	}, nil
 }
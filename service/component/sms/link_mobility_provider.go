@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/coretrix/hitrix/pkg/helper"
@@ -77,7 +78,7 @@ type linkMobilityMsg struct {
 	ServiceID string `json:"service_id"`
 }
 
-func (g *LinkMobilityProvider) SendSMSMessage(message *Message) (string, error) {
+func (g *LinkMobilityProvider) SendSMSMessage(message *Message) (string, string, error) {
 	row := &linkMobilityMsg{
 		ServiceID: strconv.Itoa(g.Service),
 		From:      strconv.Itoa(g.Shortcode),
@@ -98,29 +99,81 @@ func (g *LinkMobilityProvider) SendSMSMessage(message *Message) (string, error)
 		nil)
 
 	if err != nil {
-		return failure, err
+		return failure, "", err
 	}
 
 	if code != http.StatusOK {
-		return failure, fmt.Errorf("expected status code OK, but got %v Response: %s", code, string(responseBody))
+		return failure, "", fmt.Errorf("expected status code OK, but got %v Response: %s", code, string(responseBody))
 	}
 
 	responseBodyJSON := &struct {
 		Meta struct {
 			Code int `json:"code"`
 		} `json:"meta"`
+		Data []struct {
+			SmsID string `json:"sms_id"`
+		} `json:"data"`
 	}{}
 
 	err = json.Unmarshal(responseBody, responseBodyJSON)
 	if err != nil {
-		return failure, fmt.Errorf("cannot unmarshal response Response: %s", string(responseBody))
+		return failure, "", fmt.Errorf("cannot unmarshal response Response: %s", string(responseBody))
 	}
 
 	if responseBodyJSON.Meta.Code != 200 {
-		return failure, fmt.Errorf("unexpected status code Response: %s", string(responseBody))
+		return failure, "", fmt.Errorf("unexpected status code Response: %s", string(responseBody))
 	}
 
-	return success, nil
+	if len(responseBodyJSON.Data) == 0 {
+		return success, "", nil
+	}
+
+	return success, responseBodyJSON.Data[0].SmsID, nil
+}
+
+// ParseDeliveryReceipts handles link mobility delivery reports signed the same way as outgoing requests
+func (g *LinkMobilityProvider) ParseDeliveryReceipts(request *http.Request) ([]*DeliveryReceipt, error) {
+	body, err := readRequestBody(request)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, _ := hex.DecodeString(request.Header.Get("x-api-sign"))
+	if err := verifyHMAC(genHMAC512(body, []byte(g.Secret)), signature); err != nil {
+		return nil, err
+	}
+
+	reports := make([]*struct {
+		SmsID  string `json:"sms_id"`
+		Status string `json:"status"`
+	}, 0)
+
+	if err := json.Unmarshal(body, &reports); err != nil {
+		return nil, err
+	}
+
+	deliveryReceipts := make([]*DeliveryReceipt, 0, len(reports))
+
+	for _, report := range reports {
+		var status string
+
+		switch strings.ToLower(report.Status) {
+		case "delivered":
+			status = DeliveryStatusDelivered
+		case "undelivered", "failed", "rejected":
+			status = DeliveryStatusFailed
+		case "expired":
+			status = DeliveryStatusExpired
+		}
+
+		deliveryReceipts = append(deliveryReceipts, &DeliveryReceipt{
+			ProviderMessageID: report.SmsID,
+			Status:            status,
+			ProviderStatus:    report.Status,
+		})
+	}
+
+	return deliveryReceipts, nil
 }
 
 func (g *LinkMobilityProvider) getHeaders(body []*linkMobilityMsg) map[string]string {
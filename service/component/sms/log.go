@@ -17,6 +17,10 @@ type LogEntity interface {
 	SetFromSecondaryProvider(string)
 	SetPrimaryProviderError(string)
 	SetSecondaryProviderError(string)
+	SetSentByProvider(string)
+	SetProviderErrors(string)
+	SetCost(float64)
+	SetProviderMessageID(string)
 	SetType(string)
 	SetSentAt(time time.Time)
 }
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/coretrix/hitrix/pkg/helper"
@@ -16,11 +17,12 @@ import (
 const Mobica = "mobica"
 
 type MobicaProvider struct {
-	Email    string
-	Password string
-	Route    string
-	From     string
-	Endpoint string
+	Email                string
+	Password             string
+	Route                string
+	From                 string
+	Endpoint             string
+	DeliveryReceiptToken string
 }
 
 func NewMobicaProvider(configService config.IConfig, _ clock.IClock) (IProvider, error) {
@@ -43,12 +45,15 @@ func NewMobicaProvider(configService config.IConfig, _ clock.IClock) (IProvider,
 		return nil, errors.New("missing sms.mobica.endpoint")
 	}
 
+	deliveryReceiptToken, _ := configService.String("sms.mobica.delivery_receipt_token")
+
 	return &MobicaProvider{
-		Email:    email,
-		Password: password,
-		Route:    route,
-		From:     from,
-		Endpoint: endpoint,
+		Email:                email,
+		Password:             password,
+		Route:                route,
+		From:                 from,
+		Endpoint:             endpoint,
+		DeliveryReceiptToken: deliveryReceiptToken,
 	}, nil
 }
 
@@ -74,7 +79,7 @@ type mobicaMsg struct {
 	Pass  string `json:"pass"`
 }
 
-func (g *MobicaProvider) SendSMSMessage(message *Message) (string, error) {
+func (g *MobicaProvider) SendSMSMessage(message *Message) (string, string, error) {
 	body := &mobicaMsg{
 		Phone: message.Number,
 		Sms: sms{
@@ -98,28 +103,70 @@ func (g *MobicaProvider) SendSMSMessage(message *Message) (string, error) {
 		nil)
 
 	if err != nil {
-		return failure, err
+		return failure, "", err
 	}
 
 	if code != http.StatusOK {
-		return failure, fmt.Errorf("expected status code OK, but got %v Response: %s", code, string(responseBody))
+		return failure, "", fmt.Errorf("expected status code OK, but got %v Response: %s", code, string(responseBody))
 	}
 
 	responseBodyJSON := &struct {
-		Status int    `json:"status"`
-		Desc   string `json:"desc"`
+		Status int         `json:"status"`
+		Desc   string      `json:"desc"`
+		ID     json.Number `json:"id"`
 	}{}
 
 	err = json.Unmarshal(responseBody, responseBodyJSON)
 	if err != nil {
-		return failure, fmt.Errorf("cannot unmarshal response Response: %s", string(responseBody))
+		return failure, "", fmt.Errorf("cannot unmarshal response Response: %s", string(responseBody))
 	}
 
 	if responseBodyJSON.Status != 1004 {
-		return failure, fmt.Errorf("unexpected status code Response: %s", string(responseBody))
+		return failure, "", fmt.Errorf("unexpected status code Response: %s", string(responseBody))
+	}
+
+	return success, responseBodyJSON.ID.String(), nil
+}
+
+// ParseDeliveryReceipts handles mobica delivery callback.
+// Mobica does not sign callbacks so the callback url must contain ?token=sms.mobica.delivery_receipt_token
+func (g *MobicaProvider) ParseDeliveryReceipts(request *http.Request) ([]*DeliveryReceipt, error) {
+	if err := verifyToken(g.DeliveryReceiptToken, request.URL.Query().Get("token")); err != nil {
+		return nil, err
 	}
 
-	return success, nil
+	body, err := readRequestBody(request)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &struct {
+		ID     json.Number `json:"id"`
+		Status string      `json:"status"`
+	}{}
+
+	if err := json.Unmarshal(body, report); err != nil {
+		return nil, err
+	}
+
+	var status string
+
+	switch strings.ToLower(report.Status) {
+	case "delivered":
+		status = DeliveryStatusDelivered
+	case "undelivered", "failed", "rejected":
+		status = DeliveryStatusFailed
+	case "expired":
+		status = DeliveryStatusExpired
+	}
+
+	return []*DeliveryReceipt{
+		{
+			ProviderMessageID: report.ID.String(),
+			Status:            status,
+			ProviderStatus:    report.Status,
+		},
+	}, nil
 }
 
 func (g *MobicaProvider) getHeaders() map[string]string {
@@ -1,6 +1,8 @@
 package mocks
 
 import (
+	"net/http"
+
 	"github.com/stretchr/testify/mock"
 
 	"github.com/coretrix/hitrix/datalayer"
@@ -14,3 +16,7 @@ type FakeSMSSender struct {
 func (f *FakeSMSSender) SendMessage(_ *datalayer.ORM, message *sms.Message) error {
 	return f.Called(message).Error(0)
 }
+
+func (f *FakeSMSSender) HandleDeliveryReceipt(_ *datalayer.ORM, providerName string, request *http.Request) error {
+	return f.Called(providerName, request).Error(0)
+}
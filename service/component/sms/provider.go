@@ -6,6 +6,9 @@ import (
 )
 
 const (
+	// Success is the status which providers return when the message is sent
+	Success = success
+
 	success = "sent successfully"
 	failure = "sent unsuccessfully"
 
@@ -15,7 +18,9 @@ const (
 type NewProviderFunc func(configService config.IConfig, clockService clock.IClock) (IProvider, error)
 
 type IProvider interface {
-	SendSMSMessage(msg *Message) (string, error)
+	// SendSMSMessage returns status and id of the message assigned by the provider, the id is empty when the provider
+	// does not return it
+	SendSMSMessage(msg *Message) (status string, providerMessageID string, err error)
 	GetName() string
 }
 
@@ -0,0 +1,52 @@
+package sms
+
+import (
+	"sort"
+	"strings"
+)
+
+// Route sends numbers starting with one of Prefixes (E.164, e.g. "+98") through Providers in order
+type Route struct {
+	Prefixes  []string
+	Providers []string
+}
+
+type Router struct {
+	prefixes []string
+	routes   map[string][]string
+}
+
+func NewRouter(routes ...*Route) *Router {
+	router := &Router{
+		prefixes: make([]string, 0),
+		routes:   map[string][]string{},
+	}
+
+	for _, route := range routes {
+		for _, prefix := range route.Prefixes {
+			if _, has := router.routes[prefix]; has {
+				panic("sms route duplicated for prefix: " + prefix)
+			}
+
+			router.routes[prefix] = route.Providers
+			router.prefixes = append(router.prefixes, prefix)
+		}
+	}
+
+	// longest prefix wins, so "+4420" is checked before "+44"
+	sort.SliceStable(router.prefixes, func(i, j int) bool {
+		return len(router.prefixes[i]) > len(router.prefixes[j])
+	})
+
+	return router
+}
+
+func (r *Router) Match(number string) ([]string, bool) {
+	for _, prefix := range r.prefixes {
+		if strings.HasPrefix(number, prefix) {
+			return r.routes[prefix], true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,77 @@
+package sms_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	clockMocks "github.com/coretrix/hitrix/service/component/clock/mocks"
+	"github.com/coretrix/hitrix/service/component/sms"
+)
+
+func TestRouterMatchesLongestPrefix(t *testing.T) {
+	router := sms.NewRouter(
+		&sms.Route{Prefixes: []string{"+98"}, Providers: []string{sms.Kavenegar}},
+		&sms.Route{Prefixes: []string{"+4"}, Providers: []string{sms.LinkMobility, sms.Twilio}},
+		&sms.Route{Prefixes: []string{"+44"}, Providers: []string{sms.Twilio}},
+	)
+
+	providers, ok := router.Match("+989121234567")
+	assert.True(t, ok)
+	assert.Equal(t, []string{sms.Kavenegar}, providers)
+
+	providers, ok = router.Match("+4512345678")
+	assert.True(t, ok)
+	assert.Equal(t, []string{sms.LinkMobility, sms.Twilio}, providers)
+
+	providers, ok = router.Match("+447700900123")
+	assert.True(t, ok)
+	assert.Equal(t, []string{sms.Twilio}, providers)
+
+	_, ok = router.Match("+15555555555")
+	assert.False(t, ok)
+}
+
+func TestCostTable(t *testing.T) {
+	costTable := sms.CostTable{
+		sms.Twilio: {sms.DefaultCostPrefix: 0.0079, "+98": 0.05, "+989": 0.07},
+	}
+
+	assert.Equal(t, 0.0079, costTable.Cost(sms.Twilio, "+15555555555"))
+	assert.Equal(t, 0.05, costTable.Cost(sms.Twilio, "+981234"))
+	assert.Equal(t, 0.07, costTable.Cost(sms.Twilio, "+989121234567"))
+	assert.Equal(t, 0.0, costTable.Cost(sms.Kavenegar, "+989121234567"))
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	fakeClock := &clockMocks.FakeSysClock{}
+	fakeClock.On("Now").Return(now).Times(2)
+
+	circuitBreaker := sms.NewCircuitBreaker(fakeClock, 2, time.Minute)
+
+	circuitBreaker.Failure(sms.Twilio)
+	assert.False(t, circuitBreaker.IsOpen(sms.Twilio))
+
+	circuitBreaker.Failure(sms.Twilio)
+	assert.True(t, circuitBreaker.IsOpen(sms.Twilio))
+
+	fakeClock.On("Now").Return(now.Add(2 * time.Minute)).Times(4)
+
+	// half open, only one message probes the provider
+	assert.False(t, circuitBreaker.IsOpen(sms.Twilio))
+	assert.True(t, circuitBreaker.IsOpen(sms.Twilio))
+
+	// failed probe opens it again
+	circuitBreaker.Failure(sms.Twilio)
+	assert.True(t, circuitBreaker.IsOpen(sms.Twilio))
+
+	fakeClock.On("Now").Return(now.Add(4 * time.Minute))
+	assert.False(t, circuitBreaker.IsOpen(sms.Twilio))
+	assert.True(t, circuitBreaker.IsOpen(sms.Twilio))
+
+	circuitBreaker.Success(sms.Twilio)
+	assert.False(t, circuitBreaker.IsOpen(sms.Twilio))
+	assert.False(t, circuitBreaker.IsOpen(sms.Twilio))
+}
@@ -2,6 +2,10 @@ package sms
 
 import (
 	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/latolukasz/beeorm/v2"
 
 	"github.com/coretrix/hitrix/datalayer"
 	"github.com/coretrix/hitrix/pkg/entity"
@@ -12,6 +16,7 @@ import (
 
 type ISender interface {
 	SendMessage(ormService *datalayer.ORM, message *Message) error
+	HandleDeliveryReceipt(ormService *datalayer.ORM, providerName string, request *http.Request) error
 }
 
 type Sender struct {
@@ -20,64 +25,80 @@ type Sender struct {
 	ErrorLoggerService errorlogger.ErrorLogger
 	PrimaryProvider    IProvider
 	SecondaryProvider  IProvider
+	Providers          []IProvider
+	Router             *Router
+	CircuitBreaker     *CircuitBreaker
+	CostTable          CostTable
 }
 
 func (s *Sender) SendMessage(ormService *datalayer.ORM, message *Message) error {
-	var primaryProvider IProvider
-	var secondaryProvider IProvider
-
-	if message.Provider != nil {
-		primaryProvider = message.Provider.Primary
-		secondaryProvider = message.Provider.Secondary
-	} else {
-		primaryProvider = s.PrimaryProvider
-		secondaryProvider = s.SecondaryProvider
-	}
-
-	if primaryProvider == nil {
-		return fmt.Errorf("primary provider not supported")
+	providers, err := s.getProviderChain(message)
+	if err != nil {
+		return err
 	}
 
 	smsTrackerEntity := &entity.SmsTrackerEntity{}
 	smsTrackerEntity.SetTo(message.Number)
 	smsTrackerEntity.SetType(entity.SMSTrackerTypeSMS)
 	smsTrackerEntity.SetText(message.Text)
-	smsTrackerEntity.SetFromPrimaryProvider(primaryProvider.GetName())
+	smsTrackerEntity.SetFromPrimaryProvider(providers[0].GetName())
 	smsTrackerEntity.SetSentAt(s.ClockService.Now())
 
-	trySecondaryProvider := false
-
 	sandBoxMode, _ := s.ConfigService.Bool("sms.sandbox_mode")
 
-	var status string
-	var err error
-
-	if !sandBoxMode {
-		status, err = primaryProvider.SendSMSMessage(message)
-		if err != nil {
-			trySecondaryProvider = true
+	status := failure
+	providerErrors := make([]string, 0)
 
-			smsTrackerEntity.SetPrimaryProviderError(err.Error())
-			s.ErrorLoggerService.LogError(err)
-		}
-	} else {
+	if sandBoxMode {
 		status = success
-	}
+		smsTrackerEntity.SetSentByProvider(providers[0].GetName())
+	} else {
+		attempt := 0
 
-	if trySecondaryProvider {
-		if secondaryProvider == nil {
-			return fmt.Errorf("secondary provider not supported")
-		}
+		for _, provider := range providers {
+			if s.CircuitBreaker != nil && s.CircuitBreaker.IsOpen(provider.GetName()) {
+				providerErrors = append(providerErrors, provider.GetName()+": circuit open")
+
+				continue
+			}
+
+			if attempt == 1 {
+				smsTrackerEntity.SetFromSecondaryProvider(provider.GetName())
+			}
+
+			var providerMessageID string
+
+			status, providerMessageID, err = provider.SendSMSMessage(message)
+			if err == nil {
+				if s.CircuitBreaker != nil {
+					s.CircuitBreaker.Success(provider.GetName())
+				}
+
+				smsTrackerEntity.SetSentByProvider(provider.GetName())
+				smsTrackerEntity.SetProviderMessageID(providerMessageID)
+				smsTrackerEntity.SetCost(s.CostTable.Cost(provider.GetName(), message.Number))
 
-		smsTrackerEntity.SetFromSecondaryProvider(secondaryProvider.GetName())
+				break
+			}
 
-		status, err = secondaryProvider.SendSMSMessage(message)
-		if err != nil {
-			smsTrackerEntity.SetSecondaryProviderError(err.Error())
+			if s.CircuitBreaker != nil {
+				s.CircuitBreaker.Failure(provider.GetName())
+			}
+
+			if attempt == 0 {
+				smsTrackerEntity.SetPrimaryProviderError(err.Error())
+			} else if attempt == 1 {
+				smsTrackerEntity.SetSecondaryProviderError(err.Error())
+			}
+
+			providerErrors = append(providerErrors, provider.GetName()+": "+err.Error())
 			s.ErrorLoggerService.LogError(err)
+
+			attempt++
 		}
 	}
 
+	smsTrackerEntity.SetProviderErrors(strings.Join(providerErrors, "\n"))
 	smsTrackerEntity.SetStatus(status)
 	logger := NewSmsLog(ormService, smsTrackerEntity)
 	logger.Do()
@@ -88,3 +109,121 @@ func (s *Sender) SendMessage(ormService *datalayer.ORM, message *Message) error
 
 	return nil
 }
+
+func (s *Sender) HandleDeliveryReceipt(ormService *datalayer.ORM, providerName string, request *http.Request) error {
+	provider, ok := s.getProviderByName(providerName).(IDeliveryReceiptProvider)
+	if !ok {
+		return fmt.Errorf("sms provider %s does not support delivery receipts", providerName)
+	}
+
+	deliveryReceipts, err := provider.ParseDeliveryReceipts(request)
+	if err != nil {
+		return err
+	}
+
+	now := s.ClockService.Now()
+
+	flusher := ormService.NewFlusher()
+
+	for _, deliveryReceipt := range deliveryReceipts {
+		if deliveryReceipt.Status == "" || deliveryReceipt.ProviderMessageID == "" {
+			continue
+		}
+
+		smsTrackerEntity := &entity.SmsTrackerEntity{}
+
+		found := ormService.SearchOne(
+			beeorm.NewWhere("`ProviderMessageID` = ? AND `SentByGateway` = ?", deliveryReceipt.ProviderMessageID, providerName),
+			smsTrackerEntity,
+		)
+
+		if !found {
+			continue
+		}
+
+		smsTrackerEntity.DeliveryStatus = deliveryReceipt.Status
+		smsTrackerEntity.ProviderDeliveryStatus = deliveryReceipt.ProviderStatus
+
+		switch deliveryReceipt.Status {
+		case DeliveryStatusDelivered:
+			smsTrackerEntity.DeliveredAt = &now
+		case DeliveryStatusFailed:
+			smsTrackerEntity.FailedAt = &now
+		case DeliveryStatusExpired:
+			smsTrackerEntity.ExpiredAt = &now
+		}
+
+		flusher.Track(smsTrackerEntity)
+	}
+
+	flusher.Flush()
+
+	return nil
+}
+
+// getProviderChain returns providers in the order they should be tried.
+// Per message providers win over routing rules, routing rules win over the default chain
+func (s *Sender) getProviderChain(message *Message) ([]IProvider, error) {
+	if message.Provider != nil {
+		if message.Provider.Primary == nil {
+			return nil, fmt.Errorf("primary provider not supported")
+		}
+
+		return s.filterNil(message.Provider.Primary, message.Provider.Secondary), nil
+	}
+
+	if s.Router != nil {
+		if providerNames, ok := s.Router.Match(message.Number); ok {
+			providers := make([]IProvider, 0, len(providerNames))
+
+			for _, providerName := range providerNames {
+				provider := s.getProviderByName(providerName)
+				if provider == nil {
+					return nil, fmt.Errorf("sms provider %s not registered", providerName)
+				}
+
+				providers = append(providers, provider)
+			}
+
+			if len(providers) > 0 {
+				return providers, nil
+			}
+		}
+	}
+
+	if len(s.Providers) > 0 {
+		return s.Providers, nil
+	}
+
+	if s.PrimaryProvider == nil {
+		return nil, fmt.Errorf("primary provider not supported")
+	}
+
+	return s.filterNil(s.PrimaryProvider, s.SecondaryProvider), nil
+}
+
+func (s *Sender) getProviderByName(name string) IProvider {
+	providers := make([]IProvider, 0, len(s.Providers)+2)
+	providers = append(providers, s.Providers...)
+	providers = append(providers, s.PrimaryProvider, s.SecondaryProvider)
+
+	for _, provider := range s.filterNil(providers...) {
+		if provider.GetName() == name {
+			return provider
+		}
+	}
+
+	return nil
+}
+
+func (s *Sender) filterNil(providers ...IProvider) []IProvider {
+	result := make([]IProvider, 0, len(providers))
+
+	for _, provider := range providers {
+		if provider != nil {
+			result = append(result, provider)
+		}
+	}
+
+	return result
+}
@@ -2,10 +2,14 @@ package sms
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/coretrix/hitrix/pkg/helper"
@@ -19,11 +23,12 @@ const (
 )
 
 type SinchProvider struct {
-	Clock      clock.IClock
-	AppID      string
-	AppSecret  string
-	MsgURL     string
-	FromNumber string
+	Clock         clock.IClock
+	AppID         string
+	AppSecret     string
+	MsgURL        string
+	FromNumber    string
+	WebhookSecret string
 }
 
 func NewSinchProvider(configService config.IConfig, clockService clock.IClock) (IProvider, error) {
@@ -47,12 +52,15 @@ func NewSinchProvider(configService config.IConfig, clockService clock.IClock) (
 		return nil, errors.New("missing sms.sinch.from_number")
 	}
 
+	webhookSecret, _ := configService.String("sms.sinch.webhook_secret")
+
 	return &SinchProvider{
-		Clock:      clockService,
-		AppID:      appID,
-		AppSecret:  appSecret,
-		MsgURL:     msgURL,
-		FromNumber: fromNumberSinch,
+		Clock:         clockService,
+		AppID:         appID,
+		AppSecret:     appSecret,
+		MsgURL:        msgURL,
+		FromNumber:    fromNumberSinch,
+		WebhookSecret: webhookSecret,
 	}, nil
 }
 
@@ -60,7 +68,7 @@ func (g *SinchProvider) GetName() string {
 	return Sinch
 }
 
-func (g *SinchProvider) SendSMSMessage(message *Message) (string, error) {
+func (g *SinchProvider) SendSMSMessage(message *Message) (string, string, error) {
 	body := struct {
 		From    string `json:"from"`
 		Message string `json:"message"`
@@ -82,14 +90,83 @@ func (g *SinchProvider) SendSMSMessage(message *Message) (string, error) {
 		nil)
 
 	if err != nil {
-		return failure, err
+		return failure, "", err
 	}
 
 	if code != http.StatusOK {
-		return failure, fmt.Errorf("expected status code OK, but got %v Response: %s", code, string(responseBody))
+		return failure, "", fmt.Errorf("expected status code OK, but got %v Response: %s", code, string(responseBody))
+	}
+
+	responseBodyJSON := &struct {
+		MessageID json.Number `json:"messageId"`
+	}{}
+
+	if err := json.Unmarshal(responseBody, responseBodyJSON); err != nil {
+		return success, "", nil
+	}
+
+	return success, responseBodyJSON.MessageID.String(), nil
+}
+
+// ParseDeliveryReceipts handles sinch recipient delivery report signed with sms.sinch.webhook_secret
+func (g *SinchProvider) ParseDeliveryReceipts(request *http.Request) ([]*DeliveryReceipt, error) {
+	if g.WebhookSecret == "" {
+		return nil, errors.New("missing sms.sinch.webhook_secret")
+	}
+
+	body, err := readRequestBody(request)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := request.Header.Get("x-sinch-webhook-signature-timestamp")
+	signedData := string(body) + "." + request.Header.Get("x-sinch-webhook-signature-nonce") + "." + timestamp
+
+	mac := hmac.New(sha256.New, []byte(g.WebhookSecret))
+	mac.Write([]byte(signedData))
+
+	signature, _ := base64.StdEncoding.DecodeString(request.Header.Get("x-sinch-webhook-signature"))
+	if err := verifyHMAC(mac.Sum(nil), signature); err != nil {
+		return nil, err
+	}
+
+	if err := verifyTimestamp(g.Clock.Now(), timestamp); err != nil {
+		return nil, err
+	}
+
+	report := &struct {
+		BatchID   string `json:"batch_id"`
+		MessageID string `json:"message_id"`
+		Status    string `json:"status"`
+	}{}
+
+	if err := json.Unmarshal(body, report); err != nil {
+		return nil, err
 	}
 
-	return success, nil
+	var status string
+
+	switch strings.ToLower(report.Status) {
+	case "delivered":
+		status = DeliveryStatusDelivered
+	case "failed", "rejected", "aborted", "cancelled":
+		status = DeliveryStatusFailed
+	case "expired":
+		status = DeliveryStatusExpired
+	}
+
+	providerMessageID := report.MessageID
+	if providerMessageID == "" {
+		providerMessageID = report.BatchID
+	}
+
+	return []*DeliveryReceipt{
+		{
+			ProviderMessageID: providerMessageID,
+			Status:            status,
+			ProviderStatus:    report.Status,
+		},
+	}, nil
 }
 
 func (g *SinchProvider) getSinchHeaders() map[string]string {
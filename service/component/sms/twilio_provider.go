@@ -1,7 +1,10 @@
 package sms
 
 import (
+	"context"
 	"errors"
+	"net/http"
+	"net/url"
 
 	"github.com/kevinburke/twilio-go"
 
@@ -12,9 +15,10 @@ import (
 const Twilio = "twilio"
 
 type TwilioProvider struct {
-	SID        string
-	Token      string
-	FromNumber string
+	SID               string
+	Token             string
+	FromNumber        string
+	StatusCallbackURL string
 }
 
 func NewTwilioProvider(configService config.IConfig, _ clock.IClock) (IProvider, error) {
@@ -33,10 +37,13 @@ func NewTwilioProvider(configService config.IConfig, _ clock.IClock) (IProvider,
 		return nil, errors.New("missing sms.twilio.from_number")
 	}
 
+	statusCallbackURL, _ := configService.String("sms.twilio.status_callback_url")
+
 	return &TwilioProvider{
-		SID:        sid,
-		Token:      token,
-		FromNumber: fromNumberTwilio,
+		SID:               sid,
+		Token:             token,
+		FromNumber:        fromNumberTwilio,
+		StatusCallbackURL: statusCallbackURL,
 	}, nil
 }
 
@@ -44,13 +51,56 @@ func (g *TwilioProvider) GetName() string {
 	return Twilio
 }
 
-func (g *TwilioProvider) SendSMSMessage(message *Message) (string, error) {
+func (g *TwilioProvider) SendSMSMessage(message *Message) (string, string, error) {
 	api := twilio.NewClient(g.SID, g.Token, nil)
 
-	_, err := api.Messages.SendMessage(g.FromNumber, message.Number, message.Text, nil)
+	data := url.Values{}
+	data.Set("From", g.FromNumber)
+	data.Set("To", message.Number)
+	data.Set("Body", message.Text)
+
+	if g.StatusCallbackURL != "" {
+		data.Set("StatusCallback", g.StatusCallbackURL)
+	}
+
+	res, err := api.Messages.Create(context.Background(), data)
 	if err != nil {
-		return err.Error(), err
+		return err.Error(), "", err
 	}
 
-	return success, nil
+	return success, res.Sid, nil
+}
+
+func (g *TwilioProvider) ParseDeliveryReceipts(request *http.Request) ([]*DeliveryReceipt, error) {
+	if g.StatusCallbackURL == "" {
+		return nil, errors.New("missing sms.twilio.status_callback_url")
+	}
+
+	if err := request.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	expectedSignature := twilio.GetExpectedTwilioSignature("", g.Token, g.StatusCallbackURL, request.PostForm)
+	if err := verifyToken(expectedSignature, request.Header.Get("X-Twilio-Signature")); err != nil {
+		return nil, err
+	}
+
+	providerStatus := request.PostForm.Get("MessageStatus")
+
+	var status string
+
+	switch providerStatus {
+	case "delivered":
+		status = DeliveryStatusDelivered
+	case "undelivered", "failed", "canceled":
+		status = DeliveryStatusFailed
+	}
+
+	return []*DeliveryReceipt{
+		{
+			ProviderMessageID: request.PostForm.Get("MessageSid"),
+			Status:            status,
+			ProviderStatus:    providerStatus,
+		},
+	}, nil
 }
@@ -0,0 +1,395 @@
+package subscription
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+)
+
+type contextKey int
+
+const (
+	userIDKey contextKey = iota
+	clientIPKey
+)
+
+var (
+	ErrMissingAccessToken   = errors.New("missing access token")
+	ErrTooManySubscriptions = errors.New("too many subscriptions")
+	ErrBrokerClosed         = errors.New("subscription broker is closed")
+	ErrPubSubDisconnected   = errors.New("subscription pub/sub is disconnected")
+)
+
+// Authenticator verifies access token sent in websocket connection init payload and returns ID of the user
+type Authenticator func(ctx context.Context, accessToken string) (uint64, error)
+
+type IBroker interface {
+	// Publish sends the event to subscribers of the topic in all pods. When user IDs are set only their subscriptions get the event
+	Publish(ctx context.Context, topic string, payload interface{}, userIDs ...uint64) error
+	// Subscribe returns JSON payloads of the topic events until ctx is done. The channel is closed when the subscriber is too slow
+	Subscribe(ctx context.Context, topic string) (<-chan []byte, error)
+	// InitWebsocket is websocket transport InitFunc which authenticates the connection
+	InitWebsocket(ctx context.Context, initPayload transport.InitPayload) (context.Context, error)
+	// Close stops listening to PubSub and closes all subscriptions, it waits for the listener until ctx is done
+	Close(ctx context.Context) error
+}
+
+type event struct {
+	Topic   string          `json:"t"`
+	UserIDs []uint64        `json:"u,omitempty"`
+	Payload json.RawMessage `json:"p"`
+}
+
+type subscriber struct {
+	userID   uint64
+	limitKey string
+	events   chan []byte
+	dropped  int
+	closed   bool
+}
+
+// Broker publishes events to PubSub and fans out received events to subscribers in this pod
+type Broker struct {
+	pubSub        PubSub
+	authenticator Authenticator
+	bufferSize    int
+	maxDropped    int
+	maxPerUser    int
+
+	listenCtx    context.Context
+	stopListen   context.CancelFunc
+	connectMutex sync.Mutex
+
+	mutex             sync.Mutex
+	listening         bool
+	listenDone        chan struct{}
+	closed            bool
+	subscribers       map[string]map[*subscriber]struct{}
+	userSubscriptions map[string]int
+}
+
+// NewBroker creates broker. bufferSize is number of events waiting for one subscriber, subscriber is disconnected after
+// maxDropped events in a row were dropped because its buffer was full. maxPerUser limits subscriptions of one user,
+// anonymous subscriptions are limited per client IP. Zero maxDropped or maxPerUser means no limit, nil authenticator
+// accepts all connections
+func NewBroker(pubSub PubSub, authenticator Authenticator, bufferSize, maxDropped, maxPerUser int) *Broker {
+	if bufferSize <= 0 {
+		bufferSize = 1
+	}
+
+	listenCtx, stopListen := context.WithCancel(context.Background())
+
+	return &Broker{
+		pubSub:            pubSub,
+		authenticator:     authenticator,
+		bufferSize:        bufferSize,
+		maxDropped:        maxDropped,
+		maxPerUser:        maxPerUser,
+		listenCtx:         listenCtx,
+		stopListen:        stopListen,
+		subscribers:       map[string]map[*subscriber]struct{}{},
+		userSubscriptions: map[string]int{},
+	}
+}
+
+func (b *Broker) Publish(ctx context.Context, topic string, payload interface{}, userIDs ...uint64) error {
+	encodedPayload, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	message, err := json.Marshal(&event{Topic: topic, UserIDs: userIDs, Payload: encodedPayload})
+	if err != nil {
+		return err
+	}
+
+	return b.pubSub.Publish(ctx, message)
+}
+
+func (b *Broker) Subscribe(ctx context.Context, topic string) (<-chan []byte, error) {
+	userID := UserIDFromContext(ctx)
+	limitKey := subscriptionLimitKey(ctx, userID)
+
+	if err := b.checkLimit(limitKey); err != nil {
+		return nil, err
+	}
+
+	// connecting to PubSub can be slow, events are dispatched to other subscribers meanwhile
+	if err := b.connect(); err != nil {
+		return nil, err
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return nil, ErrBrokerClosed
+	}
+
+	if !b.listening {
+		return nil, ErrPubSubDisconnected
+	}
+
+	if b.maxPerUser > 0 && b.userSubscriptions[limitKey] >= b.maxPerUser {
+		return nil, ErrTooManySubscriptions
+	}
+
+	s := &subscriber{userID: userID, limitKey: limitKey, events: make(chan []byte, b.bufferSize)}
+
+	if b.subscribers[topic] == nil {
+		b.subscribers[topic] = map[*subscriber]struct{}{}
+	}
+
+	b.subscribers[topic][s] = struct{}{}
+	b.userSubscriptions[limitKey]++
+
+	go func() {
+		<-ctx.Done()
+
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+
+		b.unsubscribe(topic, s)
+	}()
+
+	return s.events, nil
+}
+
+func (b *Broker) Close(ctx context.Context) error {
+	b.mutex.Lock()
+
+	b.closed = true
+	b.stopListen()
+
+	for topic, subscribers := range b.subscribers {
+		for s := range subscribers {
+			b.unsubscribe(topic, s)
+		}
+	}
+
+	listenDone := b.listenDone
+
+	b.mutex.Unlock()
+
+	if listenDone == nil {
+		return nil
+	}
+
+	select {
+	case <-listenDone:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *Broker) checkLimit(limitKey string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return ErrBrokerClosed
+	}
+
+	if b.maxPerUser > 0 && b.userSubscriptions[limitKey] >= b.maxPerUser {
+		return ErrTooManySubscriptions
+	}
+
+	return nil
+}
+
+// connect subscribes to PubSub once, concurrent subscriptions wait for the first one
+func (b *Broker) connect() error {
+	b.connectMutex.Lock()
+	defer b.connectMutex.Unlock()
+
+	b.mutex.Lock()
+	listening, closed := b.listening, b.closed
+	b.mutex.Unlock()
+
+	if closed {
+		return ErrBrokerClosed
+	}
+
+	if listening {
+		return nil
+	}
+
+	messages, err := b.pubSub.Subscribe(b.listenCtx)
+	if err != nil {
+		return err
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	// broker was closed while connecting, PubSub is stopped with canceled listen context
+	if b.closed {
+		return ErrBrokerClosed
+	}
+
+	b.listening = true
+	b.listenDone = make(chan struct{})
+
+	go b.listen(messages, b.listenDone)
+
+	return nil
+}
+
+func (b *Broker) InitWebsocket(ctx context.Context, initPayload transport.InitPayload) (context.Context, error) {
+	if b.authenticator == nil {
+		return ctx, nil
+	}
+
+	accessToken := strings.TrimPrefix(strings.TrimSpace(initPayload.Authorization()), "Bearer ")
+	if accessToken == "" {
+		return nil, ErrMissingAccessToken
+	}
+
+	userID, err := b.authenticator(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return WithUserID(ctx, userID), nil
+}
+
+func (b *Broker) listen(messages <-chan []byte, listenDone chan struct{}) {
+	defer close(listenDone)
+
+	for message := range messages {
+		b.dispatch(message)
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.listening = false
+
+	// subscriptions are closed, so the clients can subscribe again when PubSub is available
+	for topic, subscribers := range b.subscribers {
+		for s := range subscribers {
+			b.unsubscribe(topic, s)
+		}
+	}
+}
+
+func (b *Broker) dispatch(message []byte) {
+	e := &event{}
+	if err := json.Unmarshal(message, e); err != nil {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for s := range b.subscribers[e.Topic] {
+		if len(e.UserIDs) > 0 && !containsUserID(e.UserIDs, s.userID) {
+			continue
+		}
+
+		select {
+		case s.events <- e.Payload:
+			s.dropped = 0
+		default:
+			s.dropped++
+
+			if b.maxDropped > 0 && s.dropped >= b.maxDropped {
+				b.unsubscribe(e.Topic, s)
+			}
+		}
+	}
+}
+
+// unsubscribe has to be called with locked mutex
+func (b *Broker) unsubscribe(topic string, s *subscriber) {
+	if s.closed {
+		return
+	}
+
+	s.closed = true
+	close(s.events)
+
+	delete(b.subscribers[topic], s)
+
+	if len(b.subscribers[topic]) == 0 {
+		delete(b.subscribers, topic)
+	}
+
+	b.userSubscriptions[s.limitKey]--
+
+	if b.userSubscriptions[s.limitKey] <= 0 {
+		delete(b.userSubscriptions, s.limitKey)
+	}
+}
+
+// Subscribe returns events of the topic decoded to T, events which can not be decoded are skipped
+func Subscribe[T any](ctx context.Context, broker IBroker, topic string) (<-chan T, error) {
+	payloads, err := broker.Subscribe(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan T)
+
+	go func() {
+		defer close(events)
+
+		for payload := range payloads {
+			var value T
+			if err := json.Unmarshal(payload, &value); err != nil {
+				continue
+			}
+
+			select {
+			case events <- value:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// WithUserID stores ID of the authenticated user, subscriptions created with the context get events of this user
+func WithUserID(ctx context.Context, userID uint64) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// UserIDFromContext returns ID of the user authenticated in websocket connection init, zero for anonymous connection
+func UserIDFromContext(ctx context.Context) uint64 {
+	userID, _ := ctx.Value(userIDKey).(uint64)
+
+	return userID
+}
+
+// WithClientIP stores IP of the websocket client, anonymous subscriptions are limited per IP
+func WithClientIP(ctx context.Context, clientIP string) context.Context {
+	return context.WithValue(ctx, clientIPKey, clientIP)
+}
+
+func subscriptionLimitKey(ctx context.Context, userID uint64) string {
+	if userID != 0 {
+		return "user:" + strconv.FormatUint(userID, 10)
+	}
+
+	clientIP, _ := ctx.Value(clientIPKey).(string)
+
+	return "ip:" + clientIP
+}
+
+func containsUserID(userIDs []uint64, userID uint64) bool {
+	for _, id := range userIDs {
+		if id == userID {
+			return true
+		}
+	}
+
+	return false
+}
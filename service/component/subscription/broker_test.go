@@ -0,0 +1,305 @@
+package subscription_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/coretrix/hitrix/service/component/subscription"
+)
+
+type memoryPubSub struct {
+	mutex      sync.Mutex
+	messages   chan []byte
+	calls      int
+	connecting chan struct{}
+	connect    chan struct{}
+}
+
+func newMemoryPubSub() *memoryPubSub {
+	return &memoryPubSub{messages: make(chan []byte, 100)}
+}
+
+func (m *memoryPubSub) Publish(_ context.Context, message []byte) error {
+	m.messages <- message
+
+	return nil
+}
+
+// Subscribe waits for connect channel when it is set, like slow redis connection
+func (m *memoryPubSub) Subscribe(ctx context.Context) (<-chan []byte, error) {
+	if m.connect != nil {
+		m.connecting <- struct{}{}
+		<-m.connect
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.calls++
+
+	messages := make(chan []byte)
+
+	go func() {
+		defer close(messages)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message := <-m.messages:
+				select {
+				case messages <- message:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return messages, nil
+}
+
+func (m *memoryPubSub) Calls() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.calls
+}
+
+type orderEvent struct {
+	ID     uint64
+	Status string
+}
+
+func receive[T any](t *testing.T, events <-chan T) (T, bool) {
+	select {
+	case event, ok := <-events:
+		return event, ok
+	case <-time.After(time.Second):
+		t.Fatal("event not received")
+	}
+
+	var empty T
+
+	return empty, false
+}
+
+func assertNoEvent[T any](t *testing.T, events <-chan T) {
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected event %v", event)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestBrokerFiltersTopicAndUser(t *testing.T) {
+	pubSub := newMemoryPubSub()
+	broker := subscription.NewBroker(pubSub, nil, 10, 0, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	user1Orders, err := subscription.Subscribe[*orderEvent](subscription.WithUserID(ctx, 1), broker, "orders")
+	assert.Nil(t, err)
+
+	user2Orders, err := subscription.Subscribe[*orderEvent](subscription.WithUserID(ctx, 2), broker, "orders")
+	assert.Nil(t, err)
+
+	user1Invoices, err := broker.Subscribe(subscription.WithUserID(ctx, 1), "invoices")
+	assert.Nil(t, err)
+
+	assert.Nil(t, broker.Publish(ctx, "orders", &orderEvent{ID: 1, Status: "paid"}))
+	assert.Nil(t, broker.Publish(ctx, "orders", &orderEvent{ID: 2, Status: "shipped"}, 2))
+	assert.Nil(t, broker.Publish(ctx, "invoices", map[string]int{"ID": 3}, 1))
+
+	event, _ := receive(t, user1Orders)
+	assert.Equal(t, &orderEvent{ID: 1, Status: "paid"}, event)
+	assertNoEvent(t, user1Orders)
+
+	event, _ = receive(t, user2Orders)
+	assert.Equal(t, &orderEvent{ID: 1, Status: "paid"}, event)
+	event, _ = receive(t, user2Orders)
+	assert.Equal(t, &orderEvent{ID: 2, Status: "shipped"}, event)
+
+	payload, _ := receive(t, user1Invoices)
+	assert.JSONEq(t, `{"ID":3}`, string(payload))
+
+	assert.Equal(t, 1, pubSub.Calls())
+
+	cancel()
+
+	_, ok := receive(t, user1Orders)
+	assert.False(t, ok)
+	_, ok = receive(t, user1Invoices)
+	assert.False(t, ok)
+}
+
+func TestBrokerBackpressure(t *testing.T) {
+	pubSub := newMemoryPubSub()
+	broker := subscription.NewBroker(pubSub, nil, 2, 3, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	userCtx := subscription.WithUserID(ctx, 7)
+
+	slow, err := broker.Subscribe(userCtx, "prices")
+	assert.Nil(t, err)
+
+	secondCtx, cancelSecond := context.WithCancel(userCtx)
+
+	_, err = broker.Subscribe(secondCtx, "prices")
+	assert.Nil(t, err)
+
+	_, err = broker.Subscribe(userCtx, "prices")
+	assert.ErrorIs(t, err, subscription.ErrTooManySubscriptions)
+
+	cancelSecond()
+
+	assert.Eventually(t, func() bool {
+		third, err := broker.Subscribe(userCtx, "stock")
+		if err == nil {
+			assert.NotNil(t, third)
+		}
+
+		return err == nil
+	}, time.Second, 5*time.Millisecond)
+
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, broker.Publish(ctx, "prices", i))
+	}
+
+	assert.Eventually(t, func() bool {
+		return len(pubSub.messages) == 0
+	}, time.Second, 5*time.Millisecond)
+
+	payload, ok := receive(t, slow)
+	assert.True(t, ok)
+	assert.Equal(t, "0", string(payload))
+	payload, ok = receive(t, slow)
+	assert.True(t, ok)
+	assert.Equal(t, "1", string(payload))
+
+	_, ok = receive(t, slow)
+	assert.False(t, ok)
+}
+
+func TestBrokerLimitsAnonymousSubscriptionsPerIP(t *testing.T) {
+	broker := subscription.NewBroker(newMemoryPubSub(), nil, 1, 0, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	firstIP := subscription.WithClientIP(ctx, "10.0.0.1")
+
+	_, err := broker.Subscribe(firstIP, "prices")
+	assert.Nil(t, err)
+
+	_, err = broker.Subscribe(firstIP, "stock")
+	assert.ErrorIs(t, err, subscription.ErrTooManySubscriptions)
+
+	_, err = broker.Subscribe(subscription.WithClientIP(ctx, "10.0.0.2"), "prices")
+	assert.Nil(t, err)
+
+	_, err = broker.Subscribe(subscription.WithUserID(firstIP, 3), "prices")
+	assert.Nil(t, err)
+}
+
+func TestBrokerSlowConnectAndClose(t *testing.T) {
+	pubSub := newMemoryPubSub()
+	pubSub.connecting = make(chan struct{})
+	pubSub.connect = make(chan struct{})
+
+	broker := subscription.NewBroker(pubSub, nil, 10, 0, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	subscribed := make(chan error, 2)
+
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := broker.Subscribe(ctx, "orders")
+			subscribed <- err
+		}()
+	}
+
+	<-pubSub.connecting
+
+	// broker is not locked while it is connecting
+	assert.Nil(t, broker.Close(context.Background()))
+
+	close(pubSub.connect)
+
+	assert.ErrorIs(t, <-subscribed, subscription.ErrBrokerClosed)
+	assert.ErrorIs(t, <-subscribed, subscription.ErrBrokerClosed)
+	assert.Equal(t, 1, pubSub.Calls())
+
+	pubSub = newMemoryPubSub()
+	broker = subscription.NewBroker(pubSub, nil, 10, 0, 0)
+
+	orders, err := broker.Subscribe(ctx, "orders")
+	assert.Nil(t, err)
+
+	closeCtx, cancelClose := context.WithTimeout(context.Background(), time.Second)
+	defer cancelClose()
+
+	assert.Nil(t, broker.Close(closeCtx))
+
+	_, ok := receive(t, orders)
+	assert.False(t, ok)
+
+	_, err = broker.Subscribe(ctx, "orders")
+	assert.ErrorIs(t, err, subscription.ErrBrokerClosed)
+}
+
+func TestBrokerInitWebsocket(t *testing.T) {
+	authenticator := func(_ context.Context, accessToken string) (uint64, error) {
+		if accessToken == "valid" {
+			return 5, nil
+		}
+
+		return 0, errors.New("invalid token")
+	}
+
+	broker := subscription.NewBroker(newMemoryPubSub(), authenticator, 1, 0, 0)
+
+	ctx, err := broker.InitWebsocket(context.Background(), transport.InitPayload{"Authorization": "Bearer valid"})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(5), subscription.UserIDFromContext(ctx))
+
+	_, err = broker.InitWebsocket(context.Background(), transport.InitPayload{"authorization": "Bearer wrong"})
+	assert.EqualError(t, err, "invalid token")
+
+	_, err = broker.InitWebsocket(context.Background(), transport.InitPayload{})
+	assert.ErrorIs(t, err, subscription.ErrMissingAccessToken)
+
+	anonymous := subscription.NewBroker(newMemoryPubSub(), nil, 1, 0, 0)
+
+	ctx, err = anonymous.InitWebsocket(context.Background(), transport.InitPayload{})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), subscription.UserIDFromContext(ctx))
+}
+
+func TestNewRedisPubSub(t *testing.T) {
+	pubSub, err := subscription.NewRedisPubSub("localhost:6379:3:app?user=u&password=p")
+	assert.Nil(t, err)
+	assert.Equal(t, "app:hitrix_subscriptions", pubSub.Channel)
+	assert.Equal(t, "localhost:6379", pubSub.Client.Options().Addr)
+	assert.Equal(t, 3, pubSub.Client.Options().DB)
+	assert.Equal(t, "u", pubSub.Client.Options().Username)
+	assert.Equal(t, "p", pubSub.Client.Options().Password)
+
+	pubSub, err = subscription.NewRedisPubSub("localhost:6379:0")
+	assert.Nil(t, err)
+	assert.Equal(t, "hitrix_subscriptions", pubSub.Channel)
+
+	_, err = subscription.NewRedisPubSub("localhost")
+	assert.EqualError(t, err, "redis uri 'localhost' is not valid")
+}
@@ -0,0 +1,128 @@
+package subscription
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const channelName = "hitrix_subscriptions"
+
+// PubSub delivers messages published in any pod to all pods
+type PubSub interface {
+	Publish(ctx context.Context, message []byte) error
+	// Subscribe returns messages until ctx is done
+	Subscribe(ctx context.Context) (<-chan []byte, error)
+}
+
+// RedisPubSub uses one Redis channel, every pod filters events of its subscribers
+type RedisPubSub struct {
+	Client  *redis.Client
+	Channel string
+}
+
+// NewRedisPubSub creates pub/sub from redis uri used in orm config, for example localhost:6379:0:namespace?user=u&password=p
+func NewRedisPubSub(uri string) (*RedisPubSub, error) {
+	options, namespace, err := parseRedisURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	channel := channelName
+	if namespace != "" {
+		channel = namespace + ":" + channel
+	}
+
+	return &RedisPubSub{Client: redis.NewClient(options), Channel: channel}, nil
+}
+
+func (r *RedisPubSub) Publish(ctx context.Context, message []byte) error {
+	return r.Client.Publish(ctx, r.Channel, message).Err()
+}
+
+func (r *RedisPubSub) Subscribe(ctx context.Context) (<-chan []byte, error) {
+	pubSub := r.Client.Subscribe(ctx, r.Channel)
+
+	if _, err := pubSub.Receive(ctx); err != nil {
+		_ = pubSub.Close()
+
+		return nil, err
+	}
+
+	messages := make(chan []byte)
+
+	go func() {
+		defer close(messages)
+		defer pubSub.Close()
+
+		redisMessages := pubSub.Channel()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-redisMessages:
+				if !ok {
+					return
+				}
+
+				select {
+				case messages <- []byte(message.Payload):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return messages, nil
+}
+
+// parseRedisURI parses uri in the same format as orm redis pools: address:port:db[:namespace][?user=&password=]
+func parseRedisURI(uri string) (*redis.Options, string, error) {
+	parts := strings.SplitN(uri, "?", 2)
+	elements := strings.Split(parts[0], ":")
+	isSocket := strings.Contains(parts[0], ".sock")
+
+	var address, db, namespace string
+
+	switch {
+	case len(elements) == 2:
+		address, db = elements[0], elements[1]
+	case len(elements) == 3 && isSocket:
+		address, db, namespace = elements[0], elements[1], elements[2]
+	case len(elements) == 3:
+		address, db = elements[0]+":"+elements[1], elements[2]
+	case len(elements) == 4:
+		address, db, namespace = elements[0]+":"+elements[1], elements[2], elements[3]
+	default:
+		return nil, "", fmt.Errorf("redis uri '%s' is not valid", uri)
+	}
+
+	dbNumber, err := strconv.Atoi(db)
+	if err != nil {
+		return nil, "", fmt.Errorf("redis uri '%s' is not valid", uri)
+	}
+
+	options := &redis.Options{Addr: address, DB: dbNumber}
+
+	if isSocket {
+		options.Network = "unix"
+	}
+
+	if len(parts) == 2 && parts[1] != "" {
+		values, err := url.ParseQuery(parts[1])
+		if err != nil {
+			return nil, "", fmt.Errorf("redis uri '%s' is not valid", uri)
+		}
+
+		options.Username = values.Get("user")
+		options.Password = values.Get("password")
+	}
+
+	return options, namespace, nil
+}
@@ -0,0 +1,304 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/latolukasz/beeorm/v2"
+	tusd "github.com/tus/tusd/pkg/handler"
+
+	"github.com/coretrix/hitrix/datalayer"
+	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/pkg/queue/streams"
+	"github.com/coretrix/hitrix/service/component/clock"
+	errorlogger "github.com/coretrix/hitrix/service/component/error_logger"
+	filevalidator "github.com/coretrix/hitrix/service/component/file_validator"
+	"github.com/coretrix/hitrix/service/component/oss"
+)
+
+// AuthorizeFunc is called before the upload is created and returns ID of the logged user, which is saved as FileEntity.UploaderID.
+// Returned error rejects the upload with 403 status code
+type AuthorizeFunc func(hook tusd.HookEvent) (uint64, error)
+
+// uploaderIDMetaData is set by the server only, value sent by the client is removed
+const uploaderIDMetaData = "uploader_id"
+
+type FileUploadedDTO struct {
+	FileEntityID uint64
+	UploadID     string
+	MetaData     map[string]string
+}
+
+var ErrUploadStoreNotSupported = errors.New("uploader does not implement UploadStore")
+
+type IFileListener interface {
+	PreUploadCreateCallback(next func(hook tusd.HookEvent) error) func(hook tusd.HookEvent) error
+	Listen(ctx context.Context, uploaderService Uploader) error
+	HandleCompletedUpload(ctx context.Context, uploadStore UploadStore, hook tusd.HookEvent) (*entity.FileEntity, error)
+	HandleTerminatedUpload(hook tusd.HookEvent) error
+}
+
+// FileListener turns completed uploads into FileEntity records. Namespace is sent by the client in `namespace` metadata
+type FileListener struct {
+	ormService         *datalayer.ORM
+	ossService         oss.IProvider
+	clockService       clock.IClock
+	errorLoggerService errorlogger.ErrorLogger
+	validatorService   filevalidator.IValidator
+	authorizeFunc      AuthorizeFunc
+}
+
+func NewFileListener(
+	ormService *datalayer.ORM,
+	ossService oss.IProvider,
+	clockService clock.IClock,
+	errorLoggerService errorlogger.ErrorLogger,
+	validatorService filevalidator.IValidator,
+	authorizeFunc AuthorizeFunc,
+) *FileListener {
+	return &FileListener{
+		ormService:         ormService,
+		ossService:         ossService,
+		clockService:       clockService,
+		errorLoggerService: errorLoggerService,
+		validatorService:   validatorService,
+		authorizeFunc:      authorizeFunc,
+	}
+}
+
+// PreUploadCreateCallback validates namespace and filename metadata and authorizes the request
+func (l *FileListener) PreUploadCreateCallback(next func(hook tusd.HookEvent) error) func(hook tusd.HookEvent) error {
+	return func(hook tusd.HookEvent) error {
+		namespace := hook.Upload.MetaData["namespace"]
+		if namespace == "" {
+			return tusd.NewHTTPError(errors.New("missing namespace metadata"), http.StatusBadRequest)
+		}
+
+		if _, err := l.ossService.GetNamespaceBucketConfig(oss.Namespace(namespace)); err != nil {
+			return tusd.NewHTTPError(fmt.Errorf("invalid namespace %s", namespace), http.StatusBadRequest)
+		}
+
+		if hook.Upload.MetaData["filename"] == "" {
+			return tusd.NewHTTPError(errors.New("missing filename metadata"), http.StatusBadRequest)
+		}
+
+		delete(hook.Upload.MetaData, uploaderIDMetaData)
+
+		if l.authorizeFunc != nil {
+			uploaderID, err := l.authorizeFunc(hook)
+			if err != nil {
+				return tusd.NewHTTPError(err, http.StatusForbidden)
+			}
+
+			// metadata map is shared with the upload info which is saved after this callback
+			if uploaderID > 0 {
+				hook.Upload.MetaData[uploaderIDMetaData] = strconv.FormatUint(uploaderID, 10)
+			}
+		}
+
+		if next != nil {
+			return next(hook)
+		}
+
+		return nil
+	}
+}
+
+// Listen handles completed and terminated uploads until the context is done.
+// tusd.Config NotifyCompleteUploads and NotifyTerminatedUploads should be enabled
+func (l *FileListener) Listen(ctx context.Context, uploaderService Uploader) error {
+	uploadStore, ok := uploaderService.(UploadStore)
+	if !ok {
+		return ErrUploadStoreNotSupported
+	}
+
+	if !isStreamRegistered(l.ormService, streams.StreamMsgFileUploaded) {
+		log.Printf("uploader file listener: %s stream is not registered, FileUploadedDTO events are not published", streams.StreamMsgFileUploaded)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case hook := <-uploaderService.GetCompletedUploadsChan():
+			if _, err := l.HandleCompletedUpload(ctx, uploadStore, hook); err != nil {
+				l.errorLoggerService.LogError(fmt.Sprintf("failed handling completed upload %s\nError: %s", hook.Upload.ID, err.Error()))
+			}
+		case hook := <-uploaderService.GetTerminatedUploadsChan():
+			if err := l.HandleTerminatedUpload(hook); err != nil {
+				l.errorLoggerService.LogError(fmt.Sprintf("failed handling terminated upload %s\nError: %s", hook.Upload.ID, err.Error()))
+			}
+		}
+	}
+}
+
+// HandleCompletedUpload copies the upload into the namespace bucket, creates FileEntity and removes the upload from the data store.
+// The upload is streamed through a temporary file so it is never loaded into memory.
+// Upload which does not match the namespace policy is stored as quarantined FileEntity without object
+func (l *FileListener) HandleCompletedUpload(ctx context.Context, uploadStore UploadStore, hook tusd.HookEvent) (*entity.FileEntity, error) {
+	ormService := l.ormService.Clone()
+
+	namespace := hook.Upload.MetaData["namespace"]
+
+	fileEntity := &entity.FileEntity{}
+	if ormService.SearchOne(beeorm.NewWhere("UploadID = ?", hook.Upload.ID), fileEntity) {
+		return fileEntity, nil
+	}
+
+	tempFile, err := l.copyUploadToTempFile(ctx, uploadStore, hook.Upload.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = tempFile.Close()
+		_ = os.Remove(tempFile.Name())
+	}()
+
+	size, err := tempFile.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	fileEntity = &entity.FileEntity{
+		Namespace: namespace,
+		Status:    entity.FileStatusNew.String(),
+		Size:      size,
+		UploadID:  hook.Upload.ID,
+		CreatedAt: l.clockService.Now(),
+	}
+
+	if uploaderID, err := strconv.ParseUint(hook.Upload.MetaData[uploaderIDMetaData], 10, 64); err == nil {
+		fileEntity.UploaderID = uploaderID
+	}
+
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(hook.Upload.MetaData["filename"]), "."))
+
+	if l.validatorService != nil {
+		if _, err := tempFile.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+
+		fileInfo, err := l.validatorService.ValidateReader(namespace, tempFile)
+		if fileInfo == nil && err != nil {
+			return nil, err
+		}
+
+		if fileInfo != nil {
+			fileEntity.MimeType = fileInfo.MimeType
+
+			if fileInfo.Extension != "" {
+				ext = fileInfo.Extension
+			}
+		}
+
+		if err != nil {
+			fileEntity.Status = entity.FileStatusQuarantined.String()
+			fileEntity.ScanResult = err.Error()
+		} else if !l.validatorService.HasScanner() {
+			fileEntity.Status = entity.FileStatusProcessed.String()
+		}
+	}
+
+	if fileEntity.Status != entity.FileStatusQuarantined.String() {
+		if _, err := tempFile.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+
+		fileObject, err := oss.UploadObjectFromReader(l.ossService, ormService, oss.Namespace(namespace), tempFile, ext)
+		if err != nil {
+			return nil, err
+		}
+
+		fileEntity.File = &fileObject
+	}
+
+	ormService.Flush(fileEntity)
+
+	if fileEntity.Status != entity.FileStatusQuarantined.String() {
+		eventBroker := ormService.GetEventBroker()
+
+		if isStreamRegistered(ormService, streams.StreamMsgFileUploaded) {
+			eventBroker.Publish(
+				streams.StreamMsgFileUploaded,
+				&FileUploadedDTO{FileEntityID: fileEntity.ID, UploadID: hook.Upload.ID, MetaData: hook.Upload.MetaData},
+				nil,
+			)
+		}
+
+		if l.validatorService != nil && l.validatorService.HasScanner() {
+			eventBroker.Publish(streams.StreamMsgFileScan, &filevalidator.FileScanDTO{FileEntityID: fileEntity.ID}, nil)
+		}
+	}
+
+	return fileEntity, uploadStore.TerminateUpload(ctx, hook.Upload.ID)
+}
+
+func (l *FileListener) copyUploadToTempFile(ctx context.Context, uploadStore UploadStore, uploadID string) (*os.File, error) {
+	reader, err := uploadStore.GetUploadReader(ctx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	if closer, ok := reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	tempFile, err := os.CreateTemp("", "hitrix-upload-*")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := io.Copy(tempFile, reader); err != nil {
+		_ = tempFile.Close()
+		_ = os.Remove(tempFile.Name())
+
+		return nil, err
+	}
+
+	return tempFile, nil
+}
+
+// HandleTerminatedUpload deletes FileEntity and its object when the client terminates upload which was already handled.
+// Files which are already referenced by some entity are kept
+func (l *FileListener) HandleTerminatedUpload(hook tusd.HookEvent) error {
+	ormService := l.ormService.Clone()
+
+	fileEntity := &entity.FileEntity{}
+	if !ormService.SearchOne(beeorm.NewWhere("UploadID = ?", hook.Upload.ID), fileEntity) {
+		return nil
+	}
+
+	fileReferenceEntities := make([]*entity.FileReferenceEntity, 0)
+	if ormService.SearchWithCount(beeorm.NewWhere("File = ?", fileEntity.ID), beeorm.NewPager(1, 1), &fileReferenceEntities) > 0 {
+		return nil
+	}
+
+	if fileEntity.File != nil {
+		if err := l.ossService.DeleteObject(oss.Namespace(fileEntity.Namespace), fileEntity.File); err != nil {
+			return err
+		}
+	}
+
+	ormService.Delete(fileEntity)
+
+	return nil
+}
+
+func isStreamRegistered(ormService *datalayer.ORM, stream string) bool {
+	for _, poolStreams := range ormService.GetRegistry().GetRedisStreams() {
+		if _, ok := poolStreams[stream]; ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,67 @@
+package uploader_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	tusd "github.com/tus/tusd/pkg/handler"
+
+	"github.com/coretrix/hitrix/service/component/oss"
+	ossMocks "github.com/coretrix/hitrix/service/component/oss/mocks"
+	"github.com/coretrix/hitrix/service/component/uploader"
+)
+
+func TestFileListenerPreUploadCreateCallback(t *testing.T) {
+	fakeOSS := &ossMocks.FakeOSSClient{}
+	fakeOSS.On("GetNamespaceBucketConfig", oss.Namespace("avatar")).Return(&oss.BucketConfig{Name: "public"})
+
+	authorize := func(hook tusd.HookEvent) (uint64, error) {
+		if hook.HTTPRequest.Header.Get("Authorization") == "" {
+			return 0, errors.New("unauthorized")
+		}
+
+		return 5, nil
+	}
+
+	nextCalled := false
+	next := func(_ tusd.HookEvent) error {
+		nextCalled = true
+
+		return nil
+	}
+
+	callback := uploader.NewFileListener(nil, fakeOSS, nil, nil, nil, authorize).PreUploadCreateCallback(next)
+
+	newHook := func(metaData tusd.MetaData, authorization string) tusd.HookEvent {
+		header := http.Header{}
+		header.Set("Authorization", authorization)
+
+		return tusd.HookEvent{Upload: tusd.FileInfo{MetaData: metaData}, HTTPRequest: tusd.HTTPRequest{Header: header}}
+	}
+
+	err := callback(newHook(tusd.MetaData{"filename": "a.png"}, "token"))
+	assert.Equal(t, http.StatusBadRequest, err.(tusd.HTTPError).StatusCode())
+
+	err = callback(newHook(tusd.MetaData{"namespace": "avatar"}, "token"))
+	assert.Equal(t, http.StatusBadRequest, err.(tusd.HTTPError).StatusCode())
+
+	err = callback(newHook(tusd.MetaData{"namespace": "avatar", "filename": "a.png"}, ""))
+	assert.Equal(t, http.StatusForbidden, err.(tusd.HTTPError).StatusCode())
+	assert.False(t, nextCalled)
+
+	// uploader_id sent by the client is replaced by the logged user
+	metaData := tusd.MetaData{"namespace": "avatar", "filename": "a.png", "uploader_id": "1"}
+
+	err = callback(newHook(metaData, "token"))
+	assert.Nil(t, err)
+	assert.True(t, nextCalled)
+	assert.Equal(t, "5", metaData["uploader_id"])
+
+	metaData = tusd.MetaData{"namespace": "avatar", "filename": "a.png", "uploader_id": "1"}
+
+	err = uploader.NewFileListener(nil, fakeOSS, nil, nil, nil, nil).PreUploadCreateCallback(nil)(newHook(metaData, ""))
+	assert.Nil(t, err)
+	assert.NotContains(t, metaData, "uploader_id")
+}
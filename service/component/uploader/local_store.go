@@ -0,0 +1,14 @@
+package uploader
+
+import (
+	"github.com/tus/tusd/pkg/filestore"
+	tusd "github.com/tus/tusd/pkg/handler"
+)
+
+type LocalStore struct {
+	store filestore.FileStore
+}
+
+func (s *LocalStore) UseIn(composer *tusd.StoreComposer) {
+	s.store.UseIn(composer)
+}
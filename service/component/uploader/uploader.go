@@ -1,13 +1,18 @@
 package uploader
 
 import (
+	"context"
+	"io"
 	"net/http"
 
 	"cloud.google.com/go/storage"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/tus/tusd/pkg/filestore"
 	"github.com/tus/tusd/pkg/gcsstore"
 	tusd "github.com/tus/tusd/pkg/handler"
 	"github.com/tus/tusd/pkg/s3store"
+
+	"github.com/coretrix/hitrix/service/component/oss"
 )
 
 type Uploader interface {
@@ -26,9 +31,17 @@ type Uploader interface {
 	GetBucket() string
 }
 
+// UploadStore gives access to the content of finished uploads. It is implemented by TUSDUploader and it is separated
+// from Uploader so custom Uploader implementations don't have to implement it
+type UploadStore interface {
+	GetUploadReader(ctx context.Context, id string) (io.Reader, error)
+	TerminateUpload(ctx context.Context, id string) error
+}
+
 type TUSDUploader struct {
-	handler *tusd.UnroutedHandler
-	bucket  string
+	handler  *tusd.UnroutedHandler
+	composer *tusd.StoreComposer
+	bucket   string
 }
 
 func GetStore(OSSClient interface{}, bucket string) Store {
@@ -40,6 +53,10 @@ func GetStore(OSSClient interface{}, bucket string) Store {
 		return &AmazonStore{store: s3store.New(bucket, OSSClient.(s3store.S3API))}
 	}
 
+	if localClient, ok := OSSClient.(*oss.LocalClient); ok {
+		return &LocalStore{store: filestore.New(localClient.GetBucketPath(bucket))}
+	}
+
 	panic("OSSClient store not found")
 }
 
@@ -49,7 +66,7 @@ func NewTUSDUploader(c tusd.Config, bucket string) Uploader {
 		panic(err)
 	}
 
-	return &TUSDUploader{handler: uploader, bucket: bucket}
+	return &TUSDUploader{handler: uploader, composer: c.StoreComposer, bucket: bucket}
 }
 
 func (u *TUSDUploader) GetBucket() string {
@@ -99,3 +116,26 @@ func (u *TUSDUploader) GetTerminatedUploadsChan() chan tusd.HookEvent {
 func (u *TUSDUploader) GetUploadProgressChan() chan tusd.HookEvent {
 	return u.handler.UploadProgress
 }
+
+func (u *TUSDUploader) GetUploadReader(ctx context.Context, id string) (io.Reader, error) {
+	upload, err := u.composer.Core.GetUpload(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return upload.GetReader(ctx)
+}
+
+// TerminateUpload removes the upload from the data store. It does nothing if the data store does not support termination
+func (u *TUSDUploader) TerminateUpload(ctx context.Context, id string) error {
+	if !u.composer.UsesTerminater {
+		return nil
+	}
+
+	upload, err := u.composer.Core.GetUpload(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return u.composer.Terminater.AsTerminatableUpload(upload).Terminate(ctx)
+}
@@ -0,0 +1,47 @@
+package uploader_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tus/tusd/pkg/filestore"
+	tusd "github.com/tus/tusd/pkg/handler"
+
+	"github.com/coretrix/hitrix/service/component/uploader"
+)
+
+func TestTUSDUploaderReadAndTerminate(t *testing.T) {
+	ctx := context.Background()
+	content := []byte("uploaded content")
+
+	composer := tusd.NewStoreComposer()
+	store := filestore.New(t.TempDir())
+	store.UseIn(composer)
+
+	uploaderService := uploader.NewTUSDUploader(tusd.Config{BasePath: "/files/", StoreComposer: composer}, "public").(uploader.UploadStore)
+
+	upload, err := store.NewUpload(ctx, tusd.FileInfo{Size: int64(len(content))})
+	assert.Nil(t, err)
+
+	_, err = upload.WriteChunk(ctx, 0, bytes.NewReader(content))
+	assert.Nil(t, err)
+
+	info, err := upload.GetInfo(ctx)
+	assert.Nil(t, err)
+
+	reader, err := uploaderService.GetUploadReader(ctx, info.ID)
+	assert.Nil(t, err)
+
+	uploadedContent, err := io.ReadAll(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, content, uploadedContent)
+
+	assert.Nil(t, reader.(io.Closer).Close())
+	assert.Nil(t, uploaderService.TerminateUpload(ctx, info.ID))
+
+	_, err = uploaderService.GetUploadReader(ctx, info.ID)
+	assert.NotNil(t, err)
+}
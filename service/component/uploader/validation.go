@@ -0,0 +1,41 @@
+package uploader
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/h2non/filetype"
+	tusd "github.com/tus/tusd/pkg/handler"
+
+	filevalidator "github.com/coretrix/hitrix/service/component/file_validator"
+)
+
+// NewPreUploadCreateCallback rejects uploads which declared size or known filename extension do not match the policy
+// of the namespace sent in upload metadata. Files without extension or with unknown one are accepted here because
+// the content type is sniffed from the content when the upload is completed
+func NewPreUploadCreateCallback(validatorService filevalidator.IValidator, next func(hook tusd.HookEvent) error) func(hook tusd.HookEvent) error {
+	return func(hook tusd.HookEvent) error {
+		policy := validatorService.GetPolicy(hook.Upload.MetaData["namespace"])
+
+		if policy != nil {
+			if policy.MaxSize > 0 && !hook.Upload.SizeIsDeferred && hook.Upload.Size > policy.MaxSize {
+				return tusd.NewHTTPError(fmt.Errorf("%w: max %d bytes", filevalidator.ErrFileTooLarge, policy.MaxSize), http.StatusRequestEntityTooLarge)
+			}
+
+			ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(hook.Upload.MetaData["filename"]), "."))
+			fileType := filetype.GetType(ext)
+
+			if fileType != filetype.Unknown && !policy.IsMimeTypeAllowed(fileType.MIME.Value) {
+				return tusd.NewHTTPError(fmt.Errorf("%w: %s", filevalidator.ErrMimeTypeNotAllowed, ext), http.StatusUnsupportedMediaType)
+			}
+		}
+
+		if next != nil {
+			return next(hook)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,34 @@
+package uploader_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	tusd "github.com/tus/tusd/pkg/handler"
+
+	filevalidator "github.com/coretrix/hitrix/service/component/file_validator"
+	"github.com/coretrix/hitrix/service/component/uploader"
+)
+
+func TestPreUploadCreateCallback(t *testing.T) {
+	validator := filevalidator.NewValidator(filevalidator.Policies{
+		"avatar": {AllowedMimeTypes: []string{"image/*"}, MaxSize: 1024},
+	}, nil)
+
+	callback := uploader.NewPreUploadCreateCallback(validator, nil)
+
+	newHook := func(filename string, size int64) tusd.HookEvent {
+		return tusd.HookEvent{Upload: tusd.FileInfo{Size: size, MetaData: tusd.MetaData{"namespace": "avatar", "filename": filename}}}
+	}
+
+	assert.Nil(t, callback(newHook("avatar.png", 100)))
+	assert.Nil(t, callback(newHook("avatar", 100)))
+	assert.Nil(t, callback(newHook("avatar.unknown", 100)))
+
+	err := callback(newHook("document.pdf", 100))
+	assert.Equal(t, http.StatusUnsupportedMediaType, err.(tusd.HTTPError).StatusCode())
+
+	err = callback(newHook("avatar", 2048))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, err.(tusd.HTTPError).StatusCode())
+}
@@ -1,12 +1,15 @@
 package registry
 
 import (
+	"errors"
 	"os"
 
+	"github.com/latolukasz/beeorm/v2"
 	"github.com/sarulabs/di"
 
 	"github.com/coretrix/hitrix/service"
 	"github.com/coretrix/hitrix/service/component/app"
+	"github.com/coretrix/hitrix/service/component/clock"
 	"github.com/coretrix/hitrix/service/component/config"
 	"github.com/coretrix/hitrix/service/component/fcm"
 )
@@ -46,3 +49,25 @@ func ServiceProviderFCM() *service.DefinitionGlobal {
 		},
 	}
 }
+
+func ServiceProviderFCMDeviceRegistry() *service.DefinitionGlobal {
+	return &service.DefinitionGlobal{
+		Name: service.FCMDeviceRegistryService,
+		Build: func(ctn di.Container) (interface{}, error) {
+			ormConfig := ctn.Get(service.ORMConfigService).(beeorm.ValidatedRegistry)
+			entities := ormConfig.GetEntities()
+			if _, ok := entities["entity.DeviceTokenEntity"]; !ok {
+				return nil, errors.New("you should register DeviceTokenEntity")
+			}
+
+			if _, ok := entities["entity.FCMTopicSubscriptionEntity"]; !ok {
+				return nil, errors.New("you should register FCMTopicSubscriptionEntity")
+			}
+
+			return fcm.NewDeviceRegistry(
+				ctn.Get(service.FCMService).(fcm.FCM),
+				ctn.Get(service.ClockService).(clock.IClock),
+			), nil
+		},
+	}
+}
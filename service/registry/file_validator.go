@@ -0,0 +1,40 @@
+package registry
+
+import (
+	"github.com/sarulabs/di"
+
+	"github.com/coretrix/hitrix/service"
+	"github.com/coretrix/hitrix/service/component/config"
+	filevalidator "github.com/coretrix/hitrix/service/component/file_validator"
+)
+
+// ServiceProviderFileValidator registers file validator. newScannerFunc is optional, without scanner files are not scanned for malware
+func ServiceProviderFileValidator(newScannerFunc filevalidator.NewScannerFunc) *service.DefinitionGlobal {
+	return &service.DefinitionGlobal{
+		Name: service.FileValidatorService,
+		Build: func(ctn di.Container) (interface{}, error) {
+			configService := ctn.Get(service.ConfigService).(config.IConfig)
+
+			policies := filevalidator.Policies{}
+
+			if _, ok := configService.Get("file_validator.policies"); ok {
+				if err := configService.MapStruct("file_validator.policies", &policies); err != nil {
+					return nil, err
+				}
+			}
+
+			var scanner filevalidator.IScanner
+
+			if newScannerFunc != nil {
+				var err error
+
+				scanner, err = newScannerFunc(configService)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			return filevalidator.NewValidator(policies, scanner), nil
+		},
+	}
+}
@@ -0,0 +1,18 @@
+package registry
+
+import (
+	"github.com/sarulabs/di"
+
+	"github.com/coretrix/hitrix/pkg/binding"
+	"github.com/coretrix/hitrix/service"
+	"github.com/coretrix/hitrix/service/component/importer"
+)
+
+func ServiceProviderImporter(definitions ...*importer.Definition) *service.DefinitionGlobal {
+	return &service.DefinitionGlobal{
+		Name: service.ImporterService,
+		Build: func(ctn di.Container) (interface{}, error) {
+			return importer.NewImporter(binding.NewValidator(), definitions...), nil
+		},
+	}
+}
@@ -0,0 +1,17 @@
+package mocks
+
+import (
+	"github.com/sarulabs/di"
+
+	"github.com/coretrix/hitrix/service"
+	"github.com/coretrix/hitrix/service/component/push"
+)
+
+func ServiceProviderMockPush(mock push.IPush) *service.DefinitionGlobal {
+	return &service.DefinitionGlobal{
+		Name: service.PushService,
+		Build: func(ctn di.Container) (interface{}, error) {
+			return mock, nil
+		},
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	redisearch "github.com/coretrix/beeorm-redisearch-plugin"
 	"github.com/sarulabs/di"
@@ -11,7 +12,9 @@ import (
 	"github.com/coretrix/hitrix/datalayer"
 	"github.com/coretrix/hitrix/pkg/entity"
 	"github.com/coretrix/hitrix/service"
+	"github.com/coretrix/hitrix/service/component/clock"
 	"github.com/coretrix/hitrix/service/component/config"
+	fakesms "github.com/coretrix/hitrix/service/component/fake_sms"
 	"github.com/coretrix/hitrix/service/component/generator"
 	"github.com/coretrix/hitrix/service/component/otp"
 )
@@ -86,7 +89,21 @@ func ServiceProviderOTP(forceProviders ...string) *service.DefinitionGlobal {
 				return nil, errors.New("missing sms.retry")
 			}
 
-			return otp.NewOTP(retry, providers...), nil
+			limits := &otp.Limits{
+				MaxVerifyAttempts:  configService.DefInt("sms.otp.max_verify_attempts", 0),
+				LockoutDuration:    time.Duration(configService.DefInt("sms.otp.lockout", 0)) * time.Second,
+				ResendCooldown:     time.Duration(configService.DefInt("sms.otp.resend_cooldown", 0)) * time.Second,
+				DailyLimitPerPhone: configService.DefInt64("sms.otp.daily_limit_per_phone", 0),
+				DailyLimitPerIP:    configService.DefInt64("sms.otp.daily_limit_per_ip", 0),
+			}
+
+			otpService := otp.NewOTP(ctn.Get(service.ClockService).(clock.IClock), retry, limits, providers...)
+
+			if channelRoutes, ok := configService.StringMap("sms.otp.channel_routes"); ok {
+				otpService.ChannelRouter = otp.NewChannelRouter(channelRoutes)
+			}
+
+			return otpService, nil
 		},
 	}
 }
@@ -96,9 +113,11 @@ var smsOTPProviderBuilderFactory = map[string]func(
 	generatorService generator.IGenerator,
 	phonePrefixes []string,
 ) (otp.IOTPSMSGateway, error){
-	otp.SMSOTPProviderTwilio: twilioSMSOTPProviderBuilder,
-	otp.SMSOTPProviderSinch:  sinchSMSOTPProviderBuilder,
-	otp.SMSOTPProviderMada:   madaSMSOTPProviderBuilder,
+	otp.SMSOTPProviderTwilio:    twilioSMSOTPProviderBuilder,
+	otp.SMSOTPProviderSinch:     sinchSMSOTPProviderBuilder,
+	otp.SMSOTPProviderMada:      madaSMSOTPProviderBuilder,
+	otp.OTPProviderMetaWhatsApp: metaWhatsAppOTPProviderBuilder,
+	fakesms.Name:                fakeSMSOTPProviderBuilder,
 }
 
 func twilioSMSOTPProviderBuilder(configService config.IConfig, _ generator.IGenerator, _ []string) (otp.IOTPSMSGateway, error) {
@@ -171,3 +190,44 @@ func madaSMSOTPProviderBuilder(
 
 	return otp.NewMadaSMSOTPProvider(username, password, url, sourceName, otpLength, phonePrefixes, generatorService), nil
 }
+
+func metaWhatsAppOTPProviderBuilder(
+	configService config.IConfig,
+	generatorService generator.IGenerator,
+	phonePrefixes []string,
+) (otp.IOTPSMSGateway, error) {
+	accessToken, ok := configService.String("sms.meta_whatsapp.access_token")
+	if !ok {
+		return nil, errors.New("missing sms.meta_whatsapp.access_token")
+	}
+
+	phoneNumberID, ok := configService.String("sms.meta_whatsapp.phone_number_id")
+	if !ok {
+		return nil, errors.New("missing sms.meta_whatsapp.phone_number_id")
+	}
+
+	templateName, ok := configService.String("sms.meta_whatsapp.template_name")
+	if !ok {
+		return nil, errors.New("missing sms.meta_whatsapp.template_name")
+	}
+
+	language := configService.DefString("sms.meta_whatsapp.language", "en")
+
+	return otp.NewMetaWhatsAppOTPProvider(
+		accessToken,
+		phoneNumberID,
+		templateName,
+		language,
+		configService.DefInt("authentication.otp_length", 0),
+		phonePrefixes,
+		generatorService,
+	), nil
+}
+
+func fakeSMSOTPProviderBuilder(_ config.IConfig, _ generator.IGenerator, _ []string) (otp.IOTPSMSGateway, error) {
+	if !service.HasService(service.SMSFakeService) {
+		return nil, errors.New("you should register ServiceProviderSMSFake")
+	}
+
+	return service.DI().SMSFake(), nil
+}
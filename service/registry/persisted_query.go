@@ -0,0 +1,47 @@
+package registry
+
+import (
+	"time"
+
+	"github.com/sarulabs/di"
+
+	"github.com/coretrix/hitrix/datalayer"
+	"github.com/coretrix/hitrix/service"
+	"github.com/coretrix/hitrix/service/component/clock"
+	"github.com/coretrix/hitrix/service/component/config"
+	persistedquery "github.com/coretrix/hitrix/service/component/persisted_query"
+)
+
+func ServiceProviderPersistedQuery() *service.DefinitionGlobal {
+	return &service.DefinitionGlobal{
+		Name: service.PersistedQueryService,
+		Build: func(ctn di.Container) (interface{}, error) {
+			configService := ctn.Get(service.ConfigService).(config.IConfig)
+			redis := ctn.Get(service.ORMEngineGlobalService).(*datalayer.ORM).GetRedis()
+
+			var loader persistedquery.ManifestLoader
+
+			refresh := 0
+
+			if manifestFile, ok := configService.String("server.graphql.persisted_queries.manifest_file"); ok {
+				loader = persistedquery.FileManifestLoader(manifestFile)
+			} else if manifestRedisKey, ok := configService.String("server.graphql.persisted_queries.manifest_redis_key"); ok {
+				loader = persistedquery.RedisManifestLoader(redis, manifestRedisKey)
+				refresh = configService.DefInt("server.graphql.persisted_queries.manifest_refresh_sec", 60)
+			}
+
+			allowList, _ := configService.Bool("server.graphql.persisted_queries.allow_list")
+
+			return persistedquery.NewPersistedQuery(
+				loader,
+				allowList,
+				&persistedquery.RedisAPQCache{
+					Redis: redis,
+					TTL:   time.Duration(configService.DefInt("server.graphql.persisted_queries.apq_ttl_sec", 24*60*60)) * time.Second,
+				},
+				ctn.Get(service.ClockService).(clock.IClock),
+				time.Duration(refresh)*time.Second,
+			)
+		},
+	}
+}
@@ -0,0 +1,52 @@
+package registry
+
+import (
+	"github.com/sarulabs/di"
+
+	"github.com/coretrix/hitrix/service"
+	"github.com/coretrix/hitrix/service/component/clock"
+	"github.com/coretrix/hitrix/service/component/config"
+	"github.com/coretrix/hitrix/service/component/fcm"
+	"github.com/coretrix/hitrix/service/component/push"
+)
+
+// ServiceProviderPush registers push service. FCM provider is added automatically when ServiceProviderFCM is registered
+func ServiceProviderPush(newProviderFunctions ...push.NewProviderFunc) *service.DefinitionGlobal {
+	return &service.DefinitionGlobal{
+		Name: service.PushService,
+		Build: func(ctn di.Container) (interface{}, error) {
+			configService := ctn.Get(service.ConfigService).(config.IConfig)
+			clockService := ctn.Get(service.ClockService).(clock.IClock)
+
+			providers := make([]push.IProvider, 0, len(newProviderFunctions)+1)
+			hasFCMProvider := false
+
+			for _, newProviderFunc := range newProviderFunctions {
+				provider, err := newProviderFunc(configService, clockService)
+				if err != nil {
+					return nil, err
+				}
+
+				hasFCMProvider = hasFCMProvider || provider.GetName() == push.FCM
+				providers = append(providers, provider)
+			}
+
+			if !hasFCMProvider {
+				if fcmService, err := ctn.SafeGet(service.FCMService); err == nil {
+					providers = append(providers, push.NewFCMProvider(fcmService.(fcm.FCM)))
+				}
+			}
+
+			platforms, _ := configService.StringMap("push.platforms")
+
+			return push.NewPush(platforms, providers...), nil
+		},
+	}
+}
+
+// PushFakeProvider returns provider which records messages in memory, see push.FakeProvider
+func PushFakeProvider(name string) push.NewProviderFunc {
+	return func(_ config.IConfig, _ clock.IClock) (push.IProvider, error) {
+		return push.NewFakeProvider(name), nil
+	}
+}
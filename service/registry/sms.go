@@ -2,6 +2,7 @@ package registry
 
 import (
 	"errors"
+	"time"
 
 	"github.com/latolukasz/beeorm/v2"
 	"github.com/sarulabs/di"
@@ -50,7 +51,87 @@ func ServiceProviderSMS(primaryNewFunc sms.NewProviderFunc, secondaryNewFunc sms
 				panic(err)
 			}
 
+			if err := configureSMSSender(sender, configService, clockService); err != nil {
+				return nil, err
+			}
+
+			return sender, nil
+		},
+	}
+}
+
+// ServiceProviderSMSChain registers sms sender which tries providers in the given order
+func ServiceProviderSMSChain(providerNewFuncs ...sms.NewProviderFunc) *service.DefinitionGlobal {
+	return &service.DefinitionGlobal{
+		Name: service.SMSService,
+		Build: func(ctn di.Container) (interface{}, error) {
+			ormConfig := ctn.Get(service.ORMConfigService).(beeorm.ValidatedRegistry)
+			entities := ormConfig.GetEntities()
+			if _, ok := entities["entity.SmsTrackerEntity"]; !ok {
+				return nil, errors.New("you should register SmsTrackerEntity")
+			}
+
+			if len(providerNewFuncs) == 0 {
+				return nil, errors.New("at least one sms provider is required")
+			}
+
+			configService := ctn.Get(service.ConfigService).(config.IConfig)
+			clockService := ctn.Get(service.ClockService).(clock.IClock)
+
+			sender := &sms.Sender{
+				ConfigService:      configService,
+				ClockService:       clockService,
+				ErrorLoggerService: ctn.Get(service.ErrorLoggerService).(errorlogger.ErrorLogger),
+				Providers:          make([]sms.IProvider, 0, len(providerNewFuncs)),
+			}
+
+			for _, providerNewFunc := range providerNewFuncs {
+				provider, err := providerNewFunc(configService, clockService)
+				if err != nil {
+					return nil, err
+				}
+
+				sender.Providers = append(sender.Providers, provider)
+			}
+
+			if err := configureSMSSender(sender, configService, clockService); err != nil {
+				return nil, err
+			}
+
 			return sender, nil
 		},
 	}
 }
+
+func configureSMSSender(sender *sms.Sender, configService config.IConfig, clockService clock.IClock) error {
+	if _, ok := configService.Get("sms.routes"); ok {
+		routesConfig := map[string][]string{}
+		if err := configService.MapStruct("sms.routes", &routesConfig); err != nil {
+			return err
+		}
+
+		routes := make([]*sms.Route, 0, len(routesConfig))
+		for prefix, providers := range routesConfig {
+			routes = append(routes, &sms.Route{Prefixes: []string{prefix}, Providers: providers})
+		}
+
+		sender.Router = sms.NewRouter(routes...)
+	}
+
+	if _, ok := configService.Get("sms.costs"); ok {
+		sender.CostTable = sms.CostTable{}
+		if err := configService.MapStruct("sms.costs", &sender.CostTable); err != nil {
+			return err
+		}
+	}
+
+	if enabled, _ := configService.Bool("sms.circuit_breaker.enabled"); enabled {
+		sender.CircuitBreaker = sms.NewCircuitBreaker(
+			clockService,
+			configService.DefInt("sms.circuit_breaker.threshold", 0),
+			time.Duration(configService.DefInt("sms.circuit_breaker.cooldown", 0))*time.Second,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package registry
+
+import (
+	"errors"
+
+	"github.com/sarulabs/di"
+
+	"github.com/coretrix/hitrix/datalayer"
+	"github.com/coretrix/hitrix/service"
+	"github.com/coretrix/hitrix/service/component/app"
+	"github.com/coretrix/hitrix/service/component/clock"
+	"github.com/coretrix/hitrix/service/component/config"
+	fakesms "github.com/coretrix/hitrix/service/component/fake_sms"
+	"github.com/coretrix/hitrix/service/component/generator"
+	"github.com/coretrix/hitrix/service/component/sms"
+)
+
+// ServiceProviderSMSFake registers in-process sms and otp gateway. It can not be used in prod mode
+func ServiceProviderSMSFake() *service.DefinitionGlobal {
+	return &service.DefinitionGlobal{
+		Name: service.SMSFakeService,
+		Build: func(ctn di.Container) (interface{}, error) {
+			appService := ctn.Get(service.AppService).(*app.App)
+			if appService.IsInProdMode() {
+				return nil, errors.New("fake sms gateway can not be used in prod mode")
+			}
+
+			configService := ctn.Get(service.ConfigService).(config.IConfig)
+
+			var store fakesms.IStore
+
+			switch storage := configService.DefString("sms.fake.storage", "memory"); storage {
+			case "memory":
+				store = fakesms.NewMemoryStore()
+			case "redis":
+				ormService := ctn.Get(service.ORMEngineGlobalService).(*datalayer.ORM)
+				store = fakesms.NewRedisStore(ormService.GetRedis(configService.DefString("sms.fake.redis_pool", "default")))
+			default:
+				return nil, errors.New("unknown sms.fake.storage: " + storage)
+			}
+
+			return fakesms.NewFake(
+				store,
+				ctn.Get(service.ClockService).(clock.IClock),
+				ctn.Get(service.GeneratorService).(generator.IGenerator),
+				configService.DefInt("authentication.otp_length", 0),
+			), nil
+		},
+	}
+}
+
+// SMSFakeProvider can be passed to ServiceProviderSMS or ServiceProviderSMSChain. It requires ServiceProviderSMSFake
+func SMSFakeProvider(_ config.IConfig, _ clock.IClock) (sms.IProvider, error) {
+	if !service.HasService(service.SMSFakeService) {
+		return nil, errors.New("you should register ServiceProviderSMSFake")
+	}
+
+	return service.DI().SMSFake(), nil
+}
@@ -0,0 +1,64 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/latolukasz/beeorm/v2"
+	"github.com/sarulabs/di"
+
+	"github.com/coretrix/hitrix/service"
+	"github.com/coretrix/hitrix/service/component/app"
+	"github.com/coretrix/hitrix/service/component/authentication"
+	"github.com/coretrix/hitrix/service/component/config"
+	"github.com/coretrix/hitrix/service/component/subscription"
+)
+
+// ServiceProviderSubscriptionBroker registers broker of GraphQL subscription events. When newUserEntity is set
+// websocket connections have to send access token in connection init payload
+func ServiceProviderSubscriptionBroker(newUserEntity func() beeorm.Entity) *service.DefinitionGlobal {
+	return &service.DefinitionGlobal{
+		Name: service.SubscriptionBrokerService,
+		Build: func(ctn di.Container) (interface{}, error) {
+			configService := ctn.Get(service.ConfigService).(config.IConfig)
+			appService := ctn.Get(service.AppService).(*app.App)
+
+			pool := configService.DefString("server.graphql.subscriptions.redis_pool", appService.RedisPools.Persistent)
+
+			uri, ok := configService.String("orm." + pool + ".redis")
+			if !ok {
+				return nil, fmt.Errorf("redis uri of pool %s not found", pool)
+			}
+
+			pubSub, err := subscription.NewRedisPubSub(uri)
+			if err != nil {
+				return nil, err
+			}
+
+			var authenticator subscription.Authenticator
+
+			if newUserEntity != nil {
+				authenticationService := ctn.Get(service.AuthenticationService).(*authentication.Authentication)
+
+				authenticator = func(ctx context.Context, accessToken string) (uint64, error) {
+					userEntity := newUserEntity()
+
+					_, err := authenticationService.VerifyAccessToken(service.DI().OrmEngineForContext(ctx), accessToken, userEntity)
+					if err != nil {
+						return 0, err
+					}
+
+					return userEntity.GetID(), nil
+				}
+			}
+
+			return subscription.NewBroker(
+				pubSub,
+				authenticator,
+				configService.DefInt("server.graphql.subscriptions.buffer_size", 100),
+				configService.DefInt("server.graphql.subscriptions.max_dropped_events", 100),
+				configService.DefInt("server.graphql.subscriptions.max_per_user", 10),
+			), nil
+		},
+	}
+}
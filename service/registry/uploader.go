@@ -4,7 +4,11 @@ import (
 	"github.com/sarulabs/di"
 	tusd "github.com/tus/tusd/pkg/handler"
 
+	"github.com/coretrix/hitrix/datalayer"
 	"github.com/coretrix/hitrix/service"
+	"github.com/coretrix/hitrix/service/component/clock"
+	errorlogger "github.com/coretrix/hitrix/service/component/error_logger"
+	filevalidator "github.com/coretrix/hitrix/service/component/file_validator"
 	"github.com/coretrix/hitrix/service/component/oss"
 	"github.com/coretrix/hitrix/service/component/uploader"
 	"github.com/coretrix/hitrix/service/component/uploader/locker"
@@ -29,7 +33,41 @@ func ServiceProviderUploader(c tusd.Config, getLockerFunc locker.GetLockerFunc)
 
 			c.StoreComposer = composer
 
+			if fileListener, err := ctn.SafeGet(service.UploaderFileListenerService); err == nil {
+				c.PreUploadCreateCallback = fileListener.(uploader.IFileListener).PreUploadCreateCallback(c.PreUploadCreateCallback)
+				c.NotifyCompleteUploads = true
+				c.NotifyTerminatedUploads = true
+			}
+
+			if validatorService, err := ctn.SafeGet(service.FileValidatorService); err == nil {
+				c.PreUploadCreateCallback = uploader.NewPreUploadCreateCallback(validatorService.(filevalidator.IValidator), c.PreUploadCreateCallback)
+			}
+
 			return uploader.NewTUSDUploader(c, uploaderBucketName), nil
 		},
 	}
 }
+
+// ServiceProviderUploaderFileListener registers listener which creates FileEntity for completed uploads.
+// authorizeFunc is optional
+func ServiceProviderUploaderFileListener(authorizeFunc uploader.AuthorizeFunc) *service.DefinitionGlobal {
+	return &service.DefinitionGlobal{
+		Name: service.UploaderFileListenerService,
+		Build: func(ctn di.Container) (interface{}, error) {
+			var validatorService filevalidator.IValidator
+
+			if validator, err := ctn.SafeGet(service.FileValidatorService); err == nil {
+				validatorService = validator.(filevalidator.IValidator)
+			}
+
+			return uploader.NewFileListener(
+				ctn.Get(service.ORMEngineGlobalService).(*datalayer.ORM),
+				ctn.Get(service.OSService).(oss.IProvider),
+				ctn.Get(service.ClockService).(clock.IClock),
+				ctn.Get(service.ErrorLoggerService).(errorlogger.ErrorLogger),
+				validatorService,
+				authorizeFunc,
+			), nil
+		},
+	}
+}
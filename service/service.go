@@ -21,14 +21,17 @@ import (
 	"github.com/coretrix/hitrix/service/component/elorus"
 	errorlogger "github.com/coretrix/hitrix/service/component/error_logger"
 	"github.com/coretrix/hitrix/service/component/exporter"
+	fakesms "github.com/coretrix/hitrix/service/component/fake_sms"
 	"github.com/coretrix/hitrix/service/component/fcm"
 	featureflag "github.com/coretrix/hitrix/service/component/feature_flag"
 	fileextractor "github.com/coretrix/hitrix/service/component/file_extractor"
+	filevalidator "github.com/coretrix/hitrix/service/component/file_validator"
 	"github.com/coretrix/hitrix/service/component/generator"
 	"github.com/coretrix/hitrix/service/component/geocoding"
 	googleanalytics "github.com/coretrix/hitrix/service/component/google_analytics"
 	"github.com/coretrix/hitrix/service/component/gql"
 	"github.com/coretrix/hitrix/service/component/html2pdf"
+	"github.com/coretrix/hitrix/service/component/importer"
 	"github.com/coretrix/hitrix/service/component/instagram"
 	"github.com/coretrix/hitrix/service/component/jwt"
 	"github.com/coretrix/hitrix/service/component/kubernetes"
@@ -38,6 +41,8 @@ import (
 	"github.com/coretrix/hitrix/service/component/oss"
 	"github.com/coretrix/hitrix/service/component/otp"
 	"github.com/coretrix/hitrix/service/component/password"
+	persistedquery "github.com/coretrix/hitrix/service/component/persisted_query"
+	"github.com/coretrix/hitrix/service/component/push"
 	requestlogger "github.com/coretrix/hitrix/service/component/request_logger"
 	"github.com/coretrix/hitrix/service/component/sentry"
 	"github.com/coretrix/hitrix/service/component/setting"
@@ -46,6 +51,7 @@ import (
 	"github.com/coretrix/hitrix/service/component/social"
 	"github.com/coretrix/hitrix/service/component/socket"
 	"github.com/coretrix/hitrix/service/component/stripe"
+	"github.com/coretrix/hitrix/service/component/subscription"
 	"github.com/coretrix/hitrix/service/component/template"
 	"github.com/coretrix/hitrix/service/component/translation"
 	"github.com/coretrix/hitrix/service/component/uploader"
@@ -62,6 +68,9 @@ const (
 	JWTService                    = "jwt"
 	DDOSService                   = "ddos"
 	FCMService                    = "fcm"
+	FCMDeviceRegistryService      = "fcm_device_registry"
+	PushService                   = "push"
+	FileValidatorService          = "file_validator"
 	ORMConfigService              = "orm_config"
 	ORMEngineGlobalService        = "orm_engine_global"
 	ORMEngineRequestService       = "orm_engine_request"
@@ -72,6 +81,7 @@ const (
 	SlackService                  = "slack"
 	AmazonS3Service               = "amazon_s3"
 	UploaderService               = "uploader"
+	UploaderFileListenerService   = "uploader_file_listener"
 	StripeService                 = "stripe"
 	CheckoutService               = "checkout"
 	DynamicLinkService            = "dynamic_link"
@@ -80,6 +90,7 @@ const (
 	AuthenticationService         = "authentication"
 	ClockService                  = "clock"
 	SMSService                    = "sms"
+	SMSFakeService                = "sms_fake"
 	GoroutineService              = "goroutine"
 	GeneratorService              = "generator"
 	MailService                   = "mail"
@@ -90,6 +101,7 @@ const (
 	UUIDService                   = "uuid"
 	OTPService                    = "otp"
 	ExporterService               = "exporter"
+	ImporterService               = "importer"
 	SettingService                = "setting"
 	FeatureFlagService            = "feature_flag"
 	TemplateService               = "template"
@@ -103,6 +115,8 @@ const (
 	RequestLoggerService          = "request_logger"
 	LicensePlateRecognizerService = "license_plate_recognizer"
 	GeocodingService              = "geocoding"
+	PersistedQueryService         = "persisted_query"
+	SubscriptionBrokerService     = "subscription_broker"
 )
 
 type DIContainer struct {
@@ -122,6 +136,10 @@ func (d *DIContainer) Exporter() exporter.IExporter {
 	return GetServiceRequired(ExporterService).(exporter.IExporter)
 }
 
+func (d *DIContainer) Importer() importer.IImporter {
+	return GetServiceRequired(ImporterService).(importer.IImporter)
+}
+
 func (d *DIContainer) AmazonS3() s3.Client {
 	return GetServiceRequired(AmazonS3Service).(s3.Client)
 }
@@ -214,6 +232,10 @@ func (d *DIContainer) Uploader() uploader.Uploader {
 	return GetServiceRequired(UploaderService).(uploader.Uploader)
 }
 
+func (d *DIContainer) UploaderFileListener() uploader.IFileListener {
+	return GetServiceRequired(UploaderFileListenerService).(uploader.IFileListener)
+}
+
 func (d *DIContainer) Crud() *crud.Crud {
 	return GetServiceRequired(CrudService).(*crud.Crud)
 }
@@ -230,6 +252,10 @@ func (d *DIContainer) UUID() uuid.IUUID {
 	return GetServiceRequired(UUIDService).(uuid.IUUID)
 }
 
+func (d *DIContainer) SMSFake() fakesms.IFake {
+	return GetServiceRequired(SMSFakeService).(fakesms.IFake)
+}
+
 func (d *DIContainer) OTP() otp.IOTP {
 	return GetServiceRequired(OTPService).(otp.IOTP)
 }
@@ -246,6 +272,18 @@ func (d *DIContainer) FCM() fcm.FCM {
 	return GetServiceRequired(FCMService).(fcm.FCM)
 }
 
+func (d *DIContainer) FCMDeviceRegistry() fcm.IDeviceRegistry {
+	return GetServiceRequired(FCMDeviceRegistryService).(fcm.IDeviceRegistry)
+}
+
+func (d *DIContainer) Push() push.IPush {
+	return GetServiceRequired(PushService).(push.IPush)
+}
+
+func (d *DIContainer) FileValidator() filevalidator.IValidator {
+	return GetServiceRequired(FileValidatorService).(filevalidator.IValidator)
+}
+
 func (d *DIContainer) HTML2PDF() html2pdf.ServiceInterface {
 	return GetServiceRequired(HTML2PDFService).(html2pdf.ServiceInterface)
 }
@@ -301,3 +339,11 @@ func (d *DIContainer) Geocoding() geocoding.IGeocoding {
 func (d *DIContainer) Translation() translation.ITranslationService {
 	return GetServiceRequired(TranslationService).(translation.ITranslationService)
 }
+
+func (d *DIContainer) PersistedQuery() persistedquery.IPersistedQuery {
+	return GetServiceRequired(PersistedQueryService).(persistedquery.IPersistedQuery)
+}
+
+func (d *DIContainer) SubscriptionBroker() subscription.IBroker {
+	return GetServiceRequired(SubscriptionBrokerService).(subscription.IBroker)
+}
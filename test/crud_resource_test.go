@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/latolukasz/beeorm/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/xorcare/pointer"
+
+	"github.com/coretrix/hitrix/datalayer"
+	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/service"
+	"github.com/coretrix/hitrix/service/component/clock/mocks"
+	"github.com/coretrix/hitrix/service/component/crud"
+	registryMocks "github.com/coretrix/hitrix/service/registry/mocks"
+)
+
+type featureFlagResourceRow struct {
+	ID        uint64
+	Name      string
+	Enabled   bool
+	CreatedAt time.Time
+}
+
+type featureFlagResourceRequest struct {
+	Name    string `binding:"required,max=100"`
+	Enabled *bool
+}
+
+type featureFlagResourceList struct {
+	Rows    []*featureFlagResourceRow
+	Total   int
+	Columns []*crud.Column
+	Facets  *crud.Facets
+}
+
+func TestCrudResource(t *testing.T) {
+	fakeClock := &mocks.FakeSysClock{}
+	fakeClock.On("Now").Return(time.Unix(1, 0))
+
+	ctx := createContextMyApp(t, "server", nil, []*service.DefinitionGlobal{registryMocks.ServiceProviderMockClock(fakeClock)}, nil)
+
+	// X-Read-Only header denies create, update and delete
+	crud.ResourceFor(&entity.FeatureFlagEntity{}, &crud.ResourceOptions{
+		ORM: func(ctx context.Context) *datalayer.ORM {
+			return service.DI().OrmEngineForContext(ctx)
+		},
+		ACL: func(c *gin.Context, _ *datalayer.ORM, resource, permission string) bool {
+			assert.Equal(t, "feature_flags", resource)
+
+			return c.GetHeader("X-Read-Only") == "" || permission == crud.ActionList.String() || permission == crud.ActionGet.String()
+		},
+		NewRequest: func() interface{} {
+			return &featureFlagResourceRequest{}
+		},
+		BeforeSave: func(_ *gin.Context, _ *datalayer.ORM, savedEntity beeorm.Entity, _ interface{}) error {
+			featureFlagEntity := savedEntity.(*entity.FeatureFlagEntity)
+			if featureFlagEntity.ID == 0 {
+				featureFlagEntity.CreatedAt = service.DI().Clock().Now()
+			}
+
+			return nil
+		},
+		Fields: []string{"Name", "Enabled", "CreatedAt"},
+		Facets: true,
+		Delete: true,
+	}).Register(ctx.GinEngine.Group("/v1/test-feature-flags"))
+
+	sendReadOnly := func(method, path, body string) int {
+		request := httptest.NewRequest(method, "/v1/test-feature-flags"+path, bytes.NewReader([]byte(body)))
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("X-Read-Only", "1")
+
+		recorder := httptest.NewRecorder()
+		ctx.GinEngine.ServeHTTP(recorder, request)
+
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, sendReadOnly(http.MethodPost, "/", `{"Name": "denied"}`))
+
+	created := &featureFlagResourceRow{}
+	err := SendHTTPRequestWithBody(ctx, http.MethodPost, "/test-feature-flags/", map[string]interface{}{"Name": "first", "Enabled": true}, false, created)
+	assert.Nil(t, err)
+	assert.NotZero(t, created.ID)
+	assert.True(t, created.Enabled)
+	assert.Equal(t, time.Unix(1, 0).UTC(), created.CreatedAt.UTC())
+
+	err = SendHTTPRequestWithBody(ctx, http.MethodPost, "/test-feature-flags/", map[string]interface{}{"Enabled": true}, false, nil)
+	assert.NotNil(t, err)
+
+	err = SendHTTPRequestWithBody(ctx, http.MethodPost, "/test-feature-flags/", map[string]interface{}{"Name": "second"}, false, nil)
+	assert.Nil(t, err)
+
+	updated := &featureFlagResourceRow{}
+	err = SendHTTPRequestWithBody(ctx, http.MethodPut, "/test-feature-flags/1/", map[string]interface{}{"Name": "renamed"}, false, updated)
+	assert.Nil(t, err)
+	assert.Equal(t, "renamed", updated.Name)
+	assert.True(t, updated.Enabled)
+
+	list := &featureFlagResourceList{}
+	err = SendHTTPRequestWithBody(ctx, http.MethodPost, "/test-feature-flags/list/", &crud.ListRequest{
+		Page:     pointer.Int(1),
+		PageSize: pointer.Int(10),
+		Search:   map[string]interface{}{"Name": "ren"},
+	}, false, list)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, list.Total)
+	assert.Equal(t, "renamed", list.Rows[0].Name)
+	assert.NotEmpty(t, list.Columns)
+
+	list = &featureFlagResourceList{}
+	err = SendHTTPRequestWithBody(ctx, http.MethodPost, "/test-feature-flags/list/", &crud.ListRequest{
+		Page:     pointer.Int(1),
+		PageSize: pointer.Int(10),
+		Search:   map[string]interface{}{"Enabled": true},
+	}, false, list)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, list.Total)
+	assert.Len(t, list.Facets.Counts["Enabled"], 2)
+	assert.Equal(t, true, list.Facets.Counts["Enabled"][0].Value)
+	assert.Equal(t, uint64(1), list.Facets.Counts["Enabled"][0].Count)
+	assert.Equal(t, uint64(1), list.Facets.Counts["Enabled"][1].Count)
+
+	row := map[string]interface{}{}
+	err = SendHTTPRequest(ctx, http.MethodGet, "/test-feature-flags/1/", false, &row)
+	assert.Nil(t, err)
+	assert.NotContains(t, row, "Registered")
+	assert.Contains(t, row, "Name")
+
+	assert.Equal(t, http.StatusForbidden, sendReadOnly(http.MethodPut, "/1/", `{"Name": "denied"}`))
+	assert.Equal(t, http.StatusForbidden, sendReadOnly(http.MethodDelete, "/1/", ""))
+	assert.Equal(t, http.StatusOK, sendReadOnly(http.MethodGet, "/1/", ""))
+
+	err = SendHTTPRequest(ctx, http.MethodDelete, "/test-feature-flags/1/", false, nil)
+	assert.Nil(t, err)
+
+	featureFlagEntity := &entity.FeatureFlagEntity{}
+	assert.False(t, service.DI().OrmEngine().LoadByID(1, featureFlagEntity))
+
+	err = SendHTTPRequest(ctx, http.MethodGet, "/test-feature-flags/1/", false, nil)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/pkg/graphql/dataloader"
+	"github.com/coretrix/hitrix/service"
+	"github.com/coretrix/hitrix/service/component/clock/mocks"
+	registryMocks "github.com/coretrix/hitrix/service/registry/mocks"
+)
+
+func TestEntityDataLoaders(t *testing.T) {
+	fakeClock := &mocks.FakeSysClock{}
+	fakeClock.On("Now").Return(time.Unix(1, 0))
+
+	env := createContextMyApp(t, "server", nil, []*service.DefinitionGlobal{registryMocks.ServiceProviderMockClock(fakeClock)}, nil)
+	ctx := context.WithValue(context.Background(), service.GinKey, env.Cxt)
+
+	flusher := service.DI().OrmEngine().Clone().NewFlusher()
+
+	user := CreateResource(flusher, map[string]interface{}{"Name": "user"})
+	car := CreateResource(flusher, map[string]interface{}{"Name": "car"})
+	empty := CreateResource(flusher, map[string]interface{}{"Name": "empty"})
+	flusher.Flush()
+
+	CreatePermission(flusher, map[string]interface{}{"ResourceID": user, "Name": "view"})
+	CreatePermission(flusher, map[string]interface{}{"ResourceID": car, "Name": "drive"})
+	CreatePermission(flusher, map[string]interface{}{"ResourceID": car, "Name": "lock"})
+	flusher.Flush()
+
+	resources, err := dataloader.EntityLoader[*entity.ResourceEntity](ctx).LoadAll(ctx, []uint64{car.ID, 9999, user.ID})
+	assert.Nil(t, err)
+	assert.Len(t, resources, 3)
+	assert.Equal(t, "car", resources[0].Name)
+	assert.Nil(t, resources[1])
+	assert.Equal(t, "user", resources[2].Name)
+
+	permissionsByResource, err := dataloader.ReferenceLoader[*entity.PermissionEntity](ctx, "ResourceID").
+		LoadAll(ctx, []uint64{user.ID, car.ID, empty.ID})
+	assert.Nil(t, err)
+	assert.Len(t, permissionsByResource[0], 1)
+	assert.Equal(t, "view", permissionsByResource[0][0].Name)
+	assert.Len(t, permissionsByResource[1], 2)
+	assert.Empty(t, permissionsByResource[2])
+
+	stats := dataloader.ForContext(ctx).Stats()
+	assert.Len(t, stats, 2)
+	assert.Equal(t, 1, stats["EntityByID:*entity.ResourceEntity"].Batches)
+	assert.Equal(t, 1, stats["EntitiesByResourceID:*entity.PermissionEntity"].Batches)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/latolukasz/beeorm/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/coretrix/hitrix/datalayer"
+	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/pkg/queue/consumers"
+	"github.com/coretrix/hitrix/service"
+	mockClockComponent "github.com/coretrix/hitrix/service/component/clock/mocks"
+	"github.com/coretrix/hitrix/service/component/crud"
+	"github.com/coretrix/hitrix/service/component/exporter"
+	"github.com/coretrix/hitrix/service/component/oss"
+	ossMocks "github.com/coretrix/hitrix/service/component/oss/mocks"
+	registryMocks "github.com/coretrix/hitrix/service/registry/mocks"
+)
+
+type exportJobEvent struct {
+	beeorm.Event
+	dto *crud.ExportJobDTO
+}
+
+func (e *exportJobEvent) Unserialize(value interface{}) {
+	*value.(*crud.ExportJobDTO) = *e.dto
+}
+
+func TestExportJobConsumer(t *testing.T) {
+	clock := &mockClockComponent.FakeSysClock{}
+	clock.On("Now").Return(time.Unix(1, 0))
+
+	createContextMyApp(t, "server", nil,
+		[]*service.DefinitionGlobal{
+			registryMocks.ServiceProviderMockClock(clock),
+		},
+		nil)
+
+	ormService := service.DI().OrmEngine()
+	namespace := oss.Namespace("export")
+
+	crudService := &crud.Crud{ExportConfigs: []crud.ExportConfig{{
+		ID: "numbers",
+		Handler: func(_ entity.TranslationTextLang, _ *datalayer.ORM, request *crud.ListRequest, _ uint64, _ map[string]string) ([]string, [][]interface{}, error) {
+			from := (*request.Page - 1) * *request.PageSize
+			rows := make([][]interface{}, 0)
+
+			for i := from; i < from+*request.PageSize && i < 5; i++ {
+				rows = append(rows, []interface{}{i})
+			}
+
+			return []string{"Number"}, rows, nil
+		},
+	}}}
+
+	uploaded := make([]string, 0)
+
+	fakeOSS := &ossMocks.FakeOSSClient{}
+	fakeOSS.On("UploadObjectFromByte", namespace, mock.Anything, "csv").Run(func(args mock.Arguments) {
+		uploaded = append(uploaded, string(args.Get(1).([]byte)))
+	}).Return(entity.FileObject{ID: 1, StorageKey: "export/1.csv"})
+	fakeOSS.On("GetObjectSignedURL", namespace, mock.Anything, time.Unix(1, 0).Add(time.Hour)).Return("https://bucket/export/1.csv?signature=1")
+
+	var notifiedURLs []string
+
+	exportJobConsumer := consumers.NewExportJobConsumer(
+		ormService,
+		crudService,
+		exporter.NewExportService(exporter.NewXLSXExportService(), exporter.NewCSVExportService()),
+		fakeOSS,
+		clock,
+		func(_ *datalayer.ORM, _ *entity.ExportJobEntity, urls []string) error {
+			notifiedURLs = urls
+
+			return nil
+		},
+		consumers.ExportJobOptions{Namespace: namespace, PageSize: 2, MaxRowsPerFile: 2, URLExpiration: time.Hour},
+	)
+
+	exportJobEntity := &entity.ExportJobEntity{
+		UserID:    1,
+		ExportID:  "numbers",
+		Format:    exporter.FormatCSV.String(),
+		Request:   "{}",
+		Status:    entity.ExportJobStatusPending.String(),
+		CreatedAt: time.Unix(1, 0),
+	}
+	ormService.Flush(exportJobEntity)
+
+	event := &exportJobEvent{dto: &crud.ExportJobDTO{ExportJobEntityID: exportJobEntity.ID}}
+	assert.Nil(t, exportJobConsumer.Consume(ormService, event))
+
+	assert.Equal(t, []string{"Number\n0\n1\n", "Number\n2\n3\n", "Number\n4\n"}, uploaded)
+	assert.Len(t, notifiedURLs, 3)
+
+	ormService.LoadByID(exportJobEntity.ID, exportJobEntity)
+	assert.Equal(t, entity.ExportJobStatusCompleted.String(), exportJobEntity.Status)
+	assert.Equal(t, 5, exportJobEntity.Rows)
+	assert.Len(t, exportJobEntity.Objects, 3)
+
+	assert.Nil(t, exportJobConsumer.Consume(ormService, event))
+	fakeOSS.AssertNumberOfCalls(t, "UploadObjectFromByte", 3)
+
+	failedExportJobEntity := &entity.ExportJobEntity{
+		UserID:    1,
+		ExportID:  "missing",
+		Format:    exporter.FormatCSV.String(),
+		Request:   "{}",
+		Status:    entity.ExportJobStatusPending.String(),
+		CreatedAt: time.Unix(1, 0),
+	}
+	ormService.Flush(failedExportJobEntity)
+
+	assert.Nil(t, exportJobConsumer.Consume(ormService, &exportJobEvent{dto: &crud.ExportJobDTO{ExportJobEntityID: failedExportJobEntity.ID}}))
+	assert.Nil(t, notifiedURLs)
+
+	ormService.LoadByID(failedExportJobEntity.ID, failedExportJobEntity)
+	assert.Equal(t, entity.ExportJobStatusFailed.String(), failedExportJobEntity.Status)
+
+	startedAt := time.Unix(1, 0).Add(-2 * time.Hour)
+	stuckExportJobEntity := &entity.ExportJobEntity{
+		UserID:    1,
+		ExportID:  "numbers",
+		Format:    exporter.FormatCSV.String(),
+		Request:   "{}",
+		Status:    entity.ExportJobStatusProcessing.String(),
+		CreatedAt: startedAt,
+		StartedAt: &startedAt,
+	}
+	ormService.Flush(stuckExportJobEntity)
+
+	assert.Equal(t, 1, exportJobConsumer.RecoverStuckJobs())
+
+	assert.Nil(t, exportJobConsumer.Consume(ormService, &exportJobEvent{dto: &crud.ExportJobDTO{ExportJobEntityID: stuckExportJobEntity.ID}}))
+
+	ormService.LoadByID(stuckExportJobEntity.ID, stuckExportJobEntity)
+	assert.Equal(t, entity.ExportJobStatusCompleted.String(), stuckExportJobEntity.Status)
+	assert.Equal(t, 5, stuckExportJobEntity.Rows)
+	assert.Equal(t, 0, exportJobConsumer.RecoverStuckJobs())
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"firebase.google.com/go/messaging"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/coretrix/hitrix/pkg/controller"
+	fcmDTO "github.com/coretrix/hitrix/pkg/dto/fcm"
+	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/pkg/middleware"
+	"github.com/coretrix/hitrix/service"
+	mockClockComponent "github.com/coretrix/hitrix/service/component/clock/mocks"
+	"github.com/coretrix/hitrix/service/component/fcm"
+	fcmMocks "github.com/coretrix/hitrix/service/component/fcm/mocks"
+	"github.com/coretrix/hitrix/service/registry"
+	mockClockRegistry "github.com/coretrix/hitrix/service/registry/mocks"
+)
+
+func TestFCMDeviceRegistryRegisterMovesTokenToNewUser(t *testing.T) {
+	clock := &mockClockComponent.FakeSysClock{}
+	clock.On("Now").Return(time.Unix(1, 0))
+
+	createContextMyApp(t, "server", nil,
+		[]*service.DefinitionGlobal{
+			mockClockRegistry.ServiceProviderMockClock(clock),
+		},
+		nil)
+
+	ormService := service.DI().OrmEngine()
+
+	fakeFCM := &fcmMocks.FakeFCM{}
+	fakeFCM.On("SubscribeToTopic", []string{"token-1"}, "news").Return(&messaging.TopicManagementResponse{SuccessCount: 1}, nil)
+	fakeFCM.On("UnsubscribeFromTopic", []string{"token-1"}, "news").Return(&messaging.TopicManagementResponse{SuccessCount: 1}, nil).Once()
+
+	deviceRegistry := fcm.NewDeviceRegistry(fakeFCM, service.DI().Clock())
+
+	_, err := deviceRegistry.Register(context.Background(), ormService, 1, entity.DeviceTokenPlatformAndroid, "token-1", "1.0.0")
+	assert.Nil(t, err)
+	assert.Nil(t, deviceRegistry.SubscribeUserToTopic(context.Background(), ormService, 1, "news"))
+
+	// token is unsubscribed from topics of the previous user
+	_, err = deviceRegistry.Register(context.Background(), ormService, 2, entity.DeviceTokenPlatformAndroid, "token-1", "1.0.1")
+	assert.Nil(t, err)
+
+	assert.Len(t, deviceRegistry.GetUserTokens(ormService, 1), 0)
+
+	deviceTokenEntities := deviceRegistry.GetUserTokens(ormService, 2)
+	assert.Len(t, deviceTokenEntities, 1)
+	assert.Equal(t, "1.0.1", deviceTokenEntities[0].AppVersion)
+
+	assert.Nil(t, deviceRegistry.Unregister(context.Background(), ormService, "token-1"))
+	assert.Len(t, deviceRegistry.GetUserTokens(ormService, 2), 0)
+
+	fakeFCM.AssertExpectations(t)
+}
+
+func TestFCMDeviceRegistrySubscribeRemovesNotRegisteredTokens(t *testing.T) {
+	clock := &mockClockComponent.FakeSysClock{}
+	clock.On("Now").Return(time.Unix(1, 0))
+
+	createContextMyApp(t, "server", nil,
+		[]*service.DefinitionGlobal{
+			mockClockRegistry.ServiceProviderMockClock(clock),
+		},
+		nil)
+
+	ormService := service.DI().OrmEngine()
+
+	fakeFCM := &fcmMocks.FakeFCM{}
+	fakeFCM.On("SubscribeToTopic", []string{"token-1", "token-2"}, "news").Return(
+		&messaging.TopicManagementResponse{
+			SuccessCount: 1,
+			FailureCount: 1,
+			Errors: []*messaging.ErrorInfo{
+				{Index: 1, Reason: "request contains an invalid argument; code: registration-token-not-registered"},
+			},
+		},
+		nil,
+	)
+
+	deviceRegistry := fcm.NewDeviceRegistry(fakeFCM, service.DI().Clock())
+
+	_, err := deviceRegistry.Register(context.Background(), ormService, 1, entity.DeviceTokenPlatformAndroid, "token-1", "1.0.0")
+	assert.Nil(t, err)
+	_, err = deviceRegistry.Register(context.Background(), ormService, 1, entity.DeviceTokenPlatformIOS, "token-2", "1.0.0")
+	assert.Nil(t, err)
+
+	err = deviceRegistry.SubscribeUserToTopic(context.Background(), ormService, 1, "news")
+	assert.Nil(t, err)
+
+	deviceTokenEntities := deviceRegistry.GetUserTokens(ormService, 1)
+	assert.Len(t, deviceTokenEntities, 1)
+	assert.Equal(t, "token-1", deviceTokenEntities[0].Token)
+
+	fakeFCM.On("SubscribeToTopic", []string{"token-1"}, "news").Return(&messaging.TopicManagementResponse{SuccessCount: 1}, nil)
+
+	err = deviceRegistry.ResyncUserTopics(context.Background(), ormService, 1)
+	assert.Nil(t, err)
+	fakeFCM.AssertExpectations(t)
+}
+
+func TestFCMDeviceRegistrySendToUserRemovesNotRegisteredTokens(t *testing.T) {
+	clock := &mockClockComponent.FakeSysClock{}
+	clock.On("Now").Return(time.Unix(1, 0))
+
+	createContextMyApp(t, "server", nil,
+		[]*service.DefinitionGlobal{
+			mockClockRegistry.ServiceProviderMockClock(clock),
+		},
+		nil)
+
+	ormService := service.DI().OrmEngine()
+
+	notRegisteredError := &messaging.SendResponse{Error: errors.New("registration-token-not-registered")}
+
+	fakeFCM := &fcmMocks.FakeFCM{}
+	fakeFCM.On("SendMulticast", &messaging.MulticastMessage{
+		Tokens:       []string{"token-1", "token-2", "token-3"},
+		Notification: &messaging.Notification{Title: "Hello"},
+	}).Return(
+		&messaging.BatchResponse{
+			SuccessCount: 1,
+			FailureCount: 2,
+			Responses: []*messaging.SendResponse{
+				{Success: true, MessageID: "1"},
+				notRegisteredError,
+				{Error: errors.New("internal error")},
+			},
+		},
+		nil,
+	)
+
+	deviceRegistry := fcm.NewDeviceRegistry(fakeFCM, service.DI().Clock())
+
+	_, err := deviceRegistry.Register(context.Background(), ormService, 1, entity.DeviceTokenPlatformAndroid, "token-1", "1.0.0")
+	assert.Nil(t, err)
+	_, err = deviceRegistry.Register(context.Background(), ormService, 1, entity.DeviceTokenPlatformIOS, "token-2", "1.0.0")
+	assert.Nil(t, err)
+	_, err = deviceRegistry.Register(context.Background(), ormService, 1, entity.DeviceTokenPlatformWeb, "token-3", "1.0.0")
+	assert.Nil(t, err)
+
+	batchResponse, err := deviceRegistry.SendToUser(context.Background(), ormService, 1, &messaging.MulticastMessage{
+		Notification: &messaging.Notification{Title: "Hello"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, batchResponse.SuccessCount)
+	assert.Equal(t, 2, batchResponse.FailureCount)
+
+	deviceTokenEntities := deviceRegistry.GetUserTokens(ormService, 1)
+	assert.Len(t, deviceTokenEntities, 2)
+	assert.Equal(t, "token-1", deviceTokenEntities[0].Token)
+	assert.Equal(t, "token-3", deviceTokenEntities[1].Token)
+}
+
+func TestFCMDeviceTokenAPI(t *testing.T) {
+	clock := &mockClockComponent.FakeSysClock{}
+	clock.On("Now").Return(time.Unix(1, 0))
+
+	fakeFCM := &fcmMocks.FakeFCM{}
+
+	env := createContextMyApp(t, "server", nil,
+		[]*service.DefinitionGlobal{
+			mockClockRegistry.ServiceProviderMockClock(clock),
+			mockClockRegistry.ServiceProviderMockFCM(fakeFCM),
+			registry.ServiceProviderFCMDeviceRegistry(),
+		},
+		nil)
+
+	middleware.FCMRouter(env.GinEngine, &controller.FCMController{
+		GetUserIDFunc: func(_ *gin.Context) uint64 {
+			return 1
+		},
+	})
+
+	ormService := service.DI().OrmEngine()
+	deviceRegistry := service.DI().FCMDeviceRegistry()
+
+	assert.Nil(t, deviceRegistry.SubscribeUserToTopic(context.Background(), ormService, 1, "news"))
+	_, err := deviceRegistry.Register(context.Background(), ormService, 2, entity.DeviceTokenPlatformAndroid, "token-2", "1.0.0")
+	assert.Nil(t, err)
+
+	fakeFCM.On("SubscribeToTopic", []string{"token-1"}, "news").Return(&messaging.TopicManagementResponse{SuccessCount: 1}, nil)
+
+	err = SendHTTPRequestWithBody(env, http.MethodPost, "/fcm/device-token/", &fcmDTO.RequestDTORegisterDeviceToken{
+		Platform:   entity.DeviceTokenPlatformAndroid,
+		Token:      "token-1",
+		AppVersion: "1.0.0",
+	}, false, nil)
+	assert.Nil(t, err)
+
+	deviceTokenEntities := deviceRegistry.GetUserTokens(ormService, 1)
+	assert.Len(t, deviceTokenEntities, 1)
+	assert.Equal(t, "token-1", deviceTokenEntities[0].Token)
+
+	err = SendHTTPRequestWithBody(env, http.MethodDelete, "/fcm/device-token/", &fcmDTO.RequestDTOUnregisterDeviceToken{Token: "token-2"}, false, nil)
+	assert.Nil(t, err)
+	assert.Len(t, deviceRegistry.GetUserTokens(ormService, 2), 1)
+
+	fakeFCM.On("UnsubscribeFromTopic", []string{"token-1"}, "news").Return(&messaging.TopicManagementResponse{SuccessCount: 1}, nil)
+
+	err = SendHTTPRequestWithBody(env, http.MethodDelete, "/fcm/device-token/", &fcmDTO.RequestDTOUnregisterDeviceToken{Token: "token-1"}, false, nil)
+	assert.Nil(t, err)
+	assert.Len(t, deviceRegistry.GetUserTokens(ormService, 1), 0)
+
+	fakeFCM.AssertExpectations(t)
+}
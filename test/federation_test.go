@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/coretrix/hitrix/example/entity"
+	"github.com/coretrix/hitrix/example/graph"
+	"github.com/coretrix/hitrix/example/graph/generated"
+	"github.com/coretrix/hitrix/pkg/graphql/federation"
+	"github.com/coretrix/hitrix/pkg/view/account"
+	"github.com/coretrix/hitrix/service"
+)
+
+func TestFederationLoadEntity(t *testing.T) {
+	env := createContextMyApp(t, "server", nil, nil, nil)
+	ctx := context.WithValue(context.Background(), service.GinKey, env.Cxt)
+
+	flusher := service.DI().OrmEngine().Clone().NewFlusher()
+
+	first := &entity.DevPanelUserEntity{Email: "first@coretrix.com"}
+	second := &entity.DevPanelUserEntity{Email: "second@coretrix.com"}
+	flusher.Track(first, second)
+	flusher.Flush()
+
+	firstID := strconv.FormatUint(first.ID, 10)
+
+	user, err := federation.LoadEntity[*entity.DevPanelUserEntity](ctx, firstID)
+	assert.Nil(t, err)
+	assert.Equal(t, "first@coretrix.com", user.Email)
+
+	_, err = federation.LoadEntity[*entity.DevPanelUserEntity](ctx, "9999")
+	assert.ErrorIs(t, err, federation.ErrEntityNotFound)
+
+	_, err = federation.LoadEntity[*entity.DevPanelUserEntity](ctx, "abc")
+	assert.EqualError(t, err, `invalid entity ID "abc"`)
+
+	users, err := federation.LoadEntities[*entity.DevPanelUserEntity](ctx, []uint64{second.ID, 9999, first.ID})
+	assert.Nil(t, err)
+	assert.Len(t, users, 3)
+	assert.Equal(t, "second@coretrix.com", users[0].Email)
+	assert.Nil(t, users[1])
+	assert.Equal(t, "first@coretrix.com", users[2].Email)
+
+	_, err = federation.LoadEntities[*entity.DevPanelUserEntity](ctx, []string{firstID, "0"})
+	assert.EqualError(t, err, `invalid entity ID "0"`)
+}
+
+func TestFederationSubgraph(t *testing.T) {
+	env := createContextMyApp(t, "server", generated.NewExecutableSchema(generated.Config{Resolvers: &graph.Resolver{}}), nil, nil)
+
+	assert.True(t, federation.IsSubgraph(generated.NewExecutableSchema(generated.Config{Resolvers: &graph.Resolver{}})))
+	assert.Nil(t, env.ValidateSubgraphSDL())
+
+	user := &entity.DevPanelUserEntity{Email: "gateway@coretrix.com"}
+	service.DI().OrmEngine().Flush(user)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"query": "query($representations: [_Any!]!) { _entities(representations: $representations) { ... on User { id name } } }",
+		"variables": map[string]interface{}{
+			"representations": []map[string]interface{}{
+				{"__typename": "User", "id": strconv.FormatUint(user.ID, 10)},
+				{"__typename": "User", "id": "9999"},
+			},
+		},
+	})
+
+	request := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(string(body)))
+	request.Header.Set("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+	env.GinEngine.ServeHTTP(recorder, request)
+
+	var response struct {
+		Data struct {
+			Entities []*struct {
+				ID   string
+				Name string
+			} `json:"_entities"`
+		}
+		Errors []struct {
+			Message string
+		}
+	}
+
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Len(t, response.Data.Entities, 2)
+	assert.Nil(t, response.Data.Entities[0])
+	assert.Nil(t, response.Data.Entities[1])
+	assert.Len(t, response.Errors, 2)
+	assert.Contains(t, response.Errors[0].Message, "permission denied")
+
+	env.Cxt.Set(account.LoggedDevPanelUserEntity, user)
+	ctx := context.WithValue(context.Background(), service.GinKey, env.Cxt)
+
+	resolver := (&graph.Resolver{}).Entity()
+
+	resolved, err := resolver.FindUserByID(ctx, strconv.FormatUint(user.ID, 10))
+	assert.Nil(t, err)
+	assert.Equal(t, strconv.FormatUint(user.ID, 10), resolved.ID)
+	assert.Equal(t, "gateway@coretrix.com", resolved.Name)
+
+	_, err = resolver.FindUserByID(ctx, "9999")
+	assert.ErrorIs(t, err, federation.ErrEntityNotFound)
+}
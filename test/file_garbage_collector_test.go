@@ -0,0 +1,104 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/pkg/model/file"
+	"github.com/coretrix/hitrix/service"
+	mockClockComponent "github.com/coretrix/hitrix/service/component/clock/mocks"
+	"github.com/coretrix/hitrix/service/component/oss"
+	ossMocks "github.com/coretrix/hitrix/service/component/oss/mocks"
+	registryMocks "github.com/coretrix/hitrix/service/registry/mocks"
+)
+
+func TestFileGarbageCollector(t *testing.T) {
+	now := time.Unix(100000, 0)
+
+	clock := &mockClockComponent.FakeSysClock{}
+	clock.On("Now").Return(now)
+
+	fakeOSS := &ossMocks.FakeOSSClient{}
+
+	env := createContextMyApp(t, "server", nil,
+		[]*service.DefinitionGlobal{
+			registryMocks.ServiceProviderMockClock(clock),
+			registryMocks.ServiceProviderMockOSS(fakeOSS),
+		},
+		nil)
+
+	ormService := service.DI().OrmEngine()
+
+	createFile := func(status entity.FileStatus, createdAt time.Time, id uint64) *entity.FileEntity {
+		fileEntity := &entity.FileEntity{
+			File:      &entity.FileObject{ID: id, StorageKey: "avatar/" + status.String()},
+			Namespace: "avatar",
+			Status:    status.String(),
+			Size:      100,
+			CreatedAt: createdAt,
+		}
+
+		ormService.Flush(fileEntity)
+
+		return fileEntity
+	}
+
+	orphaned := createFile(entity.FileStatusNew, now.Add(-48*time.Hour), 1)
+	quarantined := createFile(entity.FileStatusQuarantined, now.Add(-48*time.Hour), 2)
+	referenced := createFile(entity.FileStatusNew, now.Add(-48*time.Hour), 3)
+	fresh := createFile(entity.FileStatusNew, now, 4)
+	processed := createFile(entity.FileStatusProcessed, now.Add(-48*time.Hour), 5)
+	// created before references were introduced
+	legacy := createFile(entity.FileStatusNew, now.Add(-96*time.Hour), 6)
+
+	referencesSince := now.Add(-72 * time.Hour)
+
+	owner := &entity.DeviceTokenEntity{UserID: 1, Platform: entity.DeviceTokenPlatformAndroid, Token: "token", CreatedAt: now}
+	ormService.Flush(owner)
+
+	assert.Nil(t, file.AddReference(env.Cxt, referenced.ID, owner))
+	assert.Nil(t, file.AddReference(env.Cxt, referenced.ID, owner))
+	assert.NotNil(t, file.AddReference(env.Cxt, quarantined.ID, owner))
+
+	ormService.LoadByID(referenced.ID, referenced)
+	assert.Equal(t, entity.FileStatusNew.String(), referenced.Status)
+
+	report := file.CollectGarbage(env.Cxt, referencesSince, now.Add(-24*time.Hour), true, false)
+	assert.Equal(t, 1, report.Files)
+	assert.Equal(t, int64(100), report.Size)
+
+	report = file.CollectGarbage(env.Cxt, referencesSince, now.Add(-24*time.Hour), true, true)
+	assert.Equal(t, 2, report.Files)
+
+	fakeOSS.On("DeleteObject", oss.Namespace("avatar"), mock.Anything).Return(nil)
+
+	report = file.CollectGarbage(env.Cxt, referencesSince, now.Add(-24*time.Hour), false, false)
+	assert.Equal(t, 1, report.Files)
+	assert.Equal(t, 0, report.Failed)
+
+	fakeOSS.AssertNumberOfCalls(t, "DeleteObject", 1)
+
+	assert.False(t, ormService.LoadByID(orphaned.ID, &entity.FileEntity{}))
+	assert.True(t, ormService.LoadByID(processed.ID, &entity.FileEntity{}))
+	assert.True(t, ormService.LoadByID(legacy.ID, &entity.FileEntity{}))
+	assert.True(t, ormService.LoadByID(quarantined.ID, &entity.FileEntity{}))
+	assert.True(t, ormService.LoadByID(referenced.ID, &entity.FileEntity{}))
+	assert.True(t, ormService.LoadByID(fresh.ID, &entity.FileEntity{}))
+
+	report = file.CollectGarbage(env.Cxt, referencesSince, now.Add(-24*time.Hour), false, true)
+	assert.Equal(t, 1, report.Files)
+	assert.False(t, ormService.LoadByID(quarantined.ID, &entity.FileEntity{}))
+
+	assert.Nil(t, file.RemoveReference(env.Cxt, referenced.ID, owner))
+
+	ormService.LoadByID(referenced.ID, referenced)
+	assert.Equal(t, entity.FileStatusNew.String(), referenced.Status)
+
+	report = file.CollectGarbage(env.Cxt, referencesSince, now.Add(-24*time.Hour), false, false)
+	assert.Equal(t, 1, report.Files)
+	assert.False(t, ormService.LoadByID(referenced.ID, &entity.FileEntity{}))
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/latolukasz/beeorm/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/coretrix/hitrix/datalayer"
+	"github.com/coretrix/hitrix/pkg/binding"
+	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/pkg/queue/consumers"
+	"github.com/coretrix/hitrix/service"
+	mockClockComponent "github.com/coretrix/hitrix/service/component/clock/mocks"
+	"github.com/coretrix/hitrix/service/component/importer"
+	"github.com/coretrix/hitrix/service/component/oss"
+	ossMocks "github.com/coretrix/hitrix/service/component/oss/mocks"
+	registryMocks "github.com/coretrix/hitrix/service/registry/mocks"
+)
+
+type featureFlagRow struct {
+	Name    string `binding:"required"`
+	Enabled bool
+}
+
+type importJobEvent struct {
+	beeorm.Event
+	dto *importer.ImportJobDTO
+}
+
+func (e *importJobEvent) Unserialize(value interface{}) {
+	*value.(*importer.ImportJobDTO) = *e.dto
+}
+
+func TestImportJobConsumer(t *testing.T) {
+	clock := &mockClockComponent.FakeSysClock{}
+	clock.On("Now").Return(time.Unix(1, 0))
+
+	createContextMyApp(t, "server", nil,
+		[]*service.DefinitionGlobal{
+			registryMocks.ServiceProviderMockClock(clock),
+		},
+		nil)
+
+	ormService := service.DI().OrmEngine()
+
+	importerService := importer.NewImporter(binding.NewValidator(), &importer.Definition{
+		ID:      "feature_flags",
+		Mapping: map[string]string{"Name": "Name", "Enabled": "Enabled"},
+		NewRow:  func() interface{} { return &featureFlagRow{} },
+		ToEntity: func(ormService *datalayer.ORM, row interface{}) (beeorm.Entity, error) {
+			featureFlagRow := row.(*featureFlagRow)
+			if featureFlagRow.Name == "skipped" {
+				return nil, nil
+			}
+
+			featureFlagEntity := &entity.FeatureFlagEntity{}
+			if !ormService.SearchOne(beeorm.NewWhere("Name = ?", featureFlagRow.Name), featureFlagEntity) {
+				featureFlagEntity.Name = featureFlagRow.Name
+				featureFlagEntity.CreatedAt = time.Unix(1, 0)
+			}
+
+			featureFlagEntity.Enabled = featureFlagRow.Enabled
+
+			return featureFlagEntity, nil
+		},
+	})
+
+	content := "Name,Enabled\nfirst,true\nsecond,false\nfirst,false\n,true\n"
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	errorReport := ""
+
+	fakeOSS := &ossMocks.FakeOSSClient{}
+	fakeOSS.On("GetObjectSignedURL", oss.Namespace("import"), mock.Anything, mock.Anything).Return(server.URL)
+	fakeOSS.On("UploadObjectFromByte", oss.Namespace("import"), mock.Anything, "csv").Run(func(args mock.Arguments) {
+		errorReport = string(args.Get(1).([]byte))
+	}).Return(entity.FileObject{ID: 2, StorageKey: "import/2.csv"})
+
+	var notifiedURL string
+
+	importJobConsumer := consumers.NewImportJobConsumer(
+		ormService,
+		importerService,
+		fakeOSS,
+		clock,
+		func(_ *datalayer.ORM, _ *entity.ImportJobEntity, errorReportURL string) error {
+			notifiedURL = errorReportURL
+
+			return nil
+		},
+		consumers.ImportJobOptions{Namespace: "import"},
+	)
+
+	fileEntity := &entity.FileEntity{
+		File:      &entity.FileObject{ID: 1, StorageKey: "import/1.csv"},
+		Namespace: "import",
+		Status:    entity.FileStatusProcessed.String(),
+		CreatedAt: time.Unix(1, 0),
+	}
+	ormService.Flush(fileEntity)
+
+	importJobEntity := &entity.ImportJobEntity{
+		UserID:    1,
+		ImportID:  "feature_flags",
+		File:      fileEntity,
+		Format:    importer.FormatCSV.String(),
+		Status:    entity.ImportJobStatusPending.String(),
+		CreatedAt: time.Unix(1, 0),
+	}
+	ormService.Flush(importJobEntity)
+
+	assert.Nil(t, importJobConsumer.Consume(ormService, &importJobEvent{dto: &importer.ImportJobDTO{ImportJobEntityID: importJobEntity.ID}}))
+
+	ormService.LoadByID(importJobEntity.ID, importJobEntity)
+	assert.Equal(t, entity.ImportJobStatusCompleted.String(), importJobEntity.Status)
+	assert.Equal(t, 4, importJobEntity.Rows)
+	assert.Equal(t, 2, importJobEntity.Imported)
+	assert.Equal(t, 2, importJobEntity.Failed)
+	assert.Equal(t, "import/2.csv", importJobEntity.ErrorReport)
+	assert.Equal(t, server.URL, notifiedURL)
+
+	lines := strings.Split(strings.TrimSpace(errorReport), "\n")
+	assert.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[1], ",true,Name:"))
+	assert.True(t, strings.HasPrefix(lines[2], "first,false,Row:"))
+
+	featureFlagEntity := &entity.FeatureFlagEntity{}
+	assert.True(t, ormService.SearchOne(beeorm.NewWhere("Name = ?", "first"), featureFlagEntity))
+	assert.True(t, featureFlagEntity.Enabled)
+
+	content = "Name,Enabled\nfirst,false\n"
+
+	importJobEntity = &entity.ImportJobEntity{
+		UserID:    1,
+		ImportID:  "feature_flags",
+		File:      fileEntity,
+		Format:    importer.FormatCSV.String(),
+		Status:    entity.ImportJobStatusPending.String(),
+		CreatedAt: time.Unix(1, 0),
+	}
+	ormService.Flush(importJobEntity)
+
+	assert.Nil(t, importJobConsumer.Consume(ormService, &importJobEvent{dto: &importer.ImportJobDTO{ImportJobEntityID: importJobEntity.ID}}))
+	assert.Equal(t, "", notifiedURL)
+
+	ormService.LoadByID(featureFlagEntity.ID, featureFlagEntity)
+	assert.False(t, featureFlagEntity.Enabled)
+
+	content = "Name,Enabled\nskipped,true\n"
+
+	importJobEntity = &entity.ImportJobEntity{
+		UserID:    1,
+		ImportID:  "feature_flags",
+		File:      fileEntity,
+		Format:    importer.FormatCSV.String(),
+		Status:    entity.ImportJobStatusPending.String(),
+		CreatedAt: time.Unix(1, 0),
+	}
+	ormService.Flush(importJobEntity)
+
+	assert.Nil(t, importJobConsumer.Consume(ormService, &importJobEvent{dto: &importer.ImportJobDTO{ImportJobEntityID: importJobEntity.ID}}))
+
+	ormService.LoadByID(importJobEntity.ID, importJobEntity)
+	assert.Equal(t, 0, importJobEntity.Imported)
+	assert.Equal(t, 1, importJobEntity.Failed)
+	assert.Contains(t, errorReport, importer.ErrNoEntity.Error())
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/coretrix/hitrix/pkg/middleware"
+	"github.com/coretrix/hitrix/service"
+	mockClockComponent "github.com/coretrix/hitrix/service/component/clock/mocks"
+	"github.com/coretrix/hitrix/service/component/config"
+	"github.com/coretrix/hitrix/service/component/oss"
+	registryMocks "github.com/coretrix/hitrix/service/registry/mocks"
+)
+
+func TestOSSPutObjectAction(t *testing.T) {
+	folder := t.TempDir()
+	root := filepath.Join(folder, "storage")
+
+	assert.Nil(t, os.MkdirAll(filepath.Join(folder, "app"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(folder, "app", "config.yaml"), []byte(""), 0600))
+	assert.Nil(t, os.WriteFile(filepath.Join(folder, "hitrix.yaml"), []byte(fmt.Sprintf(`
+oss:
+  local:
+    root: %s
+    url: http://localhost/v1/oss/
+    secret: secret
+  buckets:
+    public:
+      name: public-bucket
+    private:
+      name: private-bucket
+`, root)), 0600))
+
+	configService, err := config.NewConfig("app", "test", folder)
+	assert.Nil(t, err)
+
+	now := time.Unix(1700000000, 0)
+
+	clock := &mockClockComponent.FakeSysClock{}
+	clock.On("Now").Return(now)
+
+	localOSS, err := oss.NewLocalOSS(configService, clock, oss.Namespaces{"avatar": oss.BucketPublic})
+	assert.Nil(t, err)
+
+	env := createContextMyApp(t, "server", nil,
+		[]*service.DefinitionGlobal{
+			registryMocks.ServiceProviderMockClock(clock),
+			registryMocks.ServiceProviderMockOSS(localOSS),
+		},
+		nil)
+
+	middleware.OSSRouter(env.GinEngine)
+
+	sendPutObject := func(query url.Values) int {
+		request := httptest.NewRequest(
+			http.MethodPut,
+			"/v1/oss/public-bucket/avatar/1.txt?"+query.Encode(),
+			bytes.NewReader([]byte("content")),
+		)
+		request.Header.Set("Content-Type", "text/plain")
+
+		recorder := httptest.NewRecorder()
+		env.GinEngine.ServeHTTP(recorder, request)
+
+		return recorder.Code
+	}
+
+	// HMAC-SHA256 of "public-bucket/avatar/1.txt:PUT:1700003600:100:text/plain" with "secret" key
+	query := url.Values{
+		"expires":      {"1700003600"},
+		"max_size":     {"100"},
+		"content_type": {"text/plain"},
+		"signature":    {"a4d2f1ceb7377052d71f70711e485a7ff9c93354054940641be2e0a509c98427"},
+	}
+
+	assert.Equal(t, http.StatusOK, sendPutObject(query))
+	assert.FileExists(t, filepath.Join(root, "public-bucket", "avatar", "1.txt"))
+
+	query.Set("max_size", "1000")
+	assert.Equal(t, http.StatusForbidden, sendPutObject(query))
+
+	query.Set("max_size", "100")
+	query.Set("signature", "0000")
+	assert.Equal(t, http.StatusForbidden, sendPutObject(query))
+
+	// signed url which expired one hour ago
+	query.Set("expires", "1699996400")
+	query.Set("signature", "1ee3912f500e15f8fcdf79ffa36848f5db9bb9ecb1d4f62abf4dcc7356e55768")
+	assert.Equal(t, http.StatusForbidden, sendPutObject(query))
+}
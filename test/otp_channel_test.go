@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/service"
+	mockClockComponent "github.com/coretrix/hitrix/service/component/clock/mocks"
+	fakesms "github.com/coretrix/hitrix/service/component/fake_sms"
+	"github.com/coretrix/hitrix/service/component/generator"
+	"github.com/coretrix/hitrix/service/component/otp"
+	mockClockRegistry "github.com/coretrix/hitrix/service/registry/mocks"
+)
+
+func TestOTPSendWhatsAppWithFallbackToSMS(t *testing.T) {
+	clock := &mockClockComponent.FakeSysClock{}
+	clock.On("Now").Return(time.Unix(1, 0))
+
+	createContextMyApp(t, "server", nil,
+		[]*service.DefinitionGlobal{
+			mockClockRegistry.ServiceProviderMockClock(clock),
+		},
+		nil)
+
+	ormService := service.DI().OrmEngine()
+
+	fake := fakesms.NewFake(fakesms.NewMemoryStore(), service.DI().Clock(), &generator.SimpleGenerator{}, 0)
+
+	otpService := otp.NewOTP(service.DI().Clock(), false, nil, fake)
+	otpService.ChannelRouter = otp.NewChannelRouter(map[string]string{"+91": otp.ChannelWhatsApp})
+
+	phone := &otp.Phone{Number: "+919876543210"}
+
+	code, err := otpService.Send(ormService, phone, "")
+	assert.Nil(t, err)
+	assert.Equal(t, fakesms.TypeWhatsApp, fake.LastMessageTo(phone.Number).Type)
+	assert.Equal(t, code, fake.LastMessageTo(phone.Number).Code)
+
+	fake.FailNextSends(phone.Number, 1)
+
+	code, err = otpService.Send(ormService, phone, otp.ChannelWhatsApp)
+	assert.Nil(t, err)
+	assert.Equal(t, fakesms.TypeOTP, fake.LastMessageTo(phone.Number).Type)
+	assert.Equal(t, code, fake.LastMessageTo(phone.Number).Code)
+
+	otpTrackerEntity := &entity.OTPTrackerEntity{}
+	ormService.LoadByID(2, otpTrackerEntity)
+	assert.Equal(t, entity.OTPTrackerTypeWhatsApp, otpTrackerEntity.Type)
+	assert.Equal(t, entity.OTPTrackerGatewaySendStatusGatewayError, otpTrackerEntity.GatewaySendStatus)
+
+	otpTrackerEntity = &entity.OTPTrackerEntity{}
+	ormService.LoadByID(3, otpTrackerEntity)
+	assert.Equal(t, entity.OTPTrackerTypeSMS, otpTrackerEntity.Type)
+	assert.Equal(t, entity.OTPTrackerGatewaySendStatusSent, otpTrackerEntity.GatewaySendStatus)
+
+	requestValid, codeValid, err := otpService.VerifyOTP(ormService, phone, code)
+	assert.Nil(t, err)
+	assert.True(t, requestValid)
+	assert.True(t, codeValid)
+}
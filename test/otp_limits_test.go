@@ -0,0 +1,193 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/service"
+	mockClockComponent "github.com/coretrix/hitrix/service/component/clock/mocks"
+	"github.com/coretrix/hitrix/service/component/otp"
+	"github.com/coretrix/hitrix/service/component/otp/mocks"
+	mockClockRegistry "github.com/coretrix/hitrix/service/registry/mocks"
+)
+
+func TestOTPVerifyLockout(t *testing.T) {
+	clock := &mockClockComponent.FakeSysClock{}
+	clock.On("Now").Return(time.Unix(1, 0))
+
+	createContextMyApp(t, "server", nil,
+		[]*service.DefinitionGlobal{
+			mockClockRegistry.ServiceProviderMockClock(clock),
+		},
+		nil)
+
+	ormService := service.DI().OrmEngine()
+
+	phone := &otp.Phone{Number: "+359888111222"}
+
+	gateway := &mocks.FakeGateway{}
+	gateway.On("GetName").Return("g")
+	gateway.On("GetPhonePrefixes").Return([]string(nil))
+	gateway.On("GetCode").Return("12345")
+	gateway.On("SendOTP", phone, "12345").Return("request", "response", nil)
+	gateway.On("VerifyOTP", phone, "11111", "12345").Return("", "", true, false, nil)
+
+	otpService := otp.NewOTP(service.DI().Clock(), false, &otp.Limits{MaxVerifyAttempts: 2, LockoutDuration: time.Minute}, gateway)
+
+	_, err := otpService.SendSMS(ormService, phone)
+	assert.Nil(t, err)
+
+	requestValid, codeValid, err := otpService.VerifyOTP(ormService, phone, "11111")
+	assert.Nil(t, err)
+	assert.True(t, requestValid)
+	assert.False(t, codeValid)
+
+	_, _, err = otpService.VerifyOTP(ormService, phone, "11111")
+	assert.ErrorIs(t, err, otp.ErrTooManyAttempts)
+
+	_, _, err = otpService.VerifyOTP(ormService, phone, "12345")
+	assert.ErrorIs(t, err, otp.ErrLocked)
+
+	_, err = otpService.SendSMS(ormService, phone)
+	assert.ErrorIs(t, err, otp.ErrLocked)
+
+	otpTrackerEntity := &entity.OTPTrackerEntity{}
+	ormService.LoadByID(1, otpTrackerEntity)
+
+	assert.Equal(t, 2, otpTrackerEntity.VerifyAttempts)
+	assert.Equal(t, entity.OTPTrackerGatewayVerifyStatusTooManyAttempts, otpTrackerEntity.GatewayVerifyStatus)
+}
+
+func TestOTPSendLimits(t *testing.T) {
+	clock := &mockClockComponent.FakeSysClock{}
+	clock.On("Now").Return(time.Unix(1, 0))
+
+	createContextMyApp(t, "server", nil,
+		[]*service.DefinitionGlobal{
+			mockClockRegistry.ServiceProviderMockClock(clock),
+		},
+		nil)
+
+	ormService := service.DI().OrmEngine()
+
+	phone := &otp.Phone{Number: "+359888111222", IP: "127.0.0.1"}
+	otherPhone := &otp.Phone{Number: "+359888111333", IP: "127.0.0.1"}
+
+	gateway := &mocks.FakeGateway{}
+	gateway.On("GetName").Return("g")
+	gateway.On("GetPhonePrefixes").Return([]string(nil))
+	gateway.On("GetCode").Return("12345")
+	gateway.On("SendOTP", phone, "12345").Return("request", "response", nil)
+	gateway.On("SendOTP", otherPhone, "12345").Return("request", "response", nil)
+
+	otpService := otp.NewOTP(service.DI().Clock(), false, &otp.Limits{ResendCooldown: time.Minute, DailyLimitPerIP: 2}, gateway)
+
+	_, err := otpService.SendSMS(ormService, phone)
+	assert.Nil(t, err)
+
+	_, err = otpService.SendSMS(ormService, phone)
+	assert.ErrorIs(t, err, otp.ErrResendCooldown)
+
+	_, err = otpService.SendSMS(ormService, otherPhone)
+	assert.Nil(t, err)
+
+	ormService.GetRedis().FlushAll()
+
+	otpService.Limits.DailyLimitPerIP = 1
+	_, err = otpService.SendSMS(ormService, otherPhone)
+	assert.Nil(t, err)
+
+	_, err = otpService.SendSMS(ormService, phone)
+	assert.ErrorIs(t, err, otp.ErrDailyLimitPerIP)
+}
+
+func TestOTPSendLimitsGivenBackOnFailure(t *testing.T) {
+	clock := &mockClockComponent.FakeSysClock{}
+	clock.On("Now").Return(time.Unix(1, 0))
+
+	createContextMyApp(t, "server", nil,
+		[]*service.DefinitionGlobal{
+			mockClockRegistry.ServiceProviderMockClock(clock),
+		},
+		nil)
+
+	ormService := service.DI().OrmEngine()
+
+	phone := &otp.Phone{Number: "+359888111222", IP: "127.0.0.1"}
+
+	gateway := &mocks.FakeGateway{}
+	gateway.On("GetName").Return("g")
+	gateway.On("GetPhonePrefixes").Return([]string(nil))
+	gateway.On("GetCode").Return("12345")
+	gateway.On("SendOTP", phone, "12345").Return("request", "", errors.New("gateway down")).Twice()
+	gateway.On("SendOTP", phone, "12345").Return("request", "response", nil)
+
+	otpService := otp.NewOTP(
+		service.DI().Clock(),
+		false,
+		&otp.Limits{ResendCooldown: time.Minute, DailyLimitPerPhone: 1, DailyLimitPerIP: 1},
+		gateway,
+	)
+
+	_, err := otpService.SendSMS(ormService, phone)
+	assert.EqualError(t, err, "gateway down")
+
+	_, err = otpService.SendSMS(ormService, phone)
+	assert.EqualError(t, err, "gateway down")
+
+	_, err = otpService.SendSMS(ormService, phone)
+	assert.Nil(t, err)
+
+	_, err = otpService.SendSMS(ormService, phone)
+	assert.ErrorIs(t, err, otp.ErrResendCooldown)
+
+	otpService.Limits.ResendCooldown = 0
+
+	_, err = otpService.SendSMS(ormService, phone)
+	assert.ErrorIs(t, err, otp.ErrDailyLimitPerPhone)
+}
+
+func TestOTPSendLimitsKeptWhenRetryQueued(t *testing.T) {
+	clock := &mockClockComponent.FakeSysClock{}
+	clock.On("Now").Return(time.Unix(1, 0))
+
+	createContextMyApp(t, "server", nil,
+		[]*service.DefinitionGlobal{
+			mockClockRegistry.ServiceProviderMockClock(clock),
+		},
+		nil)
+
+	ormService := service.DI().OrmEngine()
+
+	phone := &otp.Phone{Number: "+359888111222", IP: "127.0.0.1"}
+
+	gateway := &mocks.FakeGateway{}
+	gateway.On("GetName").Return("g")
+	gateway.On("GetPhonePrefixes").Return([]string(nil))
+	gateway.On("GetCode").Return("12345")
+	gateway.On("SendOTP", phone, "12345").Return("request", "", errors.New("gateway down")).Once()
+
+	otpService := otp.NewOTP(
+		service.DI().Clock(),
+		true,
+		&otp.Limits{ResendCooldown: time.Minute, DailyLimitPerPhone: 1},
+		gateway,
+	)
+
+	_, err := otpService.SendSMS(ormService, phone)
+	assert.EqualError(t, err, "gateway down")
+
+	_, err = otpService.SendSMS(ormService, phone)
+	assert.ErrorIs(t, err, otp.ErrResendCooldown)
+
+	otpService.Limits.ResendCooldown = 0
+
+	_, err = otpService.SendSMS(ormService, phone)
+	assert.ErrorIs(t, err, otp.ErrDailyLimitPerPhone)
+
+	gateway.AssertExpectations(t)
+}
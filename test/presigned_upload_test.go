@@ -0,0 +1,100 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/coretrix/hitrix/pkg/dto/file"
+	"github.com/coretrix/hitrix/pkg/entity"
+	fileModel "github.com/coretrix/hitrix/pkg/model/file"
+	"github.com/coretrix/hitrix/service"
+	mockClockComponent "github.com/coretrix/hitrix/service/component/clock/mocks"
+	"github.com/coretrix/hitrix/service/component/oss"
+	ossMocks "github.com/coretrix/hitrix/service/component/oss/mocks"
+	registryMocks "github.com/coretrix/hitrix/service/registry/mocks"
+)
+
+func TestPresignedUpload(t *testing.T) {
+	clock := &mockClockComponent.FakeSysClock{}
+	clock.On("Now").Return(time.Unix(1, 0))
+
+	namespace := oss.Namespace("video")
+
+	fakeOSS := &ossMocks.FakeOSSClient{}
+	fakeOSS.On("GetNamespaceBucketConfig", namespace).Return(&oss.BucketConfig{Type: oss.BucketPublic})
+	fakeOSS.On("GetObjectURL", namespace, mock.Anything).Return("https://cdn/video/1.mp4")
+	fakeOSS.On("DeleteObject", namespace, mock.Anything).Return(nil)
+
+	env := createContextMyApp(t, "server", nil,
+		[]*service.DefinitionGlobal{
+			registryMocks.ServiceProviderMockClock(clock),
+			registryMocks.ServiceProviderMockOSS(fakeOSS),
+		},
+		nil)
+
+	ormService := service.DI().OrmEngine()
+
+	createPresignedUpload := func(id uint64) *file.PresignedUpload {
+		fakeOSS.On("CreatePresignedUpload", namespace, "video/mp4", int64(100)).Return(&oss.PresignedUpload{
+			Object: entity.FileObject{ID: id, StorageKey: "video/1.mp4"},
+			Method: http.MethodPut,
+			URL:    "https://bucket/video/1.mp4",
+		}).Once()
+
+		presignedUpload, err := fileModel.CreatePresignedUpload(env.Cxt, 1, &file.RequestDTOPresignedUpload{
+			Namespace:   namespace.String(),
+			ContentType: "video/mp4",
+			MaxSize:     100,
+		})
+		assert.Nil(t, err)
+
+		return presignedUpload
+	}
+
+	presignedUpload := createPresignedUpload(1)
+	assert.Equal(t, http.MethodPut, presignedUpload.Method)
+	assert.Equal(t, "https://bucket/video/1.mp4", presignedUpload.URL)
+
+	fileEntity := &entity.FileEntity{}
+	assert.True(t, ormService.LoadByID(presignedUpload.ID, fileEntity))
+	assert.Equal(t, entity.FileStatusPending.String(), fileEntity.Status)
+
+	fakeOSS.On("GetObjectAttributes", namespace, mock.Anything).Return(nil, oss.ErrObjectNotFound).Once()
+
+	_, err := fileModel.ConfirmUpload(env.Cxt, 1, presignedUpload.ID)
+	assert.NotNil(t, err)
+
+	_, err = fileModel.ConfirmUpload(env.Cxt, 2, presignedUpload.ID)
+	assert.NotNil(t, err)
+
+	fakeOSS.On("GetObjectAttributes", namespace, mock.Anything).Return(&oss.ObjectAttributes{Size: 50, ContentType: "video/mp4"}, nil).Once()
+
+	confirmedFile, err := fileModel.ConfirmUpload(env.Cxt, 1, presignedUpload.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://cdn/video/1.mp4", confirmedFile.URL)
+
+	ormService.LoadByID(presignedUpload.ID, fileEntity)
+	assert.Equal(t, entity.FileStatusNew.String(), fileEntity.Status)
+	assert.Equal(t, int64(50), fileEntity.Size)
+
+	_, err = fileModel.ConfirmUpload(env.Cxt, 1, presignedUpload.ID)
+	assert.NotNil(t, err)
+
+	presignedUpload = createPresignedUpload(2)
+
+	fakeOSS.On("GetObjectAttributes", namespace, mock.Anything).Return(&oss.ObjectAttributes{Size: 500, ContentType: "video/mp4"}, nil).Once()
+
+	_, err = fileModel.ConfirmUpload(env.Cxt, 1, presignedUpload.ID)
+	assert.NotNil(t, err)
+
+	fileEntity = &entity.FileEntity{}
+	ormService.LoadByID(presignedUpload.ID, fileEntity)
+	assert.Equal(t, entity.FileStatusQuarantined.String(), fileEntity.Status)
+	assert.Equal(t, oss.ErrObjectTooLarge.Error(), fileEntity.ScanResult)
+
+	fakeOSS.AssertNumberOfCalls(t, "DeleteObject", 1)
+}
@@ -7,7 +7,9 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/coretrix/hitrix/example/entity"
+	entityHitrix "github.com/coretrix/hitrix/pkg/entity"
 	"github.com/coretrix/hitrix/service"
+	"github.com/coretrix/hitrix/service/component/crud"
 )
 
 func TestRedisSearch(t *testing.T) {
@@ -28,3 +30,13 @@ func TestRedisSearch(t *testing.T) {
 
 	assert.True(t, found)
 }
+
+func TestRedisSearchFacetsError(t *testing.T) {
+	createContextMyApp(t, "server", nil, nil, nil)
+
+	cols := []*crud.Column{{Key: "Resource", FilterType: crud.SelectTypeStringString, Searchable: true, Sortable: true}}
+
+	facets, err := (&crud.Crud{}).RedisSearchFacets(service.DI().OrmEngine(), &entityHitrix.SavedViewEntity{}, cols, crud.SearchParams{})
+	assert.Nil(t, facets)
+	assert.ErrorContains(t, err, "entity entity.SavedViewEntity is not searchable")
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/xorcare/pointer"
+
+	"github.com/coretrix/hitrix/pkg/controller"
+	"github.com/coretrix/hitrix/pkg/dto/savedview"
+	"github.com/coretrix/hitrix/pkg/middleware"
+	savedViewModel "github.com/coretrix/hitrix/pkg/model/savedview"
+	savedViewView "github.com/coretrix/hitrix/pkg/view/savedview"
+	"github.com/coretrix/hitrix/service"
+	"github.com/coretrix/hitrix/service/component/clock/mocks"
+	"github.com/coretrix/hitrix/service/component/crud"
+	registryMocks "github.com/coretrix/hitrix/service/registry/mocks"
+)
+
+func savedViewColumns() []*crud.Column {
+	return []*crud.Column{
+		{Key: "ID", FilterType: crud.InputTypeNumber, Searchable: true, Sortable: true, Visible: true},
+		{Key: "Name", FilterType: crud.InputTypeString, Searchable: true, Sortable: true, Visible: true},
+		{Key: "Email", FilterType: crud.InputTypeString, Searchable: true, Visible: true},
+	}
+}
+
+func TestSavedView(t *testing.T) {
+	fakeClock := &mocks.FakeSysClock{}
+	fakeClock.On("Now").Return(time.Unix(1, 0))
+
+	env := createContextMyApp(t, "server", nil, []*service.DefinitionGlobal{registryMocks.ServiceProviderMockClock(fakeClock)}, nil)
+
+	_, err := savedViewModel.Save(env.Cxt, 1, savedViewColumns(), &savedview.RequestDTOSaveView{
+		Resource: "users",
+		Name:     "invalid",
+		Search:   map[string]interface{}{"Missing": "john"},
+	})
+	assert.NotNil(t, err)
+
+	_, err = savedViewModel.Save(env.Cxt, 1, savedViewColumns(), &savedview.RequestDTOSaveView{
+		Resource: "users",
+		Name:     "invalid",
+		Columns:  []string{"Missing"},
+	})
+	assert.NotNil(t, err)
+
+	view, err := savedViewModel.Save(env.Cxt, 1, savedViewColumns(), &savedview.RequestDTOSaveView{
+		Resource: "users",
+		Name:     "johns",
+		PageSize: pointer.Int(50),
+		Search:   map[string]interface{}{"Name": "john", "Email": "example.com"},
+		Sort:     map[string]interface{}{"Name": "asc"},
+		Columns:  []string{"Email", "Name"},
+	})
+	assert.Nil(t, err)
+	assert.True(t, view.Owner)
+	assert.Equal(t, "john", view.Request.Search["Name"])
+
+	assert.Len(t, savedViewView.List(env.Cxt, 1, "users"), 1)
+	assert.Len(t, savedViewView.List(env.Cxt, 2, "users"), 0)
+
+	_, err = savedViewView.Apply(env.Cxt, 2, view.ID, "users", savedViewColumns())
+	assert.NotNil(t, err)
+
+	assert.NotNil(t, savedViewModel.Share(env.Cxt, 2, view.ID, true))
+	assert.Nil(t, savedViewModel.Share(env.Cxt, 1, view.ID, true))
+
+	sharedViews := savedViewView.List(env.Cxt, 2, "users")
+	assert.Len(t, sharedViews, 1)
+	assert.False(t, sharedViews[0].Owner)
+
+	columns := savedViewColumns()[:2]
+
+	applied, err := savedViewView.Apply(env.Cxt, 2, view.ID, "users", columns)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"Name": "john"}, applied.View.Request.Search)
+	assert.Equal(t, []string{"Name"}, applied.View.Columns)
+	assert.False(t, applied.Columns[0].Visible)
+	assert.True(t, applied.Columns[1].Visible)
+	assert.True(t, columns[0].Visible)
+
+	_, err = savedViewView.Apply(env.Cxt, 1, view.ID, "orders", savedViewColumns())
+	assert.NotNil(t, err)
+
+	assert.NotNil(t, savedViewModel.Delete(env.Cxt, 2, view.ID))
+	assert.Nil(t, savedViewModel.Delete(env.Cxt, 1, view.ID))
+	assert.Len(t, savedViewView.List(env.Cxt, 1, "users"), 0)
+}
+
+func TestSavedViewRouterRequiresFuncs(t *testing.T) {
+	assert.PanicsWithValue(t, "SavedViewController.GetUserIDFunc is required", func() {
+		middleware.SavedViewRouter(gin.New(), &controller.SavedViewController{})
+	})
+
+	assert.PanicsWithValue(t, "SavedViewController.GetColumnsFunc is required", func() {
+		middleware.SavedViewRouter(gin.New(), &controller.SavedViewController{GetUserIDFunc: func(c *gin.Context) uint64 {
+			return 1
+		}})
+	})
+}
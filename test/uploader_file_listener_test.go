@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tus/tusd/pkg/filestore"
+	tusd "github.com/tus/tusd/pkg/handler"
+
+	"github.com/coretrix/hitrix/pkg/entity"
+	"github.com/coretrix/hitrix/pkg/model/file"
+	"github.com/coretrix/hitrix/service"
+	mockClockComponent "github.com/coretrix/hitrix/service/component/clock/mocks"
+	filevalidator "github.com/coretrix/hitrix/service/component/file_validator"
+	"github.com/coretrix/hitrix/service/component/oss"
+	ossMocks "github.com/coretrix/hitrix/service/component/oss/mocks"
+	"github.com/coretrix/hitrix/service/component/uploader"
+	registryMocks "github.com/coretrix/hitrix/service/registry/mocks"
+)
+
+func TestUploaderFileListener(t *testing.T) {
+	clock := &mockClockComponent.FakeSysClock{}
+	clock.On("Now").Return(time.Unix(1, 0))
+
+	createContextMyApp(t, "server", nil,
+		[]*service.DefinitionGlobal{
+			registryMocks.ServiceProviderMockClock(clock),
+		},
+		nil)
+
+	ctx := context.Background()
+	content := []byte("uploaded content")
+
+	composer := tusd.NewStoreComposer()
+	store := filestore.New(t.TempDir())
+	store.UseIn(composer)
+
+	uploaderService := uploader.NewTUSDUploader(tusd.Config{BasePath: "/files/", StoreComposer: composer}, "public").(uploader.UploadStore)
+
+	upload, err := store.NewUpload(ctx, tusd.FileInfo{
+		Size:     int64(len(content)),
+		MetaData: tusd.MetaData{"namespace": "avatar", "filename": "document.txt", "uploader_id": "7"},
+	})
+	assert.Nil(t, err)
+
+	_, err = upload.WriteChunk(ctx, 0, bytes.NewReader(content))
+	assert.Nil(t, err)
+
+	info, err := upload.GetInfo(ctx)
+	assert.Nil(t, err)
+
+	fileObject := entity.FileObject{ID: 1, StorageKey: "avatar/1.txt"}
+
+	fakeOSS := &ossMocks.FakeOSSClient{}
+	fakeOSS.On("UploadObjectFromByte", oss.Namespace("avatar"), content, "txt").Return(fileObject)
+	fakeOSS.On("DeleteObject", oss.Namespace("avatar"), &fileObject).Return(nil)
+
+	fileListener := uploader.NewFileListener(service.DI().OrmEngine(), fakeOSS, clock, nil, nil, nil)
+
+	fileEntity, err := fileListener.HandleCompletedUpload(ctx, uploaderService, tusd.HookEvent{Upload: info})
+	assert.Nil(t, err)
+	assert.Equal(t, info.ID, fileEntity.UploadID)
+	assert.Equal(t, "avatar", fileEntity.Namespace)
+	assert.Equal(t, entity.FileStatusNew.String(), fileEntity.Status)
+	assert.Equal(t, uint64(7), fileEntity.UploaderID)
+	assert.Equal(t, int64(len(content)), fileEntity.Size)
+	assert.Equal(t, fileObject.StorageKey, fileEntity.File.StorageKey)
+
+	_, err = uploaderService.GetUploadReader(ctx, info.ID)
+	assert.NotNil(t, err)
+
+	sameFileEntity, err := fileListener.HandleCompletedUpload(ctx, uploaderService, tusd.HookEvent{Upload: info})
+	assert.Nil(t, err)
+	assert.Equal(t, fileEntity.ID, sameFileEntity.ID)
+
+	assert.Nil(t, fileListener.HandleTerminatedUpload(tusd.HookEvent{Upload: info}))
+	assert.False(t, service.DI().OrmEngine().LoadByID(fileEntity.ID, &entity.FileEntity{}))
+
+	fakeOSS.AssertNumberOfCalls(t, "UploadObjectFromByte", 1)
+	fakeOSS.AssertNumberOfCalls(t, "DeleteObject", 1)
+
+	upload, err = store.NewUpload(ctx, tusd.FileInfo{
+		Size:     int64(len(content)),
+		MetaData: tusd.MetaData{"namespace": "avatar", "filename": "document"},
+	})
+	assert.Nil(t, err)
+
+	_, err = upload.WriteChunk(ctx, 0, bytes.NewReader(content))
+	assert.Nil(t, err)
+
+	info, err = upload.GetInfo(ctx)
+	assert.Nil(t, err)
+
+	fakeOSS.On("UploadObjectFromByte", oss.Namespace("avatar"), content, "").Return(fileObject)
+
+	validator := filevalidator.NewValidator(filevalidator.Policies{}, nil)
+	fileListener = uploader.NewFileListener(service.DI().OrmEngine(), fakeOSS, clock, nil, validator, nil)
+
+	fileEntity, err = fileListener.HandleCompletedUpload(ctx, uploaderService, tusd.HookEvent{Upload: info})
+	assert.Nil(t, err)
+	assert.Equal(t, entity.FileStatusProcessed.String(), fileEntity.Status)
+	assert.Equal(t, "application/octet-stream", fileEntity.MimeType)
+
+	owner := &entity.DeviceTokenEntity{UserID: 1, Platform: entity.DeviceTokenPlatformAndroid, Token: "token", CreatedAt: time.Unix(1, 0)}
+	service.DI().OrmEngine().Flush(owner)
+
+	assert.Nil(t, file.AddReference(ctx, fileEntity.ID, owner))
+
+	assert.Nil(t, fileListener.HandleTerminatedUpload(tusd.HookEvent{Upload: info}))
+	assert.True(t, service.DI().OrmEngine().LoadByID(fileEntity.ID, &entity.FileEntity{}))
+
+	fakeOSS.AssertNumberOfCalls(t, "DeleteObject", 1)
+}